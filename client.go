@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"net/http/httputil"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -63,11 +66,30 @@ type (
 		// Format represents the format used to render the JWT.
 		// The default is "Bearer %s"
 		Format string
+		// Flag is the name of the command line flag used to set the JWT.
+		// The default is "jwt"
+		Flag string
 
 		// token stores the actual JWT.
 		token string
 	}
 
+	// APIKeySigner implements API key auth. The key is sent in a request header or query string
+	// parameter.
+	APIKeySigner struct {
+		// In is the location of the key, either "header" or "query".
+		// The default is "header"
+		In string
+		// Name is the name of the header or query string parameter which contains the key.
+		// The default is "X-API-Key"
+		Name string
+		// Flag is the name of the command line flag used to set the key.
+		// The default is "key"
+		Flag string
+		// Key is the API key.
+		Key string
+	}
+
 	// OAuth2Signer enables the use of OAuth2 refresh tokens. It takes care of creating access
 	// tokens given a refresh token and a refresh URL as defined in RFC 6749.
 	// Note that this signer does not concern itself with generating the initial refresh token,
@@ -128,6 +150,37 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 	return resp, err
 }
 
+// DecodeErrorDocument returns the error document contained in the given response body if the
+// response content type is the goa error media type (see ErrorDocument), nil otherwise.
+func DecodeErrorDocument(resp *http.Response, body []byte) *ErrorDocument {
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mediaType != ErrorMediaIdentifier {
+		return nil
+	}
+	var doc ErrorDocument
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil
+	}
+	return &doc
+}
+
+// WriteMultipartFile adds a file part with the given field name to the multipart writer and copies
+// the content of the file at the given path into it. The generated clients use WriteMultipartFile
+// to upload the files of multipart payloads.
+func WriteMultipartFile(w *multipart.Writer, fieldName, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	part, err := w.CreateFormFile(fieldName, filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, f)
+	return err
+}
+
 // Sign adds the basic auth header to the request.
 func (s *BasicSigner) Sign(req *http.Request) error {
 	if s.Username != "" && s.Password != "" {
@@ -152,17 +205,55 @@ func (s *JWTSigner) Sign(req *http.Request) error {
 	if format == "" {
 		format = "Bearer %s"
 	}
-	req.Header.Set(header, fmt.Sprintf(format, s.token))
+	if s.token != "" {
+		req.Header.Set(header, fmt.Sprintf(format, s.token))
+	}
 	return nil
 }
 
-// RegisterFlags adds the "--jwt" flag to the client tool.
+// RegisterFlags adds the "--jwt" flag or the flag named after Flag to the client tool.
 func (s *JWTSigner) RegisterFlags(app *kingpin.Application) {
-	app.Flag("jwt", "JSON web token").StringVar(&s.token)
+	flag := s.Flag
+	if flag == "" {
+		flag = "jwt"
+	}
+	app.Flag(flag, "JSON web token").StringVar(&s.token)
 }
 
-// Sign refreshes the access token if needed and adds the OAuth header.
+// Sign adds the API key header or query string parameter.
+func (s *APIKeySigner) Sign(req *http.Request) error {
+	if s.Key == "" {
+		return nil
+	}
+	name := s.Name
+	if name == "" {
+		name = "X-API-Key"
+	}
+	if s.In == "query" {
+		q := req.URL.Query()
+		q.Set(name, s.Key)
+		req.URL.RawQuery = q.Encode()
+	} else {
+		req.Header.Set(name, s.Key)
+	}
+	return nil
+}
+
+// RegisterFlags adds the "--key" flag or the flag named after Flag to the client tool.
+func (s *APIKeySigner) RegisterFlags(app *kingpin.Application) {
+	flag := s.Flag
+	if flag == "" {
+		flag = "key"
+	}
+	app.Flag(flag, "API key").StringVar(&s.Key)
+}
+
+// Sign refreshes the access token if needed and adds the OAuth header. Sign does nothing if no
+// refresh token is set.
 func (s *OAuth2Signer) Sign(req *http.Request) error {
+	if s.RefreshToken == "" && s.accessToken == "" {
+		return nil
+	}
 	if s.expiresAt.Before(time.Now()) {
 		if err := s.Refresh(); err != nil {
 			return fmt.Errorf("failed to refresh OAuth token: %s", err)
@@ -1,8 +1,10 @@
 package goa
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
@@ -31,6 +33,8 @@ const (
 	respWrittenKey
 	respStatusKey
 	respLenKey
+	encodersKey
+	bodyKey
 )
 
 // NewContext builds a goa context from the given context.Context and request state.
@@ -81,7 +85,7 @@ func (ctx *Context) ResponseWritten() bool {
 }
 
 // ResponseStatus returns the response status if it was set via one of the context response
-// methods (Respond, JSON, BadRequest, Bug), 0 otherwise.
+// methods (Respond, JSON, Send, BadRequest, Bug), 0 otherwise.
 func (ctx *Context) ResponseStatus() int {
 	if is := ctx.Value(respStatusKey); is != nil {
 		return is.(int)
@@ -90,7 +94,7 @@ func (ctx *Context) ResponseStatus() int {
 }
 
 // ResponseLength returns the response body length in bytes if the response was written to the
-// context via one of the response methods (Respond, JSON, BadRequest, Bug), 0 otherwise.
+// context via one of the response methods (Respond, JSON, Send, BadRequest, Bug), 0 otherwise.
 func (ctx *Context) ResponseLength() int {
 	if is := ctx.Value(respLenKey); is != nil {
 		return is.(int)
@@ -131,7 +135,22 @@ func (ctx *Context) GetMany(name string) []string {
 	return query[name]
 }
 
+// Body returns a reader for the raw request body. Reading past the maximum body size configured
+// for the action (see Service.SetMaxBodySize) returns an error and causes the request to get a
+// response with status code 413. Actions that declare a raw body in their design read the request
+// body using this reader, the body of other actions has already been consumed to build the payload.
+func (ctx *Context) Body() io.Reader {
+	if l, ok := ctx.Value(bodyKey).(*bodyLimiter); ok {
+		return l
+	}
+	if r := ctx.Request(); r != nil && r.Body != nil {
+		return r.Body
+	}
+	return bytes.NewReader(nil)
+}
+
 // Payload returns the deserialized request body or nil if body is empty.
+// The payload of multipart/form-data requests is a *multipart.Form.
 func (ctx *Context) Payload() interface{} {
 	return ctx.Value(payloadKey)
 }
@@ -156,9 +175,173 @@ func (ctx *Context) JSON(code int, body interface{}) error {
 	return ctx.Respond(code, js)
 }
 
-// BadRequest sends a HTTP response with status code 400 and the given error as body.
+// Send serializes the given body using the encoder that best matches the request Accept header and
+// sends a HTTP response with the given status code. mediaType is the identifier of the response
+// media type, it is used as the response Content-Type if acceptable. Otherwise Send picks the
+// acceptable media type with the highest quality value among the ones that have a registered
+// encoder (see Service.SetEncoder). The UTF-8 charset is added to JSON and text content types.
+// Send writes a response with status code 406 if there is no acceptable media type.
+func (ctx *Context) Send(code int, mediaType string, body interface{}) error {
+	contentType, factory, _ := ctx.negotiate(mediaType)
+	if factory == nil {
+		return ctx.notAcceptable()
+	}
+	return ctx.encode(code, contentType, factory, body)
+}
+
+// SendMedia validates the given media type, renders it using the given view and sends a HTTP
+// response with the given status code. The content type is negotiated as with Send. The response
+// body is written directly by the JSON writer generated for the media type when the negotiated
+// encoder is the built-in JSON encoder, the media type is first dumped into raw data then
+// encoded otherwise. SendMedia returns an error without writing the response if the media type is
+// invalid. This method is intended for the code generated by goagen.
+func (ctx *Context) SendMedia(code int, mediaType string, body MediaTypeRenderer, view string) error {
+	contentType, factory, native := ctx.negotiate(mediaType)
+	if factory == nil {
+		return ctx.notAcceptable()
+	}
+	if !native {
+		raw, err := body.DumpView(view)
+		if err != nil {
+			return fmt.Errorf("invalid response: %s", err)
+		}
+		return ctx.encode(code, contentType, factory, raw)
+	}
+	var w JSONWriter
+	err := body.WriteJSON(&w, view)
+	if err == nil {
+		err = w.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("invalid response: %s", err)
+	}
+	ctx.Header().Set("Content-Type", contentType)
+	return ctx.Respond(code, append(w.Bytes(), '\n'))
+}
+
+// StreamMedia behaves like SendMedia but writes the elements of the collection to the response
+// body as they get rendered instead of rendering the entire collection first. This makes it
+// possible to start sending large collections early while keeping memory usage bounded. Each
+// element is flushed to the client if the response writer implements http.Flusher. Streaming
+// only happens when the negotiated encoder is the built-in JSON encoder, StreamMedia behaves
+// exactly like SendMedia otherwise.
+// The response status code is written once the first element has been successfully rendered.
+// If a subsequent element turns out to be invalid StreamMedia logs the error and stops writing,
+// the response body is then not a valid JSON document which tells clients that the response is
+// incomplete. This method is intended for the code generated by goagen.
+func (ctx *Context) StreamMedia(code int, mediaType string, body CollectionRenderer, view string) error {
+	contentType, factory, native := ctx.negotiate(mediaType)
+	if factory == nil {
+		return ctx.notAcceptable()
+	}
+	if !native {
+		return ctx.SendMedia(code, mediaType, body, view)
+	}
+	var w JSONWriter
+	w.ArrayStart()
+	for i := 0; i < body.Len(); i++ {
+		err := body.WriteElemJSON(&w, i, view)
+		if err == nil {
+			err = w.Err()
+		}
+		if err != nil {
+			if !ctx.ResponseWritten() {
+				return fmt.Errorf("invalid response: %s", err)
+			}
+			ctx.Error("invalid response, body truncated", "index", i, "err", err)
+			return nil
+		}
+		if !ctx.ResponseWritten() {
+			ctx.Header().Set("Content-Type", contentType)
+			ctx.WriteHeader(code)
+		}
+		if _, err := ctx.Write(w.Bytes()); err != nil {
+			return err
+		}
+		ctx.flush()
+		w.Reset()
+	}
+	w.ArrayEnd()
+	if !ctx.ResponseWritten() {
+		ctx.Header().Set("Content-Type", contentType)
+		ctx.WriteHeader(code)
+	}
+	_, err := ctx.Write(append(w.Bytes(), '\n'))
+	return err
+}
+
+// flush sends the response data written so far to the client if the response writer supports it.
+func (ctx *Context) flush() {
+	if f, ok := ctx.Value(respKey).(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// negotiate returns the response content type and encoder factory that best match the request
+// Accept header, see Send. JSON and text content types include the UTF-8 charset. The returned
+// boolean is true if the encoder is the built-in JSON encoder.
+func (ctx *Context) negotiate(mediaType string) (string, EncoderFactory, bool) {
+	encoders, ok := ctx.Value(encodersKey).(*encoderRegistry)
+	if !ok {
+		encoders = newEncoderRegistry()
+	}
+	contentType, factory := encoders.negotiate(ctx.accept(), mediaType)
+	if factory == nil {
+		return "", nil, false
+	}
+	return withCharset(contentType), factory, encoders.native(contentType)
+}
+
+// notAcceptable sends a response with status code 406 indicating that none of the media types
+// the service can produce matches the request Accept header.
+func (ctx *Context) notAcceptable() error {
+	return ctx.SendError(406, &TypedError{
+		ID:   ErrNotAcceptable,
+		Mesg: fmt.Sprintf("cannot produce a response matching %q", ctx.accept()),
+	})
+}
+
+// accept returns the request Accept header.
+func (ctx *Context) accept() string {
+	if req := ctx.Request(); req != nil {
+		return req.Header.Get("Accept")
+	}
+	return ""
+}
+
+// encode writes a response with the given status code and content type whose body is the given
+// value encoded with the encoder created by factory.
+func (ctx *Context) encode(code int, contentType string, factory EncoderFactory, body interface{}) error {
+	var b bytes.Buffer
+	if err := factory(&b).Encode(body); err != nil {
+		return err
+	}
+	ctx.Header().Set("Content-Type", contentType)
+	return ctx.Respond(code, b.Bytes())
+}
+
+// SendError sends a HTTP response with the given status code and the error document built from
+// the given error as body (see NewErrorDocument). The document request ID is initialized from the
+// value set by the RequestID middleware if any.
+func (ctx *Context) SendError(code int, err error) error {
+	doc := NewErrorDocument(code, err)
+	if id, ok := ctx.Value(ReqIDKey).(string); ok {
+		doc.RequestID = id
+	}
+	js, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	if h := ctx.Header(); h != nil {
+		h.Set("Content-Type", ErrorMediaIdentifier)
+	}
+	return ctx.Respond(code, js)
+}
+
+// BadRequest sends a HTTP response with status code 400 and the error document built from the
+// given error as body.
 func (ctx *Context) BadRequest(err *BadRequestError) error {
-	return ctx.Respond(400, []byte(err.Error()))
+	return ctx.SendError(400, err)
 }
 
 // Bug sends a HTTP response with status code 500 and the given body.
@@ -3,6 +3,7 @@ package goa_test
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
@@ -150,11 +151,212 @@ var _ = Describe("Context", func() {
 			})
 		})
 
+		Context("Send", func() {
+			const mediaType = "application/vnd.goa.test+json"
+			BeforeEach(func() {
+				rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+				handler = func(c *goa.Context) error {
+					ctx = c
+					return c.Send(respStatus, mediaType, string(respContent))
+				}
+			})
+
+			sent := func() *TestResponseWriter {
+				return rw.(*TestResponseWriter)
+			}
+
+			It("uses the response media type by default", func() {
+				Ω(ctx.ResponseStatus()).Should(Equal(respStatus))
+				Ω(sent().Header().Get("Content-Type")).Should(Equal(mediaType + "; charset=utf-8"))
+				Ω(string(sent().Body)).Should(Equal(`"response"` + "\n"))
+			})
+
+			Context("with an Accept header", func() {
+				BeforeEach(func() {
+					request.Header.Set("Accept", "text/html;q=0.9, application/*;q=0.5")
+				})
+
+				It("negotiates the content type", func() {
+					Ω(ctx.ResponseStatus()).Should(Equal(respStatus))
+					Ω(sent().Header().Get("Content-Type")).Should(Equal(mediaType + "; charset=utf-8"))
+				})
+			})
+
+			Context("with an Accept header that prefers a registered encoder", func() {
+				BeforeEach(func() {
+					app.SetEncoder(TextEncoderFactory, false, "text/plain")
+					request.Header.Set("Accept", "application/json;q=0.2, text/*")
+				})
+
+				It("uses the encoder", func() {
+					Ω(ctx.ResponseStatus()).Should(Equal(respStatus))
+					Ω(sent().Header().Get("Content-Type")).Should(Equal("text/plain; charset=utf-8"))
+					Ω(string(sent().Body)).Should(Equal(string(respContent)))
+				})
+			})
+
+			Context("with an Accept header that excludes the media type", func() {
+				BeforeEach(func() {
+					request.Header.Set("Accept", mediaType+";q=0, application/json;q=0.5")
+				})
+
+				It("uses the acceptable media type", func() {
+					Ω(ctx.ResponseStatus()).Should(Equal(respStatus))
+					Ω(sent().Header().Get("Content-Type")).Should(Equal("application/json; charset=utf-8"))
+				})
+			})
+
+			Context("with an Accept header that can't be satisfied", func() {
+				BeforeEach(func() {
+					request.Header.Set("Accept", "text/html, application/xml;q=0.8")
+				})
+
+				It("responds with 406", func() {
+					Ω(ctx.ResponseStatus()).Should(Equal(406))
+					var doc goa.ErrorDocument
+					tw := rw.(*TestResponseWriter)
+					Ω(json.Unmarshal(tw.Body, &doc)).ShouldNot(HaveOccurred())
+					Ω(doc.Code).Should(Equal(goa.ErrorID(goa.ErrNotAcceptable)))
+				})
+			})
+		})
+
+		Context("SendMedia", func() {
+			const mediaType = "application/vnd.goa.bottles+json"
+			var bottles testBottles
+
+			BeforeEach(func() {
+				rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+				bottles = testBottles{"Number 8", "Number 9"}
+				handler = func(c *goa.Context) error {
+					ctx = c
+					return c.SendMedia(respStatus, mediaType, bottles, "default")
+				}
+			})
+
+			sent := func() *TestResponseWriter {
+				return rw.(*TestResponseWriter)
+			}
+
+			It("writes the media type JSON", func() {
+				Ω(ctx.ResponseStatus()).Should(Equal(respStatus))
+				Ω(sent().Header().Get("Content-Type")).Should(Equal(mediaType + "; charset=utf-8"))
+				Ω(string(sent().Body)).Should(Equal(`[{"name":"Number 8"},{"name":"Number 9"}]` + "\n"))
+			})
+
+			Context("with an invalid media type", func() {
+				var err error
+
+				BeforeEach(func() {
+					bottles = testBottles{"Number 8", ""}
+					handler = func(c *goa.Context) error {
+						ctx = c
+						err = c.SendMedia(respStatus, mediaType, bottles, "default")
+						return nil
+					}
+				})
+
+				It("returns an error and does not write the media type", func() {
+					Ω(err).Should(HaveOccurred())
+					Ω(string(sent().Body)).ShouldNot(ContainSubstring("Number"))
+				})
+			})
+
+			Context("with an Accept header that prefers a registered encoder", func() {
+				BeforeEach(func() {
+					app.SetEncoder(TextEncoderFactory, false, "text/plain")
+					request.Header.Set("Accept", "text/plain")
+				})
+
+				It("encodes the dumped media type", func() {
+					Ω(ctx.ResponseStatus()).Should(Equal(respStatus))
+					Ω(sent().Header().Get("Content-Type")).Should(Equal("text/plain; charset=utf-8"))
+					Ω(string(sent().Body)).Should(Equal("[Number 8 Number 9]"))
+				})
+			})
+
+			Context("streaming", func() {
+				var err error
+
+				BeforeEach(func() {
+					handler = func(c *goa.Context) error {
+						ctx = c
+						err = c.StreamMedia(respStatus, mediaType, bottles, "default")
+						return nil
+					}
+				})
+
+				It("writes the collection elements", func() {
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(ctx.ResponseStatus()).Should(Equal(respStatus))
+					Ω(sent().Header().Get("Content-Type")).Should(Equal(mediaType + "; charset=utf-8"))
+					Ω(string(sent().Body)).Should(Equal(`[{"name":"Number 8"},{"name":"Number 9"}]` + "\n"))
+				})
+
+				Context("with an empty collection", func() {
+					BeforeEach(func() {
+						bottles = nil
+					})
+
+					It("writes an empty array", func() {
+						Ω(err).ShouldNot(HaveOccurred())
+						Ω(ctx.ResponseStatus()).Should(Equal(respStatus))
+						Ω(string(sent().Body)).Should(Equal("[]\n"))
+					})
+				})
+
+				Context("with an invalid first element", func() {
+					BeforeEach(func() {
+						bottles = testBottles{"", "Number 9"}
+					})
+
+					It("returns an error and does not write the collection", func() {
+						Ω(err).Should(HaveOccurred())
+						Ω(string(sent().Body)).ShouldNot(ContainSubstring("Number"))
+					})
+				})
+
+				Context("with a response writer that flushes", func() {
+					var probe *streamProbe
+
+					BeforeEach(func() {
+						fw := new(flushingWriter)
+						fw.ParentHeader = make(http.Header)
+						rw = fw
+						probe = &streamProbe{testBottles: bottles, writer: fw}
+						handler = func(c *goa.Context) error {
+							ctx = c
+							err = c.StreamMedia(respStatus, mediaType, probe, "default")
+							return nil
+						}
+					})
+
+					It("sends the elements before the last one is rendered", func() {
+						Ω(err).ShouldNot(HaveOccurred())
+						Ω(string(probe.flushedBeforeLast)).Should(Equal(`[{"name":"Number 8"}`))
+					})
+				})
+
+				Context("with an invalid element", func() {
+					BeforeEach(func() {
+						bottles = testBottles{"Number 8", "", "Number 10"}
+					})
+
+					It("truncates the response body", func() {
+						Ω(err).ShouldNot(HaveOccurred())
+						Ω(ctx.ResponseStatus()).Should(Equal(respStatus))
+						Ω(string(sent().Body)).Should(Equal(`[{"name":"Number 8"}`))
+					})
+				})
+			})
+		})
+
 		Context("BadRequest", func() {
 			err := fmt.Errorf("boom")
 			var badReq = &goa.BadRequestError{Actual: err}
 
 			BeforeEach(func() {
+				rw = &TestResponseWriter{ParentHeader: make(http.Header)}
 				handler = func(c *goa.Context) error {
 					ctx = c
 					c.BadRequest(badReq)
@@ -167,7 +369,87 @@ var _ = Describe("Context", func() {
 				tw := rw.(*TestResponseWriter)
 				Ω(string(tw.Body)).Should(ContainSubstring(err.Error()))
 			})
+
+			It("writes an error document", func() {
+				tw := rw.(*TestResponseWriter)
+				Ω(tw.Header().Get("Content-Type")).Should(Equal(goa.ErrorMediaIdentifier))
+				var doc goa.ErrorDocument
+				Ω(json.Unmarshal(tw.Body, &doc)).ShouldNot(HaveOccurred())
+				Ω(doc.Status).Should(Equal(400))
+				Ω(doc.Detail).Should(Equal(err.Error()))
+			})
 		})
 	})
 
 })
+
+// TextEncoderFactory creates encoders that write strings as is.
+func TextEncoderFactory(w io.Writer) goa.Encoder {
+	return &textEncoder{w}
+}
+
+type textEncoder struct {
+	w io.Writer
+}
+
+func (e *textEncoder) Encode(v interface{}) error {
+	_, err := fmt.Fprint(e.w, v)
+	return err
+}
+
+// testBottles is a collection media type whose elements are rendered using their name.
+type testBottles []string
+
+func (b testBottles) DumpView(view string) (interface{}, error) {
+	return []string(b), nil
+}
+
+func (b testBottles) WriteJSON(w *goa.JSONWriter, view string) error {
+	w.ArrayStart()
+	for i := range b {
+		if err := b.WriteElemJSON(w, i, view); err != nil {
+			return err
+		}
+	}
+	w.ArrayEnd()
+	return nil
+}
+
+func (b testBottles) Len() int {
+	return len(b)
+}
+
+func (b testBottles) WriteElemJSON(w *goa.JSONWriter, i int, view string) error {
+	if b[i] == "" {
+		return fmt.Errorf("missing name")
+	}
+	w.ObjectStart()
+	w.Key("name")
+	w.String(b[i])
+	w.ObjectEnd()
+	return nil
+}
+
+// flushingWriter is a response writer that records the data flushed so far.
+type flushingWriter struct {
+	TestResponseWriter
+	Flushed []byte
+}
+
+func (f *flushingWriter) Flush() {
+	f.Flushed = append([]byte(nil), f.Body...)
+}
+
+// streamProbe records the data flushed by writer when its last element is rendered.
+type streamProbe struct {
+	testBottles
+	writer            *flushingWriter
+	flushedBeforeLast []byte
+}
+
+func (p *streamProbe) WriteElemJSON(w *goa.JSONWriter, i int, view string) error {
+	if i == len(p.testBottles)-1 {
+		p.flushedBeforeLast = p.writer.Flushed
+	}
+	return p.testBottles.WriteElemJSON(w, i, view)
+}
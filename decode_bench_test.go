@@ -0,0 +1,176 @@
+package goa_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/raphael/goa"
+)
+
+// benchPayload is the payload decoded by the benchmarks, it mimics the shape of the payloads
+// generated by goagen.
+type benchPayload struct {
+	Name     string
+	Vintage  int
+	Rating   float64
+	Sparkles bool
+	Tags     []string
+}
+
+// benchPayloadBody is the type benchDecode decodes request bodies into.
+type benchPayloadBody struct {
+	Name     *string   `json:"name"`
+	Vintage  *int      `json:"vintage"`
+	Rating   *float64  `json:"rating"`
+	Sparkles *bool     `json:"sparkles"`
+	Tags     *[]string `json:"tags"`
+}
+
+var benchBody = []byte(`{"name":"Number 8","vintage":2012,"rating":4.5,"sparkles":false,` +
+	`"tags":["red","dry","oak","fruity","spicy","smooth","tannic","long finish"]}`)
+
+// benchUnmarshal loads a benchPayload from a generically decoded body the way the generated
+// unmarshalers do.
+func benchUnmarshal(raw interface{}) (*benchPayload, error) {
+	var err error
+	val, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, goa.InvalidAttributeTypeError(`payload`, raw, "dictionary", nil)
+	}
+	p := new(benchPayload)
+	if v, ok := val["name"]; ok {
+		if s, ok := v.(string); ok {
+			p.Name = s
+		} else {
+			err = goa.InvalidAttributeTypeError(`payload.Name`, v, "string", err)
+		}
+	} else {
+		err = goa.MissingAttributeError(`payload`, "name", err)
+	}
+	if v, ok := val["vintage"]; ok {
+		if f, ok := v.(float64); ok {
+			p.Vintage = int(f)
+		} else {
+			err = goa.InvalidAttributeTypeError(`payload.Vintage`, v, "int", err)
+		}
+	}
+	if v, ok := val["rating"]; ok {
+		if f, ok := v.(float64); ok {
+			p.Rating = f
+		} else {
+			err = goa.InvalidAttributeTypeError(`payload.Rating`, v, "float64", err)
+		}
+	}
+	if v, ok := val["sparkles"]; ok {
+		if b, ok := v.(bool); ok {
+			p.Sparkles = b
+		} else {
+			err = goa.InvalidAttributeTypeError(`payload.Sparkles`, v, "bool", err)
+		}
+	}
+	if v, ok := val["tags"]; ok {
+		if elems, ok := v.([]interface{}); ok {
+			p.Tags = make([]string, len(elems))
+			for i, e := range elems {
+				if s, ok := e.(string); ok {
+					p.Tags[i] = s
+				} else {
+					err = goa.InvalidAttributeTypeError(`payload.Tags[*]`, e, "string", err)
+				}
+			}
+		} else {
+			err = goa.InvalidAttributeTypeError(`payload.Tags`, v, "array", err)
+		}
+	}
+	return p, err
+}
+
+// benchDecode decodes a request body directly into a benchPayload the way the generated decode
+// functions do.
+func benchDecode(dec goa.Decoder) (interface{}, error) {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	var body benchPayloadBody
+	if err := json.Unmarshal(raw, &body); err != nil {
+		var generic interface{}
+		if err := json.Unmarshal(raw, &generic); err != nil {
+			return nil, err
+		}
+		p, err := benchUnmarshal(generic)
+		if err != nil {
+			return nil, goa.NewBadRequestError(err)
+		}
+		return p, nil
+	}
+	var err error
+	p := new(benchPayload)
+	if body.Name != nil {
+		p.Name = *body.Name
+	} else {
+		err = goa.MissingAttributeError(`payload`, "name", err)
+	}
+	if body.Vintage != nil {
+		p.Vintage = *body.Vintage
+	}
+	if body.Rating != nil {
+		p.Rating = *body.Rating
+	}
+	if body.Sparkles != nil {
+		p.Sparkles = *body.Sparkles
+	}
+	if body.Tags != nil {
+		p.Tags = *body.Tags
+	}
+	if err != nil {
+		return nil, goa.NewBadRequestError(err)
+	}
+	return p, nil
+}
+
+// benchmarkDecode runs the request decoding benchmark with or without typed decoding.
+func benchmarkDecode(b *testing.B, typed bool) {
+	service := goa.New("bench")
+	ctrl := service.NewController("bottle")
+	if typed {
+		ctrl.SetDecodeFunc("create", benchDecode)
+	}
+	h := func(c *goa.Context) error {
+		p, ok := c.Payload().(*benchPayload)
+		if !ok {
+			var err error
+			if p, err = benchUnmarshal(c.Payload()); err != nil {
+				return goa.NewBadRequestError(err)
+			}
+		}
+		if p.Name == "" {
+			b.Fatal("payload not decoded")
+		}
+		return c.Respond(200, nil)
+	}
+	handle := ctrl.NewHTTPRouterHandle("create", h)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req, _ := http.NewRequest("POST", "/bottles", bytes.NewReader(benchBody))
+		req.Header.Set("Content-Type", "application/json")
+		rw := httptest.NewRecorder()
+		handle(rw, req, httprouter.Params{})
+		if rw.Code != 200 {
+			b.Fatalf("unexpected response status %d", rw.Code)
+		}
+	}
+}
+
+func BenchmarkDecodeGeneric(b *testing.B) {
+	benchmarkDecode(b, false)
+}
+
+func BenchmarkDecodeTyped(b *testing.B) {
+	benchmarkDecode(b, true)
+}
@@ -2,13 +2,16 @@ package design
 
 import (
 	"fmt"
+	"math"
 	"mime"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"sort"
 	"strings"
 	"time"
 
+	"bitbucket.org/pkg/inflect"
 	"github.com/julienschmidt/httprouter"
 	regen "github.com/zach-klippenstein/goregen"
 )
@@ -40,10 +43,31 @@ type (
 		Host string
 		// API URL schemes
 		Schemes []string
+		// Media types of the request bodies accepted by the API actions
+		Consumes []string
+		// Media types of the response bodies produced by the API actions
+		Produces []string
+		// Maximum size in bytes of the request bodies accepted by the API actions if any
+		MaxBodySize int64
+		// Media type of the error responses if declared with ErrorMedia
+		ErrorMedia *MediaTypeDefinition
 		// Common base path to all API actions
 		BasePath string
 		// Common path parameters to all API actions
 		BaseParams *AttributeDefinition
+		// Policy used to compute the base path of the resources that do not define one
+		BasePathPolicy BasePathPolicyKind
+		// API versions indexed by name
+		Versions map[string]*APIVersionDefinition
+		// Path prefix followed by the version name in the paths of the versioned actions if any
+		VersionPrefix string
+		// Name of the request header that contains the version name if any
+		VersionHeader string
+		// Name of the query string parameter that contains the version name if any
+		VersionQuery string
+		// APIVersion is the version described by this definition if it was returned by
+		// ForVersion, nil otherwise
+		APIVersion *APIVersionDefinition
 		// TermsOfService describes or links to the API terms of service
 		TermsOfService string
 		// Contact provides the API users with contact information
@@ -56,6 +80,8 @@ type (
 		Resources map[string]*ResourceDefinition
 		// Traits available to all API resources and actions indexed by name
 		Traits map[string]*TraitDefinition
+		// TraitUses records the calls to UseTrait in the order they were made
+		TraitUses []*TraitUseDefinition
 		// Responses available to all API actions indexed by name
 		Responses map[string]*ResponseDefinition
 		// Response template factories available to all API actions indexed by name
@@ -64,6 +90,12 @@ type (
 		DefaultResponses map[string]*ResponseDefinition
 		// Built-in response templates
 		DefaultResponseTemplates map[string]*ResponseTemplateDefinition
+		// Error kinds available to all API actions indexed by name
+		ErrorKinds map[string]*ErrorKindDefinition
+		// Security schemes available to all API actions indexed by name
+		SecuritySchemes map[string]*SecuritySchemeDefinition
+		// Security requirement that applies to all API actions if any
+		Security *SecurityDefinition
 		// User types
 		Types map[string]*UserTypeDefinition
 		// Media types
@@ -76,6 +108,23 @@ type (
 		rand *RandomGenerator
 	}
 
+	// APIVersionDefinition describes a version of the API. A version defines the resources and
+	// media types that differ between versions. Media types defined outside of any version are
+	// used by all versions that do not define a media type with the same identifier. Resources
+	// defined outside of any version are not part of any version, see dsl.Version.
+	APIVersionDefinition struct {
+		// Version name
+		Name string
+		// Optional description
+		Description string
+		// Version resources indexed by name
+		Resources map[string]*ResourceDefinition
+		// Version media types indexed by canonical identifier
+		MediaTypes map[string]*MediaTypeDefinition
+		// dsl contains the DSL used to create this definition if any.
+		DSL func()
+	}
+
 	// ContactDefinition contains the API contact information.
 	ContactDefinition struct {
 		// Name of the contact person/organization
@@ -102,6 +151,15 @@ type (
 		URL string `json:"url,omitempty"`
 	}
 
+	// DeprecationDefinition describes the deprecation of a resource, action or attribute.
+	DeprecationDefinition struct {
+		// Description explains why the definition is deprecated and what to use instead.
+		Description string
+		// Sunset is the time after which the deprecated resource or action may stop being
+		// available, zero if not known.
+		Sunset time.Time
+	}
+
 	// ResourceDefinition describes a REST resource.
 	// It defines both a media type and a set of actions that can be executed through HTTP
 	// requests.
@@ -112,22 +170,39 @@ type (
 		Name string
 		// Common URL prefix to all resource action HTTP requests
 		BasePath string
+		// ImplicitBasePath is true if BasePath was computed from the resource name using the
+		// API base path policy.
+		ImplicitBasePath bool
 		// Object describing each parameter that appears in BasePath if any
 		BaseParams *AttributeDefinition
 		// Name of parent resource if any
 		ParentName string
 		// Optional description
 		Description string
-		// Optional version
+		// Name of the API version that defines the resource if any
 		Version string
 		// Default media type, describes the resource attributes
 		MediaType string
+		// Media types of the request bodies accepted by the resource actions if any
+		Consumes []string
+		// Media types of the response bodies produced by the resource actions if any
+		Produces []string
+		// Maximum size in bytes of the request bodies accepted by the resource actions if any
+		MaxBodySize int64
+		// URL schemes supported by the resource actions if any
+		Schemes []string
+		// Deprecation describes the resource deprecation if it is deprecated
+		Deprecation *DeprecationDefinition
 		// Exposed resource actions indexed by name
 		Actions map[string]*ActionDefinition
 		// Action with canonical resource path
 		CanonicalActionName string
 		// Map of response definitions that apply to all actions indexed by name.
 		Responses map[string]*ResponseDefinition
+		// Error kinds available to all the resource actions indexed by name
+		ErrorKinds map[string]*ErrorKindDefinition
+		// Security requirement that applies to all the resource actions if any
+		Security *SecurityDefinition
 		// Path and query string parameters that apply to all actions.
 		Params *AttributeDefinition
 		// Request headers that apply to all actions.
@@ -185,12 +260,30 @@ type (
 		Routes []*RouteDefinition
 		// Map of possible response definitions indexed by name
 		Responses map[string]*ResponseDefinition
+		// Error kinds returned by the action indexed by name
+		ErrorKinds map[string]*ErrorKindDefinition
+		// Security requirement of the action if any
+		Security *SecurityDefinition
 		// Path and query string parameters
 		Params *AttributeDefinition
 		// Query string parameters only
 		QueryParams *AttributeDefinition
 		// Payload blueprint (request body) if any
 		Payload *UserTypeDefinition
+		// PayloadMultipart is true if the payload is sent using a multipart/form-data body
+		PayloadMultipart bool
+		// Media types of the request bodies accepted by the action if any
+		Consumes []string
+		// Media types of the response bodies produced by the action if any
+		Produces []string
+		// Maximum size in bytes of the request bodies accepted by the action if any
+		MaxBodySize int64
+		// RawBody is true if the action reads the request body as a stream instead of a payload
+		RawBody bool
+		// URL schemes supported by the action if any
+		Schemes []string
+		// Deprecation describes the action deprecation if it is deprecated
+		Deprecation *DeprecationDefinition
 		// Request headers that need to be made available to action
 		Headers *AttributeDefinition
 		// Metadata is a list of key/value pairs
@@ -212,8 +305,16 @@ type (
 		Metadata MetadataDefinition
 		// Optional member default value
 		DefaultValue interface{}
+		// Optional example value used by the generated documentation instead of a randomly
+		// generated value
+		ExampleValue interface{}
 		// Optional view used to render Attribute (only applies to media type attributes).
 		View string
+		// Optional format used to serialize array parameters: one of "csv" (the default),
+		// "ssv", "tsv", "pipes" or "multi".
+		CollectionFormat string
+		// Deprecation describes the attribute deprecation if it is deprecated
+		Deprecation *DeprecationDefinition
 	}
 	// MetadataDefinition is a set of key/value pairs
 	MetadataDefinition map[string]string
@@ -250,6 +351,74 @@ type (
 		Name string
 		// Trait DSL
 		DSL func()
+		// Template is the DSL of a parameterized trait: a function whose arguments are
+		// given by UseTrait. Template is nil if the trait does not accept arguments.
+		Template interface{}
+	}
+
+	// TraitUseDefinition records the use of a trait together with the arguments given to it.
+	TraitUseDefinition struct {
+		// Trait name
+		Name string
+		// Args lists the arguments given to the trait
+		Args []interface{}
+		// Parent is the resource, action or attribute definition using the trait
+		Parent DSLDefinition
+	}
+
+	// ErrorKindDefinition maps a named kind of error to a response. goagen generates a
+	// constructor for each error kind, the controller sends the response when an action returns
+	// an error created with the constructor.
+	ErrorKindDefinition struct {
+		// Error kind name
+		Name string
+		// Optional description
+		Description string
+		// Name of the response sent for errors of this kind
+		Response string
+		// Parent API, resource or action
+		Parent DSLDefinition
+	}
+
+	// SecuritySchemeKind is the kind of a security scheme, see the SecuritySchemeKind constants.
+	SecuritySchemeKind string
+
+	// BasePathPolicyKind is the naming policy used to compute the base path of resources that do
+	// not define one, see the BasePathPolicyKind constants.
+	BasePathPolicyKind string
+
+	// SecuritySchemeDefinition describes a mechanism used to authenticate the requests made to
+	// the API actions. Security schemes are defined at the API level and required by the API,
+	// resources or actions via security definitions.
+	SecuritySchemeDefinition struct {
+		// Security scheme name
+		Name string
+		// Security scheme kind
+		Kind SecuritySchemeKind
+		// Optional description
+		Description string
+		// Location of the API key or token, one of "header" or "query"
+		In string
+		// Name of the header or query string parameter holding the API key or token
+		ParamName string
+		// OAuth2 flow, one of "implicit", "password", "application" or "accessCode"
+		Flow string
+		// OAuth2 authorization URL
+		AuthorizationURL string
+		// OAuth2 or JWT token URL
+		TokenURL string
+		// Scopes available to OAuth2 and JWT schemes, values are the scope descriptions
+		Scopes map[string]string
+	}
+
+	// SecurityDefinition describes the security requirement of an API, resource or action.
+	SecurityDefinition struct {
+		// Name of the required security scheme, empty if the requirement disables security
+		Scheme string
+		// Scopes required by OAuth2 and JWT schemes
+		Scopes []string
+		// Parent API, resource or action
+		Parent DSLDefinition
 	}
 
 	// RouteDefinition represents an action route.
@@ -290,12 +459,22 @@ type (
 	// http://json-schema.org/latest/json-schema-validation.html#anchor21.
 	MinimumValidationDefinition struct {
 		Min float64
+		// Exclusive is true if the value must be strictly greater than Min.
+		Exclusive bool
 	}
 
 	// MaximumValidationDefinition represents a maximum value validation as described at
 	// http://json-schema.org/latest/json-schema-validation.html#anchor17.
 	MaximumValidationDefinition struct {
 		Max float64
+		// Exclusive is true if the value must be strictly lesser than Max.
+		Exclusive bool
+	}
+
+	// MultipleOfValidationDefinition represents a multiple of validation as described at
+	// http://json-schema.org/latest/json-schema-validation.html#anchor14.
+	MultipleOfValidationDefinition struct {
+		MultipleOf float64
 	}
 
 	// MinLengthValidationDefinition represents an minimum length validation as described at
@@ -310,12 +489,31 @@ type (
 		MaxLength int
 	}
 
+	// UniqueItemsValidationDefinition represents an unique items validation as described at
+	// http://json-schema.org/latest/json-schema-validation.html#anchor49.
+	UniqueItemsValidationDefinition struct{}
+
+	// MinPropertiesValidationDefinition represents a minimum properties validation as described
+	// at http://json-schema.org/latest/json-schema-validation.html#anchor57.
+	MinPropertiesValidationDefinition struct {
+		MinProperties int
+	}
+
+	// MaxPropertiesValidationDefinition represents a maximum properties validation as described
+	// at http://json-schema.org/latest/json-schema-validation.html#anchor54.
+	MaxPropertiesValidationDefinition struct {
+		MaxProperties int
+	}
+
 	// RequiredValidationDefinition represents a required validation as described at
 	// http://json-schema.org/latest/json-schema-validation.html#anchor61.
 	RequiredValidationDefinition struct {
 		Names []string
 	}
 
+	// VersionIterator is the type of functions given to IterateVersions.
+	VersionIterator func(v *APIDefinition) error
+
 	// ResourceIterator is the type of functions given to IterateResources.
 	ResourceIterator func(r *ResourceDefinition) error
 
@@ -330,16 +528,118 @@ type (
 
 	// ResponseIterator is the type of functions given to IterateResponses.
 	ResponseIterator func(r *ResponseDefinition) error
+
+	// ErrorKindIterator is the type of functions given to IterateErrorKinds.
+	ErrorKindIterator func(k *ErrorKindDefinition) error
+
+	// SecuritySchemeIterator is the type of functions given to IterateSecuritySchemes.
+	SecuritySchemeIterator func(s *SecuritySchemeDefinition) error
+)
+
+const (
+	// BasicAuthSecurityKind is the kind of security schemes that use HTTP basic authentication.
+	BasicAuthSecurityKind SecuritySchemeKind = "basic"
+	// APIKeySecurityKind is the kind of security schemes that use an API key sent in a header
+	// or query string parameter.
+	APIKeySecurityKind SecuritySchemeKind = "apiKey"
+	// JWTSecurityKind is the kind of security schemes that use JSON Web Tokens.
+	JWTSecurityKind SecuritySchemeKind = "jwt"
+	// OAuth2SecurityKind is the kind of security schemes that use OAuth2 bearer tokens.
+	OAuth2SecurityKind SecuritySchemeKind = "oauth2"
+)
+
+const (
+	// NoBasePathPolicy leaves the base path of resources that do not define one empty, their
+	// actions are mounted directly under the API base path. This is the default.
+	NoBasePathPolicy BasePathPolicyKind = ""
+	// NameBasePathPolicy uses the resource name as base path, e.g. "/WineBottle".
+	NameBasePathPolicy BasePathPolicyKind = "name"
+	// SnakeBasePathPolicy uses the snake_case resource name as base path, e.g. "/wine_bottle".
+	SnakeBasePathPolicy BasePathPolicyKind = "snake"
+	// PluralBasePathPolicy uses the pluralized snake_case resource name as base path, e.g.
+	// "/wine_bottles".
+	PluralBasePathPolicy BasePathPolicyKind = "plural"
 )
 
 // Context returns the generic definition name used in error messages.
 func (a *APIDefinition) Context() string {
 	if a.Name != "" {
+		if a.APIVersion != nil {
+			return fmt.Sprintf("api %#v version %#v", a.Name, a.APIVersion.Name)
+		}
 		return fmt.Sprintf("api %#v", a.Name)
 	}
 	return "unnamed API"
 }
 
+// ForVersion returns the definition of the API version with the given name, nil if there is no
+// such version. The returned definition shares the API properties but its resources are the version
+// resources and its media types are the API media types overridden by the version media types. Its
+// base path is the version path prefix followed by the version name if the API defines a version
+// prefix.
+func (a *APIDefinition) ForVersion(name string) *APIDefinition {
+	v, ok := a.Versions[name]
+	if !ok {
+		return nil
+	}
+	if v.Resources == nil {
+		v.Resources = make(map[string]*ResourceDefinition)
+	}
+	if v.MediaTypes == nil {
+		v.MediaTypes = make(map[string]*MediaTypeDefinition)
+	}
+	va := *a
+	va.APIVersion = v
+	va.Versions = nil
+	va.Resources = v.Resources
+	va.MediaTypes = make(map[string]*MediaTypeDefinition, len(a.MediaTypes)+len(v.MediaTypes))
+	for id, mt := range a.MediaTypes {
+		va.MediaTypes[id] = mt
+	}
+	for id, mt := range v.MediaTypes {
+		va.MediaTypes[id] = mt
+	}
+	if a.VersionPrefix != "" {
+		va.BasePath = a.VersionPathPrefix() + name
+	}
+	return &va
+}
+
+// VersionPathPrefix returns the prefix of the paths of the versioned actions: the API base path
+// followed by the version prefix. The version name follows the prefix in the action paths.
+// VersionPathPrefix may be called on the API definition or on the definition of one of its versions.
+func (a *APIDefinition) VersionPathPrefix() string {
+	if a.APIVersion != nil {
+		return strings.TrimSuffix(a.BasePath, a.APIVersion.Name)
+	}
+	return strings.TrimSuffix(a.BasePath, "/") + a.VersionPrefix
+}
+
+// IterateVersions calls the given iterator passing in the definition of each API version sorted by
+// name, see ForVersion. Design is set to the version definition during each call so that the
+// definitions looked up or created by the iterator are the version definitions.
+// Iteration stops if an iterator returns an error and in this case IterateVersions returns that
+// error.
+func (a *APIDefinition) IterateVersions(it VersionIterator) error {
+	names := make([]string, len(a.Versions))
+	i := 0
+	for n := range a.Versions {
+		names[i] = n
+		i++
+	}
+	sort.Strings(names)
+	current := Design
+	defer func() { Design = current }()
+	for _, n := range names {
+		v := a.ForVersion(n)
+		Design = v
+		if err := it(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // IterateResources calls the given iterator passing in each resource sorted in alphabetical order.
 // Iteration stops if an iterator returns an error and in this case IterateResources returns that
 // error.
@@ -416,7 +716,54 @@ func (a *APIDefinition) IterateResponses(it ResponseIterator) error {
 	return nil
 }
 
-// Example returns a random value for the given data type.
+// IterateSecuritySchemes calls the given iterator passing in each security scheme sorted in
+// alphabetical order. Iteration stops if an iterator returns an error and in this case
+// IterateSecuritySchemes returns that error.
+func (a *APIDefinition) IterateSecuritySchemes(it SecuritySchemeIterator) error {
+	names := make([]string, len(a.SecuritySchemes))
+	i := 0
+	for n := range a.SecuritySchemes {
+		names[i] = n
+		i++
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if err := it(a.SecuritySchemes[n]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IterateErrorKinds calls the given iterator passing in each error kind defined in the API,
+// resource and action definitions sorted in alphabetical order. Iteration stops if an iterator
+// returns an error and in this case IterateErrorKinds returns that error.
+func (a *APIDefinition) IterateErrorKinds(it ErrorKindIterator) error {
+	var kinds []*ErrorKindDefinition
+	for _, k := range a.ErrorKinds {
+		kinds = append(kinds, k)
+	}
+	for _, r := range a.Resources {
+		for _, k := range r.ErrorKinds {
+			kinds = append(kinds, k)
+		}
+		for _, act := range r.Actions {
+			for _, k := range act.ErrorKinds {
+				kinds = append(kinds, k)
+			}
+		}
+	}
+	sort.Sort(errorKindsByName(kinds))
+	for _, k := range kinds {
+		if err := it(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Example returns the example value defined in the design for the given data type if any, a
+// random value otherwise.
 // If the data type has validations then the example value validates them.
 // Example returns the same random value for a given api name (the random
 // generator is seeded after the api name).
@@ -427,6 +774,15 @@ func (a *APIDefinition) Example(dt DataType) interface{} {
 	return dt.Example(a.rand)
 }
 
+// AttributeExample returns the example value defined in the design for the given attribute if
+// any, a random value that validates the attribute validations otherwise.
+func (a *APIDefinition) AttributeExample(att *AttributeDefinition) interface{} {
+	if a.rand == nil {
+		a.rand = NewRandomGenerator(a.Name)
+	}
+	return att.Example(a.rand)
+}
+
 // MediaTypeWithIdentifier returns the media type with a matching
 // media type identifier. Two media type identifiers match if their
 // values sans suffix match. So for example "application/vnd.foo+xml",
@@ -535,6 +891,20 @@ func (r *ResourceDefinition) FullPath() string {
 	return httprouter.CleanPath(filepath.Join(basePath, r.BasePath))
 }
 
+// ResourceBasePath returns the base path of the resource with the given name computed using the
+// policy, the empty string if the policy is NoBasePathPolicy.
+func (p BasePathPolicyKind) ResourceBasePath(name string) string {
+	switch p {
+	case NameBasePathPolicy:
+		return "/" + name
+	case SnakeBasePathPolicy:
+		return "/" + inflect.Underscore(name)
+	case PluralBasePathPolicy:
+		return "/" + inflect.Pluralize(inflect.Underscore(name))
+	}
+	return ""
+}
+
 // Parent returns the parent resource if any, nil otherwise.
 func (r *ResourceDefinition) Parent() *ResourceDefinition {
 	if r.ParentName != "" {
@@ -545,6 +915,14 @@ func (r *ResourceDefinition) Parent() *ResourceDefinition {
 	return nil
 }
 
+// Context returns the generic definition name used in error messages.
+func (v *APIVersionDefinition) Context() string {
+	if v.Name != "" {
+		return fmt.Sprintf("version %#v", v.Name)
+	}
+	return "unnamed version"
+}
+
 // Context returns the generic definition name used in error messages.
 func (c *ContactDefinition) Context() string {
 	if c.Name != "" {
@@ -635,6 +1013,83 @@ func (r *ResponseDefinition) Merge(other *ResponseDefinition) {
 	}
 }
 
+// Context returns the generic definition name used in error messages.
+func (k *ErrorKindDefinition) Context() string {
+	var prefix, suffix string
+	if k.Name != "" {
+		prefix = fmt.Sprintf("error kind %#v", k.Name)
+	} else {
+		prefix = "unnamed error kind"
+	}
+	if k.Parent != nil {
+		suffix = fmt.Sprintf(" of %s", k.Parent.Context())
+	}
+	return prefix + suffix
+}
+
+// ResponseDefinition returns the definition of the response sent for errors of the given kind, nil
+// if there is none. The response is looked up in the parent action, resource and API definitions
+// and in the built-in responses in this order. The returned definition merges all the definitions
+// found with the same name.
+func (k *ErrorKindDefinition) ResponseDefinition() *ResponseDefinition {
+	var candidates []map[string]*ResponseDefinition
+	switch p := k.Parent.(type) {
+	case *ActionDefinition:
+		candidates = append(candidates, p.Responses)
+		if p.Parent != nil {
+			candidates = append(candidates, p.Parent.Responses)
+		}
+	case *ResourceDefinition:
+		candidates = append(candidates, p.Responses)
+	}
+	if Design != nil {
+		candidates = append(candidates, Design.Responses, Design.DefaultResponses)
+	}
+	var res *ResponseDefinition
+	for _, c := range candidates {
+		if r, ok := c[k.Response]; ok {
+			if res == nil {
+				res = r.Dup()
+			} else {
+				res.Merge(r)
+			}
+		}
+	}
+	return res
+}
+
+// Context returns the generic definition name used in error messages.
+func (s *SecuritySchemeDefinition) Context() string {
+	if s.Name != "" {
+		return fmt.Sprintf("security scheme %#v", s.Name)
+	}
+	return "unnamed security scheme"
+}
+
+// Context returns the generic definition name used in error messages.
+func (s *SecurityDefinition) Context() string {
+	if s.Parent != nil {
+		return fmt.Sprintf("security requirement of %s", s.Parent.Context())
+	}
+	return "security requirement"
+}
+
+// SchemeDefinition returns the definition of the security scheme required by s, nil if there
+// isn't one.
+func (s *SecurityDefinition) SchemeDefinition() *SecuritySchemeDefinition {
+	if s.Scheme == "" || Design == nil {
+		return nil
+	}
+	return Design.SecuritySchemes[s.Scheme]
+}
+
+// errorKindsByName implements sort.Interface to sort error kinds by name.
+type errorKindsByName []*ErrorKindDefinition
+
+func (e errorKindsByName) Len() int           { return len(e) }
+func (e errorKindsByName) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+func (e errorKindsByName) Less(i, j int) bool { return e[i].Name < e[j].Name }
+
 // Context returns the generic definition name used in error messages.
 func (r *ResponseTemplateDefinition) Context() string {
 	if r.Name != "" {
@@ -673,6 +1128,106 @@ func (a *ActionDefinition) AllParams() *AttributeDefinition {
 	return res
 }
 
+// ConsumedMediaTypes returns the media types of the request bodies accepted by the action. These
+// are the media types listed in the action definition or - if there aren't any - the ones listed
+// in the parent resource definition or else in the API definition. Actions with a multipart
+// payload that do not list media types consume "multipart/form-data". ConsumedMediaTypes returns
+// nil if none of the definitions list media types.
+func (a *ActionDefinition) ConsumedMediaTypes() []string {
+	if len(a.Consumes) > 0 {
+		return a.Consumes
+	}
+	if a.PayloadMultipart {
+		return []string{"multipart/form-data"}
+	}
+	if a.Parent != nil && len(a.Parent.Consumes) > 0 {
+		return a.Parent.Consumes
+	}
+	if Design != nil {
+		return Design.Consumes
+	}
+	return nil
+}
+
+// ProducedMediaTypes returns the media types of the response bodies produced by the action. These
+// are the media types listed in the action definition or - if there aren't any - the ones listed
+// in the parent resource definition or else in the API definition. ProducedMediaTypes returns nil
+// if none of the definitions list media types.
+func (a *ActionDefinition) ProducedMediaTypes() []string {
+	if len(a.Produces) > 0 {
+		return a.Produces
+	}
+	if a.Parent != nil && len(a.Parent.Produces) > 0 {
+		return a.Parent.Produces
+	}
+	if Design != nil {
+		return Design.Produces
+	}
+	return nil
+}
+
+// BodySizeLimit returns the maximum size in bytes of the request bodies accepted by the action.
+// This is the size listed in the action definition or - if there isn't one - the one listed in the
+// parent resource definition or else in the API definition. BodySizeLimit returns 0 if none of the
+// definitions list a size.
+func (a *ActionDefinition) BodySizeLimit() int64 {
+	if a.MaxBodySize > 0 {
+		return a.MaxBodySize
+	}
+	if a.Parent != nil && a.Parent.MaxBodySize > 0 {
+		return a.Parent.MaxBodySize
+	}
+	if Design != nil {
+		return Design.MaxBodySize
+	}
+	return 0
+}
+
+// SecurityRequirement returns the security requirement of the action. This is the requirement
+// listed in the action definition or - if there isn't one - the one listed in the parent resource
+// definition or else in the API definition. SecurityRequirement returns nil if none of the
+// definitions list a requirement or if the closest requirement disables security.
+func (a *ActionDefinition) SecurityRequirement() *SecurityDefinition {
+	sec := a.Security
+	if sec == nil && a.Parent != nil {
+		sec = a.Parent.Security
+	}
+	if sec == nil && Design != nil {
+		sec = Design.Security
+	}
+	if sec == nil || sec.Scheme == "" {
+		return nil
+	}
+	return sec
+}
+
+// Deprecated returns the deprecation of the action or else of its parent resource, nil if neither
+// is deprecated.
+func (a *ActionDefinition) Deprecated() *DeprecationDefinition {
+	if a.Deprecation != nil {
+		return a.Deprecation
+	}
+	if a.Parent != nil {
+		return a.Parent.Deprecation
+	}
+	return nil
+}
+
+// URLSchemes returns the URL schemes supported by the action. It looks for schemes in the action
+// definition first, then in the parent resource definition and finally in the API definition.
+func (a *ActionDefinition) URLSchemes() []string {
+	if len(a.Schemes) > 0 {
+		return a.Schemes
+	}
+	if a.Parent != nil && len(a.Parent.Schemes) > 0 {
+		return a.Parent.Schemes
+	}
+	if Design != nil {
+		return Design.Schemes
+	}
+	return nil
+}
+
 // AllParamNames returns the path and query string parameter names of the action across all its
 // routes.
 func (a *ActionDefinition) AllParamNames() []string {
@@ -734,17 +1289,43 @@ func (a *AttributeDefinition) Dup() *AttributeDefinition {
 		dupType = dupType.Dup()
 	}
 	dup := AttributeDefinition{
-		Type:         dupType,
-		Description:  a.Description,
-		Validations:  valDup,
-		Metadata:     a.Metadata,
-		DefaultValue: a.DefaultValue,
+		Type:             dupType,
+		Description:      a.Description,
+		Validations:      valDup,
+		Metadata:         a.Metadata,
+		DefaultValue:     a.DefaultValue,
+		ExampleValue:     a.ExampleValue,
+		CollectionFormat: a.CollectionFormat,
+		Deprecation:      a.Deprecation,
 	}
 	return &dup
 }
 
-// Example returns a random instance of the attribute that validates.
+// CollectionSeparator returns the string that separates the elements of the array parameter
+// values given the attribute collection format. It returns an empty string if the collection
+// format is "multi" in which case each element is given using a separate query string value.
+func (a *AttributeDefinition) CollectionSeparator() string {
+	switch a.CollectionFormat {
+	case "ssv":
+		return " "
+	case "tsv":
+		return "\t"
+	case "pipes":
+		return "|"
+	case "multi":
+		return ""
+	default:
+		return ","
+	}
+}
+
+// Example returns the example value set in the design if any, a random instance of the attribute
+// that validates otherwise. It returns nil if no such instance can be generated, for example
+// because the attribute uses a custom format.
 func (a *AttributeDefinition) Example(r *RandomGenerator) interface{} {
+	if a.ExampleValue != nil {
+		return a.ExampleValue
+	}
 	for _, v := range a.Validations {
 		switch actual := v.(type) {
 		case *EnumValidationDefinition:
@@ -777,47 +1358,28 @@ func (a *AttributeDefinition) Example(r *RandomGenerator) interface{} {
 				return "192.168.100.14/24"
 			case "regexp":
 				return r.faker.Characters(3) + ".*"
-			default:
-				panic("unknown format") // bug
 			}
+			// Values of custom formats registered with goa.RegisterFormat cannot be
+			// generated, leave the attribute without example rather than produce a value
+			// that does not validate.
+			return nil
 		case *PatternValidationDefinition:
 			res, err := regen.Generate(actual.Pattern)
 			if err != nil {
 				return r.faker.Name()
 			}
 			return res
-		case *MinimumValidationDefinition:
-			if a.Type.Kind() == IntegerKind {
-				res := r.Int()
-				for float64(res) < actual.Min {
-					res = r.Int()
-				}
-				return res
-			}
-			res := r.Float64()
-			for res < actual.Min {
-				res = r.Float64()
-			}
-			return res
-		case *MaximumValidationDefinition:
-			if a.Type.Kind() == IntegerKind {
-				res := r.Int()
-				for float64(res) > actual.Max {
-					res = r.Int()
-				}
-				return res
-			}
-			res := r.Float64()
-			for res > actual.Max {
-				res = r.Float64()
-			}
-			return res
+		case *MinimumValidationDefinition, *MaximumValidationDefinition,
+			*MultipleOfValidationDefinition:
+			return a.numericExample(r)
 		case *MinLengthValidationDefinition:
 			count := actual.MinLength + (r.Int() % 3)
 			if a.Type.IsArray() {
 				res := make([]interface{}, count)
 				for i := 0; i < count; i++ {
-					res[i] = a.Type.ToArray().ElemType.Example(r)
+					if res[i] = a.Type.ToArray().ElemType.Example(r); res[i] == nil {
+						return nil
+					}
 				}
 				return res
 			}
@@ -827,7 +1389,9 @@ func (a *AttributeDefinition) Example(r *RandomGenerator) interface{} {
 			if a.Type.IsArray() {
 				res := make([]interface{}, count)
 				for i := 0; i < count; i++ {
-					res[i] = a.Type.ToArray().ElemType.Example(r)
+					if res[i] = a.Type.ToArray().ElemType.Example(r); res[i] == nil {
+						return nil
+					}
 				}
 				return res
 			}
@@ -837,6 +1401,75 @@ func (a *AttributeDefinition) Example(r *RandomGenerator) interface{} {
 	return a.Type.Example(r)
 }
 
+// numericExample returns a random value that satisfies all the minimum, maximum and multiple of
+// validations of the attribute. The value is computed from the bounds rather than sampled so that
+// any combination of validations is handled. numericExample returns nil if no value satisfies the
+// validations.
+func (a *AttributeDefinition) numericExample(r *RandomGenerator) interface{} {
+	var min, max, step float64
+	var hasMin, hasMax, minExcl, maxExcl bool
+	for _, v := range a.Validations {
+		switch actual := v.(type) {
+		case *MinimumValidationDefinition:
+			min, minExcl, hasMin = actual.Min, actual.Exclusive, true
+		case *MaximumValidationDefinition:
+			max, maxExcl, hasMax = actual.Max, actual.Exclusive, true
+		case *MultipleOfValidationDefinition:
+			step = actual.MultipleOf
+		}
+	}
+	isInt := a.Type.Kind() == IntegerKind
+	if step <= 0 && !isInt {
+		lo, hi := min, max
+		if !hasMin {
+			lo = math.Min(0, hi-1)
+		}
+		if !hasMax {
+			hi = lo + 1
+		}
+		if lo > hi || lo == hi && (minExcl || maxExcl) {
+			return nil
+		}
+		res := lo + r.Float64()*(hi-lo)
+		if minExcl && res == lo || maxExcl && res == hi {
+			res = (lo + hi) / 2
+		}
+		return res
+	}
+
+	// Pick a multiple of step, integers are multiples of 1.
+	span := int64(100)
+	if step <= 0 {
+		step, span = 1, 1000
+	}
+	var lo, hi int64
+	if hasMin {
+		lo = int64(math.Ceil(min / step))
+		if minExcl && float64(lo)*step == min {
+			lo++
+		}
+	}
+	if hasMax {
+		hi = int64(math.Floor(max / step))
+		if maxExcl && float64(hi)*step == max {
+			hi--
+		}
+		if !hasMin && hi < 0 {
+			lo = hi - span + 1
+		}
+	} else {
+		hi = lo + span - 1
+	}
+	if lo > hi {
+		return nil
+	}
+	res := float64(lo+int64(r.Int())%(hi-lo+1)) * step
+	if isInt {
+		return int(res)
+	}
+	return res
+}
+
 // Merge merges the argument attributes into the target and returns the target overriding existing
 // attributes with identical names.
 // This only applies to attributes of type Object and Merge panics if the
@@ -884,6 +1517,9 @@ func (a *AttributeDefinition) inheritRecursive(parent *AttributeDefinition) {
 			if att.DefaultValue == nil {
 				att.DefaultValue = patt.DefaultValue
 			}
+			if att.ExampleValue == nil {
+				att.ExampleValue = patt.ExampleValue
+			}
 			if att.View == "" {
 				att.View = patt.View
 			}
@@ -972,6 +1608,44 @@ func (t *TraitDefinition) Context() string {
 	return "unnamed trait"
 }
 
+// Params returns the types of the arguments accepted by the trait, nil if the trait is not
+// parameterized.
+func (t *TraitDefinition) Params() []reflect.Type {
+	if t.Template == nil {
+		return nil
+	}
+	typ := reflect.TypeOf(t.Template)
+	params := make([]reflect.Type, typ.NumIn())
+	for i := 0; i < typ.NumIn(); i++ {
+		params[i] = typ.In(i)
+	}
+	return params
+}
+
+// IsVariadic returns true if the last argument of the trait template is variadic.
+func (t *TraitDefinition) IsVariadic() bool {
+	return t.Template != nil && reflect.TypeOf(t.Template).IsVariadic()
+}
+
+// ArgType returns the type of the trait argument at the given position taking into account
+// variadic templates.
+func (t *TraitDefinition) ArgType(i int) reflect.Type {
+	params := t.Params()
+	if last := len(params) - 1; t.IsVariadic() && i >= last {
+		return params[last].Elem()
+	}
+	return params[i]
+}
+
+// Context returns the generic definition name used in error messages.
+func (t *TraitUseDefinition) Context() string {
+	suffix := ""
+	if t.Parent != nil {
+		suffix = fmt.Sprintf(" in %s", t.Parent.Context())
+	}
+	return fmt.Sprintf("use of trait %#v%s", t.Name, suffix)
+}
+
 // Context returns the generic definition name used in error messages.
 func (r *RouteDefinition) Context() string {
 	return fmt.Sprintf(`route %s "%s" of %s`, r.Verb, r.Path, r.Parent.Context())
@@ -1021,6 +1695,11 @@ func (m *MaximumValidationDefinition) Context() string {
 	return "max value validation"
 }
 
+// Context returns the generic definition name used in error messages.
+func (m *MultipleOfValidationDefinition) Context() string {
+	return "multiple of validation"
+}
+
 // Context returns the generic definition name used in error messages.
 func (m *MinLengthValidationDefinition) Context() string {
 	return "min length validation"
@@ -1031,6 +1710,21 @@ func (m *MaxLengthValidationDefinition) Context() string {
 	return "max length validation"
 }
 
+// Context returns the generic definition name used in error messages.
+func (u *UniqueItemsValidationDefinition) Context() string {
+	return "unique items validation"
+}
+
+// Context returns the generic definition name used in error messages.
+func (m *MinPropertiesValidationDefinition) Context() string {
+	return "min properties validation"
+}
+
+// Context returns the generic definition name used in error messages.
+func (m *MaxPropertiesValidationDefinition) Context() string {
+	return "max properties validation"
+}
+
 // Context returns the generic definition name used in error messages.
 func (r *RequiredValidationDefinition) Context() string {
 	return "required field validation"
@@ -261,6 +261,45 @@ func Payload(p interface{}, dsls ...func()) {
 	}
 }
 
+// MultipartForm indicates that the action payload is sent using a multipart/form-data request body.
+// The payload attributes must be primitives, files or arrays of these. Use the File type to
+// describe the uploaded files:
+//
+//	Action("upload", func() {
+//		Routing(POST("/:id/label"))
+//		MultipartForm()
+//		Payload(func() {
+//			Member("label", File, "Label image")
+//			Member("caption", String)
+//			Required("label")
+//		})
+//	})
+//
+// The generated payload exposes the uploaded files as *multipart.FileHeader values and the other
+// attributes as validated values of their type.
+func MultipartForm() {
+	if a, ok := actionDefinition(true); ok {
+		a.PayloadMultipart = true
+	}
+}
+
+// RawBody indicates that the action reads the request body as a stream instead of having it decoded
+// into a payload, for example to handle large file uploads:
+//
+//	Action("upload", func() {
+//		Routing(PUT("/:id/data"))
+//		RawBody()
+//		Consumes("application/octet-stream")
+//	})
+//
+// The action code reads the body using the context Body method. RawBody and Payload are mutually
+// exclusive.
+func RawBody() {
+	if a, ok := actionDefinition(true); ok {
+		a.RawBody = true
+	}
+}
+
 // newAttribute creates a new attribute definition using the media type with the given identifier
 // as base type.
 func newAttribute(baseMT string) *design.AttributeDefinition {
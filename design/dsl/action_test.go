@@ -2,6 +2,7 @@ package dsl_test
 
 import (
 	"strconv"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -62,6 +63,265 @@ var _ = Describe("Action", func() {
 		})
 	})
 
+	Context("with media types", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(POST("/"))
+				Consumes("application/x-www-form-urlencoded")
+			}
+			API("test", func() {
+				Consumes("application/json")
+				Produces("application/json")
+			})
+		})
+
+		It("overrides the inherited media types", func() {
+			Ω(Errors).ShouldNot(HaveOccurred())
+			Ω(action.Consumes).Should(Equal([]string{"application/x-www-form-urlencoded"}))
+			Ω(action.ConsumedMediaTypes()).Should(Equal([]string{"application/x-www-form-urlencoded"}))
+			Ω(action.ProducedMediaTypes()).Should(Equal([]string{"application/json"}))
+		})
+	})
+
+	Context("with a raw body", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(PUT("/:id"))
+				RawBody()
+			}
+			API("test", func() {
+				MaxBodySize(1024)
+			})
+		})
+
+		It("produces a valid action that inherits the maximum body size", func() {
+			Ω(Errors).ShouldNot(HaveOccurred())
+			Ω(action.Validate()).ShouldNot(HaveOccurred())
+			Ω(action.RawBody).Should(BeTrue())
+			Ω(action.BodySizeLimit()).Should(Equal(int64(1024)))
+		})
+
+		Context("and a payload", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Routing(PUT("/:id"))
+					RawBody()
+					Payload(String)
+				}
+			})
+
+			It("produces an invalid action", func() {
+				Ω(Errors).ShouldNot(HaveOccurred())
+				Ω(action.Validate()).Should(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with a multipart payload", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(POST("/"))
+				MultipartForm()
+				Payload(func() {
+					Member("label", File)
+					Member("tags", ArrayOf(String))
+				})
+			}
+		})
+
+		It("produces a valid action that consumes multipart/form-data", func() {
+			Ω(Errors).ShouldNot(HaveOccurred())
+			Ω(action.Validate()).ShouldNot(HaveOccurred())
+			Ω(action.PayloadMultipart).Should(BeTrue())
+			Ω(action.ConsumedMediaTypes()).Should(Equal([]string{"multipart/form-data"}))
+		})
+
+		Context("with nested objects", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Routing(POST("/"))
+					MultipartForm()
+					Payload(func() {
+						Member("meta", func() {
+							Attribute("name")
+						})
+					})
+				}
+			})
+
+			It("produces an invalid action", func() {
+				Ω(Errors).ShouldNot(HaveOccurred())
+				Ω(action.Validate()).Should(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with a deprecation and schemes", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(GET("/:id"))
+				Deprecated("use bar", "2016-12-31")
+				Schemes("https", "wss")
+				Params(func() {
+					Param("id", Integer)
+					Param("verbose", Boolean, func() {
+						Deprecated()
+					})
+				})
+			}
+		})
+
+		It("sets the action deprecation and schemes", func() {
+			Ω(Errors).ShouldNot(HaveOccurred())
+			Ω(action.Validate()).ShouldNot(HaveOccurred())
+			Ω(action.Deprecated()).ShouldNot(BeNil())
+			Ω(action.Deprecated().Description).Should(Equal("use bar"))
+			Ω(action.Deprecated().Sunset).Should(Equal(time.Date(2016, time.December, 31, 0, 0, 0, 0, time.UTC)))
+			Ω(action.URLSchemes()).Should(Equal([]string{"https", "wss"}))
+			params := action.Params.Type.ToObject()
+			Ω(params["verbose"].Deprecation).ShouldNot(BeNil())
+			Ω(params["id"].Deprecation).Should(BeNil())
+		})
+
+		Context("with an invalid sunset date", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Routing(GET("/:id"))
+					Deprecated("use bar", "end of year")
+				}
+			})
+
+			It("fails", func() {
+				Ω(Errors).Should(HaveOccurred())
+			})
+		})
+
+		Context("with an invalid scheme", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Routing(GET("/:id"))
+					Schemes("ftp")
+				}
+			})
+
+			It("fails", func() {
+				Ω(Errors).Should(HaveOccurred())
+			})
+		})
+	})
+
+	Context("in a deprecated resource", func() {
+		JustBeforeEach(func() {
+			Design = nil
+			Errors = nil
+			Resource("res", func() {
+				Deprecated()
+				Schemes("wss")
+				Action(name, dsl)
+			})
+			RunDSL()
+			action = Design.Resources["res"].Actions[name]
+		})
+
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() { Routing(GET("/:id")) }
+		})
+
+		It("inherits the resource deprecation and schemes", func() {
+			Ω(Errors).ShouldNot(HaveOccurred())
+			Ω(action.Deprecated()).ShouldNot(BeNil())
+			Ω(action.URLSchemes()).Should(Equal([]string{"wss"}))
+		})
+	})
+
+	Context("with array parameters using collection formats", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(GET("/:ids"))
+				Params(func() {
+					Param("ids", ArrayOf(Integer), func() {
+						CollectionFormat("pipes")
+					})
+					Param("tags", ArrayOf(String), func() {
+						CollectionFormat("multi")
+					})
+				})
+			}
+		})
+
+		It("produces a valid action", func() {
+			Ω(Errors).ShouldNot(HaveOccurred())
+			Ω(action.Validate()).ShouldNot(HaveOccurred())
+			params := action.Params.Type.ToObject()
+			Ω(params["ids"].CollectionFormat).Should(Equal("pipes"))
+			Ω(params["ids"].CollectionSeparator()).Should(Equal("|"))
+			Ω(params["tags"].CollectionFormat).Should(Equal("multi"))
+		})
+
+		Context("with a multi path parameter", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Routing(GET("/:ids"))
+					Params(func() {
+						Param("ids", ArrayOf(Integer), func() {
+							CollectionFormat("multi")
+						})
+					})
+				}
+			})
+
+			It("produces an invalid action", func() {
+				Ω(Errors).ShouldNot(HaveOccurred())
+				Ω(action.Validate()).Should(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with a header and a parameter that have the same name", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(GET("/"))
+				Params(func() {
+					Param("account_id", Integer)
+				})
+				Headers(func() {
+					Header("Account-ID")
+				})
+			}
+		})
+
+		It("produces an invalid action", func() {
+			Ω(Errors).ShouldNot(HaveOccurred())
+			err := action.Validate()
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("header Account-ID and parameter account_id"))
+		})
+	})
+
+	Context("with a file payload that is not multipart", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(POST("/"))
+				Payload(func() {
+					Member("label", File)
+				})
+			}
+		})
+
+		It("produces an invalid action", func() {
+			Ω(Errors).ShouldNot(HaveOccurred())
+			Ω(action.Validate()).Should(HaveOccurred())
+		})
+	})
+
 	Context("with a string payload", func() {
 		BeforeEach(func() {
 			name = "foo"
@@ -2,8 +2,11 @@ package dsl
 
 import (
 	"fmt"
+	"mime"
 	"reflect"
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/raphael/goa/design"
 )
@@ -31,7 +34,14 @@ import (
 //		})
 //		Host("goa.design")                      // API hostname
 //		Scheme("http")
+//		Consumes("application/json")            // Media types of accepted request bodies
+//		Produces("application/json")            // Media types of response bodies
+//		JWTSecurity("jwt", func() {             // Security scheme used by actions
+//			Scope("read", "Read access")
+//		})
+//		Security("jwt")                         // Security requirement of all API actions
 // 		BasePath("/base/:param")                // Common base path to all API actions
+// 		BasePathPolicy("plural")                // Base path of resources that do not define one
 // 		BaseParams(func() {                     // Common parameters to all API actions
 // 			Param("param")
 // 		})
@@ -76,10 +86,12 @@ func API(name string, dsl func()) *design.APIDefinition {
 }
 
 // Description sets the definition description.
-// Description can be called inside API, Resource, Action or MediaType.
+// Description can be called inside API, Version, Resource, Action or MediaType.
 func Description(d string) {
 	if a, ok := apiDefinition(false); ok {
 		a.Description = d
+	} else if v, ok := versionDefinition(false); ok {
+		v.Description = d
 	} else if r, ok := resourceDefinition(false); ok {
 		r.Description = d
 	} else if a, ok := actionDefinition(false); ok {
@@ -90,6 +102,8 @@ func Description(d string) {
 		a.Description = d
 	} else if r, ok := responseDefinition(false); ok {
 		r.Description = d
+	} else if s, ok := securitySchemeDefinition(false); ok {
+		s.Description = d
 	} else if do, ok := docsDefinition(true); ok {
 		do.Description = d
 	}
@@ -115,6 +129,31 @@ func BasePath(val string) {
 	}
 }
 
+// BasePathPolicy sets the naming policy used to compute the base path of the resources that do not
+// call BasePath. The supported policies are "name" (the resource name as is), "snake" (the
+// snake_case resource name) and "plural" (the pluralized snake_case resource name). By default
+// resources that do not define a base path have their actions mounted directly under the API base
+// path. Resources may still opt out of the policy by calling BasePath explicitly, e.g. with "/".
+// Example:
+//
+//	API("cellar", func() {
+//		BasePathPolicy("plural")
+//	})
+//
+//	Resource("WineBottle", func() { // Base path is "/wine_bottles"
+//		// ...
+//	})
+func BasePathPolicy(policy string) {
+	if a, ok := apiDefinition(true); ok {
+		switch p := design.BasePathPolicyKind(policy); p {
+		case design.NameBasePathPolicy, design.SnakeBasePathPolicy, design.PluralBasePathPolicy:
+			a.BasePathPolicy = p
+		default:
+			ReportError(`invalid base path policy "%s", must be one of "name", "snake" or "plural"`, policy)
+		}
+	}
+}
+
 // BaseParams defines the API base path parameters. These parameters may correspond to wildcards in
 // the BasePath or URL query string values.
 // The DSL for describing each Param is the Attribute DSL.
@@ -153,17 +192,175 @@ func Host(host string) {
 	}
 }
 
-// Scheme sets the API URL schemes.
+// Schemes sets the URL schemes supported by the API, resource or action. Schemes can be called
+// inside API, Resource or Action. Action definitions inherit the schemes listed in their parent
+// resource or - if the resource does not list any - in the API definition:
+//
+//	Resource("stream", func() {
+//		Schemes("ws", "wss")
+//		// ...
+//	})
+func Schemes(vals ...string) {
+	var schemes *[]string
+	if a, ok := apiDefinition(false); ok {
+		schemes = &a.Schemes
+	} else if r, ok := resourceDefinition(false); ok {
+		schemes = &r.Schemes
+	} else if a, ok := actionDefinition(true); ok {
+		schemes = &a.Schemes
+	} else {
+		return
+	}
+	for _, v := range vals {
+		if v != "http" && v != "https" && v != "ws" && v != "wss" {
+			ReportError(`invalid scheme "%s", must be one of "http", "https", "ws" or "wss"`, v)
+		} else {
+			*schemes = append(*schemes, v)
+		}
+	}
+}
+
+// Scheme is an alias of Schemes.
 func Scheme(vals ...string) {
-	if a, ok := apiDefinition(true); ok {
-		for _, v := range vals {
-			if v != "http" && v != "https" && v != "ws" && v != "wss" {
-				ReportError(`invalid scheme "%s", must be one of "http", "https", "ws" or "wss"`, v)
-			} else {
-				a.Schemes = append(a.Schemes, v)
+	Schemes(vals...)
+}
+
+// Deprecated marks the resource, action or attribute as deprecated. The optional arguments are a
+// description of the deprecation - typically what to use instead - and the sunset date after
+// which the resource or action may stop being available. The date uses the RFC3339 format, with
+// or without the time part:
+//
+//	Action("show", func() {
+//		Deprecated("use the v2 show action instead", "2016-12-31")
+//		// ...
+//	})
+//
+// The generated controllers set the Deprecation and Sunset headers in the responses of deprecated
+// actions and log their use, the generated client tool warns when a deprecated action is called.
+func Deprecated(vals ...string) {
+	if len(vals) > 2 {
+		ReportError("too many arguments given to Deprecated")
+		return
+	}
+	dep := new(design.DeprecationDefinition)
+	if len(vals) > 0 {
+		dep.Description = vals[0]
+	}
+	if len(vals) > 1 {
+		sunset, err := time.Parse("2006-01-02", vals[1])
+		if err != nil {
+			sunset, err = time.Parse(time.RFC3339, vals[1])
+		}
+		if err != nil {
+			ReportError("invalid sunset date %#v, must use the RFC3339 format", vals[1])
+			return
+		}
+		dep.Sunset = sunset
+	}
+	if r, ok := resourceDefinition(false); ok {
+		r.Deprecation = dep
+	} else if a, ok := actionDefinition(false); ok {
+		a.Deprecation = dep
+	} else if a, ok := attributeDefinition(true); ok {
+		if !dep.Sunset.IsZero() {
+			ReportError("sunset dates only apply to deprecated resources and actions")
+			return
+		}
+		a.Deprecation = dep
+	}
+}
+
+// Consumes lists the media types of the request bodies accepted by the API, resource or action.
+// Consumes can be called inside API, Resource or Action. Action definitions inherit the media
+// types listed in their parent resource or - if the resource does not list any - in the API
+// definition:
+//
+//	Action("create", func() {
+//		Consumes("application/json", "application/x-www-form-urlencoded")
+//		// ...
+//	})
+//
+// The generated code responds with 415 to requests whose body uses a different media type.
+func Consumes(vals ...string) {
+	if mts, ok := mediaTypes(vals); ok {
+		if a, ok := apiDefinition(false); ok {
+			a.Consumes = appendMediaTypes(a.Consumes, mts)
+		} else if r, ok := resourceDefinition(false); ok {
+			r.Consumes = appendMediaTypes(r.Consumes, mts)
+		} else if a, ok := actionDefinition(true); ok {
+			a.Consumes = appendMediaTypes(a.Consumes, mts)
+		}
+	}
+}
+
+// Produces lists the media types of the response bodies produced by the API, resource or action.
+// Produces can be called inside API, Resource or Action. Action definitions inherit the media
+// types listed in their parent resource or - if the resource does not list any - in the API
+// definition.
+func Produces(vals ...string) {
+	if mts, ok := mediaTypes(vals); ok {
+		if a, ok := apiDefinition(false); ok {
+			a.Produces = appendMediaTypes(a.Produces, mts)
+		} else if r, ok := resourceDefinition(false); ok {
+			r.Produces = appendMediaTypes(r.Produces, mts)
+		} else if a, ok := actionDefinition(true); ok {
+			a.Produces = appendMediaTypes(a.Produces, mts)
+		}
+	}
+}
+
+// MaxBodySize sets the maximum size in bytes of the request bodies accepted by the API, resource
+// or action. MaxBodySize can be called inside API, Resource or Action. Action definitions inherit
+// the size set in their parent resource or - if the resource does not set one - in the API
+// definition:
+//
+//	Resource("upload", func() {
+//		MaxBodySize(10 * 1024 * 1024) // 10MB
+//		// ...
+//	})
+//
+// The generated code responds with 413 to requests whose body exceeds the size.
+func MaxBodySize(n int64) {
+	if n <= 0 {
+		ReportError("invalid maximum body size %d, must be positive", n)
+		return
+	}
+	if a, ok := apiDefinition(false); ok {
+		a.MaxBodySize = n
+	} else if r, ok := resourceDefinition(false); ok {
+		r.MaxBodySize = n
+	} else if a, ok := actionDefinition(true); ok {
+		a.MaxBodySize = n
+	}
+}
+
+// mediaTypes validates the given media types and returns them. It reports an error and returns
+// false if any of the values is not a valid media type.
+func mediaTypes(vals []string) ([]string, bool) {
+	for _, v := range vals {
+		if _, _, err := mime.ParseMediaType(v); err != nil || !strings.Contains(v, "/") {
+			ReportError(`invalid media type "%s"`, v)
+			return nil, false
+		}
+	}
+	return vals, true
+}
+
+// appendMediaTypes appends the media types in vals that are not already in mts.
+func appendMediaTypes(mts []string, vals []string) []string {
+	for _, v := range vals {
+		found := false
+		for _, mt := range mts {
+			if mt == v {
+				found = true
+				break
 			}
 		}
+		if !found {
+			mts = append(mts, v)
+		}
 	}
+	return mts
 }
 
 // Contact sets the API contact information.
@@ -340,8 +537,24 @@ func Title(val string) {
 }
 
 // Trait defines an API trait. A trait encapsulates arbitrary DSL that gets executed wherever the
-// trait is called via the UseTrait function.
-func Trait(name string, val ...func()) {
+// trait is called via the UseTrait function. The trait DSL may accept arguments in which case
+// UseTrait must be given values that match the number and types of the parameters, for example:
+//
+//	Trait("Paginated", func(max int) {
+//		Params(func() {
+//			Param("page", Integer, func() {
+//				Minimum(1)
+//			})
+//			Param("per_page", Integer, func() {
+//				Maximum(max)
+//			})
+//		})
+//	})
+//
+//	Action("list", func() {
+//		UseTrait("Paginated", 100)
+//	})
+func Trait(name string, val ...interface{}) {
 	if a, ok := apiDefinition(true); ok {
 		if len(val) < 1 {
 			ReportError("missing trait DSL for %s", name)
@@ -354,7 +567,17 @@ func Trait(name string, val ...func()) {
 			ReportError("multiple definitions for trait %s", name)
 			return
 		}
-		trait := &design.TraitDefinition{Name: name, DSL: val[0]}
+		trait := &design.TraitDefinition{Name: name}
+		if dsl, ok := val[0].(func()); ok {
+			trait.DSL = dsl
+		} else {
+			typ := reflect.TypeOf(val[0])
+			if typ == nil || typ.Kind() != reflect.Func || typ.NumOut() != 0 {
+				ReportError("trait DSL for %s must be a function with no return value, got %#v", name, val[0])
+				return
+			}
+			trait.Template = val[0]
+		}
 		if a.Traits == nil {
 			a.Traits = make(map[string]*design.TraitDefinition)
 		}
@@ -363,8 +586,9 @@ func Trait(name string, val ...func()) {
 }
 
 // UseTrait executes the API trait with the given name. UseTrait can be used inside a Resource,
-// Action or Attribute DSL.
-func UseTrait(name string) {
+// Action or Attribute DSL. The arguments are given to the trait DSL if it accepts any, mismatches
+// between the arguments and the trait parameters are reported when the design is validated.
+func UseTrait(name string, args ...interface{}) {
 	var def design.DSLDefinition
 	if r, ok := resourceDefinition(false); ok {
 		def = r
@@ -374,10 +598,28 @@ func UseTrait(name string) {
 		def = a
 	}
 	if def != nil {
-		if trait, ok := design.Design.Traits[name]; ok {
-			executeDSL(trait.DSL, def)
-		} else {
+		trait, ok := design.Design.Traits[name]
+		if !ok {
 			ReportError("unknown trait %s", name)
+			return
+		}
+		design.Design.TraitUses = append(design.Design.TraitUses,
+			&design.TraitUseDefinition{Name: name, Args: args, Parent: def})
+		if trait.ValidateArgs(args) != nil {
+			return
+		}
+		if trait.Template == nil {
+			executeDSL(trait.DSL, def)
+			return
+		}
+		in := make([]reflect.Value, len(args))
+		for i, arg := range args {
+			if arg == nil {
+				in[i] = reflect.Zero(trait.ArgType(i))
+			} else {
+				in[i] = reflect.ValueOf(arg)
+			}
 		}
+		executeDSL(func() { reflect.ValueOf(trait.Template).Call(in) }, def)
 	}
 }
@@ -254,5 +254,123 @@ var _ = Describe("API", func() {
 				Ω(Design.Traits).Should(HaveKey(traitName))
 			})
 		})
+
+		Context("with Consumes and Produces", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Consumes("application/json", "application/xml")
+					Consumes("application/json")
+					Produces("application/json")
+				}
+			})
+
+			It("sets the API media types", func() {
+				Ω(Design.Consumes).Should(Equal([]string{"application/json", "application/xml"}))
+				Ω(Design.Produces).Should(Equal([]string{"application/json"}))
+			})
+		})
+	})
+
+	Context("with an invalid media type in Consumes", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Consumes("json")
+			}
+		})
+
+		It("returns an error", func() {
+			Ω(Errors).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("BasePathPolicy", func() {
+	var policy string
+	var runErr error
+
+	BeforeEach(func() {
+		Design = nil
+		Errors = nil
+		policy = "plural"
+	})
+
+	JustBeforeEach(func() {
+		API("test", func() {
+			BasePathPolicy(policy)
+		})
+		Resource("WineBottle", func() {
+			Action("list", func() {
+				Routing(GET(""))
+			})
+		})
+		Resource("account", func() {
+			BasePath("/accounts/:id")
+			Action("show", func() {
+				Routing(GET(""))
+			})
+		})
+		runErr = RunDSL()
+	})
+
+	It("computes the base path of resources that do not define one", func() {
+		Ω(runErr).ShouldNot(HaveOccurred())
+		res := Design.Resources["WineBottle"]
+		Ω(res.BasePath).Should(Equal("/wine_bottles"))
+		Ω(res.ImplicitBasePath).Should(BeTrue())
+		Ω(res.Actions["list"].Routes[0].FullPath()).Should(Equal("/wine_bottles"))
+	})
+
+	It("keeps explicit base paths", func() {
+		res := Design.Resources["account"]
+		Ω(res.BasePath).Should(Equal("/accounts/:id"))
+		Ω(res.ImplicitBasePath).Should(BeFalse())
+	})
+
+	Context("using snake_case", func() {
+		BeforeEach(func() {
+			policy = "snake"
+		})
+
+		It("uses the snake_case resource name", func() {
+			Ω(Design.Resources["WineBottle"].BasePath).Should(Equal("/wine_bottle"))
+		})
+	})
+
+	Context("that is invalid", func() {
+		BeforeEach(func() {
+			policy = "camel"
+		})
+
+		It("fails", func() {
+			Ω(Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("causing route collisions", func() {
+		JustBeforeEach(func() {
+			Design = nil
+			Errors = nil
+			API("test", func() {
+				BasePathPolicy("name")
+			})
+			Resource("bottle", func() {
+				Action("list", func() {
+					Routing(GET(""))
+				})
+			})
+			Resource("bottles", func() {
+				BasePath("/bottle")
+				Action("index", func() {
+					Routing(GET(""))
+				})
+			})
+			runErr = RunDSL()
+		})
+
+		It("reports the collision", func() {
+			Ω(runErr).Should(HaveOccurred())
+			Ω(runErr.Error()).Should(ContainSubstring(`route "GET /bottle" collides with route "GET /bottle" of bottles action index`))
+		})
 	})
 })
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/raphael/goa"
 	"github.com/raphael/goa/design"
 )
 
@@ -15,7 +16,7 @@ import (
 // attributes may include other attributes. At the basic level an attribute has a name,
 // a type and optionally a default value and validation rules. The type of an attribute can be one of:
 //
-// * The primitive types Boolean, Integer, Number or String.
+// * The primitive types Boolean, Integer, Number, String, DateTime, UUID or Any.
 //
 // * A type defined via the Type function.
 //
@@ -179,8 +180,17 @@ func parseAttributeArgs(baseAttr *design.AttributeDefinition, args ...interface{
 	return dataType, description, dsl
 }
 
-// Header is an alias of Attribute.
+// Header is an alias of Attribute. When used in the DSL of an APIKeySecurity or JWTSecurity
+// scheme Header sets the name of the request header that holds the API key or token instead.
 func Header(name string, args ...interface{}) {
+	if s, ok := securitySchemeDefinition(false); ok {
+		if len(args) > 0 {
+			ReportError("too many arguments in call to Header")
+			return
+		}
+		s.In, s.ParamName = "header", name
+		return
+	}
 	Attribute(name, args...)
 }
 
@@ -206,6 +216,66 @@ func Default(def interface{}) {
 	}
 }
 
+// Example sets the example value of an attribute, type or media type. The example must be
+// compatible with the attribute type and satisfy its validations. It is used by the generated
+// documentation instead of a randomly generated value. Example:
+//
+//	Attribute("name", String, func() {
+//		Example("Corton Charlemagne")
+//	})
+//
+//	MediaType("application/vnd.goa.example.bottle", func() {
+//		Attributes(func() {
+//			Attribute("id", Integer)
+//			Attribute("name", String)
+//		})
+//		Example(map[string]interface{}{"id": 1, "name": "Corton Charlemagne"})
+//	})
+func Example(val interface{}) {
+	if val == nil {
+		ReportError("example value cannot be nil")
+		return
+	}
+	var a *design.AttributeDefinition
+	if m, ok := mediaTypeDefinition(false); ok {
+		a = m.AttributeDefinition
+	} else if a, ok = attributeDefinition(true); !ok {
+		return
+	}
+	if a.Type != nil && !a.Type.IsCompatible(val) {
+		ReportError("example value %#v is incompatible with attribute of type %s",
+			val, a.Type.Name())
+		return
+	}
+	a.ExampleValue = val
+}
+
+// CollectionFormat sets the format used to serialize the values of an array parameter or header.
+// The supported formats are "csv" (comma separated values, the default), "ssv" (space separated
+// values), "tsv" (tab separated values), "pipes" (pipe separated values) and "multi" (one query
+// string value per element, e.g. "?tag=a&tag=b"). The "multi" format only applies to query string
+// parameters. Example:
+//
+//	 Param("tags", ArrayOf(String), func() {
+//	 	CollectionFormat("multi")
+//	 })
+func CollectionFormat(format string) {
+	if a, ok := attributeDefinition(true); ok {
+		if a.Type != nil && !a.Type.IsArray() {
+			ReportError("collection format is only supported on array attributes, %s is not an array",
+				a.Type.Name())
+			return
+		}
+		switch format {
+		case "csv", "ssv", "tsv", "pipes", "multi":
+			a.CollectionFormat = format
+		default:
+			ReportError("invalid collection format %#v, must be one of csv, ssv, tsv, pipes or multi",
+				format)
+		}
+	}
+}
+
 // Enum adds a "enum" validation to the attribute.
 // See http://json-schema.org/latest/json-schema-validation.html#anchor76.
 func Enum(val ...interface{}) {
@@ -275,12 +345,27 @@ var SupportedValidationFormats = []string{
 // "cidr": RFC4632 or RFC4291 CIDR notation IP address
 //
 // "regexp": RE2 regular expression
+//
+// Format also accepts the names of the custom formats registered with goa.RegisterFormat, for
+// example:
+//
+//	func init() {
+//		goa.RegisterFormat("semver", ValidateSemver)
+//	}
+//
+//	var _ = Type("Release", func() {
+//		Attribute("version", String, func() {
+//			Format("semver")
+//		})
+//	})
+//
+// The application must register the same format for the generated code to validate its values.
 func Format(f string) {
 	if a, ok := attributeDefinition(true); ok {
 		if a.Type != nil && a.Type.Kind() != design.StringKind {
 			incompatibleAttributeType("format", a.Type.Name(), "a string")
 		} else {
-			supported := false
+			supported := goa.LookupFormat(goa.Format(f)) != nil
 			for _, s := range SupportedValidationFormats {
 				if s == f {
 					supported = true
@@ -288,8 +373,12 @@ func Format(f string) {
 				}
 			}
 			if !supported {
+				formats := append([]string(nil), SupportedValidationFormats...)
+				for _, c := range goa.RegisteredFormats() {
+					formats = append(formats, string(c))
+				}
 				ReportError("unsupported format %#v, supported formats are: %s",
-					f, strings.Join(SupportedValidationFormats, ", "))
+					f, strings.Join(formats, ", "))
 			} else {
 				a.Validations = append(a.Validations, &design.FormatValidationDefinition{Format: f})
 			}
@@ -297,6 +386,18 @@ func Format(f string) {
 	}
 }
 
+// formatChecker implements design.FormatChecker using the goa runtime format validators.
+type formatChecker struct{}
+
+func init() {
+	design.Formats = formatChecker{}
+}
+
+// CheckFormat validates val using the validator of the given built-in or registered format.
+func (formatChecker) CheckFormat(format, val string) error {
+	return goa.ValidateFormat(goa.Format(format), val)
+}
+
 // Pattern adds a "pattern" validation to the attribute.
 // See http://json-schema.org/latest/json-schema-validation.html#anchor33.
 func Pattern(p string) {
@@ -317,57 +418,94 @@ func Pattern(p string) {
 // Minimum adds a "minimum" validation to the attribute.
 // See http://json-schema.org/latest/json-schema-validation.html#anchor21.
 func Minimum(val interface{}) {
-	if a, ok := attributeDefinition(true); ok {
-		if a.Type != nil && a.Type.Kind() != design.IntegerKind && a.Type.Kind() != design.NumberKind {
-			incompatibleAttributeType("minimum", a.Type.Name(), "an integer or a number")
-		} else {
-			var f float64
-			switch v := val.(type) {
-			case float32, float64, int, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-				f = reflect.ValueOf(v).Convert(reflect.TypeOf(float64(0.0))).Float()
-			case string:
-				var err error
-				f, err = strconv.ParseFloat(v, 64)
-				if err != nil {
-					ReportError("invalid number value %#v", v)
-					return
-				}
-			default:
-				ReportError("invalid number value %#v", v)
-				return
-			}
-			a.Validations = append(a.Validations, &design.MinimumValidationDefinition{Min: f})
-		}
-	}
+	minimum("minimum", val, false)
+}
+
+// ExclusiveMinimum adds a "minimum" validation with "exclusiveMinimum" set to the attribute: the
+// attribute value must be strictly greater than val.
+// See http://json-schema.org/latest/json-schema-validation.html#anchor21.
+func ExclusiveMinimum(val interface{}) {
+	minimum("exclusive minimum", val, true)
 }
 
 // Maximum adds a "maximum" validation to the attribute.
 // See http://json-schema.org/latest/json-schema-validation.html#anchor17.
 func Maximum(val interface{}) {
-	if a, ok := attributeDefinition(true); ok {
-		if a.Type != nil && a.Type.Kind() != design.IntegerKind && a.Type.Kind() != design.NumberKind {
-			incompatibleAttributeType("maximum", a.Type.Name(), "an integer or a number")
-		} else {
-			var f float64
-			switch v := val.(type) {
-			case float32, float64, int, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-				f = reflect.ValueOf(v).Convert(reflect.TypeOf(float64(0.0))).Float()
-			case string:
-				var err error
-				f, err = strconv.ParseFloat(v, 64)
-				if err != nil {
-					ReportError("invalid number value %#v", v)
-					return
-				}
-			default:
-				ReportError("invalid number value %#v", v)
+	maximum("maximum", val, false)
+}
+
+// ExclusiveMaximum adds a "maximum" validation with "exclusiveMaximum" set to the attribute: the
+// attribute value must be strictly lesser than val.
+// See http://json-schema.org/latest/json-schema-validation.html#anchor17.
+func ExclusiveMaximum(val interface{}) {
+	maximum("exclusive maximum", val, true)
+}
+
+// MultipleOf adds a "multipleOf" validation to the attribute. val must be strictly greater than 0.
+// See http://json-schema.org/latest/json-schema-validation.html#anchor14.
+func MultipleOf(val interface{}) {
+	if a, ok := numericAttributeDefinition("multiple of"); ok {
+		if f, ok := numberValue(val); ok {
+			if f <= 0 {
+				ReportError("invalid multiple of value %#v, must be strictly greater than 0", val)
 				return
 			}
-			a.Validations = append(a.Validations, &design.MaximumValidationDefinition{Max: f})
+			a.Validations = append(a.Validations, &design.MultipleOfValidationDefinition{MultipleOf: f})
+		}
+	}
+}
+
+// minimum implements Minimum and ExclusiveMinimum.
+func minimum(validation string, val interface{}, exclusive bool) {
+	if a, ok := numericAttributeDefinition(validation); ok {
+		if f, ok := numberValue(val); ok {
+			a.Validations = append(a.Validations, &design.MinimumValidationDefinition{Min: f, Exclusive: exclusive})
+		}
+	}
+}
+
+// maximum implements Maximum and ExclusiveMaximum.
+func maximum(validation string, val interface{}, exclusive bool) {
+	if a, ok := numericAttributeDefinition(validation); ok {
+		if f, ok := numberValue(val); ok {
+			a.Validations = append(a.Validations, &design.MaximumValidationDefinition{Max: f, Exclusive: exclusive})
 		}
 	}
 }
 
+// numericAttributeDefinition returns the attribute being defined if its type is an integer or a
+// number, it reports an error and returns false otherwise.
+func numericAttributeDefinition(validation string) (*design.AttributeDefinition, bool) {
+	a, ok := attributeDefinition(true)
+	if !ok {
+		return nil, false
+	}
+	if a.Type != nil && a.Type.Kind() != design.IntegerKind && a.Type.Kind() != design.NumberKind {
+		incompatibleAttributeType(validation, a.Type.Name(), "an integer or a number")
+		return nil, false
+	}
+	return a, true
+}
+
+// numberValue converts val to a float64. val may be any Go numeric value or a string containing
+// a number. numberValue reports an error and returns false if val cannot be converted.
+func numberValue(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case float32, float64, int, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
+		return reflect.ValueOf(v).Convert(reflect.TypeOf(float64(0.0))).Float(), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			ReportError("invalid number value %#v", v)
+			return 0, false
+		}
+		return f, true
+	default:
+		ReportError("invalid number value %#v", v)
+		return 0, false
+	}
+}
+
 // MinLength adss a "minItems" validation to the attribute.
 // See http://json-schema.org/latest/json-schema-validation.html#anchor45.
 func MinLength(val int) {
@@ -392,6 +530,45 @@ func MaxLength(val int) {
 	}
 }
 
+// UniqueItems adds a "uniqueItems" validation to the attribute: the elements of the array must all
+// be different.
+// See http://json-schema.org/latest/json-schema-validation.html#anchor49.
+func UniqueItems() {
+	if a, ok := attributeDefinition(true); ok {
+		if a.Type != nil && a.Type.Kind() != design.ArrayKind {
+			incompatibleAttributeType("unique items", a.Type.Name(), "an array")
+		} else {
+			a.Validations = append(a.Validations, &design.UniqueItemsValidationDefinition{})
+		}
+	}
+}
+
+// MinProperties adds a "minProperties" validation to the attribute: the hash must contain at
+// least val entries.
+// See http://json-schema.org/latest/json-schema-validation.html#anchor57.
+func MinProperties(val int) {
+	if a, ok := attributeDefinition(true); ok {
+		if a.Type != nil && a.Type.Kind() != design.HashKind {
+			incompatibleAttributeType("minimum properties", a.Type.Name(), "a hash")
+		} else {
+			a.Validations = append(a.Validations, &design.MinPropertiesValidationDefinition{MinProperties: val})
+		}
+	}
+}
+
+// MaxProperties adds a "maxProperties" validation to the attribute: the hash must contain at most
+// val entries.
+// See http://json-schema.org/latest/json-schema-validation.html#anchor54.
+func MaxProperties(val int) {
+	if a, ok := attributeDefinition(true); ok {
+		if a.Type != nil && a.Type.Kind() != design.HashKind {
+			incompatibleAttributeType("maximum properties", a.Type.Name(), "a hash")
+		} else {
+			a.Validations = append(a.Validations, &design.MaxPropertiesValidationDefinition{MaxProperties: val})
+		}
+	}
+}
+
 // Required adds a "required" validation to the attribute.
 // See http://json-schema.org/latest/json-schema-validation.html#anchor61.
 func Required(names ...string) {
@@ -161,6 +161,149 @@ var _ = Describe("Attribute", func() {
 		})
 	})
 
+	Context("with a collection format", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = ArrayOf(String)
+			dsl = func() { CollectionFormat("ssv") }
+		})
+
+		It("sets the collection format", func() {
+			Ω(Errors).ShouldNot(HaveOccurred())
+			o := parent.Type.(Object)
+			Ω(o[name].CollectionFormat).Should(Equal("ssv"))
+			Ω(o[name].CollectionSeparator()).Should(Equal(" "))
+		})
+
+		Context("that is invalid", func() {
+			BeforeEach(func() {
+				dsl = func() { CollectionFormat("semicolons") }
+			})
+
+			It("fails", func() {
+				Ω(Errors).Should(HaveOccurred())
+			})
+		})
+
+		Context("on an attribute that is not an array", func() {
+			BeforeEach(func() {
+				dataType = String
+			})
+
+			It("fails", func() {
+				Ω(Errors).Should(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with an example", func() {
+		const example = "example"
+
+		BeforeEach(func() {
+			name = "foo"
+			dataType = String
+			dsl = func() { Example(example) }
+		})
+
+		It("sets the example value", func() {
+			Ω(Errors).ShouldNot(HaveOccurred())
+			Ω(Design.Validate()).ShouldNot(HaveOccurred())
+			o := parent.Type.(Object)
+			Ω(o[name].ExampleValue).Should(Equal(example))
+			Ω(Design.AttributeExample(o[name])).Should(Equal(example))
+			Ω(Design.Example(Design.Types["type"])).Should(Equal(map[string]interface{}{name: example}))
+		})
+
+		Context("that is incompatible with the attribute type", func() {
+			BeforeEach(func() {
+				dataType = Integer
+			})
+
+			It("fails", func() {
+				Ω(Errors).Should(HaveOccurred())
+			})
+		})
+
+		Context("that does not satisfy the attribute validations", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					MinLength(10)
+					Example(example)
+				}
+			})
+
+			It("produces a validation error", func() {
+				Ω(Errors).ShouldNot(HaveOccurred())
+				err := Design.Validate()
+				Ω(err).Should(HaveOccurred())
+				Ω(err.Error()).Should(ContainSubstring("invalid example"))
+			})
+		})
+
+		Context("that does not satisfy the attribute format", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Format("email")
+					Example(example)
+				}
+			})
+
+			It("produces a validation error", func() {
+				Ω(Errors).ShouldNot(HaveOccurred())
+				err := Design.Validate()
+				Ω(err).Should(HaveOccurred())
+				Ω(err.Error()).Should(ContainSubstring("invalid example"))
+			})
+		})
+
+		Context("on an object attribute", func() {
+			BeforeEach(func() {
+				dataType = nil
+				dsl = func() {
+					Attribute("bar", Integer, func() {
+						Maximum(10)
+					})
+					Required("bar")
+					Example(map[string]interface{}{"bar": 1})
+				}
+			})
+
+			It("validates the example members", func() {
+				Ω(Errors).ShouldNot(HaveOccurred())
+				Ω(Design.Validate()).ShouldNot(HaveOccurred())
+			})
+
+			Context("with an invalid member", func() {
+				BeforeEach(func() {
+					dsl = func() {
+						Attribute("bar", Integer, func() {
+							Maximum(10)
+						})
+						Example(map[string]interface{}{"bar": 100})
+					}
+				})
+
+				It("produces a validation error", func() {
+					Ω(Design.Validate()).Should(HaveOccurred())
+				})
+			})
+
+			Context("with a missing required member", func() {
+				BeforeEach(func() {
+					dsl = func() {
+						Attribute("bar", Integer)
+						Required("bar")
+						Example(map[string]interface{}{})
+					}
+				})
+
+				It("produces a validation error", func() {
+					Ω(Design.Validate()).Should(HaveOccurred())
+				})
+			})
+		})
+	})
+
 	Context("with child attributes", func() {
 		const childAtt = "childAtt"
 
@@ -54,6 +54,7 @@ var mediaTypeCount int
 // 	})
 //
 // This function returns the media type definition so it can be referred to throughout the DSL.
+// Media types defined in a Version DSL belong to that API version, see Version.
 func MediaType(identifier string, dsl func()) *design.MediaTypeDefinition {
 	if design.Design == nil {
 		InitDesign()
@@ -61,65 +62,84 @@ func MediaType(identifier string, dsl func()) *design.MediaTypeDefinition {
 	if design.Design.MediaTypes == nil {
 		design.Design.MediaTypes = make(map[string]*design.MediaTypeDefinition)
 	}
-	if topLevelDefinition(true) {
-		// Validate Media Type
-		identifier, params, err := mime.ParseMediaType(identifier)
-		if err != nil {
-			ReportError("invalid media type identifier %#v: %s",
-				identifier, err)
-			// We don't return so that other errors may be
-			// captured in this one run.
-			identifier = "plain/text"
-		}
-		canonicalID := design.CanonicalIdentifier(identifier)
-		// Validate that media type identifier doesn't clash
-		if _, ok := design.Design.MediaTypes[canonicalID]; ok {
-			ReportError("media type %#v is defined twice", identifier)
-			return nil
-		}
-		parts := strings.Split(identifier, "+")
-		// Make sure it has the `+json` suffix (TBD update when goa supports other encodings)
-		if len(parts) > 1 {
-			parts = parts[1:]
-			found := false
-			for _, part := range parts {
-				if part == "json" {
-					found = true
-					break
-				}
-			}
-			if !found {
-				identifier += "+json"
+	if _, ok := versionDefinition(false); ok || topLevelDefinition(true) {
+		return newMediaType(identifier, dsl)
+	}
+	return nil
+}
+
+// newMediaType creates and registers a media type with the given identifier and DSL. It does not
+// run the DSL.
+func newMediaType(identifier string, dsl func()) *design.MediaTypeDefinition {
+	// Validate Media Type
+	identifier, params, err := mime.ParseMediaType(identifier)
+	if err != nil {
+		ReportError("invalid media type identifier %#v: %s",
+			identifier, err)
+		// We don't return so that other errors may be
+		// captured in this one run.
+		identifier = "plain/text"
+	}
+	canonicalID := design.CanonicalIdentifier(identifier)
+	// Validate that media type identifier doesn't clash
+	mts := design.Design.MediaTypes
+	if v := design.Design.APIVersion; v != nil {
+		mts = v.MediaTypes
+	}
+	if _, ok := mts[canonicalID]; ok {
+		ReportError("media type %#v is defined twice", identifier)
+		return nil
+	}
+	parts := strings.Split(identifier, "+")
+	// Make sure it has the `+json` suffix (TBD update when goa supports other encodings)
+	if len(parts) > 1 {
+		parts = parts[1:]
+		found := false
+		for _, part := range parts {
+			if part == "json" {
+				found = true
+				break
 			}
 		}
-		identifier = mime.FormatMediaType(identifier, params)
-		// Concoct a Go type name from the identifier, should it be possible to set it in the DSL?
-		// pros: control the type name generated, cons: not needed in DSL, adds one more thing to worry about
-		lastPart := identifier
-		lastPartIndex := strings.LastIndex(identifier, "/")
-		if lastPartIndex > -1 {
-			lastPart = identifier[lastPartIndex+1:]
-		}
-		plusIndex := strings.Index(lastPart, "+")
-		if plusIndex > 0 {
-			lastPart = lastPart[:plusIndex]
-		}
-		lastPart = strings.TrimPrefix(lastPart, "vnd.")
-		elems := strings.Split(lastPart, ".")
-		for i, e := range elems {
-			elems[i] = strings.Title(e)
-		}
-		typeName := strings.Join(elems, "")
-		if typeName == "" {
-			mediaTypeCount++
-			typeName = fmt.Sprintf("MediaType%d", mediaTypeCount)
+		if !found {
+			identifier += "+json"
 		}
-		// Now save the type in the API media types map
-		mt := design.NewMediaTypeDefinition(typeName, identifier, dsl)
-		design.Design.MediaTypes[canonicalID] = mt
-		return mt
 	}
-	return nil
+	identifier = mime.FormatMediaType(identifier, params)
+	// Concoct a Go type name from the identifier, should it be possible to set it in the DSL?
+	// pros: control the type name generated, cons: not needed in DSL, adds one more thing to worry about
+	lastPart := identifier
+	lastPartIndex := strings.LastIndex(identifier, "/")
+	if lastPartIndex > -1 {
+		lastPart = identifier[lastPartIndex+1:]
+	}
+	plusIndex := strings.Index(lastPart, "+")
+	if plusIndex > 0 {
+		lastPart = lastPart[:plusIndex]
+	}
+	lastPart = strings.TrimPrefix(lastPart, "vnd.")
+	elems := strings.Split(lastPart, ".")
+	for i, e := range elems {
+		elems[i] = strings.Title(e)
+	}
+	typeName := strings.Join(elems, "")
+	if typeName == "" {
+		mediaTypeCount++
+		typeName = fmt.Sprintf("MediaType%d", mediaTypeCount)
+	}
+	// Now save the type in the API media types map
+	mt := design.NewMediaTypeDefinition(typeName, identifier, dsl)
+	registerMediaType(canonicalID, mt)
+	return mt
+}
+
+// registerMediaType saves the media type in the API definition and in the definition of the API
+// version being defined if any.
+func registerMediaType(canonicalID string, mt *design.MediaTypeDefinition) {
+	design.Design.MediaTypes[canonicalID] = mt
+	if v := design.Design.APIVersion; v != nil {
+		v.MediaTypes[canonicalID] = mt
+	}
 }
 
 // Media sets a response media type by name or by reference using a value returned by MediaType:
@@ -129,7 +149,8 @@ func MediaType(identifier string, dsl func()) *design.MediaTypeDefinition {
 //		Media("application/json")
 //	})
 //
-// Media can be used inside Response or ResponseTemplate.
+// Media can be used inside Response or ResponseTemplate. When used inside Response Media may also
+// be given a media type DSL to define the response media type inline, see Response.
 func Media(val interface{}) {
 	if r, ok := responseDefinition(true); ok {
 		if m, ok := val.(*design.MediaTypeDefinition); ok {
@@ -138,8 +159,12 @@ func Media(val interface{}) {
 			}
 		} else if identifier, ok := val.(string); ok {
 			r.MediaType = identifier
+		} else if dsl, ok := val.(func()); ok {
+			if m := inlineMediaType(r, dsl); m != nil {
+				r.MediaType = m.Identifier
+			}
 		} else {
-			ReportError("media type must be a string or a pointer to MediaTypeDefinition, got %#v", val)
+			ReportError("media type must be a string, a pointer to MediaTypeDefinition or a DSL, got %#v", val)
 		}
 	}
 }
@@ -365,7 +390,69 @@ func CollectionOf(v interface{}, dsl ...func()) *design.MediaTypeDefinition {
 		}
 	})
 	if executeDSL(mt.DSL, mt) {
-		design.Design.MediaTypes[design.CanonicalIdentifier(id)] = mt
+		registerMediaType(design.CanonicalIdentifier(id), mt)
 	}
 	return mt
 }
+
+// ErrorMedia declares the media type of the error documents written by goa in the API definition.
+// This media type describes the "application/problem+json" documents defined in RFC 7807 and
+// written by the default error handlers. ErrorMedia must appear in the API DSL:
+//
+//	API("cellar", func() {
+//		ErrorMedia()
+//	})
+//
+// All the responses with a status code greater or equal to 400 that do not specify a media type
+// use the error media type so that clients and the Swagger specification describe the body of
+// error responses.
+func ErrorMedia() {
+	if a, ok := apiDefinition(true); ok {
+		if a.MediaTypes == nil {
+			a.MediaTypes = make(map[string]*design.MediaTypeDefinition)
+		}
+		canonicalID := design.CanonicalIdentifier(errorMediaIdentifier)
+		if _, ok := a.MediaTypes[canonicalID]; ok {
+			ReportError("media type %#v is defined twice", errorMediaIdentifier)
+			return
+		}
+		mt := design.NewMediaTypeDefinition("ErrorDocument", errorMediaIdentifier, errorMediaDSL)
+		a.MediaTypes[canonicalID] = mt
+		a.ErrorMedia = mt
+	}
+}
+
+// errorMediaIdentifier is the identifier of the media type created by ErrorMedia.
+const errorMediaIdentifier = "application/problem+json"
+
+// errorMediaDSL describes the goa error document.
+func errorMediaDSL() {
+	detail := design.Object{
+		"code":   &design.AttributeDefinition{Type: design.Integer, Description: "goa error code"},
+		"title":  &design.AttributeDefinition{Type: design.String, Description: "Error code title"},
+		"detail": &design.AttributeDefinition{Type: design.String, Description: "Error message"},
+		"field":  &design.AttributeDefinition{Type: design.String, Description: "Path to the offending field"},
+	}
+	Description("Error response document, see RFC 7807")
+	Attributes(func() {
+		Attribute("type", design.String, "URI reference that identifies the problem type")
+		Attribute("title", design.String, "Short, human-readable summary of the problem type")
+		Attribute("status", design.Integer, "HTTP status code")
+		Attribute("detail", design.String, "Explanation specific to this occurrence of the problem")
+		Attribute("code", design.Integer, "goa error code, 0 for generic errors")
+		Attribute("kind", design.String, "Name of the error kind defined in the design if any")
+		Attribute("request_id", design.String, "ID of the request that caused the problem")
+		Attribute("errors", ArrayOf(detail), "Individual errors")
+		Required("type", "title", "status", "code")
+	})
+	View("default", func() {
+		Attribute("type")
+		Attribute("title")
+		Attribute("status")
+		Attribute("detail")
+		Attribute("code")
+		Attribute("kind")
+		Attribute("request_id")
+		Attribute("errors")
+	})
+}
@@ -295,3 +295,39 @@ var _ = Describe("CollectionOf", func() {
 		})
 	})
 })
+
+var _ = Describe("ErrorMedia", func() {
+	BeforeEach(func() {
+		Design = nil
+		Errors = nil
+		API("test", func() {
+			ErrorMedia()
+		})
+		Resource("foo", func() {
+			Action("show", func() {
+				Routing(GET(""))
+				Response(OK)
+				Response(NotFound)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		RunDSL()
+		Ω(Errors).ShouldNot(HaveOccurred())
+	})
+
+	It("defines the error media type", func() {
+		Ω(Design.ErrorMedia).ShouldNot(BeNil())
+		Ω(Design.ErrorMedia.Identifier).Should(Equal("application/problem+json"))
+		Ω(Design.MediaTypeWithIdentifier("application/problem+json")).Should(Equal(Design.ErrorMedia))
+		Ω(Design.ErrorMedia.Type.ToObject()).Should(HaveKey("code"))
+		Ω(Design.ErrorMedia.Views).Should(HaveKey("default"))
+	})
+
+	It("uses the error media type in error responses", func() {
+		a := Design.Resources["foo"].Actions["show"]
+		Ω(a.Responses[NotFound].MediaType).Should(Equal("application/problem+json"))
+		Ω(a.Responses[OK].MediaType).ShouldNot(Equal("application/problem+json"))
+	})
+})
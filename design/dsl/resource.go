@@ -18,7 +18,8 @@ import "github.com/raphael/goa/design"
 // hrefs to the resource collection or resource collection items. By default goa uses the show
 // action if present to compute a resource href (basically concatenating the parent resource href
 // with the base path and show action path). The resource definition may specify a canonical action
-// via CanonicalActionName to override that default. Here is an example of a resource definition:
+// via CanonicalActionName to override that default. Resources defined in a Version DSL belong to
+// that API version, see Version. Here is an example of a resource definition:
 //
 //	Resource("bottle", func() {
 //		Description("A wine bottle") // Resource description
@@ -27,6 +28,8 @@ import "github.com/raphael/goa/design"
 //		Parent("account")            // Name of parent resource if any
 //		CanonicalActionName("get")   // Name of action that returns canonical representation if not "show"
 //		UseTrait("Authenticated")    // Included trait if any, can appear more than once
+//		Schemes("https")             // Supported URL schemes if not the API schemes
+//		Deprecated("use bins")       // Deprecation notice if the resource is deprecated
 //
 //	 	Action("show", func() {      // Action definition, can appear more than once
 //			// ... Action DSL
@@ -40,12 +43,15 @@ func Resource(name string, dsl func()) *design.ResourceDefinition {
 		design.Design.Resources = make(map[string]*design.ResourceDefinition)
 	}
 	var resource *design.ResourceDefinition
-	if topLevelDefinition(true) {
+	if _, ok := versionDefinition(false); ok || topLevelDefinition(true) {
 		if _, ok := design.Design.Resources[name]; ok {
 			ReportError("resource %#v is defined twice", name)
 			return nil
 		}
 		resource = design.NewResourceDefinition(name, dsl)
+		if v := design.Design.APIVersion; v != nil {
+			resource.Version = v.Name
+		}
 		design.Design.Resources[name] = resource
 	}
 	return resource
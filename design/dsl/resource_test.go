@@ -1,6 +1,8 @@
 package dsl_test
 
 import (
+	"strings"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	. "github.com/raphael/goa/design"
@@ -236,4 +238,92 @@ var _ = Describe("Resource", func() {
 			Ω(res.Description).Should(Equal(description))
 		})
 	})
+	Context("with a parameterized trait", func() {
+		const traitName = "descTrait"
+		var args []interface{}
+
+		BeforeEach(func() {
+			name = "foo"
+			args = []interface{}{"desc", 2}
+			dsl = func() { UseTrait(traitName, args...) }
+			API("test", func() {
+				Trait(traitName, func(desc string, count int) {
+					Description(strings.Repeat(desc, count))
+				})
+			})
+		})
+
+		It("runs the trait with the given arguments", func() {
+			Ω(res).ShouldNot(BeNil())
+			Ω(Errors).ShouldNot(HaveOccurred())
+			Ω(Design.Validate()).ShouldNot(HaveOccurred())
+			Ω(res.Description).Should(Equal("descdesc"))
+			Ω(Design.TraitUses).Should(HaveLen(1))
+			Ω(Design.TraitUses[0].Parent).Should(Equal(res))
+		})
+
+		Context("given too few arguments", func() {
+			BeforeEach(func() {
+				args = []interface{}{"desc"}
+			})
+
+			It("does not run the trait and produces a validation error", func() {
+				Ω(res.Description).Should(BeEmpty())
+				err := Design.Validate()
+				Ω(err).Should(HaveOccurred())
+				Ω(err.Error()).Should(ContainSubstring("expects 2 arguments, got 1"))
+			})
+		})
+
+		Context("given an argument of the wrong type", func() {
+			BeforeEach(func() {
+				args = []interface{}{"desc", "2"}
+			})
+
+			It("does not run the trait and produces a validation error", func() {
+				Ω(res.Description).Should(BeEmpty())
+				err := Design.Validate()
+				Ω(err).Should(HaveOccurred())
+				Ω(err.Error()).Should(ContainSubstring("at position 1 must be of type int, got string"))
+			})
+		})
+	})
+
+	Context("with a variadic trait", func() {
+		const traitName = "descTrait"
+
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() { UseTrait(traitName, "a", "b", "c") }
+			API("test", func() {
+				Trait(traitName, func(descs ...string) {
+					Description(strings.Join(descs, ","))
+				})
+			})
+		})
+
+		It("runs the trait with the given arguments", func() {
+			Ω(Errors).ShouldNot(HaveOccurred())
+			Ω(Design.Validate()).ShouldNot(HaveOccurred())
+			Ω(res.Description).Should(Equal("a,b,c"))
+		})
+	})
+
+	Context("with arguments given to a trait that does not accept any", func() {
+		const traitName = "descTrait"
+
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() { UseTrait(traitName, "a") }
+			API("test", func() {
+				Trait(traitName, func() {
+					Description("desc")
+				})
+			})
+		})
+
+		It("produces a validation error", func() {
+			Ω(Design.Validate()).Should(HaveOccurred())
+		})
+	})
 })
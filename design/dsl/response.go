@@ -1,6 +1,10 @@
 package dsl
 
-import "github.com/raphael/goa/design"
+import (
+	"strings"
+
+	"github.com/raphael/goa/design"
+)
 
 // Response implements the response definition DSL. Response takes the name of the response as
 // first parameter. goa defines all the standard HTTP status name as global variables so they can be
@@ -27,6 +31,24 @@ import "github.com/raphael/goa/design"
 //		Media(BottleMedia)
 //	})
 //
+// The response media type may also be defined inline by giving a media type DSL to Media. The
+// inline media type attributes that are not given a type inherit the type, description and
+// validations of the attribute with the same name in the resource default media type. An inline
+// media type that does not define views gets a "default" view that renders all its attributes:
+//
+//	Response(OK, func() {
+//		Media(func() {
+//			Attributes(func() {
+//				Attribute("id")                          // Inherited from the resource media type
+//				Attribute("count", Integer, "Number of bottles")
+//			})
+//		})
+//	})
+//
+// goagen registers inline media types under an identifier derived from the resource default media
+// type identifier, the action name and the response name, e.g.
+// "application/vnd.goa.example.bottle.show.ok+json".
+//
 // goa defines a default response for all the HTTP status code. The default response simply sets
 // the status code. So if an action can return NotFound for example all it has to do is specify
 // Response(NotFound) - there is no need to specify the status code as the default response already
@@ -83,6 +105,51 @@ func Response(name string, paramsAndDSL ...interface{}) {
 	}
 }
 
+// ErrorKind maps a named kind of error to a response. ErrorKind takes the name of the error kind,
+// the name of the response and an optional description. It can be used in API, Resource or Action:
+//
+//	Action("show", func() {
+//		Routing(GET("/:id"))
+//		Response(OK)
+//		Response(NotFound)
+//		ErrorKind("BottleNotFound", NotFound, "The bottle does not exist")
+//	})
+//
+// The response is looked up in the action, resource and API definitions and in the built-in
+// responses. goagen generates a constructor for each error kind (NewBottleNotFoundError in the
+// example above). The generated controller code sends the response when an action returns an error
+// created by the constructor. Error kind names must be unique across the API.
+func ErrorKind(name, response string, description ...string) {
+	kind := &design.ErrorKindDefinition{Name: name, Response: response}
+	if len(description) > 0 {
+		kind.Description = description[0]
+	}
+	var kinds map[string]*design.ErrorKindDefinition
+	if a, ok := apiDefinition(false); ok {
+		if a.ErrorKinds == nil {
+			a.ErrorKinds = make(map[string]*design.ErrorKindDefinition)
+		}
+		kinds, kind.Parent = a.ErrorKinds, a
+	} else if r, ok := resourceDefinition(false); ok {
+		if r.ErrorKinds == nil {
+			r.ErrorKinds = make(map[string]*design.ErrorKindDefinition)
+		}
+		kinds, kind.Parent = r.ErrorKinds, r
+	} else if a, ok := actionDefinition(true); ok {
+		if a.ErrorKinds == nil {
+			a.ErrorKinds = make(map[string]*design.ErrorKindDefinition)
+		}
+		kinds, kind.Parent = a.ErrorKinds, a
+	} else {
+		return
+	}
+	if _, ok := kinds[name]; ok {
+		ReportError("error kind %s is defined twice", name)
+		return
+	}
+	kinds[name] = kind
+}
+
 // Status sets the Response status.
 func Status(status int) {
 	if r, ok := responseDefinition(true); ok {
@@ -90,6 +157,63 @@ func Status(status int) {
 	}
 }
 
+// inlineMediaType creates the media type defined inline in the given response definition. The
+// media type inherits from the default media type of the resource that defines the response.
+func inlineMediaType(resp *design.ResponseDefinition, dsl func()) *design.MediaTypeDefinition {
+	var res *design.ResourceDefinition
+	var action string
+	for i := len(ctxStack) - 1; i >= 0 && res == nil; i-- {
+		switch def := ctxStack[i].(type) {
+		case *design.ActionDefinition:
+			action = def.Name
+		case *design.ResourceDefinition:
+			res = def
+		}
+	}
+	if res == nil {
+		ReportError("inline media types can only be defined in action or resource responses")
+		return nil
+	}
+	parent := design.Design.MediaTypeWithIdentifier(res.MediaType)
+	base, typeName := "application/vnd."+res.Name, strings.Title(res.Name)
+	if parent != nil {
+		base, typeName = design.CanonicalIdentifier(parent.Identifier), parent.TypeName
+		if i := strings.Index(base, ";"); i > -1 {
+			base = base[:i]
+		}
+	}
+	suffix := strings.ToLower(resp.Name)
+	if action != "" {
+		suffix = strings.ToLower(action) + "." + suffix
+		typeName += strings.Title(action)
+	}
+	mt := newMediaType(base+"."+suffix+"+json", dsl)
+	if mt == nil {
+		return nil
+	}
+	mt.TypeName = typeName + strings.Title(resp.Name)
+	if parent != nil {
+		mt.Reference = parent
+	}
+	if !executeDSL(mt.DSL, mt) {
+		return nil
+	}
+	if len(mt.Views) == 0 && mt.Type.IsObject() {
+		view := make(design.Object, len(mt.Type.ToObject()))
+		for n, att := range mt.Type.ToObject() {
+			view[n] = att
+		}
+		mt.Views = map[string]*design.ViewDefinition{
+			"default": {
+				AttributeDefinition: &design.AttributeDefinition{Type: view},
+				Name:                "default",
+				Parent:              mt,
+			},
+		}
+	}
+	return mt
+}
+
 func executeResponseDSL(name string, paramsAndDSL ...interface{}) *design.ResponseDefinition {
 	var params []string
 	var dsl func()
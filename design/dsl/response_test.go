@@ -154,3 +154,199 @@ var _ = Describe("Response", func() {
 	})
 
 })
+
+var _ = Describe("Response with an inline media type", func() {
+	var mediaDSL func()
+	var res *ResponseDefinition
+
+	BeforeEach(func() {
+		Design = nil
+		Errors = nil
+		mediaDSL = func() {
+			Attributes(func() {
+				Attribute("id")
+				Attribute("count", Integer)
+			})
+		}
+	})
+
+	JustBeforeEach(func() {
+		BottleMedia := MediaType("application/vnd.goa.bottle+json", func() {
+			Attributes(func() {
+				Attribute("id", Integer, "ID of bottle", func() {
+					Minimum(1)
+				})
+				Attribute("name")
+			})
+			View("default", func() {
+				Attribute("id")
+				Attribute("name")
+			})
+		})
+		Resource("bottle", func() {
+			DefaultMedia(BottleMedia)
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Response(OK, func() {
+					Media(mediaDSL)
+				})
+			})
+		})
+		RunDSL()
+		res = Design.Resources["bottle"].Actions["show"].Responses[OK]
+	})
+
+	It("registers the media type under a derived identifier", func() {
+		Ω(Errors).ShouldNot(HaveOccurred())
+		Ω(res.MediaType).Should(Equal("application/vnd.goa.bottle.show.ok+json"))
+		mt := Design.MediaTypeWithIdentifier(res.MediaType)
+		Ω(mt).ShouldNot(BeNil())
+		Ω(mt.TypeName).Should(Equal("GoaBottleShowOK"))
+	})
+
+	It("inherits the attributes of the resource media type", func() {
+		mt := Design.MediaTypeWithIdentifier(res.MediaType)
+		o := mt.Type.ToObject()
+		Ω(o).Should(HaveLen(2))
+		Ω(o["id"].Type).Should(Equal(Integer))
+		Ω(o["id"].Description).Should(Equal("ID of bottle"))
+		Ω(o["id"].Validations).Should(HaveLen(1))
+		Ω(o["count"].Type).Should(Equal(Integer))
+	})
+
+	It("creates a default view rendering all the attributes", func() {
+		mt := Design.MediaTypeWithIdentifier(res.MediaType)
+		Ω(mt.Views).Should(HaveKey("default"))
+		Ω(mt.Views["default"].Type.ToObject()).Should(HaveLen(2))
+	})
+
+	Context("with views", func() {
+		BeforeEach(func() {
+			mediaDSL = func() {
+				Attributes(func() {
+					Attribute("id")
+					Attribute("count", Integer)
+				})
+				View("default", func() {
+					Attribute("count")
+				})
+			}
+		})
+
+		It("uses the views", func() {
+			Ω(Errors).ShouldNot(HaveOccurred())
+			mt := Design.MediaTypeWithIdentifier(res.MediaType)
+			Ω(mt.Views).Should(HaveLen(1))
+			Ω(mt.Views["default"].Type.ToObject()).Should(HaveLen(1))
+		})
+	})
+})
+
+var _ = Describe("ErrorKind", func() {
+	var apiDSL, resDSL, actionDSL func()
+	var runErr error
+
+	BeforeEach(func() {
+		Design = nil
+		Errors = nil
+		apiDSL = nil
+		resDSL = nil
+		actionDSL = nil
+	})
+
+	JustBeforeEach(func() {
+		API("test", func() {
+			if apiDSL != nil {
+				apiDSL()
+			}
+		})
+		Resource("res", func() {
+			if resDSL != nil {
+				resDSL()
+			}
+			Action("action", func() {
+				Routing(GET("/"))
+				Response(OK)
+				if actionDSL != nil {
+					actionDSL()
+				}
+			})
+		})
+		runErr = RunDSL()
+	})
+
+	Context("in an action", func() {
+		BeforeEach(func() {
+			actionDSL = func() {
+				Response("Taken", func() {
+					Status(409)
+				})
+				ErrorKind("AlreadyTaken", "Taken", "description")
+			}
+		})
+
+		It("records the error kind", func() {
+			Ω(runErr).ShouldNot(HaveOccurred())
+			a := Design.Resources["res"].Actions["action"]
+			Ω(a.ErrorKinds).Should(HaveKey("AlreadyTaken"))
+			k := a.ErrorKinds["AlreadyTaken"]
+			Ω(k.Description).Should(Equal("description"))
+			Ω(k.Parent).Should(Equal(a))
+			Ω(k.ResponseDefinition()).ShouldNot(BeNil())
+			Ω(k.ResponseDefinition().Status).Should(Equal(409))
+		})
+	})
+
+	Context("in the API and a resource", func() {
+		BeforeEach(func() {
+			apiDSL = func() {
+				ErrorKind("Unavailable", ServiceUnavailable)
+			}
+			resDSL = func() {
+				ErrorKind("NotThere", NotFound)
+			}
+		})
+
+		It("uses the built-in responses", func() {
+			Ω(runErr).ShouldNot(HaveOccurred())
+			var names []string
+			var statuses []int
+			Design.IterateErrorKinds(func(k *ErrorKindDefinition) error {
+				names = append(names, k.Name)
+				statuses = append(statuses, k.ResponseDefinition().Status)
+				return nil
+			})
+			Ω(names).Should(Equal([]string{"NotThere", "Unavailable"}))
+			Ω(statuses).Should(Equal([]int{404, 503}))
+		})
+	})
+
+	Context("with an unknown response", func() {
+		BeforeEach(func() {
+			actionDSL = func() {
+				ErrorKind("Oops", "Unknown")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(runErr).Should(HaveOccurred())
+			Ω(runErr.Error()).Should(ContainSubstring("unknown response"))
+		})
+	})
+
+	Context("with the same name defined twice", func() {
+		BeforeEach(func() {
+			apiDSL = func() {
+				ErrorKind("NotThere", NotFound)
+			}
+			actionDSL = func() {
+				ErrorKind("NotThere", Gone)
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(runErr).Should(HaveOccurred())
+			Ω(runErr.Error()).Should(ContainSubstring("conflicts"))
+		})
+	})
+})
@@ -48,6 +48,11 @@ func RunDSL() error {
 	// First run the top level API DSL to initialize responses and
 	// response templates needed by resources.
 	executeDSL(design.Design.DSL, design.Design)
+	// Then run the version DSLs to create the versioned media types and resources.
+	design.Design.IterateVersions(func(v *design.APIDefinition) error {
+		executeDSL(v.APIVersion.DSL, v.APIVersion)
+		return nil
+	})
 	// Then run the user type DSLs
 	for _, t := range design.Design.Types {
 		executeDSL(t.DSL, t.AttributeDefinition)
@@ -56,10 +61,32 @@ func RunDSL() error {
 	for _, mt := range design.Design.MediaTypes {
 		executeDSL(mt.DSL, mt)
 	}
+	design.Design.IterateVersions(func(v *design.APIDefinition) error {
+		for _, mt := range v.APIVersion.MediaTypes {
+			executeDSL(mt.DSL, mt)
+		}
+		return nil
+	})
 	// And now that we have everything the resources.
 	for _, r := range design.Design.Resources {
 		executeDSL(r.DSL, r)
 	}
+	design.Design.IterateVersions(func(v *design.APIDefinition) error {
+		for _, r := range v.Resources {
+			executeDSL(r.DSL, r)
+		}
+		return nil
+	})
+	// Compute the base paths of the resources that don't define one.
+	for _, r := range design.Design.Resources {
+		applyBasePathPolicy(r)
+	}
+	design.Design.IterateVersions(func(v *design.APIDefinition) error {
+		for _, r := range v.Resources {
+			applyBasePathPolicy(r)
+		}
+		return nil
+	})
 	// Don't attempt to validate syntactically incorrect DSL
 	if Errors != nil {
 		return Errors
@@ -80,9 +107,21 @@ func RunDSL() error {
 	for _, mt := range design.Design.MediaTypes {
 		finalizeMediaType(mt)
 	}
+	for _, r := range design.Design.Responses {
+		finalizeErrorResponse(r)
+	}
 	for _, r := range design.Design.Resources {
 		finalizeResource(r)
 	}
+	design.Design.IterateVersions(func(v *design.APIDefinition) error {
+		for _, mt := range v.APIVersion.MediaTypes {
+			finalizeMediaType(mt)
+		}
+		for _, r := range v.Resources {
+			finalizeResource(r)
+		}
+		return nil
+	})
 
 	return nil
 }
@@ -143,6 +182,18 @@ func finalizeType(ut *design.UserTypeDefinition) {
 	}
 }
 
+// applyBasePathPolicy sets the base path of the resource using the API base path policy if the
+// resource does not define one.
+func applyBasePathPolicy(r *design.ResourceDefinition) {
+	if r.BasePath != "" {
+		return
+	}
+	if bp := design.Design.BasePathPolicy.ResourceBasePath(r.Name); bp != "" {
+		r.BasePath = bp
+		r.ImplicitBasePath = true
+	}
+}
+
 // finalizeResource makes the final pass at the resource DSL. This is needed so that the order
 // of DSL function calls is irrelevant. For example a resource response may be defined after an
 // action refers to it.
@@ -159,6 +210,7 @@ func finalizeResource(r *design.ResourceDefinition) {
 			if dr, ok := design.Design.DefaultResponses[name]; ok {
 				resp.Merge(dr)
 			}
+			finalizeErrorResponse(resp)
 		}
 		// 2. Create implicit action parameters for path wildcards that dont' have one
 		for _, r := range a.Routes {
@@ -200,6 +252,14 @@ func finalizeResource(r *design.ResourceDefinition) {
 	})
 }
 
+// finalizeErrorResponse sets the media type of error responses that don't define one to the API
+// error media type if any.
+func finalizeErrorResponse(r *design.ResponseDefinition) {
+	if em := design.Design.ErrorMedia; em != nil && r.Status >= 400 && r.MediaType == "" {
+		r.MediaType = em.Identifier
+	}
+}
+
 // incompatibleDSL should be called by DSL functions when they are
 // invoked in an incorrect context (e.g. "Params" in "Resource").
 func incompatibleDSL(dslFunc string) {
@@ -301,6 +361,16 @@ func apiDefinition(failIfNotAPI bool) (*design.APIDefinition, bool) {
 	return a, ok
 }
 
+// versionDefinition returns true and current context if it is an APIVersionDefinition,
+// nil and false otherwise.
+func versionDefinition(failIfNotVersion bool) (*design.APIVersionDefinition, bool) {
+	v, ok := ctxStack.current().(*design.APIVersionDefinition)
+	if !ok && failIfNotVersion {
+		incompatibleDSL(caller())
+	}
+	return v, ok
+}
+
 // contactDefinition returns true and current context if it is an ContactDefinition,
 // nil and false otherwise.
 func contactDefinition(failIfNotContact bool) (*design.ContactDefinition, bool) {
@@ -381,6 +451,26 @@ func responseDefinition(failIfNotResponse bool) (*design.ResponseDefinition, boo
 	return r, ok
 }
 
+// securitySchemeDefinition returns true and current context if it is a SecuritySchemeDefinition,
+// nil and false otherwise.
+func securitySchemeDefinition(failIfNotSecurityScheme bool) (*design.SecuritySchemeDefinition, bool) {
+	s, ok := ctxStack.current().(*design.SecuritySchemeDefinition)
+	if !ok && failIfNotSecurityScheme {
+		incompatibleDSL(caller())
+	}
+	return s, ok
+}
+
+// securityDefinition returns true and current context if it is a SecurityDefinition,
+// nil and false otherwise.
+func securityDefinition(failIfNotSecurity bool) (*design.SecurityDefinition, bool) {
+	s, ok := ctxStack.current().(*design.SecurityDefinition)
+	if !ok && failIfNotSecurity {
+		incompatibleDSL(caller())
+	}
+	return s, ok
+}
+
 // Name of calling function.
 func caller() string {
 	pc, _, _, ok := runtime.Caller(2)
@@ -0,0 +1,189 @@
+package dsl
+
+import "github.com/raphael/goa/design"
+
+// BasicAuthSecurity defines a security scheme that uses HTTP basic authentication. It can only
+// be used in an API definition. The optional DSL may set the scheme description:
+//
+//	BasicAuthSecurity("basic", func() {
+//		Description("Use your account username and password")
+//	})
+func BasicAuthSecurity(name string, dsl ...func()) {
+	securityScheme(name, design.BasicAuthSecurityKind, dsl)
+}
+
+// APIKeySecurity defines a security scheme that uses an API key sent in a request header or query
+// string parameter. It can only be used in an API definition. The DSL must specify where to read
+// the key from using either Header or Query:
+//
+//	APIKeySecurity("key", func() {
+//		Header("X-Shared-Secret")
+//	})
+func APIKeySecurity(name string, dsl ...func()) {
+	securityScheme(name, design.APIKeySecurityKind, dsl)
+}
+
+// JWTSecurity defines a security scheme that uses JSON Web Tokens. It can only be used in an API
+// definition. The token is read from the "Authorization" header by default, the DSL may use
+// Header or Query to override that. The DSL may also list the scopes that can be required by
+// actions and the URL used to retrieve tokens:
+//
+//	JWTSecurity("jwt", func() {
+//		TokenURL("https://example.com/token")
+//		Scope("read", "Read-only access")
+//		Scope("write", "Read and write access")
+//	})
+func JWTSecurity(name string, dsl ...func()) {
+	if s := securityScheme(name, design.JWTSecurityKind, dsl); s != nil {
+		if s.In == "" {
+			s.In, s.ParamName = "header", "Authorization"
+		}
+	}
+}
+
+// OAuth2Security defines a security scheme that uses OAuth2 bearer tokens sent in the
+// "Authorization" header. It can only be used in an API definition. The DSL must specify the
+// OAuth2 flow with one of ImplicitFlow, PasswordFlow, ApplicationFlow or AccessCodeFlow and may
+// list the scopes that can be required by actions:
+//
+//	OAuth2Security("oauth2", func() {
+//		AccessCodeFlow("https://example.com/auth", "https://example.com/token")
+//		Scope("api:read", "Read access to the API")
+//	})
+func OAuth2Security(name string, dsl ...func()) {
+	if s := securityScheme(name, design.OAuth2SecurityKind, dsl); s != nil {
+		s.In, s.ParamName = "header", "Authorization"
+	}
+}
+
+// Security requires the security scheme with the given name for all the actions of the API or
+// resource or for the action being defined. Security defined on an action overrides the one
+// defined on the parent resource which itself overrides the one defined on the API. The optional
+// DSL may list the scopes required by OAuth2 and JWT schemes:
+//
+//	Action("update", func() {
+//		Security("oauth2", func() {
+//			Scope("api:write")
+//		})
+//	})
+func Security(scheme string, dsl ...func()) {
+	sec := &design.SecurityDefinition{Scheme: scheme}
+	if len(dsl) > 1 {
+		ReportError("too many arguments in call to Security")
+		return
+	}
+	if len(dsl) == 1 && !executeDSL(dsl[0], sec) {
+		return
+	}
+	setSecurity(sec)
+}
+
+// NoSecurity disables the security requirement inherited from the parent resource or API. It can
+// be used in a resource or action definition.
+func NoSecurity() {
+	if _, ok := apiDefinition(false); ok {
+		incompatibleDSL("NoSecurity")
+		return
+	}
+	setSecurity(&design.SecurityDefinition{})
+}
+
+// Query sets the name of the query string parameter that holds the API key or token of an
+// APIKeySecurity or JWTSecurity scheme.
+func Query(name string) {
+	if s, ok := securitySchemeDefinition(true); ok {
+		s.In, s.ParamName = "query", name
+	}
+}
+
+// TokenURL sets the URL used to retrieve tokens of a JWTSecurity scheme.
+func TokenURL(url string) {
+	if s, ok := securitySchemeDefinition(true); ok {
+		s.TokenURL = url
+	}
+}
+
+// ImplicitFlow sets the flow of an OAuth2Security scheme to "implicit".
+func ImplicitFlow(authorizationURL string) {
+	if s, ok := securitySchemeDefinition(true); ok {
+		s.Flow, s.AuthorizationURL = "implicit", authorizationURL
+	}
+}
+
+// PasswordFlow sets the flow of an OAuth2Security scheme to "password".
+func PasswordFlow(tokenURL string) {
+	if s, ok := securitySchemeDefinition(true); ok {
+		s.Flow, s.TokenURL = "password", tokenURL
+	}
+}
+
+// ApplicationFlow sets the flow of an OAuth2Security scheme to "application".
+func ApplicationFlow(tokenURL string) {
+	if s, ok := securitySchemeDefinition(true); ok {
+		s.Flow, s.TokenURL = "application", tokenURL
+	}
+}
+
+// AccessCodeFlow sets the flow of an OAuth2Security scheme to "accessCode".
+func AccessCodeFlow(authorizationURL, tokenURL string) {
+	if s, ok := securitySchemeDefinition(true); ok {
+		s.Flow, s.AuthorizationURL, s.TokenURL = "accessCode", authorizationURL, tokenURL
+	}
+}
+
+// Scope defines a scope available to an OAuth2Security or JWTSecurity scheme when used in the
+// scheme DSL and requires the scope when used in a Security DSL.
+func Scope(name string, description ...string) {
+	if s, ok := securitySchemeDefinition(false); ok {
+		if s.Scopes == nil {
+			s.Scopes = make(map[string]string)
+		}
+		if len(description) > 0 {
+			s.Scopes[name] = description[0]
+		} else {
+			s.Scopes[name] = ""
+		}
+	} else if sec, ok := securityDefinition(true); ok {
+		sec.Scopes = append(sec.Scopes, name)
+	}
+}
+
+// securityScheme creates a security scheme of the given kind, runs its DSL and records it in the
+// API definition.
+func securityScheme(name string, kind design.SecuritySchemeKind, dsl []func()) *design.SecuritySchemeDefinition {
+	a, ok := apiDefinition(true)
+	if !ok {
+		return nil
+	}
+	if len(dsl) > 1 {
+		ReportError("too many arguments in call to security scheme %#v", name)
+		return nil
+	}
+	if _, ok := a.SecuritySchemes[name]; ok {
+		ReportError("security scheme %#v is defined twice", name)
+		return nil
+	}
+	s := &design.SecuritySchemeDefinition{Name: name, Kind: kind}
+	if len(dsl) == 1 && !executeDSL(dsl[0], s) {
+		return nil
+	}
+	if a.SecuritySchemes == nil {
+		a.SecuritySchemes = make(map[string]*design.SecuritySchemeDefinition)
+	}
+	a.SecuritySchemes[name] = s
+	return s
+}
+
+// setSecurity sets the security requirement of the API, resource or action being defined.
+func setSecurity(sec *design.SecurityDefinition) {
+	if a, ok := apiDefinition(false); ok {
+		sec.Parent = a
+		a.Security = sec
+	} else if r, ok := resourceDefinition(false); ok {
+		sec.Parent = r
+		r.Security = sec
+	} else if a, ok := actionDefinition(true); ok {
+		sec.Parent = a
+		a.Security = sec
+	}
+}
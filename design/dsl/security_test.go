@@ -0,0 +1,202 @@
+package dsl_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/raphael/goa/design"
+	. "github.com/raphael/goa/design/dsl"
+)
+
+var _ = Describe("Security", func() {
+	var apiDSL, resDSL, actionDSL func()
+	var runErr error
+
+	BeforeEach(func() {
+		Design = nil
+		Errors = nil
+		apiDSL = nil
+		resDSL = nil
+		actionDSL = nil
+	})
+
+	JustBeforeEach(func() {
+		API("test", func() {
+			if apiDSL != nil {
+				apiDSL()
+			}
+		})
+		Resource("res", func() {
+			if resDSL != nil {
+				resDSL()
+			}
+			Action("action", func() {
+				Routing(GET("/"))
+				Response(OK)
+				if actionDSL != nil {
+					actionDSL()
+				}
+			})
+		})
+		runErr = RunDSL()
+	})
+
+	Context("with security schemes", func() {
+		BeforeEach(func() {
+			apiDSL = func() {
+				BasicAuthSecurity("basic", func() {
+					Description("desc")
+				})
+				APIKeySecurity("key", func() {
+					Query("api_key")
+				})
+				JWTSecurity("jwt", func() {
+					TokenURL("http://example.com/token")
+					Scope("read", "read access")
+				})
+				OAuth2Security("oauth2", func() {
+					AccessCodeFlow("http://example.com/auth", "http://example.com/token")
+				})
+			}
+		})
+
+		It("records the schemes", func() {
+			Ω(runErr).ShouldNot(HaveOccurred())
+			Ω(Design.SecuritySchemes).Should(HaveLen(4))
+			basic := Design.SecuritySchemes["basic"]
+			Ω(basic.Kind).Should(Equal(BasicAuthSecurityKind))
+			Ω(basic.Description).Should(Equal("desc"))
+			key := Design.SecuritySchemes["key"]
+			Ω(key.Kind).Should(Equal(APIKeySecurityKind))
+			Ω(key.In).Should(Equal("query"))
+			Ω(key.ParamName).Should(Equal("api_key"))
+			jwt := Design.SecuritySchemes["jwt"]
+			Ω(jwt.Kind).Should(Equal(JWTSecurityKind))
+			Ω(jwt.In).Should(Equal("header"))
+			Ω(jwt.ParamName).Should(Equal("Authorization"))
+			Ω(jwt.TokenURL).Should(Equal("http://example.com/token"))
+			Ω(jwt.Scopes).Should(Equal(map[string]string{"read": "read access"}))
+			oauth2 := Design.SecuritySchemes["oauth2"]
+			Ω(oauth2.Kind).Should(Equal(OAuth2SecurityKind))
+			Ω(oauth2.Flow).Should(Equal("accessCode"))
+			Ω(oauth2.AuthorizationURL).Should(Equal("http://example.com/auth"))
+			Ω(oauth2.TokenURL).Should(Equal("http://example.com/token"))
+		})
+	})
+
+	Context("with requirements", func() {
+		BeforeEach(func() {
+			apiDSL = func() {
+				BasicAuthSecurity("basic")
+				JWTSecurity("jwt", func() {
+					Scope("read")
+					Scope("write")
+				})
+				Security("basic")
+			}
+			resDSL = func() {
+				Security("jwt", func() {
+					Scope("read")
+				})
+			}
+		})
+
+		It("inherits the closest requirement", func() {
+			Ω(runErr).ShouldNot(HaveOccurred())
+			sec := Design.Resources["res"].Actions["action"].SecurityRequirement()
+			Ω(sec).ShouldNot(BeNil())
+			Ω(sec.Scheme).Should(Equal("jwt"))
+			Ω(sec.Scopes).Should(Equal([]string{"read"}))
+			Ω(sec.SchemeDefinition()).Should(Equal(Design.SecuritySchemes["jwt"]))
+		})
+
+		Context("overridden in the action", func() {
+			BeforeEach(func() {
+				actionDSL = func() {
+					Security("jwt", func() {
+						Scope("write")
+					})
+				}
+			})
+
+			It("uses the action requirement", func() {
+				Ω(runErr).ShouldNot(HaveOccurred())
+				sec := Design.Resources["res"].Actions["action"].SecurityRequirement()
+				Ω(sec).ShouldNot(BeNil())
+				Ω(sec.Scopes).Should(Equal([]string{"write"}))
+			})
+		})
+
+		Context("disabled in the action", func() {
+			BeforeEach(func() {
+				actionDSL = func() {
+					NoSecurity()
+				}
+			})
+
+			It("does not require security", func() {
+				Ω(runErr).ShouldNot(HaveOccurred())
+				Ω(Design.Resources["res"].Actions["action"].SecurityRequirement()).Should(BeNil())
+			})
+		})
+	})
+
+	Context("with an unknown scheme", func() {
+		BeforeEach(func() {
+			actionDSL = func() {
+				Security("unknown")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(runErr).Should(HaveOccurred())
+			Ω(runErr.Error()).Should(ContainSubstring("unknown security scheme"))
+		})
+	})
+
+	Context("with an undefined scope", func() {
+		BeforeEach(func() {
+			apiDSL = func() {
+				JWTSecurity("jwt", func() {
+					Scope("read")
+				})
+			}
+			actionDSL = func() {
+				Security("jwt", func() {
+					Scope("admin")
+				})
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(runErr).Should(HaveOccurred())
+			Ω(runErr.Error()).Should(ContainSubstring(`scope "admin" is not defined`))
+		})
+	})
+
+	Context("with an OAuth2 scheme missing its flow", func() {
+		BeforeEach(func() {
+			apiDSL = func() {
+				OAuth2Security("oauth2")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(runErr).Should(HaveOccurred())
+			Ω(runErr.Error()).Should(ContainSubstring("invalid OAuth2 flow"))
+		})
+	})
+
+	Context("with a scheme defined twice", func() {
+		BeforeEach(func() {
+			apiDSL = func() {
+				BasicAuthSecurity("basic")
+				BasicAuthSecurity("basic")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(runErr).Should(HaveOccurred())
+			Ω(runErr.Error()).Should(ContainSubstring("defined twice"))
+		})
+	})
+})
@@ -1,8 +1,12 @@
 package dsl_test
 
 import (
+	"errors"
+	"strings"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/raphael/goa"
 	. "github.com/raphael/goa/design"
 	. "github.com/raphael/goa/design/dsl"
 )
@@ -98,6 +102,36 @@ var _ = Describe("Validation", func() {
 			})
 		})
 
+		Context("with a custom format validation", func() {
+			BeforeEach(func() {
+				goa.RegisterFormat("dsl-semver", func(val string) error {
+					if !strings.HasPrefix(val, "v") {
+						return errors.New("must start with v")
+					}
+					return nil
+				})
+				dsl = func() {
+					Attribute(attName, String, func() {
+						Format("dsl-semver")
+						Example("1.0.0")
+					})
+				}
+			})
+
+			It("records the validation", func() {
+				Ω(Errors).ShouldNot(HaveOccurred())
+				Ω(att.Validations).Should(HaveLen(1))
+				expected := &FormatValidationDefinition{Format: "dsl-semver"}
+				Ω(att.Validations[0]).Should(Equal(expected))
+			})
+
+			It("validates examples using the registered validator", func() {
+				err := Design.Validate()
+				Ω(err).Should(HaveOccurred())
+				Ω(err.Error()).Should(ContainSubstring("invalid dsl-semver value, must start with v"))
+			})
+		})
+
 		Context("with a valid pattern validation", func() {
 			BeforeEach(func() {
 				dsl = func() {
@@ -277,6 +311,182 @@ var _ = Describe("Validation", func() {
 			})
 		})
 
+		Context("with a valid exclusive min value validation", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, Number, func() {
+						ExclusiveMinimum(0)
+					})
+				}
+			})
+
+			It("records the validation", func() {
+				Ω(Errors).ShouldNot(HaveOccurred())
+				Ω(att.Validations).Should(HaveLen(1))
+				expected := &MinimumValidationDefinition{Min: 0, Exclusive: true}
+				Ω(att.Validations[0]).Should(Equal(expected))
+			})
+		})
+
+		Context("with a valid exclusive max value validation", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, Integer, func() {
+						ExclusiveMaximum("10")
+					})
+				}
+			})
+
+			It("records the validation", func() {
+				Ω(Errors).ShouldNot(HaveOccurred())
+				Ω(att.Validations).Should(HaveLen(1))
+				expected := &MaximumValidationDefinition{Max: 10, Exclusive: true}
+				Ω(att.Validations[0]).Should(Equal(expected))
+			})
+		})
+
+		Context("with a valid multiple of validation", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, Number, func() {
+						MultipleOf(0.5)
+					})
+				}
+			})
+
+			It("records the validation", func() {
+				Ω(Errors).ShouldNot(HaveOccurred())
+				Ω(att.Validations).Should(HaveLen(1))
+				expected := &MultipleOfValidationDefinition{MultipleOf: 0.5}
+				Ω(att.Validations[0]).Should(Equal(expected))
+			})
+		})
+
+		Context("with a multiple of validation on a string", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, String, func() {
+						MultipleOf(2)
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(Errors).Should(HaveOccurred())
+			})
+		})
+
+		Context("with a multiple of validation that is not positive", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, Integer, func() {
+						MultipleOf(0)
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(Errors).Should(HaveOccurred())
+			})
+		})
+
+		Context("with a valid unique items validation", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, ArrayOf(String), func() {
+						UniqueItems()
+					})
+				}
+			})
+
+			It("records the validation", func() {
+				Ω(Errors).ShouldNot(HaveOccurred())
+				Ω(att.Validations).Should(HaveLen(1))
+				Ω(att.Validations[0]).Should(BeAssignableToTypeOf(&UniqueItemsValidationDefinition{}))
+			})
+		})
+
+		Context("with an invalid unique items validation", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, String, func() {
+						UniqueItems()
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(Errors).Should(HaveOccurred())
+			})
+		})
+
+		Context("with valid min and max properties validations", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, HashOf(String, Integer), func() {
+						MinProperties(1)
+						MaxProperties(3)
+					})
+				}
+			})
+
+			It("records the validations", func() {
+				Ω(Errors).ShouldNot(HaveOccurred())
+				Ω(att.Validations).Should(HaveLen(2))
+				Ω(att.Validations[0]).Should(Equal(&MinPropertiesValidationDefinition{MinProperties: 1}))
+				Ω(att.Validations[1]).Should(Equal(&MaxPropertiesValidationDefinition{MaxProperties: 3}))
+			})
+		})
+
+		Context("with an invalid min properties validation", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, ArrayOf(String), func() {
+						MinProperties(1)
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(Errors).Should(HaveOccurred())
+			})
+		})
+
+		Context("with a unique items validation on an attribute whose type is not known yet", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, func() {
+						UniqueItems()
+					})
+				}
+			})
+
+			It("fails design validation", func() {
+				Ω(Errors).ShouldNot(HaveOccurred())
+				err := Design.Validate()
+				Ω(err).Should(HaveOccurred())
+				Ω(err.Error()).Should(ContainSubstring(`only arrays may define a "UniqueItems" validation`))
+			})
+		})
+
+		Context("with an example that is not greater than the exclusive minimum", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, Integer, func() {
+						ExclusiveMinimum(0)
+						Example(0)
+					})
+				}
+			})
+
+			It("fails design validation", func() {
+				Ω(Errors).ShouldNot(HaveOccurred())
+				err := Design.Validate()
+				Ω(err).Should(HaveOccurred())
+				Ω(err.Error()).Should(ContainSubstring("must be greater than 0"))
+			})
+		})
+
 		Context("with a required field validation", func() {
 			BeforeEach(func() {
 				dsl = func() {
@@ -0,0 +1,91 @@
+package dsl
+
+import (
+	"strings"
+
+	"github.com/raphael/goa/design"
+)
+
+// Version implements the API version definition DSL. A version defines the resources and media
+// types that differ between the versions of the API. Media types defined outside of any version
+// are used by all versions that do not define a media type with the same identifier. Resources
+// defined outside of any version are not part of any version, they are mounted without version
+// prefix. With VersionHeader or VersionQuery they also handle the requests that target a version
+// which defines no route for the request path and method. With VersionPrefix they are only
+// served under the API base path, not under the version paths. goagen generates the code of each
+// version in its own package and emits one swagger specification per version.
+//
+// The API definition must describe how requests select the version they target using
+// VersionPrefix, VersionHeader and/or VersionQuery. Example:
+//
+//	API("cellar", func() {
+//		VersionHeader("X-API-Version")
+//	})
+//
+//	var _ = Version("2.0", func() {
+//		Description("Bottles have a vintage")
+//
+//		MediaType("application/vnd.goa.bottle", func() {
+//			// ... Media type DSL
+//		})
+//
+//		Resource("bottle", func() {
+//			// ... Resource DSL
+//		})
+//	})
+func Version(name string, dsl func()) *design.APIVersionDefinition {
+	if design.Design == nil {
+		InitDesign()
+	}
+	if !topLevelDefinition(true) {
+		return nil
+	}
+	if name == "" || strings.Contains(name, "/") {
+		ReportError("invalid version name %#v, must not be empty or contain slashes", name)
+		return nil
+	}
+	if design.Design.Versions == nil {
+		design.Design.Versions = make(map[string]*design.APIVersionDefinition)
+	}
+	if _, ok := design.Design.Versions[name]; ok {
+		ReportError("version %#v is defined twice", name)
+		return nil
+	}
+	v := &design.APIVersionDefinition{Name: name, DSL: dsl}
+	design.Design.Versions[name] = v
+	return v
+}
+
+// VersionPrefix sets the prefix used to select API versions by request path. The paths of the
+// actions of a version consist of the API base path followed by the prefix and the version name.
+// For example given the prefix "/v" the "list" action of the resource "bottle" of version "1" may
+// be mounted under "/v1/bottles". VersionPrefix must appear in the API DSL:
+//
+//	API("cellar", func() {
+//		VersionPrefix("/v")
+//	})
+func VersionPrefix(prefix string) {
+	if a, ok := apiDefinition(true); ok {
+		if !strings.HasPrefix(prefix, "/") {
+			ReportError("invalid version prefix %#v, must start with /", prefix)
+			return
+		}
+		a.VersionPrefix = prefix
+	}
+}
+
+// VersionHeader sets the name of the request header used to select API versions.
+// VersionHeader must appear in the API DSL.
+func VersionHeader(name string) {
+	if a, ok := apiDefinition(true); ok {
+		a.VersionHeader = name
+	}
+}
+
+// VersionQuery sets the name of the query string parameter used to select API versions.
+// VersionQuery must appear in the API DSL.
+func VersionQuery(name string) {
+	if a, ok := apiDefinition(true); ok {
+		a.VersionQuery = name
+	}
+}
@@ -0,0 +1,175 @@
+package dsl_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/raphael/goa/design"
+	. "github.com/raphael/goa/design/dsl"
+)
+
+var _ = Describe("Version", func() {
+	var name string
+	var dsl func()
+	var declErr error
+	var runErr error
+
+	BeforeEach(func() {
+		Design = nil
+		Errors = nil
+		name = "2.0"
+		dsl = nil
+	})
+
+	JustBeforeEach(func() {
+		API("test", func() {
+			VersionPrefix("/v")
+		})
+		MediaType("application/vnd.bottle", func() {
+			Attributes(func() {
+				Attribute("name")
+			})
+			View("default", func() {
+				Attribute("name")
+			})
+		})
+		Resource("bottle", func() {
+			BasePath("/bottles")
+			Action("list", func() {
+				Routing(GET(""))
+			})
+		})
+		Version(name, dsl)
+		declErr = Errors
+		runErr = RunDSL()
+	})
+
+	It("defines the version", func() {
+		Ω(runErr).ShouldNot(HaveOccurred())
+		Ω(Design.Versions).Should(HaveKey(name))
+		v := Design.ForVersion(name)
+		Ω(v.APIVersion).Should(Equal(Design.Versions[name]))
+		Ω(v.BasePath).Should(Equal("/v2.0"))
+		Ω(v.VersionPathPrefix()).Should(Equal("/v"))
+		Ω(v.Resources).Should(BeEmpty())
+		Ω(v.MediaTypes).Should(HaveLen(len(Design.MediaTypes)))
+	})
+
+	Context("with a name containing a slash", func() {
+		BeforeEach(func() {
+			name = "v/2"
+		})
+
+		It("fails", func() {
+			Ω(declErr).Should(HaveOccurred())
+		})
+	})
+
+	Context("with resources and media types", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Description("second version")
+				MediaType("application/vnd.bottle", func() {
+					Attributes(func() {
+						Attribute("name")
+						Attribute("vintage", Integer)
+					})
+					View("default", func() {
+						Attribute("name")
+						Attribute("vintage")
+					})
+				})
+				Resource("bottle", func() {
+					BasePath("/bottles")
+					DefaultMedia("application/vnd.bottle")
+					Action("show", func() {
+						Routing(GET("/:id"))
+						Response(OK)
+					})
+				})
+			}
+		})
+
+		It("defines the version resources and media types", func() {
+			Ω(runErr).ShouldNot(HaveOccurred())
+			ver := Design.Versions[name]
+			Ω(ver.Description).Should(Equal("second version"))
+			Ω(ver.Resources).Should(HaveKey("bottle"))
+			Ω(ver.Resources["bottle"].Version).Should(Equal(name))
+			Ω(ver.Resources["bottle"].Actions).Should(HaveKey("show"))
+			Ω(ver.MediaTypes).Should(HaveLen(1))
+		})
+
+		It("keeps the unversioned definitions", func() {
+			Ω(Design.Resources["bottle"].Actions).Should(HaveKey("list"))
+			Ω(Design.Resources["bottle"].Version).Should(BeEmpty())
+			mt := Design.MediaTypeWithIdentifier("application/vnd.bottle")
+			Ω(mt.Type.ToObject()).Should(HaveLen(1))
+		})
+
+		It("uses the version definitions in the version", func() {
+			var path string
+			var mt *MediaTypeDefinition
+			Design.IterateVersions(func(v *APIDefinition) error {
+				path = v.Resources["bottle"].Actions["show"].Routes[0].FullPath()
+				mt = v.MediaTypeWithIdentifier("application/vnd.bottle")
+				return nil
+			})
+			Ω(path).Should(Equal("/v2.0/bottles/:id"))
+			Ω(mt.Type.ToObject()).Should(HaveLen(2))
+			Ω(Design.APIVersion).Should(BeNil())
+		})
+	})
+
+	Context("with colliding resources", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Resource("bottle", func() {
+					Action("show", func() {
+						Routing(GET("/:id"))
+					})
+				})
+				Resource("bottle", func() {})
+			}
+		})
+
+		It("fails", func() {
+			Ω(Errors).Should(HaveOccurred())
+			Ω(Errors.Error()).Should(ContainSubstring(`resource "bottle" is defined twice`))
+		})
+	})
+})
+
+var _ = Describe("VersionPrefix", func() {
+	BeforeEach(func() {
+		Design = nil
+		Errors = nil
+	})
+
+	It("requires a way to select versions", func() {
+		API("test", func() {})
+		Version("1.0", func() {})
+		err := RunDSL()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("API defines versions but no way to select them"))
+	})
+
+	It("sets the version selectors", func() {
+		API("test", func() {
+			VersionPrefix("/v")
+			VersionHeader("X-API-Version")
+			VersionQuery("version")
+		})
+		Version("1.0", func() {})
+		Ω(RunDSL()).ShouldNot(HaveOccurred())
+		Ω(Design.VersionPrefix).Should(Equal("/v"))
+		Ω(Design.VersionHeader).Should(Equal("X-API-Version"))
+		Ω(Design.VersionQuery).Should(Equal("version"))
+	})
+
+	It("must start with a slash", func() {
+		API("test", func() {
+			VersionPrefix("v")
+		})
+		Ω(RunDSL()).Should(HaveOccurred())
+	})
+})
@@ -3,7 +3,9 @@ package design
 import (
 	"crypto/md5"
 	"encoding/binary"
+	"fmt"
 	"math/rand"
+	"time"
 
 	"github.com/manveru/faker"
 )
@@ -52,6 +54,23 @@ func (r *RandomGenerator) Bool() bool {
 	return r.rand.Int()%2 == 0
 }
 
+// DateTime produces a random date time between 2000 and 2030.
+func (r *RandomGenerator) DateTime() time.Time {
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC).Unix()
+	return time.Unix(start+r.rand.Int63n(30*365*24*3600), 0).UTC()
+}
+
+// UUID produces the canonical string representation of a random (version 4) UUID.
+func (r *RandomGenerator) UUID() string {
+	var u [16]byte
+	for i := range u {
+		u[i] = byte(r.rand.Intn(256))
+	}
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
+
 // Float64 produces a random float64 value.
 func (r *RandomGenerator) Float64() float64 {
 	return r.rand.Float64()
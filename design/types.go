@@ -2,8 +2,10 @@
 // These are the data structures of the request payloads and parameters as well as the response
 // payloads.
 // There are primitive types corresponding to the JSON primitive types (bool, string, integer and
-// number), array types which represent a collection of another type and object types corresponding
-// to JSON objects (i.e. a map indexed by strings where each value may be any of the data types).
+// number), primitive types for values serialized as JSON strings (date times and UUIDs), a
+// primitive type for arbitrary JSON values, array types which represent a collection of another
+// type and object types corresponding to JSON objects (i.e. a map indexed by strings where each
+// value may be any of the data types).
 // On top of these the package also defines "user types" and "media types". Both these types are
 // named objects with additional properties (a description and for media types the media type
 // identifier, links and views).
@@ -11,7 +13,9 @@ package design
 
 import (
 	"reflect"
+	"regexp"
 	"sort"
+	"time"
 )
 
 type (
@@ -129,6 +133,14 @@ const (
 	UserTypeKind
 	// MediaTypeKind represents a media type.
 	MediaTypeKind
+	// FileKind represents a file uploaded in a multipart/form-data request body.
+	FileKind
+	// DateTimeKind represents a JSON string that contains a RFC3339 date time.
+	DateTimeKind
+	// UUIDKind represents a JSON string that contains a RFC4122 UUID.
+	UUIDKind
+	// AnyKind represents any JSON value.
+	AnyKind
 )
 
 const (
@@ -143,8 +155,24 @@ const (
 
 	// String is the type for a JSON string.
 	String = Primitive(StringKind)
+
+	// File is the type for a file uploaded in a multipart/form-data request body. File
+	// attributes may only be used in multipart payloads (see MultipartForm in the dsl package).
+	File = Primitive(FileKind)
+
+	// DateTime is the type for a JSON string that contains a RFC3339 date time.
+	DateTime = Primitive(DateTimeKind)
+
+	// UUID is the type for a JSON string that contains a RFC4122 UUID.
+	UUID = Primitive(UUIDKind)
+
+	// Any is the type for any JSON value.
+	Any = Primitive(AnyKind)
 )
 
+// uuidRegex matches the canonical string representation of UUIDs.
+var uuidRegex = regexp.MustCompile(`^(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
 // DataType implementation
 
 // Kind implements DataKind.
@@ -161,6 +189,14 @@ func (p Primitive) Name() string {
 		return "number"
 	case String:
 		return "string"
+	case File:
+		return "file"
+	case DateTime:
+		return "datetime"
+	case UUID:
+		return "uuid"
+	case Any:
+		return "any"
 	default:
 		panic("unknown primitive type") // bug
 	}
@@ -231,6 +267,25 @@ func (p Primitive) IsCompatible(val interface{}) (ok bool) {
 		}
 	case String:
 		_, ok = val.(string)
+	case File:
+		// Files cannot be described with literal values
+	case DateTime:
+		switch v := val.(type) {
+		case string:
+			_, err := time.Parse(time.RFC3339, v)
+			ok = err == nil
+		case time.Time:
+			ok = true
+		}
+	case UUID:
+		switch v := val.(type) {
+		case string:
+			ok = uuidRegex.MatchString(v)
+		case [16]byte:
+			ok = true
+		}
+	case Any:
+		ok = true
 	default:
 		panic("unknown primitive type") // bug
 	}
@@ -251,8 +306,12 @@ func (p Primitive) Example(r *RandomGenerator) interface{} {
 		return r.Int()
 	case Number:
 		return r.Float64()
-	case String:
+	case String, File, Any:
 		return r.String()
+	case DateTime:
+		return r.DateTime().Format(time.RFC3339)
+	case UUID:
+		return r.UUID()
 	default:
 		panic("unknown primitive type") // bug
 	}
@@ -298,12 +357,14 @@ func (a *Array) Dup() DataType {
 	return &Array{ElemType: a.ElemType.Dup()}
 }
 
-// Example produces a random array value.
+// Example produces a random array value, nil if the elements have no example.
 func (a *Array) Example(r *RandomGenerator) interface{} {
 	count := r.Int()%3 + 1
 	res := make([]interface{}, count)
 	for i := 0; i < count; i++ {
-		res[i] = a.ElemType.Type.Example(r)
+		if res[i] = a.ElemType.Example(r); res[i] == nil {
+			return nil
+		}
 	}
 	return res
 }
@@ -357,11 +418,13 @@ func (o Object) Dup() DataType {
 	return res
 }
 
-// Example returns a random value of the object.
+// Example returns a random value of the object, attributes that have no example are omitted.
 func (o Object) Example(r *RandomGenerator) interface{} {
 	res := make(map[string]interface{})
 	for n, att := range o {
-		res[n] = att.Type.Example(r)
+		if ex := att.Example(r); ex != nil {
+			res[n] = ex
+		}
 	}
 	return res
 }
@@ -407,12 +470,16 @@ func (h *Hash) Dup() DataType {
 	}
 }
 
-// Example returns a random hash value.
+// Example returns a random hash value, nil if the keys or the values have no example.
 func (h *Hash) Example(r *RandomGenerator) interface{} {
 	count := r.Int()%3 + 1
 	res := make(map[interface{}]interface{})
 	for i := 0; i < count; i++ {
-		res[h.KeyType.Type.Example(r)] = h.ElemType.Type.Example(r)
+		k, v := h.KeyType.Example(r), h.ElemType.Example(r)
+		if k == nil || v == nil {
+			return nil
+		}
+		res[k] = v
 	}
 	return res
 }
@@ -0,0 +1,152 @@
+package design_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/raphael/goa/design"
+)
+
+var _ = Describe("IsCompatible", func() {
+	var t design.DataType
+	var val interface{}
+	var res bool
+
+	JustBeforeEach(func() {
+		res = t.IsCompatible(val)
+	})
+
+	Context("with a DateTime", func() {
+		BeforeEach(func() {
+			t = design.DateTime
+		})
+
+		It("accepts RFC3339 strings", func() {
+			val = "2015-10-21T07:28:00Z"
+			Ω(t.IsCompatible(val)).Should(BeTrue())
+		})
+
+		It("accepts time values", func() {
+			val = time.Now()
+			Ω(t.IsCompatible(val)).Should(BeTrue())
+		})
+
+		Context("and an invalid string", func() {
+			BeforeEach(func() {
+				val = "yesterday"
+			})
+
+			It("returns false", func() {
+				Ω(res).Should(BeFalse())
+			})
+		})
+	})
+
+	Context("with a UUID", func() {
+		BeforeEach(func() {
+			t = design.UUID
+		})
+
+		Context("and a valid string", func() {
+			BeforeEach(func() {
+				val = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+			})
+
+			It("returns true", func() {
+				Ω(res).Should(BeTrue())
+			})
+		})
+
+		Context("and an invalid string", func() {
+			BeforeEach(func() {
+				val = "6ba7b810"
+			})
+
+			It("returns false", func() {
+				Ω(res).Should(BeFalse())
+			})
+		})
+	})
+
+	Context("with Any", func() {
+		BeforeEach(func() {
+			t = design.Any
+			val = map[string]interface{}{"foo": 1}
+		})
+
+		It("returns true", func() {
+			Ω(res).Should(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("Example", func() {
+	var r *design.RandomGenerator
+
+	BeforeEach(func() {
+		r = design.NewRandomGenerator("seed")
+	})
+
+	It("generates compatible DateTime and UUID examples", func() {
+		Ω(design.DateTime.IsCompatible(design.DateTime.Example(r))).Should(BeTrue())
+		Ω(design.UUID.IsCompatible(design.UUID.Example(r))).Should(BeTrue())
+	})
+
+	It("does not generate examples for custom formats", func() {
+		semver := &design.AttributeDefinition{
+			Type: design.String,
+			Validations: []design.ValidationDefinition{
+				&design.FormatValidationDefinition{Format: "semver"},
+			},
+		}
+		obj := design.Object{"name": {Type: design.String}, "version": semver}
+		Ω(semver.Example(r)).Should(BeNil())
+		Ω((&design.Array{ElemType: semver}).Example(r)).Should(BeNil())
+		Ω(obj.Example(r)).Should(HaveKey("name"))
+		Ω(obj.Example(r)).ShouldNot(HaveKey("version"))
+	})
+
+	It("generates numeric examples within exclusive bounds", func() {
+		negative := &design.AttributeDefinition{
+			Type: design.Integer,
+			Validations: []design.ValidationDefinition{
+				&design.MaximumValidationDefinition{Max: 0, Exclusive: true},
+			},
+		}
+		large := &design.AttributeDefinition{
+			Type: design.Number,
+			Validations: []design.ValidationDefinition{
+				&design.MinimumValidationDefinition{Min: 1, Exclusive: true},
+			},
+		}
+		for i := 0; i < 10; i++ {
+			Ω(negative.Example(r)).Should(BeNumerically("<", 0))
+			Ω(large.Example(r)).Should(BeNumerically(">", 1))
+		}
+	})
+
+	It("generates numeric examples that satisfy all the validations", func() {
+		min := &design.MinimumValidationDefinition{Min: 10}
+		multiple := &design.MultipleOfValidationDefinition{MultipleOf: 5}
+		for _, vals := range [][]design.ValidationDefinition{{min, multiple}, {multiple, min}} {
+			att := &design.AttributeDefinition{Type: design.Integer, Validations: vals}
+			for i := 0; i < 10; i++ {
+				ex := att.Example(r)
+				Ω(ex).Should(BeNumerically(">=", 10))
+				Ω(ex.(int) % 5).Should(Equal(0))
+			}
+		}
+	})
+
+	It("does not generate numeric examples when no value satisfies the validations", func() {
+		att := &design.AttributeDefinition{
+			Type: design.Integer,
+			Validations: []design.ValidationDefinition{
+				&design.MinimumValidationDefinition{Min: 1, Exclusive: true},
+				&design.MaximumValidationDefinition{Max: 2, Exclusive: true},
+			},
+		}
+		Ω(att.Example(r)).Should(BeNil())
+	})
+})
@@ -2,10 +2,25 @@ package design
 
 import (
 	"fmt"
+	"math"
 	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
 	"strings"
 )
 
+// FormatChecker is the interface used to check that the example and default values defined in
+// the design satisfy their format validations.
+type FormatChecker interface {
+	// CheckFormat returns an error if val is not a valid value of the given format.
+	CheckFormat(format, val string) error
+}
+
+// Formats checks the format of the example and default values. The dsl package sets it to a
+// checker that uses the goa runtime validators. Format validations are not checked when it is nil.
+var Formats FormatChecker
+
 // ValidationErrors records the errors encountered when running Validate.
 type ValidationErrors struct {
 	Errors      []error
@@ -96,7 +111,6 @@ func (r *routeInfo) DifferentWildcards(other *routeInfo) (res [][2]*wildCardInfo
 // an actual resource.
 func (a *APIDefinition) Validate() *ValidationErrors {
 	verr := new(ValidationErrors)
-	var allRoutes []*routeInfo
 	if a.BaseParams != nil {
 		if err := a.BaseParams.Validate("base parameters", a); err != nil {
 			verr.Merge(err)
@@ -117,6 +131,66 @@ func (a *APIDefinition) Validate() *ValidationErrors {
 			verr.Add(a, "invalid docs URL value: %s", err)
 		}
 	}
+	a.validateResources(verr)
+	a.validateVersions(verr)
+	a.IterateMediaTypes(func(mt *MediaTypeDefinition) error {
+		if err := mt.Validate(); err != nil {
+			verr.Merge(err)
+		}
+		return nil
+	})
+	a.IterateUserTypes(func(t *UserTypeDefinition) error {
+		if err := t.Validate("", a); err != nil {
+			verr.Merge(err)
+		}
+		return nil
+	})
+	a.IterateResponses(func(r *ResponseDefinition) error {
+		if err := r.Validate(); err != nil {
+			verr.Merge(err)
+		}
+		return nil
+	})
+	kinds := make(map[string]*ErrorKindDefinition)
+	a.IterateErrorKinds(func(k *ErrorKindDefinition) error {
+		if other, ok := kinds[k.Name]; ok {
+			verr.Add(k, "error kind name conflicts with %s", other.Context())
+		} else {
+			kinds[k.Name] = k
+		}
+		if err := k.Validate(); err != nil {
+			verr.Merge(err)
+		}
+		return nil
+	})
+	a.IterateSecuritySchemes(func(s *SecuritySchemeDefinition) error {
+		if err := s.Validate(); err != nil {
+			verr.Merge(err)
+		}
+		return nil
+	})
+	if a.Security != nil {
+		if err := a.Security.Validate(); err != nil {
+			verr.Merge(err)
+		}
+	}
+	for _, use := range a.TraitUses {
+		trait, ok := a.Traits[use.Name]
+		if !ok {
+			// Unknown traits are reported when running the DSL.
+			continue
+		}
+		if err := trait.ValidateArgs(use.Args); err != nil {
+			verr.Add(use, "%s", err)
+		}
+	}
+
+	return verr.AsError()
+}
+
+// validateResources validates the API resources and checks that their routes do not conflict.
+func (a *APIDefinition) validateResources(verr *ValidationErrors) {
+	var allRoutes []*routeInfo
 	a.IterateResources(func(r *ResourceDefinition) error {
 		if err := r.Validate(); err != nil {
 			verr.Merge(err)
@@ -161,26 +235,87 @@ func (a *APIDefinition) Validate() *ValidationErrors {
 			}
 		}
 	}
-	a.IterateMediaTypes(func(mt *MediaTypeDefinition) error {
-		if err := mt.Validate(); err != nil {
-			verr.Merge(err)
+	for i, route := range allRoutes {
+		for _, other := range allRoutes[i+1:] {
+			if route.Key != other.Key || route.Route.Verb != other.Route.Verb {
+				continue
+			}
+			for _, info := range []*routeInfo{route, other} {
+				if info.Resource.ImplicitBasePath {
+					verr.Add(route.Action,
+						`route "%s %s" collides with route "%s %s" of %s action %s. The base path of resource %s is computed from its name with the %#v base path policy, use BasePath to set it explicitly.`,
+						route.Route.Verb,
+						route.Route.FullPath(),
+						other.Route.Verb,
+						other.Route.FullPath(),
+						other.Resource.Name,
+						other.Action.Name,
+						info.Resource.Name,
+						a.BasePathPolicy,
+					)
+					break
+				}
+			}
 		}
-		return nil
-	})
-	a.IterateUserTypes(func(t *UserTypeDefinition) error {
-		if err := t.Validate("", a); err != nil {
-			verr.Merge(err)
+	}
+}
+
+// validateVersions validates the API versions, their resources and their media types.
+func (a *APIDefinition) validateVersions(verr *ValidationErrors) {
+	if len(a.Versions) == 0 {
+		return
+	}
+	if a.VersionPrefix == "" && a.VersionHeader == "" && a.VersionQuery == "" {
+		verr.Add(a, "API defines versions but no way to select them, use VersionPrefix, VersionHeader or VersionQuery")
+	}
+	if a.VersionPrefix != "" && len(ExtractWildcards(a.BasePath)) > 0 {
+		verr.Add(a, "cannot use version prefix with base path %#v which contains wildcards", a.BasePath)
+	}
+	a.IterateVersions(func(v *APIDefinition) error {
+		v.validateResources(verr)
+		ids := make([]string, len(v.APIVersion.MediaTypes))
+		i := 0
+		for id := range v.APIVersion.MediaTypes {
+			ids[i] = id
+			i++
 		}
-		return nil
-	})
-	a.IterateResponses(func(r *ResponseDefinition) error {
-		if err := r.Validate(); err != nil {
-			verr.Merge(err)
+		sort.Strings(ids)
+		for _, id := range ids {
+			if err := v.APIVersion.MediaTypes[id].Validate(); err != nil {
+				verr.Merge(err)
+			}
 		}
 		return nil
 	})
+}
 
-	return verr.AsError()
+// ValidateArgs checks that the given arguments match the number and types of the trait template
+// parameters.
+func (t *TraitDefinition) ValidateArgs(args []interface{}) error {
+	params := t.Params()
+	variadic := t.IsVariadic()
+	n := len(params)
+	if variadic {
+		if len(args) < n-1 {
+			return fmt.Errorf("trait %s expects at least %d arguments, got %d", t.Name, n-1, len(args))
+		}
+	} else if len(args) != n {
+		return fmt.Errorf("trait %s expects %d arguments, got %d", t.Name, n, len(args))
+	}
+	for i, arg := range args {
+		typ := t.ArgType(i)
+		if arg == nil {
+			switch typ.Kind() {
+			case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+				continue
+			}
+			return fmt.Errorf("argument of trait %s at position %d cannot be nil, must be of type %s", t.Name, i, typ)
+		}
+		if at := reflect.TypeOf(arg); !at.AssignableTo(typ) {
+			return fmt.Errorf("argument of trait %s at position %d must be of type %s, got %s", t.Name, i, typ, at)
+		}
+	}
+	return nil
 }
 
 // Validate tests whether the resource definition is consistent: action names are valid and each action is
@@ -258,6 +393,11 @@ func (r *ResourceDefinition) Validate() *ValidationErrors {
 			verr.Merge(err)
 		}
 	}
+	if r.Security != nil {
+		if err := r.Security.Validate(); err != nil {
+			verr.Merge(err)
+		}
+	}
 	return verr.AsError()
 }
 
@@ -288,10 +428,45 @@ func (a *ActionDefinition) Validate() *ValidationErrors {
 		if err := a.Payload.Validate("action payload", a); err != nil {
 			verr.Merge(err)
 		}
+		if a.RawBody {
+			verr.Add(a, "action cannot define both a payload and a raw body")
+		}
+	}
+	if a.PayloadMultipart {
+		if err := a.validateMultipartPayload(); err != nil {
+			verr.Merge(err)
+		}
+	} else if a.Payload != nil && hasFile(a.Payload.Type, nil) {
+		verr.Add(a, "payload contains file attributes but is not a multipart payload, use MultipartForm")
+	}
+	if a.Params != nil && hasFile(a.Params.Type, nil) {
+		verr.Add(a, "parameters cannot be files")
+	}
+	if a.Headers != nil && hasFile(a.Headers.Type, nil) {
+		verr.Add(a, "headers cannot be files")
+	}
+	if a.Headers != nil {
+		a.Headers.Type.ToObject().IterateAttributes(func(n string, h *AttributeDefinition) error {
+			if h.CollectionFormat == "multi" {
+				verr.Add(a, `header %s cannot use the "multi" collection format`, n)
+			}
+			return nil
+		})
+	}
+	if err := a.validateHeaderNames(); err != nil {
+		verr.Merge(err)
+	}
+	if a.MaxBodySize < 0 {
+		verr.Add(a, "invalid maximum body size %d, must be positive", a.MaxBodySize)
 	}
 	if a.Parent == nil {
 		verr.Add(a, "missing parent resource")
 	}
+	if a.Security != nil {
+		if err := a.Security.Validate(); err != nil {
+			verr.Merge(err)
+		}
+	}
 	return verr.AsError()
 }
 
@@ -336,6 +511,14 @@ func (a *ActionDefinition) ValidateParams() *ValidationErrors {
 		if err := p.Validate(ctx, a); err != nil {
 			verr.Merge(err)
 		}
+		if p.CollectionFormat == "multi" {
+			for _, wc := range wcs {
+				if wc == n {
+					verr.Add(a, `path parameter %s cannot use the "multi" collection format`, n)
+					break
+				}
+			}
+		}
 	}
 	for _, resp := range a.Responses {
 		if err := resp.Validate(); err != nil {
@@ -345,6 +528,132 @@ func (a *ActionDefinition) ValidateParams() *ValidationErrors {
 	return verr.AsError()
 }
 
+// validateHeaderNames checks that the action headers do not have the same name as one of the
+// action parameters. The generated action context has a field for each header and parameter so
+// the names must differ by more than their case or their non alphanumeric characters.
+func (a *ActionDefinition) validateHeaderNames() *ValidationErrors {
+	verr := new(ValidationErrors)
+	params := []*AttributeDefinition{a.Params}
+	headers := []*AttributeDefinition{a.Headers}
+	if a.Parent != nil {
+		params = append(params, a.Parent.BaseParams)
+		headers = append(headers, a.Parent.Headers)
+	}
+	if Design != nil {
+		params = append(params, Design.BaseParams)
+	}
+	paramNames := make(map[string]string)
+	for _, att := range params {
+		if att != nil {
+			for n := range att.Type.ToObject() {
+				paramNames[fieldKey(n)] = n
+			}
+		}
+	}
+	headerNames := make(map[string]bool)
+	for _, att := range headers {
+		if att != nil {
+			for n := range att.Type.ToObject() {
+				headerNames[n] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(headerNames))
+	for n := range headerNames {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if p, ok := paramNames[fieldKey(n)]; ok {
+			verr.Add(a, "header %s and parameter %s map to the same action context field", n, p)
+		}
+	}
+	return verr.AsError()
+}
+
+// fieldKey returns the key used to detect header and parameter names that produce the same Go
+// identifier in the generated code.
+func fieldKey(name string) string {
+	return strings.ToLower(nonAlphaNumRegex.ReplaceAllString(name, ""))
+}
+
+// nonAlphaNumRegex matches the characters ignored when comparing header and parameter names.
+var nonAlphaNumRegex = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// validateMultipartPayload checks that the action payload can be sent in a multipart/form-data
+// request body: it is an object whose attributes are primitives, files or arrays of these.
+func (a *ActionDefinition) validateMultipartPayload() *ValidationErrors {
+	verr := new(ValidationErrors)
+	if a.Payload == nil {
+		verr.Add(a, "multipart payload requires a payload definition")
+		return verr
+	}
+	obj := a.Payload.Type.ToObject()
+	if obj == nil {
+		verr.Add(a, "multipart payload must be an object")
+		return verr
+	}
+	obj.IterateAttributes(func(n string, at *AttributeDefinition) error {
+		t := at.Type
+		if arr, ok := t.(*Array); ok {
+			t = arr.ElemType.Type
+		}
+		if _, ok := t.(Primitive); !ok {
+			verr.Add(a, "invalid type for multipart payload attribute %#v, must be a primitive, a file or an array of primitives or files", n)
+		}
+		return nil
+	})
+	if len(a.Consumes) > 0 {
+		found := false
+		for _, mt := range a.Consumes {
+			if mt == "multipart/form-data" {
+				found = true
+				break
+			}
+		}
+		if !found {
+			verr.Add(a, "action with multipart payload must consume multipart/form-data")
+		}
+	}
+	return verr.AsError()
+}
+
+// hasFile returns true if the given type is a file or contains file attributes. seen records the
+// user types already visited to handle recursive types.
+func hasFile(t DataType, seen map[string]bool) bool {
+	switch actual := t.(type) {
+	case Primitive:
+		return actual.Kind() == FileKind
+	case *Array:
+		return hasFile(actual.ElemType.Type, seen)
+	case *Hash:
+		return hasFile(actual.KeyType.Type, seen) || hasFile(actual.ElemType.Type, seen)
+	case Object:
+		for _, at := range actual {
+			if hasFile(at.Type, seen) {
+				return true
+			}
+		}
+	case *UserTypeDefinition:
+		if seen == nil {
+			seen = make(map[string]bool)
+		} else if seen[actual.TypeName] {
+			return false
+		}
+		seen[actual.TypeName] = true
+		return hasFile(actual.Type, seen)
+	case *MediaTypeDefinition:
+		if seen == nil {
+			seen = make(map[string]bool)
+		} else if seen[actual.TypeName] {
+			return false
+		}
+		seen[actual.TypeName] = true
+		return hasFile(actual.Type, seen)
+	}
+	return false
+}
+
 // Validate tests whether the attribute definition is consistent: required fields exist.
 // Since attributes are unaware of their context, additional context information can be provided
 // to be used in error messages.
@@ -359,12 +668,15 @@ func (a *AttributeDefinition) Validate(ctx string, parent DSLDefinition) *Valida
 		ctx += " - "
 	}
 	o, isObject := a.Type.(Object)
+	kind := a.Type.Kind()
+	isNumber := kind == IntegerKind || kind == NumberKind
 	for _, v := range a.Validations {
-		if r, ok := v.(*RequiredValidationDefinition); ok {
+		switch actual := v.(type) {
+		case *RequiredValidationDefinition:
 			if !isObject {
 				verr.Add(parent, `%sonly objects may define a "Required" validation`, ctx)
 			}
-			for _, n := range r.Names {
+			for _, n := range actual.Names {
 				var found bool
 				for an := range o {
 					if n == an {
@@ -376,6 +688,38 @@ func (a *AttributeDefinition) Validate(ctx string, parent DSLDefinition) *Valida
 					verr.Add(parent, `%srequired field "%s" does not exist`, ctx, n)
 				}
 			}
+		case *MultipleOfValidationDefinition:
+			if !isNumber {
+				verr.Add(parent, `%sonly integers and numbers may define a "MultipleOf" validation`, ctx)
+			}
+			if actual.MultipleOf <= 0 {
+				verr.Add(parent, `%s"MultipleOf" value must be strictly greater than 0`, ctx)
+			}
+		case *MinimumValidationDefinition:
+			if actual.Exclusive && !isNumber {
+				verr.Add(parent, `%sonly integers and numbers may define an "ExclusiveMinimum" validation`, ctx)
+			}
+		case *MaximumValidationDefinition:
+			if actual.Exclusive && !isNumber {
+				verr.Add(parent, `%sonly integers and numbers may define an "ExclusiveMaximum" validation`, ctx)
+			}
+		case *UniqueItemsValidationDefinition:
+			if kind != ArrayKind {
+				verr.Add(parent, `%sonly arrays may define a "UniqueItems" validation`, ctx)
+			}
+		case *MinPropertiesValidationDefinition:
+			if kind != HashKind {
+				verr.Add(parent, `%sonly hashes may define a "MinProperties" validation`, ctx)
+			}
+		case *MaxPropertiesValidationDefinition:
+			if kind != HashKind {
+				verr.Add(parent, `%sonly hashes may define a "MaxProperties" validation`, ctx)
+			}
+		}
+	}
+	if a.ExampleValue != nil {
+		if err := a.validateValue(a.ExampleValue); err != nil {
+			verr.Add(parent, "%sinvalid example: %s", ctx, err)
 		}
 	}
 	if isObject {
@@ -397,6 +741,180 @@ func (a *AttributeDefinition) Validate(ctx string, parent DSLDefinition) *Valida
 	return verr.AsError()
 }
 
+// validateValue checks that the given value is compatible with the attribute type and satisfies
+// the attribute validations. Object, array and user type values are validated recursively.
+func (a *AttributeDefinition) validateValue(val interface{}) error {
+	if val == nil {
+		return nil
+	}
+	if !a.Type.IsCompatible(val) {
+		return fmt.Errorf("value %#v is incompatible with type %s", val, a.Type.Name())
+	}
+	rv := reflect.ValueOf(val)
+	for _, v := range a.Validations {
+		switch actual := v.(type) {
+		case *EnumValidationDefinition:
+			found := false
+			for _, e := range actual.Values {
+				if equalValues(e, val) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("value %#v must be one of %v", val, actual.Values)
+			}
+		case *FormatValidationDefinition:
+			if s, ok := val.(string); ok && Formats != nil {
+				if err := Formats.CheckFormat(actual.Format, s); err != nil {
+					return err
+				}
+			}
+		case *PatternValidationDefinition:
+			if s, ok := val.(string); ok {
+				if matched, _ := regexp.MatchString(actual.Pattern, s); !matched {
+					return fmt.Errorf("value %#v does not match the regexp %s", s, actual.Pattern)
+				}
+			}
+		case *MinimumValidationDefinition:
+			if f, ok := toFloat(val); ok {
+				if actual.Exclusive && f <= actual.Min {
+					return fmt.Errorf("value %#v must be greater than %v", val, actual.Min)
+				}
+				if f < actual.Min {
+					return fmt.Errorf("value %#v must be greater or equal than %v", val, actual.Min)
+				}
+			}
+		case *MaximumValidationDefinition:
+			if f, ok := toFloat(val); ok {
+				if actual.Exclusive && f >= actual.Max {
+					return fmt.Errorf("value %#v must be lesser than %v", val, actual.Max)
+				}
+				if f > actual.Max {
+					return fmt.Errorf("value %#v must be lesser or equal than %v", val, actual.Max)
+				}
+			}
+		case *MultipleOfValidationDefinition:
+			if f, ok := toFloat(val); ok && !isMultipleOf(f, actual.MultipleOf) {
+				return fmt.Errorf("value %#v must be a multiple of %v", val, actual.MultipleOf)
+			}
+		case *UniqueItemsValidationDefinition:
+			if !hasUniqueItems(rv) {
+				return fmt.Errorf("elements of value %#v must be unique", val)
+			}
+		case *MinPropertiesValidationDefinition:
+			if rv.Kind() == reflect.Map && rv.Len() < actual.MinProperties {
+				return fmt.Errorf("number of properties of value %#v must be greater or equal than %d", val, actual.MinProperties)
+			}
+		case *MaxPropertiesValidationDefinition:
+			if rv.Kind() == reflect.Map && rv.Len() > actual.MaxProperties {
+				return fmt.Errorf("number of properties of value %#v must be lesser or equal than %d", val, actual.MaxProperties)
+			}
+		case *MinLengthValidationDefinition:
+			if l, ok := length(rv); ok && l < actual.MinLength {
+				return fmt.Errorf("length of value %#v must be greater or equal than %d", val, actual.MinLength)
+			}
+		case *MaxLengthValidationDefinition:
+			if l, ok := length(rv); ok && l > actual.MaxLength {
+				return fmt.Errorf("length of value %#v must be lesser or equal than %d", val, actual.MaxLength)
+			}
+		case *RequiredValidationDefinition:
+			if rv.Kind() == reflect.Map {
+				for _, n := range actual.Names {
+					if !rv.MapIndex(reflect.ValueOf(n)).IsValid() {
+						return fmt.Errorf("required field %#v is missing from value %#v", n, val)
+					}
+				}
+			}
+		}
+	}
+	switch actual := a.Type.(type) {
+	case *UserTypeDefinition:
+		return actual.AttributeDefinition.validateValue(val)
+	case *MediaTypeDefinition:
+		return actual.AttributeDefinition.validateValue(val)
+	case *Array:
+		if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			for i := 0; i < rv.Len(); i++ {
+				if err := actual.ElemType.validateValue(rv.Index(i).Interface()); err != nil {
+					return err
+				}
+			}
+		}
+	case Object:
+		if rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String {
+			for _, k := range rv.MapKeys() {
+				att, ok := actual[k.String()]
+				if !ok {
+					return fmt.Errorf("value %#v defines unknown field %#v", val, k.String())
+				}
+				if err := att.validateValue(rv.MapIndex(k).Interface()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// equalValues returns true if the two values are equal, numbers of different types compare equal
+// if their values are equal.
+func equalValues(a, b interface{}) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// isMultipleOf returns true if val is a multiple of divisor.
+func isMultipleOf(val, divisor float64) bool {
+	if divisor == 0 {
+		return false
+	}
+	q := val / divisor
+	return math.Abs(q-math.Floor(q+0.5)) < 1e-9
+}
+
+// hasUniqueItems returns true if the elements of the given array or slice are all different.
+func hasUniqueItems(v reflect.Value) bool {
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return true
+	}
+	for i := 0; i < v.Len(); i++ {
+		for j := i + 1; j < v.Len(); j++ {
+			if equalValues(v.Index(i).Interface(), v.Index(j).Interface()) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// toFloat converts numeric values to float64, the second return value is false if val is not a
+// number.
+func toFloat(val interface{}) (float64, bool) {
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// length returns the length of string, array, slice or map values.
+func length(v reflect.Value) (int, bool) {
+	switch v.Kind() {
+	case reflect.String, reflect.Array, reflect.Slice, reflect.Map:
+		return v.Len(), true
+	}
+	return 0, false
+}
+
 // Validate checks that the response definition is consistent: its status is set and the media
 // type definition if any is valid.
 func (r *ResponseDefinition) Validate() *ValidationErrors {
@@ -412,6 +930,94 @@ func (r *ResponseDefinition) Validate() *ValidationErrors {
 	return verr.AsError()
 }
 
+// Validate checks that the error kind definition is consistent: it has a name and its response
+// is defined.
+func (k *ErrorKindDefinition) Validate() *ValidationErrors {
+	verr := new(ValidationErrors)
+	if k.Name == "" {
+		verr.Add(k, "error kind name cannot be empty")
+	}
+	if k.ResponseDefinition() == nil {
+		verr.Add(k, "unknown response %#v", k.Response)
+	}
+	return verr.AsError()
+}
+
+// Validate checks that the security scheme definition is consistent: it has a name and the
+// properties required by its kind.
+func (s *SecuritySchemeDefinition) Validate() *ValidationErrors {
+	verr := new(ValidationErrors)
+	if s.Name == "" {
+		verr.Add(s, "security scheme name cannot be empty")
+	}
+	switch s.Kind {
+	case BasicAuthSecurityKind:
+	case APIKeySecurityKind, JWTSecurityKind:
+		if s.In != "header" && s.In != "query" {
+			verr.Add(s, `invalid location %#v, must be "header" or "query"`, s.In)
+		}
+		if s.ParamName == "" {
+			verr.Add(s, "missing header or query string parameter name")
+		}
+	case OAuth2SecurityKind:
+		switch s.Flow {
+		case "implicit":
+			if s.AuthorizationURL == "" {
+				verr.Add(s, "implicit flow requires an authorization URL")
+			}
+		case "password", "application":
+			if s.TokenURL == "" {
+				verr.Add(s, "%s flow requires a token URL", s.Flow)
+			}
+		case "accessCode":
+			if s.AuthorizationURL == "" || s.TokenURL == "" {
+				verr.Add(s, "access code flow requires an authorization URL and a token URL")
+			}
+		default:
+			verr.Add(s, "missing or invalid OAuth2 flow %#v", s.Flow)
+		}
+	default:
+		verr.Add(s, "invalid security scheme kind %#v", s.Kind)
+	}
+	if len(s.Scopes) > 0 && s.Kind != OAuth2SecurityKind && s.Kind != JWTSecurityKind {
+		verr.Add(s, "scopes are only supported by OAuth2 and JWT security schemes")
+	}
+	for _, u := range []string{s.AuthorizationURL, s.TokenURL} {
+		if u == "" {
+			continue
+		}
+		if _, err := url.ParseRequestURI(u); err != nil {
+			verr.Add(s, "invalid URL %#v: %s", u, err)
+		}
+	}
+	return verr.AsError()
+}
+
+// Validate checks that the security requirement is consistent: it refers to an existing security
+// scheme and the required scopes are supported by the scheme.
+func (s *SecurityDefinition) Validate() *ValidationErrors {
+	verr := new(ValidationErrors)
+	if s.Scheme == "" {
+		return nil
+	}
+	scheme := s.SchemeDefinition()
+	if scheme == nil {
+		verr.Add(s, "unknown security scheme %#v", s.Scheme)
+		return verr
+	}
+	if len(s.Scopes) > 0 && scheme.Kind != OAuth2SecurityKind && scheme.Kind != JWTSecurityKind {
+		verr.Add(s, "security scheme %#v does not support scopes", s.Scheme)
+	}
+	if len(scheme.Scopes) > 0 {
+		for _, sc := range s.Scopes {
+			if _, ok := scheme.Scopes[sc]; !ok {
+				verr.Add(s, "scope %#v is not defined by security scheme %#v", sc, s.Scheme)
+			}
+		}
+	}
+	return verr.AsError()
+}
+
 // Validate checks that the route definition is consistent: it has a parent.
 func (r *RouteDefinition) Validate() *ValidationErrors {
 	verr := new(ValidationErrors)
@@ -30,13 +30,16 @@ implemented with it.
 The request state is accessible through the Get, GetMany and Payload methods which return the values
 of the request parameters, query strings and request body. Action specific contexts wrap Context and
 expose properly typed fields corresponding to the request parameters and body data structure
-descriptions appearing in the design.
+descriptions appearing in the design. Request bodies are decoded using the decoder registered on the
+service for the request Content-Type, see Service.SetDecoder. Requests whose content type has no
+registered decoder are rejected with a 415 response.
 
 The response state can be accessed through the ResponseStatus, ResponseLength and Header methods.
 The Context type implements the http.ResponseWriter interface and thus action contexts can be used
 in places http.ResponseWriter can. Action contexts provide action specific helper methods that write
 the responses as described in the design optionally taking an instance of the media type for
-responses that contain a body.
+responses that contain a body. Response bodies are serialized by Context.Send using the encoder
+registered on the service that best matches the request Accept header, see Service.SetEncoder.
 
 Here is an example showing an "update" action corresponding to following design (extract):
 
@@ -96,6 +99,11 @@ function on either a controller or service wide. goa comes with an alternative e
 TerseErrorHandler - which also returns a response with status 500 but does not write the error
 message to the body of the response.
 
+Both handlers write error documents that follow the problem details format described in RFC 7807
+using the "application/problem+json" content type, see ErrorDocument. The ErrorMedia design
+function declares the corresponding media type in the API design so that clients and the Swagger
+specification describe the error responses.
+
 Middleware
 
 A goa middleware is a function that takes and returns a Handler. A Handler is a the low level
@@ -106,12 +114,27 @@ Middleware can be added to a goa service or a specific controller using the Serv
 goa comes with a few stock middleware that handle common needs such as logging, panic recovery or
 using the RequestID header to trace requests across multiple services.
 
+The BasicAuthSecurity, APIKeySecurity and TokenSecurity middleware enforce the security schemes
+defined in the design. goagen generates one such middleware per scheme and wraps the handlers of the
+secured actions with it. The application provides the functions that validate the request
+credentials, requests that lack valid credentials produce an UnauthorizedError which the error
+handler sends as a response with status code 401. The JWTSpec Validate method implements such a
+function for JSON Web Tokens and the JWT middleware applies it to all the requests.
+
 Validation
 
 The goa design language documented in the dsl package makes it possible to attach validations to
 data structure definitions. One specific type of validation consists of defining the format that a
 data structure string field must follow. Example of formats include email, data time, hostnames etc.
-The ValidateFormat function provides the implementation for the format validation invoked from the
-code generated by goagen.
+The ValidateFormat function provides the implementation for the format validation, the code
+generated by goagen calls the validator of each format (e.g. ValidateEmail) directly.
+
+Applications may define their own formats such as "semver" with RegisterFormat. The design package
+must register them for the DSL to accept them. The application must also register them before
+mounting the controllers as the generated code validates custom formats with ValidateFormat. The
+generated mount functions log an error for each custom format that has no registered validator.
+
+The generated code also compiles the regular expressions used by pattern validations once when the
+package is initialized so that the validations are safe for concurrent use.
 */
 package goa
@@ -0,0 +1,427 @@
+package goa
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Decoder is the interface implemented by objects that can decode a request body.
+	// The standard library json.Decoder and xml.Decoder types implement this interface.
+	Decoder interface {
+		Decode(v interface{}) error
+	}
+
+	// DecoderFactory creates a decoder that reads from the given request body.
+	DecoderFactory func(body io.Reader) Decoder
+
+	// DecodeFunc decodes a JSON request body directly into the payload type of an action and
+	// validates it. It returns a BadRequestError if the body does not match the payload design.
+	// goagen generates one such function for each action payload that supports it, see
+	// Controller.SetDecodeFunc.
+	DecodeFunc func(dec Decoder) (interface{}, error)
+
+	// Encoder is the interface implemented by objects that can encode a response body.
+	// The standard library json.Encoder and xml.Encoder types implement this interface.
+	Encoder interface {
+		Encode(v interface{}) error
+	}
+
+	// EncoderFactory creates an encoder that writes to the given response body.
+	EncoderFactory func(body io.Writer) Encoder
+
+	// decoderRegistry maps media types to the decoder factories used to decode request bodies
+	// with the corresponding Content-Type.
+	decoderRegistry struct {
+		factories map[string]DecoderFactory
+		def       DecoderFactory
+	}
+
+	// MediaTypeRenderer is implemented by the media types generated by goagen. It makes it
+	// possible to write responses using either the generic encoders or the generated JSON
+	// writers, see Context.SendMedia.
+	MediaTypeRenderer interface {
+		// DumpView validates and renders the media type into raw data using the given view.
+		DumpView(view string) (interface{}, error)
+		// WriteJSON validates the media type and writes its JSON representation rendered
+		// with the given view.
+		WriteJSON(w *JSONWriter, view string) error
+	}
+
+	// CollectionRenderer is implemented by the media type collections generated by goagen. It
+	// makes it possible to stream the collection elements, see Context.StreamMedia.
+	CollectionRenderer interface {
+		MediaTypeRenderer
+		// Len returns the number of elements in the collection.
+		Len() int
+		// WriteElemJSON validates the i-th element of the collection and writes its JSON
+		// representation rendered with the given view.
+		WriteElemJSON(w *JSONWriter, i int, view string) error
+	}
+
+	// encoderRegistry maps media types to the encoder factories used to write response bodies.
+	// order records the registration order so that content negotiation is deterministic.
+	// natives records the media types that still use the built-in JSON encoder.
+	encoderRegistry struct {
+		factories map[string]EncoderFactory
+		order     []string
+		def       EncoderFactory
+		natives   map[string]bool
+		defNative bool
+	}
+
+	// acceptRange is a media range listed in a request Accept header.
+	acceptRange struct {
+		mediaType   string
+		q           float64
+		specificity int
+	}
+
+	// formDecoder decodes application/x-www-form-urlencoded request bodies.
+	formDecoder struct {
+		body io.Reader
+	}
+)
+
+// JSONDecoderFactory creates decoders for JSON request bodies. It is registered by default for the
+// "application/json" content type and is also used for requests that do not specify a
+// Content-Type.
+func JSONDecoderFactory(body io.Reader) Decoder {
+	return json.NewDecoder(body)
+}
+
+// JSONEncoderFactory creates encoders that write JSON response bodies. It is registered by default
+// for the "application/json" content type.
+func JSONEncoderFactory(body io.Writer) Encoder {
+	return json.NewEncoder(body)
+}
+
+// FormDecoderFactory creates decoders for application/x-www-form-urlencoded request bodies.
+// The decoded payload is a map[string]interface{} whose values are strings for single valued
+// keys and []string for multi-valued keys.
+// Register it with:
+//
+//	service.SetDecoder(goa.FormDecoderFactory, false, "application/x-www-form-urlencoded")
+func FormDecoderFactory(body io.Reader) Decoder {
+	return &formDecoder{body: body}
+}
+
+// Decode reads the form values from the body and stores them in v which must be a pointer to an
+// empty interface or to a map[string]interface{}.
+func (d *formDecoder) Decode(v interface{}) error {
+	b, err := ioutil.ReadAll(d.body)
+	if err != nil {
+		return err
+	}
+	values, err := url.ParseQuery(string(b))
+	if err != nil {
+		return err
+	}
+	m := make(map[string]interface{}, len(values))
+	for k, vals := range values {
+		if len(vals) == 1 {
+			m[k] = vals[0]
+		} else {
+			m[k] = vals
+		}
+	}
+	switch t := v.(type) {
+	case *interface{}:
+		*t = m
+	case *map[string]interface{}:
+		*t = m
+	default:
+		return fmt.Errorf("form decoder: unsupported target type %T", v)
+	}
+	return nil
+}
+
+// MultipartMaxMemory is the maximum number of bytes of the multipart/form-data request bodies that
+// are kept in memory. The content of the files that do not fit is stored in temporary files which
+// are removed once the request has been handled.
+var MultipartMaxMemory int64 = 32 << 20
+
+// isMultipart returns true if the given content type is multipart/form-data.
+func isMultipart(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "multipart/form-data"
+}
+
+// decodeMultipart reads the multipart/form-data body with the given content type. The decoded
+// payload is a *multipart.Form.
+func decodeMultipart(body io.Reader, contentType string) (*multipart.Form, error) {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+	boundary := params["boundary"]
+	if boundary == "" {
+		return nil, fmt.Errorf("missing multipart boundary")
+	}
+	return multipart.NewReader(body, boundary).ReadForm(MultipartMaxMemory)
+}
+
+// newDecoderRegistry returns a registry that decodes JSON request bodies.
+func newDecoderRegistry() *decoderRegistry {
+	return &decoderRegistry{
+		factories: map[string]DecoderFactory{"application/json": JSONDecoderFactory},
+		def:       JSONDecoderFactory,
+	}
+}
+
+// set registers the decoder factory for the given content types.
+func (r *decoderRegistry) set(f DecoderFactory, makeDefault bool, contentTypes ...string) {
+	for _, ct := range contentTypes {
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil {
+			mediaType = strings.ToLower(ct)
+		}
+		r.factories[mediaType] = f
+	}
+	if makeDefault {
+		r.def = f
+	}
+}
+
+// lookup returns the decoder factory for the given Content-Type header value, nil if there is
+// none. The default decoder is returned if contentType is empty. Media types that use a
+// structured syntax suffix (e.g. "application/vnd.goa.bottle+json") fall back to the decoder
+// registered for the suffix (e.g. "application/json").
+func (r *decoderRegistry) lookup(contentType string) DecoderFactory {
+	if contentType == "" {
+		return r.def
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+	if f, ok := r.factories[mediaType]; ok {
+		return f
+	}
+	if idx := strings.LastIndex(mediaType, "+"); idx > -1 {
+		if f, ok := r.factories["application/"+mediaType[idx+1:]]; ok {
+			return f
+		}
+	}
+	return nil
+}
+
+// isJSON returns true if the given content type is application/json or a media type using the
+// +json structured syntax suffix.
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// withCharset returns the given content type with the UTF-8 charset parameter appended if it is
+// a JSON or text content type that does not specify a charset already.
+func withCharset(contentType string) string {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || params["charset"] != "" {
+		return contentType
+	}
+	if isJSON(contentType) || strings.HasPrefix(mediaType, "text/") {
+		return contentType + "; charset=utf-8"
+	}
+	return contentType
+}
+
+// acceptsContentType returns true if the given Content-Type header value is one of the given media
+// types. It also returns true if mediaTypes is empty or if contentType is empty as the default
+// decoder is used in this case.
+func acceptsContentType(mediaTypes []string, contentType string) bool {
+	if len(mediaTypes) == 0 || contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, mt := range mediaTypes {
+		if m, _, err := mime.ParseMediaType(mt); err == nil && m == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// newEncoderRegistry returns a registry that encodes response bodies into JSON.
+func newEncoderRegistry() *encoderRegistry {
+	return &encoderRegistry{
+		factories: map[string]EncoderFactory{"application/json": JSONEncoderFactory},
+		order:     []string{"application/json"},
+		def:       JSONEncoderFactory,
+		natives:   map[string]bool{"application/json": true},
+		defNative: true,
+	}
+}
+
+// set registers the encoder factory for the given content types.
+func (r *encoderRegistry) set(f EncoderFactory, makeDefault bool, contentTypes ...string) {
+	for _, ct := range contentTypes {
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil {
+			mediaType = strings.ToLower(ct)
+		}
+		if _, ok := r.factories[mediaType]; !ok {
+			r.order = append(r.order, mediaType)
+		}
+		r.factories[mediaType] = f
+		delete(r.natives, mediaType)
+	}
+	if makeDefault {
+		r.def = f
+		r.defNative = false
+	}
+}
+
+// native returns true if the encoder used to write responses with the given content type is the
+// built-in JSON encoder. The generated JSON writers produce the same output as that encoder and
+// may be used in its place.
+func (r *encoderRegistry) native(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(contentType)
+	}
+	if _, ok := r.factories[mediaType]; ok {
+		return r.natives[mediaType]
+	}
+	if idx := strings.LastIndex(mediaType, "+"); idx > -1 {
+		suffix := "application/" + mediaType[idx+1:]
+		if _, ok := r.factories[suffix]; ok {
+			return r.natives[suffix]
+		}
+	}
+	return r.defNative
+}
+
+// lookup returns the encoder factory registered for the given media type, nil if there is none.
+// Media types that use a structured syntax suffix fall back to the encoder registered for the
+// suffix.
+func (r *encoderRegistry) lookup(contentType string) EncoderFactory {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+	if f, ok := r.factories[mediaType]; ok {
+		return f
+	}
+	if idx := strings.LastIndex(mediaType, "+"); idx > -1 {
+		if f, ok := r.factories["application/"+mediaType[idx+1:]]; ok {
+			return f
+		}
+	}
+	return nil
+}
+
+// negotiate returns the response content type and encoder factory that best match the given
+// Accept header value. mediaType is the media type of the response as described in the design, it
+// is preferred over the other registered media types when acceptable. Its encoder is the one
+// registered for it or the default encoder if there is none. negotiate returns an empty string
+// and a nil factory if none of the media types are acceptable.
+func (r *encoderRegistry) negotiate(accept, mediaType string) (string, EncoderFactory) {
+	type offer struct {
+		contentType, mediaType string
+		factory                EncoderFactory
+	}
+	var offers []offer
+	if mediaType != "" {
+		base, _, err := mime.ParseMediaType(mediaType)
+		if err != nil {
+			base = strings.ToLower(mediaType)
+		}
+		f := r.lookup(base)
+		if f == nil {
+			f = r.def
+		}
+		offers = append(offers, offer{mediaType, base, f})
+	}
+	for _, mt := range r.order {
+		if len(offers) == 0 || offers[0].mediaType != mt {
+			offers = append(offers, offer{mt, mt, r.factories[mt]})
+		}
+	}
+	if len(offers) == 0 {
+		return "", nil
+	}
+	if strings.TrimSpace(accept) == "" {
+		return offers[0].contentType, offers[0].factory
+	}
+	ranges := parseAccept(accept)
+	excluded := make(map[string]bool)
+	for _, ar := range ranges {
+		if ar.q == 0 && ar.specificity == 2 {
+			excluded[ar.mediaType] = true
+		}
+	}
+	for _, ar := range ranges {
+		if ar.q == 0 {
+			continue
+		}
+		for _, o := range offers {
+			if !excluded[o.mediaType] && ar.matches(o.mediaType) {
+				return o.contentType, o.factory
+			}
+		}
+	}
+	return "", nil
+}
+
+// parseAccept parses the given Accept header value and returns the media ranges it lists sorted by
+// decreasing quality value then decreasing specificity. Invalid media ranges are ignored.
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, elem := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(elem))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if qs, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(qs, 64); err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		specificity := 2
+		if mediaType == "*/*" {
+			specificity = 0
+		} else if strings.HasSuffix(mediaType, "/*") {
+			specificity = 1
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q, specificity: specificity})
+	}
+	sort.Stable(acceptRanges(ranges))
+	return ranges
+}
+
+// acceptRanges implements sort.Interface to sort media ranges by preference.
+type acceptRanges []acceptRange
+
+func (a acceptRanges) Len() int      { return len(a) }
+func (a acceptRanges) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a acceptRanges) Less(i, j int) bool {
+	if a[i].q != a[j].q {
+		return a[i].q > a[j].q
+	}
+	return a[i].specificity > a[j].specificity
+}
+
+// matches returns true if the media range includes the given media type.
+func (ar acceptRange) matches(mediaType string) bool {
+	switch ar.specificity {
+	case 0:
+		return true
+	case 1:
+		return strings.HasPrefix(mediaType, ar.mediaType[:len(ar.mediaType)-1])
+	default:
+		return ar.mediaType == mediaType
+	}
+}
@@ -15,14 +15,25 @@
 // InvalidAttributeTypeError etc. These methods take and return an error which is a MultiError that
 // gets built over time. The final MultiError object then gets serialized into the response and sent
 // back to the client. The response status code is inferred from the type wrapping the error object:
-// a BadRequestError produces a 400 status code while any other error produce a 500. This behavior
-// can be overridden by setting a custom ErrorHandler in the application.
+// a BadRequestError produces a 400 status code, an UnauthorizedError a 401 while any other error
+// produce a 500. This behavior can be overridden by setting a custom ErrorHandler in the application.
+//
+// The default error handlers write the errors using the ErrorDocument data structure. This data
+// structure follows the problem details format described in RFC 7807 and is sent with the
+// "application/problem+json" content type. It carries the error id as a stable code, the title, a
+// detail message and the request ID if any. The individual errors that make up a MultiError are
+// listed in the document together with the path to the offending field when known.
+//
+// The design may also map error kinds to responses. goagen generates a constructor for each error
+// kind which creates a ResponseError. The controller sends the corresponding response when an
+// action returns such an error instead of invoking the error handler.
 package goa
 
 import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"strings"
 )
 
@@ -34,6 +45,8 @@ type (
 	TypedError struct {
 		ID   ErrorID
 		Mesg string
+		// Field is the path to the offending parameter, header or attribute if any.
+		Field string
 	}
 
 	// MultiError records multiple errors.
@@ -43,8 +56,68 @@ type (
 	BadRequestError struct {
 		Actual error
 	}
+
+	// UnauthorizedError is the type of errors that result in a response with status code 401.
+	UnauthorizedError struct {
+		Actual error
+	}
+
+	// ResponseError is the type of the errors created by the constructors that goagen generates
+	// for the error kinds defined in the design. The controller sends the response described in
+	// the design when an action returns a ResponseError instead of invoking the error handler.
+	ResponseError struct {
+		// Kind is the name of the error kind as defined in the design.
+		Kind string
+		// Status is the response status code.
+		Status int
+		// MediaType is the response media type identifier if the response has a body.
+		MediaType string
+		// Body is the response body if any, the response body is the error document built
+		// from the error if nil.
+		Body interface{}
+		// Actual is the underlying error if any.
+		Actual error
+	}
+
+	// ErrorDocument is the data structure written to the body of error responses. It follows the
+	// problem details format described in RFC 7807.
+	ErrorDocument struct {
+		// Type is a URI reference that identifies the problem type.
+		Type string `json:"type"`
+		// Title is a short, human-readable summary of the problem type.
+		Title string `json:"title"`
+		// Status is the HTTP status code of the response.
+		Status int `json:"status"`
+		// Detail is a human-readable explanation specific to this occurrence of the problem.
+		Detail string `json:"detail,omitempty"`
+		// Code is the id of the error, 0 if the error is not a TypedError.
+		Code ErrorID `json:"code"`
+		// Kind is the name of the error kind defined in the design if any.
+		Kind string `json:"kind,omitempty"`
+		// RequestID is the ID of the request that caused the error if any.
+		RequestID string `json:"request_id,omitempty"`
+		// Errors lists the individual errors when there is more than one or when the error
+		// relates to a specific field.
+		Errors []*ErrorDetail `json:"errors,omitempty"`
+	}
+
+	// ErrorDetail describes one of the errors listed in an ErrorDocument.
+	ErrorDetail struct {
+		// Code is the id of the error, 0 if the error is not a TypedError.
+		Code ErrorID `json:"code"`
+		// Title is the error id title.
+		Title string `json:"title"`
+		// Detail is the error message.
+		Detail string `json:"detail"`
+		// Field is the path to the offending parameter, header or attribute if any.
+		Field string `json:"field,omitempty"`
+	}
 )
 
+// ErrorMediaIdentifier is the media type identifier of the error documents written by the default
+// error handlers.
+const ErrorMediaIdentifier = "application/problem+json"
+
 const (
 	// ErrInvalidParamType is the error produced by the generated code when
 	// a request parameter type does not match the design.
@@ -93,6 +166,42 @@ const (
 	// specified in the design definition or more elements than the
 	// maximum length.
 	ErrInvalidLength
+
+	// ErrInvalidEncoding is the error produced when a request body cannot be decoded.
+	ErrInvalidEncoding
+
+	// ErrUnsupportedMediaType is the error produced when there is no decoder registered for the
+	// request content type or when the action does not accept it.
+	ErrUnsupportedMediaType
+
+	// ErrNotAcceptable is the error produced when none of the media types listed in the request
+	// Accept header can be produced.
+	ErrNotAcceptable
+
+	// ErrUnauthorized is the error produced when a request made to a secured action is missing
+	// credentials or the credentials are not valid.
+	ErrUnauthorized
+
+	// ErrBodyTooLarge is the error produced when the size of a request body exceeds the maximum
+	// configured for the service or the action.
+	ErrBodyTooLarge
+
+	// ErrInvalidHeaderType is the error produced by the generated code when the value of a
+	// request header does not match the type defined in the design.
+	ErrInvalidHeaderType
+
+	// ErrInvalidMultipleOf is the error produced by the generated code when a value is not a
+	// multiple of the number specified in the design definition.
+	ErrInvalidMultipleOf
+
+	// ErrInvalidUniqueItems is the error produced by the generated code when an array whose
+	// design definition requires unique items contains duplicate elements.
+	ErrInvalidUniqueItems
+
+	// ErrInvalidPropertyCount is the error produced by the generated code when a hash has less
+	// entries than the minimum number of properties specified in the design definition or more
+	// entries than the maximum.
+	ErrInvalidPropertyCount
 )
 
 // Title returns a human friendly error title
@@ -118,6 +227,24 @@ func (k ErrorID) Title() string {
 		return "invalid value range"
 	case ErrInvalidLength:
 		return "invalid value length"
+	case ErrInvalidEncoding:
+		return "invalid request body encoding"
+	case ErrUnsupportedMediaType:
+		return "unsupported media type"
+	case ErrNotAcceptable:
+		return "not acceptable"
+	case ErrUnauthorized:
+		return "unauthorized"
+	case ErrBodyTooLarge:
+		return "request body too large"
+	case ErrInvalidHeaderType:
+		return "invalid HTTP header value"
+	case ErrInvalidMultipleOf:
+		return "invalid value multiple"
+	case ErrInvalidUniqueItems:
+		return "duplicate items"
+	case ErrInvalidPropertyCount:
+		return "invalid number of properties"
 	}
 	return "unknown error"
 }
@@ -128,10 +255,12 @@ func (t *TypedError) MarshalJSON() ([]byte, error) {
 		ID    int    `json:"id"`
 		Title string `json:"title"`
 		Msg   string `json:"msg"`
+		Field string `json:"field,omitempty"`
 	}{
 		ID:    int(t.ID),
 		Title: t.ID.Title(),
 		Msg:   t.Mesg,
+		Field: t.Field,
 	})
 }
 
@@ -185,6 +314,104 @@ func (b *BadRequestError) Error() string {
 	return b.Actual.Error()
 }
 
+// NewUnauthorizedError wraps the given error into an UnauthorizedError.
+func NewUnauthorizedError(err error) *UnauthorizedError {
+	return &UnauthorizedError{Actual: err}
+}
+
+// Error implements error.
+func (u *UnauthorizedError) Error() string {
+	return u.Actual.Error()
+}
+
+// NewErrorDocument builds the error document for a response with the given status code from the
+// given error. The error may be a TypedError, a MultiError or a BadRequestError or
+// UnauthorizedError wrapping any of these. The document type is "about:blank" so its title is the
+// text of the status code as mandated by RFC 7807. The document code and detail are the ones of
+// the typed error if there is only one. The error details are listed in the document Errors field.
+func NewErrorDocument(status int, err error) *ErrorDocument {
+	doc := &ErrorDocument{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+	}
+	if rerr, ok := err.(*ResponseError); ok {
+		doc.Kind = rerr.Kind
+		err = rerr.Actual
+	}
+	if err == nil {
+		return doc
+	}
+	details := errorDetails(err)
+	if len(details) == 1 {
+		d := details[0]
+		doc.Code = d.Code
+		doc.Detail = d.Detail
+		if d.Field == "" {
+			details = nil
+		}
+	} else if len(details) > 1 {
+		msgs := make([]string, len(details))
+		for i, d := range details {
+			msgs[i] = d.Detail
+		}
+		doc.Detail = strings.Join(msgs, ", ")
+	}
+	doc.Errors = details
+	return doc
+}
+
+// Error returns the document title and detail.
+func (d *ErrorDocument) Error() string {
+	if d.Detail == "" {
+		return d.Title
+	}
+	return d.Title + ": " + d.Detail
+}
+
+// errorDetails flattens the given error into a list of error details.
+func errorDetails(err error) []*ErrorDetail {
+	switch e := err.(type) {
+	case *BadRequestError:
+		return errorDetails(e.Actual)
+	case *UnauthorizedError:
+		return errorDetails(e.Actual)
+	case *ResponseError:
+		if e.Actual == nil {
+			return []*ErrorDetail{{Title: e.Kind, Detail: e.Kind}}
+		}
+		return errorDetails(e.Actual)
+	case MultiError:
+		var details []*ErrorDetail
+		for _, err := range e {
+			details = append(details, errorDetails(err)...)
+		}
+		return details
+	case *TypedError:
+		return []*ErrorDetail{{Code: e.ID, Title: e.ID.Title(), Detail: e.Mesg, Field: e.Field}}
+	case *ErrorDocument:
+		return []*ErrorDetail{{Code: e.Code, Title: e.Title, Detail: e.Detail}}
+	default:
+		return []*ErrorDetail{{Title: "generic", Detail: err.Error()}}
+	}
+}
+
+// Error returns the error kind and the underlying error message if any.
+func (r *ResponseError) Error() string {
+	if r.Actual == nil {
+		return r.Kind
+	}
+	return r.Kind + ": " + r.Actual.Error()
+}
+
+// Send sends the response described by the error.
+func (r *ResponseError) Send(ctx *Context) error {
+	if r.Body != nil {
+		return ctx.Send(r.Status, r.MediaType, r.Body)
+	}
+	return ctx.SendError(r.Status, r)
+}
+
 // InvalidParamTypeError appends a typed error of id ErrInvalidParamType to
 // err and returns it.
 func InvalidParamTypeError(name string, val interface{}, expected string, err error) error {
@@ -192,6 +419,7 @@ func InvalidParamTypeError(name string, val interface{}, expected string, err er
 		ID: ErrInvalidParamType,
 		Mesg: fmt.Sprintf("invalid value %#v for parameter %#v, must be a %s",
 			val, name, expected),
+		Field: name,
 	}
 	return ReportError(err, &terr)
 }
@@ -200,8 +428,9 @@ func InvalidParamTypeError(name string, val interface{}, expected string, err er
 // returns it.
 func MissingParamError(name string, err error) error {
 	terr := TypedError{
-		ID:   ErrMissingParam,
-		Mesg: fmt.Sprintf("missing required parameter %#v", name),
+		ID:    ErrMissingParam,
+		Mesg:  fmt.Sprintf("missing required parameter %#v", name),
+		Field: name,
 	}
 	return ReportError(err, &terr)
 }
@@ -213,6 +442,7 @@ func InvalidAttributeTypeError(ctx string, val interface{}, expected string, err
 		ID: ErrInvalidAttributeType,
 		Mesg: fmt.Sprintf("type of %s must be %s but got value %#v", ctx,
 			expected, val),
+		Field: ctx,
 	}
 	return ReportError(err, &terr)
 }
@@ -221,8 +451,9 @@ func InvalidAttributeTypeError(ctx string, val interface{}, expected string, err
 // err and returns it.
 func MissingAttributeError(ctx, name string, err error) error {
 	terr := TypedError{
-		ID:   ErrMissingAttribute,
-		Mesg: fmt.Sprintf("attribute %#v of %s is missing and required", name, ctx),
+		ID:    ErrMissingAttribute,
+		Mesg:  fmt.Sprintf("attribute %#v of %s is missing and required", name, ctx),
+		Field: ctx + "." + name,
 	}
 	return ReportError(err, &terr)
 }
@@ -231,8 +462,20 @@ func MissingAttributeError(ctx, name string, err error) error {
 // returns it.
 func MissingHeaderError(name string, err error) error {
 	terr := TypedError{
-		ID:   ErrMissingHeader,
-		Mesg: fmt.Sprintf("missing required HTTP header %#v", name),
+		ID:    ErrMissingHeader,
+		Mesg:  fmt.Sprintf("missing required HTTP header %#v", name),
+		Field: name,
+	}
+	return ReportError(err, &terr)
+}
+
+// InvalidHeaderTypeError appends a typed error of id ErrInvalidHeaderType to err and returns it.
+func InvalidHeaderTypeError(name string, val interface{}, expected string, err error) error {
+	terr := TypedError{
+		ID: ErrInvalidHeaderType,
+		Mesg: fmt.Sprintf("invalid value %#v for HTTP header %#v, must be a %s",
+			val, name, expected),
+		Field: name,
 	}
 	return ReportError(err, &terr)
 }
@@ -248,6 +491,7 @@ func InvalidEnumValueError(ctx string, val interface{}, allowed []interface{}, e
 		ID: ErrInvalidEnumValue,
 		Mesg: fmt.Sprintf("value of %s must be one of %s but got value %#v", ctx,
 			strings.Join(elems, ", "), val),
+		Field: ctx,
 	}
 	return ReportError(err, &terr)
 }
@@ -259,6 +503,7 @@ func InvalidFormatError(ctx, target string, format Format, formatError, err erro
 		ID: ErrInvalidFormat,
 		Mesg: fmt.Sprintf("%s must be formatted as a %s but got value %#v, %s",
 			ctx, format, target, formatError.Error()),
+		Field: ctx,
 	}
 	return ReportError(err, &terr)
 }
@@ -270,6 +515,7 @@ func InvalidPatternError(ctx, target string, pattern string, err error) error {
 		ID: ErrInvalidPattern,
 		Mesg: fmt.Sprintf("%s must be match the regexp %#v but got value %#v",
 			ctx, pattern, target),
+		Field: ctx,
 	}
 	return ReportError(err, &terr)
 }
@@ -285,6 +531,61 @@ func InvalidRangeError(ctx string, target interface{}, value int, min bool, err
 		ID: ErrInvalidRange,
 		Mesg: fmt.Sprintf("%s must be %s than %d but got value %#v",
 			ctx, comp, value, target),
+		Field: ctx,
+	}
+	return ReportError(err, &terr)
+}
+
+// InvalidExclusiveRangeError appends a typed error of id ErrInvalidRange to err and returns it.
+// It is produced by exclusive minimum and maximum validations.
+func InvalidExclusiveRangeError(ctx string, target interface{}, value float64, min bool, err error) error {
+	comp := "greater"
+	if !min {
+		comp = "lesser"
+	}
+	terr := TypedError{
+		ID: ErrInvalidRange,
+		Mesg: fmt.Sprintf("%s must be %s than %v but got value %#v",
+			ctx, comp, value, target),
+		Field: ctx,
+	}
+	return ReportError(err, &terr)
+}
+
+// InvalidMultipleOfError appends a typed error of id ErrInvalidMultipleOf to err and returns it.
+func InvalidMultipleOfError(ctx string, target interface{}, value float64, err error) error {
+	terr := TypedError{
+		ID: ErrInvalidMultipleOf,
+		Mesg: fmt.Sprintf("%s must be a multiple of %v but got value %#v",
+			ctx, value, target),
+		Field: ctx,
+	}
+	return ReportError(err, &terr)
+}
+
+// InvalidUniqueItemsError appends a typed error of id ErrInvalidUniqueItems to err and returns
+// it.
+func InvalidUniqueItemsError(ctx string, target interface{}, err error) error {
+	terr := TypedError{
+		ID:    ErrInvalidUniqueItems,
+		Mesg:  fmt.Sprintf("elements of %s must be unique but got value %#v", ctx, target),
+		Field: ctx,
+	}
+	return ReportError(err, &terr)
+}
+
+// InvalidPropertyCountError appends a typed error of id ErrInvalidPropertyCount to err and
+// returns it. count is the number of entries in target.
+func InvalidPropertyCountError(ctx string, target interface{}, count, value int, min bool, err error) error {
+	comp := "greater or equal"
+	if !min {
+		comp = "lesser or equal"
+	}
+	terr := TypedError{
+		ID: ErrInvalidPropertyCount,
+		Mesg: fmt.Sprintf("number of properties of %s must be %s than %d but got value %#v (count=%d)",
+			ctx, comp, value, target, count),
+		Field: ctx,
 	}
 	return ReportError(err, &terr)
 }
@@ -300,6 +601,7 @@ func InvalidLengthError(ctx, target string, value int, min bool, err error) erro
 		ID: ErrInvalidLength,
 		Mesg: fmt.Sprintf("length of %s must be %s than %d but got value %#v (len=%d)",
 			ctx, comp, value, target, len(target)),
+		Field: ctx,
 	}
 	return ReportError(err, &terr)
 }
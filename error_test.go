@@ -11,7 +11,7 @@ import (
 )
 
 // allErrorKinds list all the existing goa.ErrorID values.
-var allErrorKinds = [10]goa.ErrorID{
+var allErrorKinds = [...]goa.ErrorID{
 	goa.ErrInvalidParamType,
 	goa.ErrMissingParam,
 	goa.ErrInvalidAttributeType,
@@ -22,6 +22,15 @@ var allErrorKinds = [10]goa.ErrorID{
 	goa.ErrInvalidPattern,
 	goa.ErrInvalidRange,
 	goa.ErrInvalidLength,
+	goa.ErrInvalidEncoding,
+	goa.ErrUnsupportedMediaType,
+	goa.ErrNotAcceptable,
+	goa.ErrUnauthorized,
+	goa.ErrBodyTooLarge,
+	goa.ErrInvalidHeaderType,
+	goa.ErrInvalidMultipleOf,
+	goa.ErrInvalidUniqueItems,
+	goa.ErrInvalidPropertyCount,
 }
 
 var _ = Describe("ErrorKind", func() {
@@ -145,6 +154,99 @@ var _ = Describe("BadRequestError", func() {
 	})
 })
 
+var _ = Describe("NewErrorDocument", func() {
+	var status int
+	var err error
+	var doc *goa.ErrorDocument
+
+	BeforeEach(func() {
+		status = 400
+		err = nil
+	})
+
+	JustBeforeEach(func() {
+		doc = goa.NewErrorDocument(status, err)
+	})
+
+	It("uses the status text as title", func() {
+		Ω(doc.Type).Should(Equal("about:blank"))
+		Ω(doc.Title).Should(Equal("Bad Request"))
+		Ω(doc.Status).Should(Equal(400))
+		Ω(doc.Code).Should(Equal(goa.ErrorID(0)))
+		Ω(doc.Errors).Should(BeEmpty())
+	})
+
+	Context("with a typed error", func() {
+		BeforeEach(func() {
+			err = goa.NewBadRequestError(goa.MissingParamError("id", nil))
+		})
+
+		It("uses the typed error code and message", func() {
+			Ω(doc.Code).Should(Equal(goa.ErrorID(goa.ErrMissingParam)))
+			Ω(doc.Title).Should(Equal("Bad Request"))
+			Ω(doc.Detail).Should(ContainSubstring("id"))
+			Ω(doc.Errors).Should(HaveLen(1))
+			Ω(doc.Errors[0].Field).Should(Equal("id"))
+		})
+
+		It("serializes to JSON", func() {
+			js, err := json.Marshal(doc)
+			Ω(err).ShouldNot(HaveOccurred())
+			var data map[string]interface{}
+			err = json.Unmarshal(js, &data)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(data["code"]).Should(Equal(float64(goa.ErrMissingParam)))
+			Ω(data["status"]).Should(Equal(float64(400)))
+			Ω(data).ShouldNot(HaveKey("request_id"))
+		})
+	})
+
+	Context("with multiple errors", func() {
+		BeforeEach(func() {
+			err = goa.MissingParamError("id", nil)
+			err = goa.ReportError(err, errors.New("boom"))
+		})
+
+		It("lists all the errors", func() {
+			Ω(doc.Code).Should(Equal(goa.ErrorID(0)))
+			Ω(doc.Title).Should(Equal("Bad Request"))
+			Ω(doc.Errors).Should(HaveLen(2))
+			Ω(doc.Errors[0].Code).Should(Equal(goa.ErrorID(goa.ErrMissingParam)))
+			Ω(doc.Errors[1].Code).Should(Equal(goa.ErrorID(0)))
+			Ω(doc.Errors[1].Title).Should(Equal("generic"))
+			Ω(doc.Errors[1].Detail).Should(Equal("boom"))
+			Ω(doc.Detail).Should(ContainSubstring("boom"))
+		})
+	})
+
+	Context("with a response error", func() {
+		BeforeEach(func() {
+			status = 404
+			err = &goa.ResponseError{Kind: "NotThere", Status: 404, Actual: errors.New("boom")}
+		})
+
+		It("sets the document kind", func() {
+			Ω(doc.Kind).Should(Equal("NotThere"))
+			Ω(doc.Title).Should(Equal("Not Found"))
+			Ω(doc.Detail).Should(Equal("boom"))
+		})
+	})
+
+	Context("with a generic error", func() {
+		BeforeEach(func() {
+			status = 500
+			err = errors.New("boom")
+		})
+
+		It("uses the error message as detail", func() {
+			Ω(doc.Title).Should(Equal("Internal Server Error"))
+			Ω(doc.Detail).Should(Equal("boom"))
+			Ω(doc.Errors).Should(BeEmpty())
+			Ω(doc.Error()).Should(Equal("Internal Server Error: boom"))
+		})
+	})
+})
+
 var _ = Describe("InvalidParamTypeError", func() {
 	var valErr, err error
 	name := "param"
@@ -306,6 +408,7 @@ var _ = Describe("MissingAttributeError", func() {
 		Ω(tErr.ID).Should(Equal(goa.ErrorID((goa.ErrMissingAttribute))))
 		Ω(tErr.Mesg).Should(ContainSubstring(ctx))
 		Ω(tErr.Mesg).Should(ContainSubstring(name))
+		Ω(tErr.Field).Should(Equal(ctx + "." + name))
 	})
 
 	Context("with a pre-existing error", func() {
@@ -370,6 +473,30 @@ var _ = Describe("MissingHeaderError", func() {
 	})
 })
 
+var _ = Describe("InvalidHeaderTypeError", func() {
+	var valErr error
+	name := "X-Count"
+	val := "foo"
+	expected := "integer"
+
+	JustBeforeEach(func() {
+		valErr = goa.InvalidHeaderTypeError(name, val, expected, nil)
+	})
+
+	It("creates a multi error", func() {
+		Ω(valErr).ShouldNot(BeNil())
+		Ω(valErr).Should(BeAssignableToTypeOf(goa.MultiError{}))
+		mErr := valErr.(goa.MultiError)
+		Ω(mErr).Should(HaveLen(1))
+		Ω(mErr[0]).Should(BeAssignableToTypeOf(&goa.TypedError{}))
+		tErr := mErr[0].(*goa.TypedError)
+		Ω(tErr.ID).Should(Equal(goa.ErrorID((goa.ErrInvalidHeaderType))))
+		Ω(tErr.Mesg).Should(ContainSubstring(name))
+		Ω(tErr.Mesg).Should(ContainSubstring(val))
+		Ω(tErr.Mesg).Should(ContainSubstring(expected))
+	})
+})
+
 var _ = Describe("InvalidEnumValueError", func() {
 	var valErr, err error
 	ctx := "ctx"
@@ -619,6 +746,106 @@ var _ = Describe("InvalidLengthError", func() {
 	})
 })
 
+var _ = Describe("InvalidExclusiveRangeError", func() {
+	var valErr error
+	ctx := "ctx"
+	target := 0
+
+	JustBeforeEach(func() {
+		valErr = goa.InvalidExclusiveRangeError(ctx, target, 0, true, nil)
+	})
+
+	It("creates a multi error", func() {
+		Ω(valErr).Should(BeAssignableToTypeOf(goa.MultiError{}))
+		mErr := valErr.(goa.MultiError)
+		Ω(mErr).Should(HaveLen(1))
+		tErr := mErr[0].(*goa.TypedError)
+		Ω(tErr.ID).Should(Equal(goa.ErrorID((goa.ErrInvalidRange))))
+		Ω(tErr.Mesg).Should(ContainSubstring(ctx))
+		Ω(tErr.Mesg).Should(ContainSubstring("must be greater than 0"))
+	})
+})
+
+var _ = Describe("InvalidMultipleOfError", func() {
+	var valErr error
+	ctx := "ctx"
+	target := 7
+
+	JustBeforeEach(func() {
+		valErr = goa.InvalidMultipleOfError(ctx, target, 2.5, nil)
+	})
+
+	It("creates a multi error", func() {
+		Ω(valErr).Should(BeAssignableToTypeOf(goa.MultiError{}))
+		mErr := valErr.(goa.MultiError)
+		Ω(mErr).Should(HaveLen(1))
+		tErr := mErr[0].(*goa.TypedError)
+		Ω(tErr.ID).Should(Equal(goa.ErrorID((goa.ErrInvalidMultipleOf))))
+		Ω(tErr.Mesg).Should(ContainSubstring(ctx))
+		Ω(tErr.Mesg).Should(ContainSubstring("multiple of 2.5"))
+		Ω(tErr.Mesg).Should(ContainSubstring("7"))
+	})
+})
+
+var _ = Describe("InvalidUniqueItemsError", func() {
+	var valErr error
+	ctx := "ctx"
+	target := []string{"a", "a"}
+
+	JustBeforeEach(func() {
+		valErr = goa.InvalidUniqueItemsError(ctx, target, nil)
+	})
+
+	It("creates a multi error", func() {
+		Ω(valErr).Should(BeAssignableToTypeOf(goa.MultiError{}))
+		mErr := valErr.(goa.MultiError)
+		Ω(mErr).Should(HaveLen(1))
+		tErr := mErr[0].(*goa.TypedError)
+		Ω(tErr.ID).Should(Equal(goa.ErrorID((goa.ErrInvalidUniqueItems))))
+		Ω(tErr.Mesg).Should(ContainSubstring(ctx))
+		Ω(tErr.Mesg).Should(ContainSubstring(fmt.Sprintf("%#v", target)))
+	})
+})
+
+var _ = Describe("InvalidPropertyCountError", func() {
+	var valErr, err error
+	ctx := "ctx"
+	target := map[string]int{"a": 1}
+
+	BeforeEach(func() {
+		err = nil
+	})
+
+	JustBeforeEach(func() {
+		valErr = goa.InvalidPropertyCountError(ctx, target, len(target), 2, true, err)
+	})
+
+	It("creates a multi error", func() {
+		Ω(valErr).Should(BeAssignableToTypeOf(goa.MultiError{}))
+		mErr := valErr.(goa.MultiError)
+		Ω(mErr).Should(HaveLen(1))
+		tErr := mErr[0].(*goa.TypedError)
+		Ω(tErr.ID).Should(Equal(goa.ErrorID((goa.ErrInvalidPropertyCount))))
+		Ω(tErr.Mesg).Should(ContainSubstring(ctx))
+		Ω(tErr.Mesg).Should(ContainSubstring("greater or equal than 2"))
+		Ω(tErr.Mesg).Should(ContainSubstring("count=1"))
+	})
+
+	Context("with a pre-existing error", func() {
+		BeforeEach(func() {
+			err = errors.New("pre-existing")
+		})
+
+		It("appends to the multi-error", func() {
+			mErr := valErr.(goa.MultiError)
+			Ω(mErr).Should(HaveLen(2))
+			Ω(mErr[0]).Should(Equal(err))
+			tErr := mErr[1].(*goa.TypedError)
+			Ω(tErr.ID).Should(Equal(goa.ErrorID((goa.ErrInvalidPropertyCount))))
+		})
+	})
+})
+
 var _ = Describe("ReportError", func() {
 	var err, err2 error
 	var mErr error
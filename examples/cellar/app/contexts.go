@@ -13,7 +13,7 @@
 package app
 
 import (
-	"fmt"
+	"encoding/json"
 	"strconv"
 	"strings"
 
@@ -31,9 +31,12 @@ type CreateAccountContext struct {
 func NewCreateAccountContext(c *goa.Context) (*CreateAccountContext, error) {
 	var err error
 	ctx := CreateAccountContext{Context: c}
-	p, err := NewCreateAccountPayload(c.Payload())
-	if err != nil {
-		return nil, err
+	p, ok := c.Payload().(*CreateAccountPayload)
+	if !ok {
+		p, err = NewCreateAccountPayload(c.Payload())
+		if err != nil {
+			return nil, err
+		}
 	}
 	ctx.Payload = p
 	return &ctx, err
@@ -74,6 +77,46 @@ func UnmarshalCreateAccountPayload(source interface{}, inErr error) (target *Cre
 	return
 }
 
+// createAccountPayloadBody is the type the JSON request bodies of the account create action are
+// decoded into, its pointer fields make it possible to tell missing attributes from zero values.
+type createAccountPayloadBody struct {
+	Name *string `json:"name"`
+}
+
+// DecodeCreateAccountPayload decodes a JSON request body directly into a CreateAccountPayload.
+// It validates each field and returns a BadRequestError if any validation fails.
+// Bodies that do not decode into the payload type are coerced by NewCreateAccountPayload instead
+// so that they produce the same errors as generically decoded bodies.
+func DecodeCreateAccountPayload(dec goa.Decoder) (interface{}, error) {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	var body createAccountPayloadBody
+	if err := json.Unmarshal(raw, &body); err != nil {
+		var generic interface{}
+		if err := json.Unmarshal(raw, &generic); err != nil {
+			return nil, err
+		}
+		p, err := NewCreateAccountPayload(generic)
+		if err != nil {
+			return nil, goa.NewBadRequestError(err)
+		}
+		return p, nil
+	}
+	var err error
+	p := new(CreateAccountPayload)
+	if body.Name != nil {
+		p.Name = *body.Name
+	} else {
+		err = goa.MissingAttributeError(`payload`, "name", err)
+	}
+	if err != nil {
+		return nil, goa.NewBadRequestError(err)
+	}
+	return p, nil
+}
+
 // Created sends a HTTP response with status code 201.
 func (ctx *CreateAccountContext) Created() error {
 	return ctx.Respond(201, nil)
@@ -140,12 +183,7 @@ func (ctx *ShowAccountContext) NotFound() error {
 
 // OK sends a HTTP response with status code 200.
 func (ctx *ShowAccountContext) OK(resp *Account, view AccountViewEnum) error {
-	r, err := resp.Dump(view)
-	if err != nil {
-		return fmt.Errorf("invalid response: %s", err)
-	}
-	ctx.Header().Set("Content-Type", "application/vnd.account+json; charset=utf-8")
-	return ctx.JSON(200, r)
+	return ctx.SendMedia(200, "application/vnd.account+json", resp, string(view))
 }
 
 // UpdateAccountContext provides the account update action context.
@@ -168,9 +206,12 @@ func NewUpdateAccountContext(c *goa.Context) (*UpdateAccountContext, error) {
 			err = goa.InvalidParamTypeError("accountID", rawAccountID, "integer", err)
 		}
 	}
-	p, err := NewUpdateAccountPayload(c.Payload())
-	if err != nil {
-		return nil, err
+	p, ok := c.Payload().(*UpdateAccountPayload)
+	if !ok {
+		p, err = NewUpdateAccountPayload(c.Payload())
+		if err != nil {
+			return nil, err
+		}
 	}
 	ctx.Payload = p
 	return &ctx, err
@@ -211,6 +252,46 @@ func UnmarshalUpdateAccountPayload(source interface{}, inErr error) (target *Upd
 	return
 }
 
+// updateAccountPayloadBody is the type the JSON request bodies of the account update action are
+// decoded into, its pointer fields make it possible to tell missing attributes from zero values.
+type updateAccountPayloadBody struct {
+	Name *string `json:"name"`
+}
+
+// DecodeUpdateAccountPayload decodes a JSON request body directly into a UpdateAccountPayload.
+// It validates each field and returns a BadRequestError if any validation fails.
+// Bodies that do not decode into the payload type are coerced by NewUpdateAccountPayload instead
+// so that they produce the same errors as generically decoded bodies.
+func DecodeUpdateAccountPayload(dec goa.Decoder) (interface{}, error) {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	var body updateAccountPayloadBody
+	if err := json.Unmarshal(raw, &body); err != nil {
+		var generic interface{}
+		if err := json.Unmarshal(raw, &generic); err != nil {
+			return nil, err
+		}
+		p, err := NewUpdateAccountPayload(generic)
+		if err != nil {
+			return nil, goa.NewBadRequestError(err)
+		}
+		return p, nil
+	}
+	var err error
+	p := new(UpdateAccountPayload)
+	if body.Name != nil {
+		p.Name = *body.Name
+	} else {
+		err = goa.MissingAttributeError(`payload`, "name", err)
+	}
+	if err != nil {
+		return nil, goa.NewBadRequestError(err)
+	}
+	return p, nil
+}
+
 // NoContent sends a HTTP response with status code 204.
 func (ctx *UpdateAccountContext) NoContent() error {
 	return ctx.Respond(204, nil)
@@ -241,9 +322,12 @@ func NewCreateBottleContext(c *goa.Context) (*CreateBottleContext, error) {
 			err = goa.InvalidParamTypeError("accountID", rawAccountID, "integer", err)
 		}
 	}
-	p, err := NewCreateBottlePayload(c.Payload())
-	if err != nil {
-		return nil, err
+	p, ok := c.Payload().(*CreateBottlePayload)
+	if !ok {
+		p, err = NewCreateBottlePayload(c.Payload())
+		if err != nil {
+			return nil, err
+		}
 	}
 	ctx.Payload = p
 	return &ctx, err
@@ -422,6 +506,121 @@ func UnmarshalCreateBottlePayload(source interface{}, inErr error) (target *Crea
 	return
 }
 
+// createBottlePayloadBody is the type the JSON request bodies of the bottle create action are
+// decoded into, its pointer fields make it possible to tell missing attributes from zero values.
+type createBottlePayloadBody struct {
+	Color     *string `json:"color"`
+	Country   *string `json:"country"`
+	Name      *string `json:"name"`
+	Region    *string `json:"region"`
+	Review    *string `json:"review"`
+	Sweetness *int    `json:"sweetness"`
+	Varietal  *string `json:"varietal"`
+	Vineyard  *string `json:"vineyard"`
+	Vintage   *int    `json:"vintage"`
+}
+
+// DecodeCreateBottlePayload decodes a JSON request body directly into a CreateBottlePayload.
+// It validates each field and returns a BadRequestError if any validation fails.
+// Bodies that do not decode into the payload type are coerced by NewCreateBottlePayload instead
+// so that they produce the same errors as generically decoded bodies.
+func DecodeCreateBottlePayload(dec goa.Decoder) (interface{}, error) {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	var body createBottlePayloadBody
+	if err := json.Unmarshal(raw, &body); err != nil {
+		var generic interface{}
+		if err := json.Unmarshal(raw, &generic); err != nil {
+			return nil, err
+		}
+		p, err := NewCreateBottlePayload(generic)
+		if err != nil {
+			return nil, goa.NewBadRequestError(err)
+		}
+		return p, nil
+	}
+	var err error
+	p := new(CreateBottlePayload)
+	if body.Color != nil {
+		p.Color = *body.Color
+		if p.Color != "" {
+			if !(p.Color == "red" || p.Color == "white" || p.Color == "rose" || p.Color == "yellow" || p.Color == "sparkling") {
+				err = goa.InvalidEnumValueError(`payload.Color`, p.Color, []interface{}{"red", "white", "rose", "yellow", "sparkling"}, err)
+			}
+		}
+	} else {
+		err = goa.MissingAttributeError(`payload`, "color", err)
+	}
+	if body.Country != nil {
+		p.Country = *body.Country
+		if len(p.Country) < 2 {
+			err = goa.InvalidLengthError(`payload.Country`, p.Country, 2, true, err)
+		}
+	}
+	if body.Name != nil {
+		p.Name = *body.Name
+		if len(p.Name) < 2 {
+			err = goa.InvalidLengthError(`payload.Name`, p.Name, 2, true, err)
+		}
+	} else {
+		err = goa.MissingAttributeError(`payload`, "name", err)
+	}
+	if body.Region != nil {
+		p.Region = *body.Region
+	}
+	if body.Review != nil {
+		p.Review = *body.Review
+		if len(p.Review) < 10 {
+			err = goa.InvalidLengthError(`payload.Review`, p.Review, 10, true, err)
+		}
+		if len(p.Review) > 300 {
+			err = goa.InvalidLengthError(`payload.Review`, p.Review, 300, false, err)
+		}
+	}
+	if body.Sweetness != nil {
+		p.Sweetness = *body.Sweetness
+		if p.Sweetness < 1 {
+			err = goa.InvalidRangeError(`payload.Sweetness`, p.Sweetness, 1, true, err)
+		}
+		if p.Sweetness > 5 {
+			err = goa.InvalidRangeError(`payload.Sweetness`, p.Sweetness, 5, false, err)
+		}
+	}
+	if body.Varietal != nil {
+		p.Varietal = *body.Varietal
+		if len(p.Varietal) < 4 {
+			err = goa.InvalidLengthError(`payload.Varietal`, p.Varietal, 4, true, err)
+		}
+	} else {
+		err = goa.MissingAttributeError(`payload`, "varietal", err)
+	}
+	if body.Vineyard != nil {
+		p.Vineyard = *body.Vineyard
+		if len(p.Vineyard) < 2 {
+			err = goa.InvalidLengthError(`payload.Vineyard`, p.Vineyard, 2, true, err)
+		}
+	} else {
+		err = goa.MissingAttributeError(`payload`, "vineyard", err)
+	}
+	if body.Vintage != nil {
+		p.Vintage = *body.Vintage
+		if p.Vintage < 1900 {
+			err = goa.InvalidRangeError(`payload.Vintage`, p.Vintage, 1900, true, err)
+		}
+		if p.Vintage > 2020 {
+			err = goa.InvalidRangeError(`payload.Vintage`, p.Vintage, 2020, false, err)
+		}
+	} else {
+		err = goa.MissingAttributeError(`payload`, "vintage", err)
+	}
+	if err != nil {
+		return nil, goa.NewBadRequestError(err)
+	}
+	return p, nil
+}
+
 // Created sends a HTTP response with status code 201.
 func (ctx *CreateBottleContext) Created() error {
 	return ctx.Respond(201, nil)
@@ -514,12 +713,13 @@ func (ctx *ListBottleContext) NotFound() error {
 
 // OK sends a HTTP response with status code 200.
 func (ctx *ListBottleContext) OK(resp BottleCollection, view BottleCollectionViewEnum) error {
-	r, err := resp.Dump(view)
-	if err != nil {
-		return fmt.Errorf("invalid response: %s", err)
-	}
-	ctx.Header().Set("Content-Type", "application/vnd.bottle+json; type=collection; charset=utf-8")
-	return ctx.JSON(200, r)
+	return ctx.SendMedia(200, "application/vnd.bottle+json; type=collection", resp, string(view))
+}
+
+// OKStream sends a HTTP response with status code 200, it writes the
+// elements of the collection to the response body as they get rendered.
+func (ctx *ListBottleContext) OKStream(resp BottleCollection, view BottleCollectionViewEnum) error {
+	return ctx.StreamMedia(200, "application/vnd.bottle+json; type=collection", resp, string(view))
 }
 
 // RateBottleContext provides the bottle rate action context.
@@ -551,9 +751,12 @@ func NewRateBottleContext(c *goa.Context) (*RateBottleContext, error) {
 			err = goa.InvalidParamTypeError("bottleID", rawBottleID, "integer", err)
 		}
 	}
-	p, err := NewRateBottlePayload(c.Payload())
-	if err != nil {
-		return nil, err
+	p, ok := c.Payload().(*RateBottlePayload)
+	if !ok {
+		p, err = NewRateBottlePayload(c.Payload())
+		if err != nil {
+			return nil, err
+		}
 	}
 	ctx.Payload = p
 	return &ctx, err
@@ -602,6 +805,52 @@ func UnmarshalRateBottlePayload(source interface{}, inErr error) (target *RateBo
 	return
 }
 
+// rateBottlePayloadBody is the type the JSON request bodies of the bottle rate action are
+// decoded into, its pointer fields make it possible to tell missing attributes from zero values.
+type rateBottlePayloadBody struct {
+	Rating *int `json:"rating"`
+}
+
+// DecodeRateBottlePayload decodes a JSON request body directly into a RateBottlePayload.
+// It validates each field and returns a BadRequestError if any validation fails.
+// Bodies that do not decode into the payload type are coerced by NewRateBottlePayload instead
+// so that they produce the same errors as generically decoded bodies.
+func DecodeRateBottlePayload(dec goa.Decoder) (interface{}, error) {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	var body rateBottlePayloadBody
+	if err := json.Unmarshal(raw, &body); err != nil {
+		var generic interface{}
+		if err := json.Unmarshal(raw, &generic); err != nil {
+			return nil, err
+		}
+		p, err := NewRateBottlePayload(generic)
+		if err != nil {
+			return nil, goa.NewBadRequestError(err)
+		}
+		return p, nil
+	}
+	var err error
+	p := new(RateBottlePayload)
+	if body.Rating != nil {
+		p.Rating = *body.Rating
+		if p.Rating < 1 {
+			err = goa.InvalidRangeError(`payload.Rating`, p.Rating, 1, true, err)
+		}
+		if p.Rating > 5 {
+			err = goa.InvalidRangeError(`payload.Rating`, p.Rating, 5, false, err)
+		}
+	} else {
+		err = goa.MissingAttributeError(`payload`, "rating", err)
+	}
+	if err != nil {
+		return nil, goa.NewBadRequestError(err)
+	}
+	return p, nil
+}
+
 // NoContent sends a HTTP response with status code 204.
 func (ctx *RateBottleContext) NoContent() error {
 	return ctx.Respond(204, nil)
@@ -650,12 +899,7 @@ func (ctx *ShowBottleContext) NotFound() error {
 
 // OK sends a HTTP response with status code 200.
 func (ctx *ShowBottleContext) OK(resp *Bottle, view BottleViewEnum) error {
-	r, err := resp.Dump(view)
-	if err != nil {
-		return fmt.Errorf("invalid response: %s", err)
-	}
-	ctx.Header().Set("Content-Type", "application/vnd.bottle+json; charset=utf-8")
-	return ctx.JSON(200, r)
+	return ctx.SendMedia(200, "application/vnd.bottle+json", resp, string(view))
 }
 
 // UpdateBottleContext provides the bottle update action context.
@@ -687,9 +931,12 @@ func NewUpdateBottleContext(c *goa.Context) (*UpdateBottleContext, error) {
 			err = goa.InvalidParamTypeError("bottleID", rawBottleID, "integer", err)
 		}
 	}
-	p, err := NewUpdateBottlePayload(c.Payload())
-	if err != nil {
-		return nil, err
+	p, ok := c.Payload().(*UpdateBottlePayload)
+	if !ok {
+		p, err = NewUpdateBottlePayload(c.Payload())
+		if err != nil {
+			return nil, err
+		}
 	}
 	ctx.Payload = p
 	return &ctx, err
@@ -858,6 +1105,111 @@ func UnmarshalUpdateBottlePayload(source interface{}, inErr error) (target *Upda
 	return
 }
 
+// updateBottlePayloadBody is the type the JSON request bodies of the bottle update action are
+// decoded into, its pointer fields make it possible to tell missing attributes from zero values.
+type updateBottlePayloadBody struct {
+	Color     *string `json:"color"`
+	Country   *string `json:"country"`
+	Name      *string `json:"name"`
+	Region    *string `json:"region"`
+	Review    *string `json:"review"`
+	Sweetness *int    `json:"sweetness"`
+	Varietal  *string `json:"varietal"`
+	Vineyard  *string `json:"vineyard"`
+	Vintage   *int    `json:"vintage"`
+}
+
+// DecodeUpdateBottlePayload decodes a JSON request body directly into a UpdateBottlePayload.
+// It validates each field and returns a BadRequestError if any validation fails.
+// Bodies that do not decode into the payload type are coerced by NewUpdateBottlePayload instead
+// so that they produce the same errors as generically decoded bodies.
+func DecodeUpdateBottlePayload(dec goa.Decoder) (interface{}, error) {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	var body updateBottlePayloadBody
+	if err := json.Unmarshal(raw, &body); err != nil {
+		var generic interface{}
+		if err := json.Unmarshal(raw, &generic); err != nil {
+			return nil, err
+		}
+		p, err := NewUpdateBottlePayload(generic)
+		if err != nil {
+			return nil, goa.NewBadRequestError(err)
+		}
+		return p, nil
+	}
+	var err error
+	p := new(UpdateBottlePayload)
+	if body.Color != nil {
+		p.Color = *body.Color
+		if p.Color != "" {
+			if !(p.Color == "red" || p.Color == "white" || p.Color == "rose" || p.Color == "yellow" || p.Color == "sparkling") {
+				err = goa.InvalidEnumValueError(`payload.Color`, p.Color, []interface{}{"red", "white", "rose", "yellow", "sparkling"}, err)
+			}
+		}
+	}
+	if body.Country != nil {
+		p.Country = *body.Country
+		if len(p.Country) < 2 {
+			err = goa.InvalidLengthError(`payload.Country`, p.Country, 2, true, err)
+		}
+	}
+	if body.Name != nil {
+		p.Name = *body.Name
+		if len(p.Name) < 2 {
+			err = goa.InvalidLengthError(`payload.Name`, p.Name, 2, true, err)
+		}
+	}
+	if body.Region != nil {
+		p.Region = *body.Region
+	}
+	if body.Review != nil {
+		p.Review = *body.Review
+		if len(p.Review) < 10 {
+			err = goa.InvalidLengthError(`payload.Review`, p.Review, 10, true, err)
+		}
+		if len(p.Review) > 300 {
+			err = goa.InvalidLengthError(`payload.Review`, p.Review, 300, false, err)
+		}
+	}
+	if body.Sweetness != nil {
+		p.Sweetness = *body.Sweetness
+		if p.Sweetness < 1 {
+			err = goa.InvalidRangeError(`payload.Sweetness`, p.Sweetness, 1, true, err)
+		}
+		if p.Sweetness > 5 {
+			err = goa.InvalidRangeError(`payload.Sweetness`, p.Sweetness, 5, false, err)
+		}
+	}
+	if body.Varietal != nil {
+		p.Varietal = *body.Varietal
+		if len(p.Varietal) < 4 {
+			err = goa.InvalidLengthError(`payload.Varietal`, p.Varietal, 4, true, err)
+		}
+	}
+	if body.Vineyard != nil {
+		p.Vineyard = *body.Vineyard
+		if len(p.Vineyard) < 2 {
+			err = goa.InvalidLengthError(`payload.Vineyard`, p.Vineyard, 2, true, err)
+		}
+	}
+	if body.Vintage != nil {
+		p.Vintage = *body.Vintage
+		if p.Vintage < 1900 {
+			err = goa.InvalidRangeError(`payload.Vintage`, p.Vintage, 1900, true, err)
+		}
+		if p.Vintage > 2020 {
+			err = goa.InvalidRangeError(`payload.Vintage`, p.Vintage, 2020, false, err)
+		}
+	}
+	if err != nil {
+		return nil, goa.NewBadRequestError(err)
+	}
+	return p, nil
+}
+
 // NoContent sends a HTTP response with status code 204.
 func (ctx *UpdateBottleContext) NoContent() error {
 	return ctx.Respond(204, nil)
@@ -37,6 +37,7 @@ func MountAccountController(service goa.Service, ctrl AccountController) {
 		}
 		return ctrl.Create(ctx)
 	}
+	ctrl.SetDecodeFunc("Create", DecodeCreateAccountPayload)
 	router.Handle("POST", "/cellar/accounts", ctrl.NewHTTPRouterHandle("Create", h))
 	service.Info("mount", "ctrl", "Account", "action", "Create", "route", "POST /cellar/accounts")
 	h = func(c *goa.Context) error {
@@ -64,6 +65,7 @@ func MountAccountController(service goa.Service, ctrl AccountController) {
 		}
 		return ctrl.Update(ctx)
 	}
+	ctrl.SetDecodeFunc("Update", DecodeUpdateAccountPayload)
 	router.Handle("PUT", "/cellar/accounts/:accountID", ctrl.NewHTTPRouterHandle("Update", h))
 	service.Info("mount", "ctrl", "Account", "action", "Update", "route", "PUT /cellar/accounts/:accountID")
 }
@@ -90,6 +92,7 @@ func MountBottleController(service goa.Service, ctrl BottleController) {
 		}
 		return ctrl.Create(ctx)
 	}
+	ctrl.SetDecodeFunc("Create", DecodeCreateBottlePayload)
 	router.Handle("POST", "/cellar/accounts/:accountID/bottles", ctrl.NewHTTPRouterHandle("Create", h))
 	service.Info("mount", "ctrl", "Bottle", "action", "Create", "route", "POST /cellar/accounts/:accountID/bottles")
 	h = func(c *goa.Context) error {
@@ -117,6 +120,7 @@ func MountBottleController(service goa.Service, ctrl BottleController) {
 		}
 		return ctrl.Rate(ctx)
 	}
+	ctrl.SetDecodeFunc("Rate", DecodeRateBottlePayload)
 	router.Handle("PUT", "/cellar/accounts/:accountID/bottles/:bottleID/actions/rate", ctrl.NewHTTPRouterHandle("Rate", h))
 	service.Info("mount", "ctrl", "Bottle", "action", "Rate", "route", "PUT /cellar/accounts/:accountID/bottles/:bottleID/actions/rate")
 	h = func(c *goa.Context) error {
@@ -135,6 +139,7 @@ func MountBottleController(service goa.Service, ctrl BottleController) {
 		}
 		return ctrl.Update(ctx)
 	}
+	ctrl.SetDecodeFunc("Update", DecodeUpdateBottlePayload)
 	router.Handle("PATCH", "/cellar/accounts/:accountID/bottles/:bottleID", ctrl.NewHTTPRouterHandle("Update", h))
 	service.Info("mount", "ctrl", "Bottle", "action", "Update", "route", "PATCH /cellar/accounts/:accountID/bottles/:bottleID")
 }
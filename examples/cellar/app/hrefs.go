@@ -12,14 +12,184 @@
 
 package app
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"github.com/raphael/goa"
+)
 
 // AccountHref returns the resource href.
 func AccountHref(accountID interface{}) string {
 	return fmt.Sprintf("/cellar/accounts/%v", accountID)
 }
 
+// CreateAccountPath returns the URL path to the account create action (POST).
+func CreateAccountPath() string {
+	return "/cellar/accounts"
+}
+
+// DeleteAccountPath returns the URL path to the account delete action (DELETE).
+func DeleteAccountPath(accountID int) string {
+	param0 := goa.EscapePathSegment(strconv.Itoa(accountID))
+	return fmt.Sprintf("/cellar/accounts/%s", param0)
+}
+
+// ShowAccountPath returns the URL path to the account show action (GET).
+func ShowAccountPath(accountID int) string {
+	param0 := goa.EscapePathSegment(strconv.Itoa(accountID))
+	return fmt.Sprintf("/cellar/accounts/%s", param0)
+}
+
+// UpdateAccountPath returns the URL path to the account update action (PUT).
+func UpdateAccountPath(accountID int) string {
+	param0 := goa.EscapePathSegment(strconv.Itoa(accountID))
+	return fmt.Sprintf("/cellar/accounts/%s", param0)
+}
+
 // BottleHref returns the resource href.
 func BottleHref(accountID, bottleID interface{}) string {
 	return fmt.Sprintf("/cellar/accounts/%v/bottles/%v", accountID, bottleID)
 }
+
+// CreateBottlePath returns the URL path to the bottle create action (POST).
+func CreateBottlePath(accountID int) string {
+	param0 := goa.EscapePathSegment(strconv.Itoa(accountID))
+	return fmt.Sprintf("/cellar/accounts/%s/bottles", param0)
+}
+
+// DeleteBottlePath returns the URL path to the bottle delete action (DELETE).
+func DeleteBottlePath(accountID int, bottleID int) string {
+	param0 := goa.EscapePathSegment(strconv.Itoa(accountID))
+	param1 := goa.EscapePathSegment(strconv.Itoa(bottleID))
+	return fmt.Sprintf("/cellar/accounts/%s/bottles/%s", param0, param1)
+}
+
+// ListBottlePath returns the URL path to the bottle list action (GET).
+func ListBottlePath(accountID int) string {
+	param0 := goa.EscapePathSegment(strconv.Itoa(accountID))
+	return fmt.Sprintf("/cellar/accounts/%s/bottles", param0)
+}
+
+// RateBottlePath returns the URL path to the bottle rate action (PUT).
+func RateBottlePath(accountID int, bottleID int) string {
+	param0 := goa.EscapePathSegment(strconv.Itoa(accountID))
+	param1 := goa.EscapePathSegment(strconv.Itoa(bottleID))
+	return fmt.Sprintf("/cellar/accounts/%s/bottles/%s/actions/rate", param0, param1)
+}
+
+// ShowBottlePath returns the URL path to the bottle show action (GET).
+func ShowBottlePath(accountID int, bottleID int) string {
+	param0 := goa.EscapePathSegment(strconv.Itoa(accountID))
+	param1 := goa.EscapePathSegment(strconv.Itoa(bottleID))
+	return fmt.Sprintf("/cellar/accounts/%s/bottles/%s", param0, param1)
+}
+
+// UpdateBottlePath returns the URL path to the bottle update action (PATCH).
+func UpdateBottlePath(accountID int, bottleID int) string {
+	param0 := goa.EscapePathSegment(strconv.Itoa(accountID))
+	param1 := goa.EscapePathSegment(strconv.Itoa(bottleID))
+	return fmt.Sprintf("/cellar/accounts/%s/bottles/%s", param0, param1)
+}
+
+// Href describes the resource action identified by an href, see ParseHref.
+type Href struct {
+	// Resource is the name of the resource.
+	Resource string
+	// Action is the name of the action.
+	Action string
+	// Params maps the names of the action path parameters to their values.
+	Params map[string]interface{}
+}
+
+// hrefRoutes lists the action routes ParseHref matches hrefs against, most specific routes first.
+var hrefRoutes = []struct {
+	resource string
+	action   string
+	pattern  *regexp.Regexp
+	parse    func([]string) (map[string]interface{}, error)
+}{
+	{"bottle", "show", regexp.MustCompile(`^/cellar/accounts/([^/]+)/bottles/([^/]+)/?$`), parseShowBottlePath},
+	{"bottle", "list", regexp.MustCompile(`^/cellar/accounts/([^/]+)/bottles/?$`), parseListBottlePath},
+	{"account", "show", regexp.MustCompile(`^/cellar/accounts/([^/]+)/?$`), parseShowAccountPath},
+}
+
+// ParseHref returns the resource action identified by the given href together with the values of
+// the action path parameters coerced to their design types. ParseHref only considers the action
+// routes that use the GET method.
+func ParseHref(href string) (*Href, error) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return nil, err
+	}
+	path := u.EscapedPath()
+	for _, r := range hrefRoutes {
+		if m := r.pattern.FindStringSubmatch(path); m != nil {
+			params, err := r.parse(m[1:])
+			if err != nil {
+				return nil, err
+			}
+			return &Href{Resource: r.resource, Action: r.action, Params: params}, nil
+		}
+	}
+	return nil, fmt.Errorf("no action matches href %#v", href)
+}
+
+// parseShowBottlePath coerces the values of the bottle show action path parameters.
+func parseShowBottlePath(raw []string) (map[string]interface{}, error) {
+	var err error
+	hrefParams := make(map[string]interface{}, 2)
+	rawAccountID, err2 := goa.UnescapePathSegment(raw[0])
+	if err2 != nil {
+		return nil, err2
+	}
+	if accountID, err2 := strconv.Atoi(rawAccountID); err2 == nil {
+		hrefParams["accountID"] = int(accountID)
+	} else {
+		err = goa.InvalidParamTypeError("accountID", rawAccountID, "integer", err)
+	}
+	rawBottleID, err2 := goa.UnescapePathSegment(raw[1])
+	if err2 != nil {
+		return nil, err2
+	}
+	if bottleID, err2 := strconv.Atoi(rawBottleID); err2 == nil {
+		hrefParams["bottleID"] = int(bottleID)
+	} else {
+		err = goa.InvalidParamTypeError("bottleID", rawBottleID, "integer", err)
+	}
+	return hrefParams, err
+}
+
+// parseListBottlePath coerces the values of the bottle list action path parameters.
+func parseListBottlePath(raw []string) (map[string]interface{}, error) {
+	var err error
+	hrefParams := make(map[string]interface{}, 1)
+	rawAccountID, err2 := goa.UnescapePathSegment(raw[0])
+	if err2 != nil {
+		return nil, err2
+	}
+	if accountID, err2 := strconv.Atoi(rawAccountID); err2 == nil {
+		hrefParams["accountID"] = int(accountID)
+	} else {
+		err = goa.InvalidParamTypeError("accountID", rawAccountID, "integer", err)
+	}
+	return hrefParams, err
+}
+
+// parseShowAccountPath coerces the values of the account show action path parameters.
+func parseShowAccountPath(raw []string) (map[string]interface{}, error) {
+	var err error
+	hrefParams := make(map[string]interface{}, 1)
+	rawAccountID, err2 := goa.UnescapePathSegment(raw[0])
+	if err2 != nil {
+		return nil, err2
+	}
+	if accountID, err2 := strconv.Atoi(rawAccountID); err2 == nil {
+		hrefParams["accountID"] = int(accountID)
+	} else {
+		err = goa.InvalidParamTypeError("accountID", rawAccountID, "integer", err)
+	}
+	return hrefParams, err
+}
@@ -12,7 +12,11 @@
 
 package app
 
-import "github.com/raphael/goa"
+import (
+	"fmt"
+
+	"github.com/raphael/goa"
+)
 
 // A tenant account
 // Identifier: application/vnd.account+json
@@ -63,29 +67,50 @@ func (mt *Account) Dump(view AccountViewEnum) (res map[string]interface{}, err e
 // Validate validates the media type instance.
 func (mt *Account) Validate() (err error) {
 	if mt.CreatedAt != "" {
-		if err2 := goa.ValidateFormat(goa.FormatDateTime, mt.CreatedAt); err2 != nil {
+		if err2 := goa.ValidateDateTime(mt.CreatedAt); err2 != nil {
 			err = goa.InvalidFormatError(`response.created_at`, mt.CreatedAt, goa.FormatDateTime, err2, err)
 		}
 	}
 	if mt.CreatedBy != "" {
-		if err2 := goa.ValidateFormat(goa.FormatEmail, mt.CreatedBy); err2 != nil {
+		if err2 := goa.ValidateEmail(mt.CreatedBy); err2 != nil {
 			err = goa.InvalidFormatError(`response.created_by`, mt.CreatedBy, goa.FormatEmail, err2, err)
 		}
 	}
 	return
 }
 
+// DumpView produces raw data from an instance of Account using the view with the given name.
+// It implements goa.MediaTypeRenderer.
+func (mt *Account) DumpView(view string) (interface{}, error) {
+	res, err := mt.Dump(AccountViewEnum(view))
+	return res, err
+}
+
+// WriteJSON validates the media type instance and writes its JSON representation using the view
+// with the given name. It implements goa.MediaTypeRenderer.
+func (mt *Account) WriteJSON(w *goa.JSONWriter, view string) (err error) {
+	switch view {
+	case "default":
+		err = WriteAccountJSON(w, mt, err)
+	case "link":
+		err = WriteAccountLinkJSON(w, mt, err)
+	default:
+		err = fmt.Errorf("unknown view %#v", view)
+	}
+	return
+}
+
 // MarshalAccount validates and renders an instance of Account into a interface{}
 // using view "default".
 func MarshalAccount(source *Account, inErr error) (target map[string]interface{}, err error) {
 	err = inErr
 	if source.CreatedAt != "" {
-		if err2 := goa.ValidateFormat(goa.FormatDateTime, source.CreatedAt); err2 != nil {
+		if err2 := goa.ValidateDateTime(source.CreatedAt); err2 != nil {
 			err = goa.InvalidFormatError(`.created_at`, source.CreatedAt, goa.FormatDateTime, err2, err)
 		}
 	}
 	if source.CreatedBy != "" {
-		if err2 := goa.ValidateFormat(goa.FormatEmail, source.CreatedBy); err2 != nil {
+		if err2 := goa.ValidateEmail(source.CreatedBy); err2 != nil {
 			err = goa.InvalidFormatError(`.created_by`, source.CreatedBy, goa.FormatEmail, err2, err)
 		}
 	}
@@ -100,6 +125,35 @@ func MarshalAccount(source *Account, inErr error) (target map[string]interface{}
 	return
 }
 
+// WriteAccountJSON validates an instance of Account and writes its JSON representation
+// using view "default".
+func WriteAccountJSON(w *goa.JSONWriter, source *Account, inErr error) (err error) {
+	err = inErr
+	if source.CreatedAt != "" {
+		if err2 := goa.ValidateDateTime(source.CreatedAt); err2 != nil {
+			err = goa.InvalidFormatError(`.created_at`, source.CreatedAt, goa.FormatDateTime, err2, err)
+		}
+	}
+	if source.CreatedBy != "" {
+		if err2 := goa.ValidateEmail(source.CreatedBy); err2 != nil {
+			err = goa.InvalidFormatError(`.created_by`, source.CreatedBy, goa.FormatEmail, err2, err)
+		}
+	}
+	w.ObjectStart()
+	w.Key("created_at")
+	w.String(source.CreatedAt)
+	w.Key("created_by")
+	w.String(source.CreatedBy)
+	w.Key("href")
+	w.String(source.Href)
+	w.Key("id")
+	w.Int(source.ID)
+	w.Key("name")
+	w.String(source.Name)
+	w.ObjectEnd()
+	return
+}
+
 // MarshalAccountLink validates and renders an instance of Account into a interface{}
 // using view "link".
 func MarshalAccountLink(source *Account, inErr error) (target map[string]interface{}, err error) {
@@ -113,6 +167,21 @@ func MarshalAccountLink(source *Account, inErr error) (target map[string]interfa
 	return
 }
 
+// WriteAccountLinkJSON validates an instance of Account and writes its JSON representation
+// using view "link".
+func WriteAccountLinkJSON(w *goa.JSONWriter, source *Account, inErr error) (err error) {
+	err = inErr
+	w.ObjectStart()
+	w.Key("href")
+	w.String(source.Href)
+	w.Key("id")
+	w.Int(source.ID)
+	w.Key("name")
+	w.String(source.Name)
+	w.ObjectEnd()
+	return
+}
+
 // UnmarshalAccount unmarshals and validates a raw interface{} into an instance of Account
 func UnmarshalAccount(source interface{}, inErr error) (target *Account, err error) {
 	err = inErr
@@ -127,7 +196,7 @@ func UnmarshalAccount(source interface{}, inErr error) (target *Account, err err
 			}
 			if err == nil {
 				if tmp24 != "" {
-					if err2 := goa.ValidateFormat(goa.FormatDateTime, tmp24); err2 != nil {
+					if err2 := goa.ValidateDateTime(tmp24); err2 != nil {
 						err = goa.InvalidFormatError(`load.CreatedAt`, tmp24, goa.FormatDateTime, err2, err)
 					}
 				}
@@ -143,7 +212,7 @@ func UnmarshalAccount(source interface{}, inErr error) (target *Account, err err
 			}
 			if err == nil {
 				if tmp25 != "" {
-					if err2 := goa.ValidateFormat(goa.FormatEmail, tmp25); err2 != nil {
+					if err2 := goa.ValidateEmail(tmp25); err2 != nil {
 						err = goa.InvalidFormatError(`load.CreatedBy`, tmp25, goa.FormatEmail, err2, err)
 					}
 				}
@@ -248,12 +317,12 @@ func (mt *Bottle) Dump(view BottleViewEnum) (res map[string]interface{}, err err
 // Validate validates the media type instance.
 func (mt *Bottle) Validate() (err error) {
 	if mt.Account.CreatedAt != "" {
-		if err2 := goa.ValidateFormat(goa.FormatDateTime, mt.Account.CreatedAt); err2 != nil {
+		if err2 := goa.ValidateDateTime(mt.Account.CreatedAt); err2 != nil {
 			err = goa.InvalidFormatError(`response.account.created_at`, mt.Account.CreatedAt, goa.FormatDateTime, err2, err)
 		}
 	}
 	if mt.Account.CreatedBy != "" {
-		if err2 := goa.ValidateFormat(goa.FormatEmail, mt.Account.CreatedBy); err2 != nil {
+		if err2 := goa.ValidateEmail(mt.Account.CreatedBy); err2 != nil {
 			err = goa.InvalidFormatError(`response.account.created_by`, mt.Account.CreatedBy, goa.FormatEmail, err2, err)
 		}
 	}
@@ -266,7 +335,7 @@ func (mt *Bottle) Validate() (err error) {
 		err = goa.InvalidLengthError(`response.country`, mt.Country, 2, true, err)
 	}
 	if mt.CreatedAt != "" {
-		if err2 := goa.ValidateFormat(goa.FormatDateTime, mt.CreatedAt); err2 != nil {
+		if err2 := goa.ValidateDateTime(mt.CreatedAt); err2 != nil {
 			err = goa.InvalidFormatError(`response.created_at`, mt.CreatedAt, goa.FormatDateTime, err2, err)
 		}
 	}
@@ -292,7 +361,7 @@ func (mt *Bottle) Validate() (err error) {
 		err = goa.InvalidRangeError(`response.sweetness`, mt.Sweetness, 5, false, err)
 	}
 	if mt.UpdatedAt != "" {
-		if err2 := goa.ValidateFormat(goa.FormatDateTime, mt.UpdatedAt); err2 != nil {
+		if err2 := goa.ValidateDateTime(mt.UpdatedAt); err2 != nil {
 			err = goa.InvalidFormatError(`response.updated_at`, mt.UpdatedAt, goa.FormatDateTime, err2, err)
 		}
 	}
@@ -311,6 +380,29 @@ func (mt *Bottle) Validate() (err error) {
 	return
 }
 
+// DumpView produces raw data from an instance of Bottle using the view with the given name.
+// It implements goa.MediaTypeRenderer.
+func (mt *Bottle) DumpView(view string) (interface{}, error) {
+	res, err := mt.Dump(BottleViewEnum(view))
+	return res, err
+}
+
+// WriteJSON validates the media type instance and writes its JSON representation using the view
+// with the given name. It implements goa.MediaTypeRenderer.
+func (mt *Bottle) WriteJSON(w *goa.JSONWriter, view string) (err error) {
+	switch view {
+	case "default":
+		err = WriteBottleJSON(w, mt, err)
+	case "full":
+		err = WriteBottleFullJSON(w, mt, err)
+	case "tiny":
+		err = WriteBottleTinyJSON(w, mt, err)
+	default:
+		err = fmt.Errorf("unknown view %#v", view)
+	}
+	return
+}
+
 // MarshalBottle validates and renders an instance of Bottle into a interface{}
 // using view "default".
 func MarshalBottle(source *Bottle, inErr error) (target map[string]interface{}, err error) {
@@ -354,6 +446,57 @@ func MarshalBottle(source *Bottle, inErr error) (target map[string]interface{},
 	return
 }
 
+// WriteBottleJSON validates an instance of Bottle and writes its JSON representation
+// using view "default".
+func WriteBottleJSON(w *goa.JSONWriter, source *Bottle, inErr error) (err error) {
+	err = inErr
+	if len(source.Name) < 2 {
+		err = goa.InvalidLengthError(`.name`, source.Name, 2, true, err)
+	}
+	if source.Rating < 1 {
+		err = goa.InvalidRangeError(`.rating`, source.Rating, 1, true, err)
+	}
+	if source.Rating > 5 {
+		err = goa.InvalidRangeError(`.rating`, source.Rating, 5, false, err)
+	}
+	if len(source.Varietal) < 4 {
+		err = goa.InvalidLengthError(`.varietal`, source.Varietal, 4, true, err)
+	}
+	if len(source.Vineyard) < 2 {
+		err = goa.InvalidLengthError(`.vineyard`, source.Vineyard, 2, true, err)
+	}
+	if source.Vintage < 1900 {
+		err = goa.InvalidRangeError(`.vintage`, source.Vintage, 1900, true, err)
+	}
+	if source.Vintage > 2020 {
+		err = goa.InvalidRangeError(`.vintage`, source.Vintage, 2020, false, err)
+	}
+	w.ObjectStart()
+	w.Key("href")
+	w.String(source.Href)
+	w.Key("id")
+	w.Int(source.ID)
+	w.Key("links")
+	w.ObjectStart()
+	if source.Account != nil {
+		w.Key("account")
+		err = WriteAccountLinkJSON(w, source.Account, err)
+	}
+	w.ObjectEnd()
+	w.Key("name")
+	w.String(source.Name)
+	w.Key("rating")
+	w.Int(source.Rating)
+	w.Key("varietal")
+	w.String(source.Varietal)
+	w.Key("vineyard")
+	w.String(source.Vineyard)
+	w.Key("vintage")
+	w.Int(source.Vintage)
+	w.ObjectEnd()
+	return
+}
+
 // MarshalBottleFull validates and renders an instance of Bottle into a interface{}
 // using view "full".
 func MarshalBottleFull(source *Bottle, inErr error) (target map[string]interface{}, err error) {
@@ -367,7 +510,7 @@ func MarshalBottleFull(source *Bottle, inErr error) (target map[string]interface
 		err = goa.InvalidLengthError(`.country`, source.Country, 2, true, err)
 	}
 	if source.CreatedAt != "" {
-		if err2 := goa.ValidateFormat(goa.FormatDateTime, source.CreatedAt); err2 != nil {
+		if err2 := goa.ValidateDateTime(source.CreatedAt); err2 != nil {
 			err = goa.InvalidFormatError(`.created_at`, source.CreatedAt, goa.FormatDateTime, err2, err)
 		}
 	}
@@ -393,7 +536,7 @@ func MarshalBottleFull(source *Bottle, inErr error) (target map[string]interface
 		err = goa.InvalidRangeError(`.sweetness`, source.Sweetness, 5, false, err)
 	}
 	if source.UpdatedAt != "" {
-		if err2 := goa.ValidateFormat(goa.FormatDateTime, source.UpdatedAt); err2 != nil {
+		if err2 := goa.ValidateDateTime(source.UpdatedAt); err2 != nil {
 			err = goa.InvalidFormatError(`.updated_at`, source.UpdatedAt, goa.FormatDateTime, err2, err)
 		}
 	}
@@ -437,6 +580,105 @@ func MarshalBottleFull(source *Bottle, inErr error) (target map[string]interface
 	return
 }
 
+// WriteBottleFullJSON validates an instance of Bottle and writes its JSON representation
+// using view "full".
+func WriteBottleFullJSON(w *goa.JSONWriter, source *Bottle, inErr error) (err error) {
+	err = inErr
+	if source.Color != "" {
+		if !(source.Color == "red" || source.Color == "white" || source.Color == "rose" || source.Color == "yellow" || source.Color == "sparkling") {
+			err = goa.InvalidEnumValueError(`.color`, source.Color, []interface{}{"red", "white", "rose", "yellow", "sparkling"}, err)
+		}
+	}
+	if len(source.Country) < 2 {
+		err = goa.InvalidLengthError(`.country`, source.Country, 2, true, err)
+	}
+	if source.CreatedAt != "" {
+		if err2 := goa.ValidateDateTime(source.CreatedAt); err2 != nil {
+			err = goa.InvalidFormatError(`.created_at`, source.CreatedAt, goa.FormatDateTime, err2, err)
+		}
+	}
+	if len(source.Name) < 2 {
+		err = goa.InvalidLengthError(`.name`, source.Name, 2, true, err)
+	}
+	if source.Rating < 1 {
+		err = goa.InvalidRangeError(`.rating`, source.Rating, 1, true, err)
+	}
+	if source.Rating > 5 {
+		err = goa.InvalidRangeError(`.rating`, source.Rating, 5, false, err)
+	}
+	if len(source.Review) < 10 {
+		err = goa.InvalidLengthError(`.review`, source.Review, 10, true, err)
+	}
+	if len(source.Review) > 300 {
+		err = goa.InvalidLengthError(`.review`, source.Review, 300, false, err)
+	}
+	if source.Sweetness < 1 {
+		err = goa.InvalidRangeError(`.sweetness`, source.Sweetness, 1, true, err)
+	}
+	if source.Sweetness > 5 {
+		err = goa.InvalidRangeError(`.sweetness`, source.Sweetness, 5, false, err)
+	}
+	if source.UpdatedAt != "" {
+		if err2 := goa.ValidateDateTime(source.UpdatedAt); err2 != nil {
+			err = goa.InvalidFormatError(`.updated_at`, source.UpdatedAt, goa.FormatDateTime, err2, err)
+		}
+	}
+	if len(source.Varietal) < 4 {
+		err = goa.InvalidLengthError(`.varietal`, source.Varietal, 4, true, err)
+	}
+	if len(source.Vineyard) < 2 {
+		err = goa.InvalidLengthError(`.vineyard`, source.Vineyard, 2, true, err)
+	}
+	if source.Vintage < 1900 {
+		err = goa.InvalidRangeError(`.vintage`, source.Vintage, 1900, true, err)
+	}
+	if source.Vintage > 2020 {
+		err = goa.InvalidRangeError(`.vintage`, source.Vintage, 2020, false, err)
+	}
+	w.ObjectStart()
+	if source.Account != nil {
+		w.Key("account")
+		err = WriteAccountJSON(w, source.Account, err)
+	}
+	w.Key("color")
+	w.String(source.Color)
+	w.Key("country")
+	w.String(source.Country)
+	w.Key("created_at")
+	w.String(source.CreatedAt)
+	w.Key("href")
+	w.String(source.Href)
+	w.Key("id")
+	w.Int(source.ID)
+	w.Key("links")
+	w.ObjectStart()
+	if source.Account != nil {
+		w.Key("account")
+		err = WriteAccountLinkJSON(w, source.Account, err)
+	}
+	w.ObjectEnd()
+	w.Key("name")
+	w.String(source.Name)
+	w.Key("rating")
+	w.Int(source.Rating)
+	w.Key("region")
+	w.String(source.Region)
+	w.Key("review")
+	w.String(source.Review)
+	w.Key("sweetness")
+	w.Int(source.Sweetness)
+	w.Key("updated_at")
+	w.String(source.UpdatedAt)
+	w.Key("varietal")
+	w.String(source.Varietal)
+	w.Key("vineyard")
+	w.String(source.Vineyard)
+	w.Key("vintage")
+	w.Int(source.Vintage)
+	w.ObjectEnd()
+	return
+}
+
 // MarshalBottleTiny validates and renders an instance of Bottle into a interface{}
 // using view "tiny".
 func MarshalBottleTiny(source *Bottle, inErr error) (target map[string]interface{}, err error) {
@@ -465,6 +707,39 @@ func MarshalBottleTiny(source *Bottle, inErr error) (target map[string]interface
 	return
 }
 
+// WriteBottleTinyJSON validates an instance of Bottle and writes its JSON representation
+// using view "tiny".
+func WriteBottleTinyJSON(w *goa.JSONWriter, source *Bottle, inErr error) (err error) {
+	err = inErr
+	if len(source.Name) < 2 {
+		err = goa.InvalidLengthError(`.name`, source.Name, 2, true, err)
+	}
+	if source.Rating < 1 {
+		err = goa.InvalidRangeError(`.rating`, source.Rating, 1, true, err)
+	}
+	if source.Rating > 5 {
+		err = goa.InvalidRangeError(`.rating`, source.Rating, 5, false, err)
+	}
+	w.ObjectStart()
+	w.Key("href")
+	w.String(source.Href)
+	w.Key("id")
+	w.Int(source.ID)
+	w.Key("links")
+	w.ObjectStart()
+	if source.Account != nil {
+		w.Key("account")
+		err = WriteAccountLinkJSON(w, source.Account, err)
+	}
+	w.ObjectEnd()
+	w.Key("name")
+	w.String(source.Name)
+	w.Key("rating")
+	w.Int(source.Rating)
+	w.ObjectEnd()
+	return
+}
+
 // UnmarshalBottle unmarshals and validates a raw interface{} into an instance of Bottle
 func UnmarshalBottle(source interface{}, inErr error) (target *Bottle, err error) {
 	err = inErr
@@ -514,7 +789,7 @@ func UnmarshalBottle(source interface{}, inErr error) (target *Bottle, err error
 			}
 			if err == nil {
 				if tmp35 != "" {
-					if err2 := goa.ValidateFormat(goa.FormatDateTime, tmp35); err2 != nil {
+					if err2 := goa.ValidateDateTime(tmp35); err2 != nil {
 						err = goa.InvalidFormatError(`load.CreatedAt`, tmp35, goa.FormatDateTime, err2, err)
 					}
 				}
@@ -622,7 +897,7 @@ func UnmarshalBottle(source interface{}, inErr error) (target *Bottle, err error
 			}
 			if err == nil {
 				if tmp43 != "" {
-					if err2 := goa.ValidateFormat(goa.FormatDateTime, tmp43); err2 != nil {
+					if err2 := goa.ValidateDateTime(tmp43); err2 != nil {
 						err = goa.InvalidFormatError(`load.UpdatedAt`, tmp43, goa.FormatDateTime, err2, err)
 					}
 				}
@@ -729,12 +1004,12 @@ func (mt BottleCollection) Dump(view BottleCollectionViewEnum) (res []map[string
 func (mt BottleCollection) Validate() (err error) {
 	for _, e := range mt {
 		if e.Account.CreatedAt != "" {
-			if err2 := goa.ValidateFormat(goa.FormatDateTime, e.Account.CreatedAt); err2 != nil {
+			if err2 := goa.ValidateDateTime(e.Account.CreatedAt); err2 != nil {
 				err = goa.InvalidFormatError(`response[*].account.created_at`, e.Account.CreatedAt, goa.FormatDateTime, err2, err)
 			}
 		}
 		if e.Account.CreatedBy != "" {
-			if err2 := goa.ValidateFormat(goa.FormatEmail, e.Account.CreatedBy); err2 != nil {
+			if err2 := goa.ValidateEmail(e.Account.CreatedBy); err2 != nil {
 				err = goa.InvalidFormatError(`response[*].account.created_by`, e.Account.CreatedBy, goa.FormatEmail, err2, err)
 			}
 		}
@@ -747,7 +1022,7 @@ func (mt BottleCollection) Validate() (err error) {
 			err = goa.InvalidLengthError(`response[*].country`, e.Country, 2, true, err)
 		}
 		if e.CreatedAt != "" {
-			if err2 := goa.ValidateFormat(goa.FormatDateTime, e.CreatedAt); err2 != nil {
+			if err2 := goa.ValidateDateTime(e.CreatedAt); err2 != nil {
 				err = goa.InvalidFormatError(`response[*].created_at`, e.CreatedAt, goa.FormatDateTime, err2, err)
 			}
 		}
@@ -773,7 +1048,7 @@ func (mt BottleCollection) Validate() (err error) {
 			err = goa.InvalidRangeError(`response[*].sweetness`, e.Sweetness, 5, false, err)
 		}
 		if e.UpdatedAt != "" {
-			if err2 := goa.ValidateFormat(goa.FormatDateTime, e.UpdatedAt); err2 != nil {
+			if err2 := goa.ValidateDateTime(e.UpdatedAt); err2 != nil {
 				err = goa.InvalidFormatError(`response[*].updated_at`, e.UpdatedAt, goa.FormatDateTime, err2, err)
 			}
 		}
@@ -793,6 +1068,42 @@ func (mt BottleCollection) Validate() (err error) {
 	return
 }
 
+// DumpView produces raw data from an instance of BottleCollection using the view with the given name.
+// It implements goa.MediaTypeRenderer.
+func (mt BottleCollection) DumpView(view string) (interface{}, error) {
+	res, err := mt.Dump(BottleCollectionViewEnum(view))
+	return res, err
+}
+
+// WriteJSON validates the media type instance and writes its JSON representation using the view
+// with the given name. It implements goa.MediaTypeRenderer.
+func (mt BottleCollection) WriteJSON(w *goa.JSONWriter, view string) (err error) {
+	switch view {
+	case "default":
+		err = WriteBottleCollectionJSON(w, mt, err)
+	case "tiny":
+		err = WriteBottleCollectionTinyJSON(w, mt, err)
+	default:
+		err = fmt.Errorf("unknown view %#v", view)
+	}
+	return
+}
+
+// Len returns the number of elements in the collection. It implements goa.CollectionRenderer.
+func (mt BottleCollection) Len() int {
+	return len(mt)
+}
+
+// WriteElemJSON validates the element of the collection at index i and writes its JSON
+// representation using the view with the given name. It implements goa.CollectionRenderer.
+func (mt BottleCollection) WriteElemJSON(w *goa.JSONWriter, i int, view string) error {
+	if mt[i] == nil {
+		w.Null()
+		return nil
+	}
+	return mt[i].WriteJSON(w, view)
+}
+
 // MarshalBottleCollection validates and renders an instance of BottleCollection into a interface{}
 // using view "default".
 func MarshalBottleCollection(source BottleCollection, inErr error) (target []map[string]interface{}, err error) {
@@ -804,6 +1115,22 @@ func MarshalBottleCollection(source BottleCollection, inErr error) (target []map
 	return
 }
 
+// WriteBottleCollectionJSON validates an instance of BottleCollection and writes its JSON representation
+// using view "default".
+func WriteBottleCollectionJSON(w *goa.JSONWriter, source BottleCollection, inErr error) (err error) {
+	err = inErr
+	w.ArrayStart()
+	for _, res := range source {
+		if res == nil {
+			w.Null()
+		} else {
+			err = WriteBottleJSON(w, res, err)
+		}
+	}
+	w.ArrayEnd()
+	return
+}
+
 // MarshalBottleCollectionTiny validates and renders an instance of BottleCollection into a interface{}
 // using view "tiny".
 func MarshalBottleCollectionTiny(source BottleCollection, inErr error) (target []map[string]interface{}, err error) {
@@ -815,6 +1142,22 @@ func MarshalBottleCollectionTiny(source BottleCollection, inErr error) (target [
 	return
 }
 
+// WriteBottleCollectionTinyJSON validates an instance of BottleCollection and writes its JSON representation
+// using view "tiny".
+func WriteBottleCollectionTinyJSON(w *goa.JSONWriter, source BottleCollection, inErr error) (err error) {
+	err = inErr
+	w.ArrayStart()
+	for _, res := range source {
+		if res == nil {
+			w.Null()
+		} else {
+			err = WriteBottleTinyJSON(w, res, err)
+		}
+	}
+	w.ArrayEnd()
+	return
+}
+
 // UnmarshalBottleCollection unmarshals and validates a raw interface{} into an instance of BottleCollection
 func UnmarshalBottleCollection(source interface{}, inErr error) (target BottleCollection, err error) {
 	err = inErr
@@ -80,6 +80,67 @@ func MarshalBottlePayload(source *BottlePayload, inErr error) (target map[string
 	return
 }
 
+// WriteBottlePayloadJSON validates an instance of BottlePayload and writes its JSON representation.
+func WriteBottlePayloadJSON(w *goa.JSONWriter, source *BottlePayload, inErr error) (err error) {
+	err = inErr
+	if source.Color != "" {
+		if !(source.Color == "red" || source.Color == "white" || source.Color == "rose" || source.Color == "yellow" || source.Color == "sparkling") {
+			err = goa.InvalidEnumValueError(`.color`, source.Color, []interface{}{"red", "white", "rose", "yellow", "sparkling"}, err)
+		}
+	}
+	if len(source.Country) < 2 {
+		err = goa.InvalidLengthError(`.country`, source.Country, 2, true, err)
+	}
+	if len(source.Name) < 2 {
+		err = goa.InvalidLengthError(`.name`, source.Name, 2, true, err)
+	}
+	if len(source.Review) < 10 {
+		err = goa.InvalidLengthError(`.review`, source.Review, 10, true, err)
+	}
+	if len(source.Review) > 300 {
+		err = goa.InvalidLengthError(`.review`, source.Review, 300, false, err)
+	}
+	if source.Sweetness < 1 {
+		err = goa.InvalidRangeError(`.sweetness`, source.Sweetness, 1, true, err)
+	}
+	if source.Sweetness > 5 {
+		err = goa.InvalidRangeError(`.sweetness`, source.Sweetness, 5, false, err)
+	}
+	if len(source.Varietal) < 4 {
+		err = goa.InvalidLengthError(`.varietal`, source.Varietal, 4, true, err)
+	}
+	if len(source.Vineyard) < 2 {
+		err = goa.InvalidLengthError(`.vineyard`, source.Vineyard, 2, true, err)
+	}
+	if source.Vintage < 1900 {
+		err = goa.InvalidRangeError(`.vintage`, source.Vintage, 1900, true, err)
+	}
+	if source.Vintage > 2020 {
+		err = goa.InvalidRangeError(`.vintage`, source.Vintage, 2020, false, err)
+	}
+	w.ObjectStart()
+	w.Key("color")
+	w.String(source.Color)
+	w.Key("country")
+	w.String(source.Country)
+	w.Key("name")
+	w.String(source.Name)
+	w.Key("region")
+	w.String(source.Region)
+	w.Key("review")
+	w.String(source.Review)
+	w.Key("sweetness")
+	w.Int(source.Sweetness)
+	w.Key("varietal")
+	w.String(source.Varietal)
+	w.Key("vineyard")
+	w.String(source.Vineyard)
+	w.Key("vintage")
+	w.Int(source.Vintage)
+	w.ObjectEnd()
+	return
+}
+
 // UnmarshalBottlePayload unmarshals and validates a raw interface{} into an instance of BottlePayload
 func UnmarshalBottlePayload(source interface{}, inErr error) (target *BottlePayload, err error) {
 	err = inErr
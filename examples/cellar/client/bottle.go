@@ -60,13 +60,16 @@ func (c *Client) ListBottle(path string, years []int) (*http.Response, error) {
 	var body io.Reader
 	u := url.URL{Host: c.Host, Scheme: c.Scheme, Path: path}
 	values := u.Query()
-	tmp12 := make([]string, len(years))
-	for i, e := range years {
-		tmp13 := strconv.Itoa(e)
-		tmp12[i] = tmp13
+	if len(years) > 0 {
+		tmp12 := make([]string, len(years))
+		for i, e := range years {
+			tmp13 := strconv.Itoa(e)
+			tmp12[i] = tmp13
+		}
+		tmp11 := strings.Join(tmp12, ",")
+		values.Set("years", tmp11)
 	}
-	tmp11 := strings.Join(tmp12, ",")
-	values.Set("years", tmp11)
+	u.RawQuery = values.Encode()
 	req, err := http.NewRequest("GET", u.String(), body)
 	if err != nil {
 		return nil, err
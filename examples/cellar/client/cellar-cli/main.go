@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 
+	"github.com/raphael/goa"
 	"github.com/raphael/goa/examples/cellar/client"
 	"gopkg.in/alecthomas/kingpin.v2"
 )
@@ -50,7 +51,9 @@ func main() {
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
 		// Let user know if something went wrong
 		var sbody string
-		if len(body) > 0 {
+		if doc := goa.DecodeErrorDocument(resp, body); doc != nil {
+			sbody = ": " + doc.Error()
+		} else if len(body) > 0 {
 			sbody = ": " + string(body)
 		}
 		fmt.Printf("error: %d%s", resp.StatusCode, sbody)
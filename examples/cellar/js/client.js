@@ -8,6 +8,11 @@ define(['axios'] , function (axios) {
     return obj3;
   }
 
+  // join serializes the elements of an array parameter using the given separator.
+  function join(vals, sep) {
+    return vals && vals.join ? vals.join(sep) : vals;
+  }
+
   return function (scheme, host, timeout) {
     scheme = scheme || 'http';
     host = host || 'cellar.goa.design';
@@ -107,7 +112,7 @@ define(['axios'] , function (axios) {
       url: urlPrefix + path,
       method: 'get',
       params: {
-        years: years
+        years: join(years, ",")
       },
       responseType: 'json'
     };
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
@@ -73,3 +74,16 @@ func Tabs(depth int) string {
 	//	return fmt.Sprintf("%d%s", depth, tabs)
 	return tabs
 }
+
+// VersionPackage returns the name of the Go package that contains the code generated for the API
+// version with the given name, e.g. "v1_0" for version "1.0".
+func VersionPackage(version string) string {
+	pkg := strings.ToLower(versionPackageRegex.ReplaceAllString(version, "_"))
+	if pkg == "" || pkg[0] < 'a' || pkg[0] > 'z' {
+		pkg = "v" + pkg
+	}
+	return pkg
+}
+
+// versionPackageRegex matches the characters that are not valid in package names.
+var versionPackageRegex = regexp.MustCompile(`[^a-zA-Z0-9_]`)
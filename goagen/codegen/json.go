@@ -0,0 +1,280 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/raphael/goa/design"
+)
+
+var jsonUserImplT *template.Template
+
+// init instantiates the templates.
+func init() {
+	var err error
+	fm := template.FuncMap{
+		"gotypename": GoTypeName,
+		"gotyperef":  GoTypeRef,
+	}
+	if jsonUserImplT, err = template.New("user JSON writer").Funcs(fm).Parse(jsonUserImplTmpl); err != nil {
+		panic(err)
+	}
+}
+
+// MediaTypeJSONWriterImpl returns the Go code for a function that validates instances of the given
+// media type and writes their JSON representation to a goa.JSONWriter using the given view to
+// render the attributes. The generated code renders the media type links and the elements of
+// collections the same way the marshaler produced by MediaTypeMarshalerImpl does, it does not
+// build intermediary values and does not rely on reflection.
+func MediaTypeJSONWriterImpl(mt *design.MediaTypeDefinition, view string) string {
+	var impl string
+	if elem := collectionElemMediaType(mt); elem != nil {
+		impl = collectionJSONWriterImpl(elem, view)
+	} else if mt.Type.IsObject() {
+		final, renderLinks := renderedAttribute(mt, view)
+		var links map[string]*design.LinkDefinition
+		if renderLinks {
+			links = mt.Links
+		}
+		impl = attributeJSONWriterR(final, "", "source", links, 1)
+	} else {
+		impl = attributeJSONWriterR(mt.AttributeDefinition, "", "source", nil, 1)
+	}
+	if view == "" {
+		view = "default"
+	}
+	data := map[string]interface{}{
+		"Name": MediaTypeJSONWriterName(mt, view),
+		"Type": mt,
+		"Impl": impl,
+		"View": view,
+	}
+	return RunTemplate(jsonUserImplT, data)
+}
+
+// UserTypeJSONWriterImpl returns the Go code for a function that validates instances of the given
+// user type and writes their JSON representation to a goa.JSONWriter.
+func UserTypeJSONWriterImpl(u *design.UserTypeDefinition) string {
+	if u.IsPrimitive() {
+		return "" // No function for primitive types - they get written inline
+	}
+	data := map[string]interface{}{
+		"Name": userTypeJSONWriterFuncName(u),
+		"Type": u,
+		"Impl": attributeJSONWriterR(u.AttributeDefinition, "", "source", nil, 1),
+	}
+	return RunTemplate(jsonUserImplT, data)
+}
+
+// attributeJSONWriterR is the recursive function that produces the code writing the JSON
+// representation of an attribute value after running the attribute validations. links contains the
+// links to render if the attribute is the object of a media type.
+func attributeJSONWriterR(att *design.AttributeDefinition, context, source string, links map[string]*design.LinkDefinition, depth int) string {
+	var writer string
+	switch actual := att.Type.(type) {
+	case *design.MediaTypeDefinition:
+		writer = mediaTypeJSONWriterR(actual, source, att.View, depth)
+	case design.Object:
+		writer = objectJSONWriterR(actual, att.AllRequired(), context, source, links, depth)
+	default:
+		writer = typeJSONWriterR(att.Type, context, source, depth)
+	}
+	if validation := ValidationChecker(att, false, source, context, depth); validation != "" {
+		return strings.TrimRight(validation, "\n") + "\n" + writer
+	}
+	return writer
+}
+
+// objectJSONWriterR produces the code writing the JSON representation of an object. The members
+// are written in lexicographical order like encoding/json does for maps, attributes whose value
+// is nil are omitted.
+func objectJSONWriterR(o design.Object, required []string, context, source string, links map[string]*design.LinkDefinition, depth int) string {
+	var b bytes.Buffer
+	keys := make([]string, 0, len(o)+1)
+	for n := range o {
+		keys = append(keys, n)
+	}
+	sort.Strings(keys)
+	for _, n := range keys {
+		at := o[n]
+		if !at.Type.IsPrimitive() {
+			continue
+		}
+		field := fmt.Sprintf("%s.%s", source, Goify(n, true))
+		validation := ValidationChecker(at, has(required, n), field, fmt.Sprintf("%s.%s", context, n), depth)
+		if validation != "" {
+			b.WriteString(strings.TrimRight(validation, "\n"))
+			b.WriteByte('\n')
+		}
+	}
+	renderLinks := len(links) > 0
+	if _, ok := o["links"]; !ok && renderLinks {
+		keys = append(keys, "links")
+		sort.Strings(keys)
+	}
+	fmt.Fprintf(&b, "%sw.ObjectStart()\n", Tabs(depth))
+	for _, n := range keys {
+		if n == "links" && renderLinks {
+			b.WriteString(linksJSONWriterR(links, source, depth))
+			continue
+		}
+		at := o[n]
+		field := fmt.Sprintf("%s.%s", source, Goify(n, true))
+		ctx := fmt.Sprintf("%s.%s", context, n)
+		if at.Type.IsPrimitive() {
+			fmt.Fprintf(&b, "%sw.Key(%q)\n", Tabs(depth), n)
+			b.WriteString(typeJSONWriterR(at.Type, ctx, field, depth))
+			b.WriteByte('\n')
+			continue
+		}
+		fmt.Fprintf(&b, "%sif %s != nil {\n", Tabs(depth), field)
+		fmt.Fprintf(&b, "%s\tw.Key(%q)\n", Tabs(depth), n)
+		b.WriteString(attributeJSONWriterR(at, ctx, field, nil, depth+1))
+		fmt.Fprintf(&b, "\n%s}\n", Tabs(depth))
+	}
+	fmt.Fprintf(&b, "%sw.ObjectEnd()", Tabs(depth))
+	return b.String()
+}
+
+// linksJSONWriterR produces the code writing the "links" member of a media type.
+func linksJSONWriterR(links map[string]*design.LinkDefinition, source string, depth int) string {
+	var b bytes.Buffer
+	names := make([]string, 0, len(links))
+	for n := range links {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(&b, "%sw.Key(\"links\")\n", Tabs(depth))
+	fmt.Fprintf(&b, "%sw.ObjectStart()\n", Tabs(depth))
+	for _, n := range names {
+		l := links[n]
+		field := fmt.Sprintf("%s.%s", source, Goify(l.Name, true))
+		fmt.Fprintf(&b, "%sif %s != nil {\n", Tabs(depth), field)
+		fmt.Fprintf(&b, "%s\tw.Key(%q)\n", Tabs(depth), n)
+		b.WriteString(mediaTypeJSONWriterR(l.MediaType(), field, l.View, depth+1))
+		fmt.Fprintf(&b, "\n%s}\n", Tabs(depth))
+	}
+	fmt.Fprintf(&b, "%sw.ObjectEnd()\n", Tabs(depth))
+	return b.String()
+}
+
+// arrayJSONWriterR produces the code writing the JSON representation of an array. nil elements
+// are written as null.
+func arrayJSONWriterR(a *design.Array, context, source string, depth int) string {
+	var b bytes.Buffer
+	elem := Tempvar()
+	ctx := fmt.Sprintf("%s[*]", context)
+	fmt.Fprintf(&b, "%sw.ArrayStart()\n", Tabs(depth))
+	fmt.Fprintf(&b, "%sfor _, %s := range %s {\n", Tabs(depth), elem, source)
+	if a.ElemType.Type.IsPrimitive() {
+		b.WriteString(attributeJSONWriterR(a.ElemType, ctx, elem, nil, depth+1))
+		b.WriteByte('\n')
+	} else {
+		fmt.Fprintf(&b, "%s\tif %s == nil {\n", Tabs(depth), elem)
+		fmt.Fprintf(&b, "%s\t\tw.Null()\n", Tabs(depth))
+		fmt.Fprintf(&b, "%s\t} else {\n", Tabs(depth))
+		b.WriteString(attributeJSONWriterR(a.ElemType, ctx, elem, nil, depth+2))
+		fmt.Fprintf(&b, "\n%s\t}\n", Tabs(depth))
+	}
+	fmt.Fprintf(&b, "%s}\n", Tabs(depth))
+	fmt.Fprintf(&b, "%sw.ArrayEnd()", Tabs(depth))
+	return b.String()
+}
+
+// typeJSONWriterR produces the code writing the JSON representation of an instance of a type.
+func typeJSONWriterR(t design.DataType, context, source string, depth int) string {
+	switch actual := t.(type) {
+	case design.Primitive:
+		return Tabs(depth) + primitiveJSONWriter(actual, source)
+	case *design.Array:
+		return arrayJSONWriterR(actual, context, source, depth)
+	case *design.Hash:
+		// Hash keys may be of any type, leave it to encoding/json to render them.
+		return fmt.Sprintf("%sw.Value(%s)", Tabs(depth), source)
+	case design.Object:
+		return objectJSONWriterR(actual, nil, context, source, nil, depth)
+	case *design.MediaTypeDefinition:
+		return mediaTypeJSONWriterR(actual, source, "", depth)
+	case *design.UserTypeDefinition:
+		if p, ok := actual.Type.(design.Primitive); ok {
+			return Tabs(depth) + primitiveJSONWriter(p, fmt.Sprintf("%s(%s)", GoNativeType(p), source))
+		}
+		return fmt.Sprintf("%serr = %s(w, %s, err)", Tabs(depth), userTypeJSONWriterFuncName(actual), source)
+	default:
+		panic(fmt.Sprintf("goa bug: unknown type %#v", actual))
+	}
+}
+
+// primitiveJSONWriter returns the statement writing the JSON representation of a primitive value.
+func primitiveJSONWriter(p design.Primitive, source string) string {
+	switch p.Kind() {
+	case design.BooleanKind:
+		return fmt.Sprintf("w.Bool(%s)", source)
+	case design.IntegerKind:
+		return fmt.Sprintf("w.Int(%s)", source)
+	case design.NumberKind:
+		return fmt.Sprintf("w.Float(%s)", source)
+	case design.StringKind:
+		return fmt.Sprintf("w.String(%s)", source)
+	case design.DateTimeKind:
+		return fmt.Sprintf("w.Time(%s)", source)
+	case design.UUIDKind:
+		return fmt.Sprintf("w.String(%s.String())", source)
+	default:
+		return fmt.Sprintf("w.Value(%s)", source)
+	}
+}
+
+// mediaTypeJSONWriterR produces Go code that calls the media type JSON writer function.
+func mediaTypeJSONWriterR(mt *design.MediaTypeDefinition, source, view string, depth int) string {
+	return fmt.Sprintf("%serr = %s(w, %s, err)", Tabs(depth), MediaTypeJSONWriterName(mt, view), source)
+}
+
+// collectionJSONWriterImpl produces the code writing the elements of a media type collection.
+func collectionJSONWriterImpl(elem *design.MediaTypeDefinition, view string) string {
+	return fmt.Sprintf(`	w.ArrayStart()
+	for _, res := range source {
+		if res == nil {
+			w.Null()
+		} else {
+%s
+		}
+	}
+	w.ArrayEnd()`, mediaTypeJSONWriterR(elem, "res", view, 3))
+}
+
+// collectionElemMediaType returns the media type of the elements of mt if mt is a collection, nil
+// otherwise.
+func collectionElemMediaType(mt *design.MediaTypeDefinition) *design.MediaTypeDefinition {
+	if a := mt.Type.ToArray(); a != nil {
+		if elem, ok := a.ElemType.Type.(*design.MediaTypeDefinition); ok {
+			return elem
+		}
+	}
+	return nil
+}
+
+// userTypeJSONWriterFuncName returns the name for the given user type JSON writer function.
+func userTypeJSONWriterFuncName(u *design.UserTypeDefinition) string {
+	return fmt.Sprintf("Write%sJSON", GoTypeName(u, 0))
+}
+
+// MediaTypeJSONWriterName returns the name of the function generated by MediaTypeJSONWriterImpl
+// for the given media type and view.
+func MediaTypeJSONWriterName(mt *design.MediaTypeDefinition, view string) string {
+	if view == "" || view == "default" {
+		return userTypeJSONWriterFuncName(mt.UserTypeDefinition)
+	}
+	return fmt.Sprintf("Write%s%sJSON", GoTypeName(mt, 0), strings.Title(view))
+}
+
+const jsonUserImplTmpl = `// {{.Name}} validates an instance of {{gotypename .Type 0}} and writes its JSON representation{{if .View}}
+// using view "{{.View}}"{{end}}.
+func {{.Name}}(w *goa.JSONWriter, source {{gotyperef .Type 0}}, inErr error) (err error) {
+	err = inErr
+{{.Impl}}
+	return
+}`
@@ -0,0 +1,152 @@
+package codegen_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/raphael/goa/design"
+	. "github.com/raphael/goa/design/dsl"
+	"github.com/raphael/goa/goagen/codegen"
+)
+
+var _ = Describe("JSON writer", func() {
+	var bottle, collection *MediaTypeDefinition
+	var view string
+	var writer string
+
+	BeforeEach(func() {
+		Design = nil
+		Errors = nil
+		codegen.TempCount = 0
+		view = ""
+		account := MediaType("application/vnd.account", func() {
+			Attributes(func() {
+				Attribute("id", Integer)
+				Attribute("href")
+				Attribute("name")
+			})
+			View("default", func() {
+				Attribute("id")
+				Attribute("href")
+				Attribute("name")
+			})
+			View("link", func() {
+				Attribute("href")
+			})
+		})
+		bottle = MediaType("application/vnd.bottle", func() {
+			Attributes(func() {
+				Attribute("name", String, func() {
+					MinLength(2)
+				})
+				Attribute("vintage", Integer)
+				Attribute("tags", ArrayOf(String))
+				Attribute("account", account)
+				Required("name")
+			})
+			Links(func() {
+				Link("account")
+			})
+			View("default", func() {
+				Attribute("name")
+				Attribute("tags")
+				Attribute("links")
+			})
+			View("tiny", func() {
+				Attribute("vintage")
+			})
+		})
+		collection = CollectionOf(bottle)
+		Ω(RunDSL()).ShouldNot(HaveOccurred())
+	})
+
+	Context("with a media type", func() {
+		JustBeforeEach(func() {
+			writer = codegen.MediaTypeJSONWriterImpl(bottle, view)
+		})
+
+		It("renders the view attributes and links", func() {
+			Ω(writer).Should(Equal(bottleJSONWriter))
+		})
+
+		Context("using a view without links", func() {
+			BeforeEach(func() {
+				view = "tiny"
+			})
+
+			It("only renders the view attributes", func() {
+				Ω(writer).Should(Equal(bottleTinyJSONWriter))
+			})
+		})
+	})
+
+	Context("with a collection", func() {
+		JustBeforeEach(func() {
+			writer = codegen.MediaTypeJSONWriterImpl(collection, "tiny")
+		})
+
+		It("renders the elements with the view", func() {
+			Ω(writer).Should(Equal(collectionTinyJSONWriter))
+		})
+	})
+})
+
+const (
+	bottleJSONWriter = `// WriteBottleJSON validates an instance of Bottle and writes its JSON representation
+// using view "default".
+func WriteBottleJSON(w *goa.JSONWriter, source *Bottle, inErr error) (err error) {
+	err = inErr
+	if source.Name == "" {
+		err = goa.MissingAttributeError(` + "``" + `, "name", err)
+	}
+	if len(source.Name) < 2 {
+		err = goa.InvalidLengthError(` + "`.name`" + `, source.Name, 2, true, err)
+	}
+	w.ObjectStart()
+	w.Key("links")
+	w.ObjectStart()
+	if source.Account != nil {
+		w.Key("account")
+		err = WriteAccountLinkJSON(w, source.Account, err)
+	}
+	w.ObjectEnd()
+	w.Key("name")
+	w.String(source.Name)
+	if source.Tags != nil {
+		w.Key("tags")
+		w.ArrayStart()
+		for _, tmp1 := range source.Tags {
+			w.String(tmp1)
+		}
+		w.ArrayEnd()
+	}
+	w.ObjectEnd()
+	return
+}`
+
+	bottleTinyJSONWriter = `// WriteBottleTinyJSON validates an instance of Bottle and writes its JSON representation
+// using view "tiny".
+func WriteBottleTinyJSON(w *goa.JSONWriter, source *Bottle, inErr error) (err error) {
+	err = inErr
+	w.ObjectStart()
+	w.Key("vintage")
+	w.Int(source.Vintage)
+	w.ObjectEnd()
+	return
+}`
+
+	collectionTinyJSONWriter = `// WriteBottleCollectionTinyJSON validates an instance of BottleCollection and writes its JSON representation
+// using view "tiny".
+func WriteBottleCollectionTinyJSON(w *goa.JSONWriter, source BottleCollection, inErr error) (err error) {
+	err = inErr
+	w.ArrayStart()
+	for _, res := range source {
+		if res == nil {
+			w.Null()
+		} else {
+			err = WriteBottleTinyJSON(w, res, err)
+		}
+	}
+	w.ArrayEnd()
+	return
+}`
+)
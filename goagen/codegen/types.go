@@ -2,11 +2,14 @@ package codegen
 
 import (
 	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"sort"
 	"strings"
 	"text/template"
+	"time"
 	"unicode"
 
 	"github.com/raphael/goa/design"
@@ -47,6 +50,7 @@ func init() {
 		"add":                func(a, b int) int { return a + b },
 		"tempvar":            Tempvar,
 		"has":                has,
+		"literal":            GoLiteral,
 	}
 	if mArrayT, err = template.New("array marshaler").Funcs(fm).Parse(mArrayTmpl); err != nil {
 		panic(err)
@@ -271,6 +275,14 @@ func GoNativeType(t design.DataType) string {
 			return "float64"
 		case design.StringKind:
 			return "string"
+		case design.FileKind:
+			return "*multipart.FileHeader"
+		case design.DateTimeKind:
+			return "time.Time"
+		case design.UUIDKind:
+			return "goa.UUID"
+		case design.AnyKind:
+			return "interface{}"
 		default:
 			panic(fmt.Sprintf("goa bug: unknown primitive type %#v", actual))
 		}
@@ -287,6 +299,110 @@ func GoNativeType(t design.DataType) string {
 	}
 }
 
+// GoLiteral returns the Go expression that initializes a value of type t with val, typically the
+// default value of an attribute. val must be compatible with t (see design.DataType.IsCompatible).
+// GoLiteral returns an empty string if val is nil or if t is an object type as objects cannot be
+// initialized from literal values.
+func GoLiteral(t design.DataType, val interface{}) string {
+	if val == nil {
+		return ""
+	}
+	switch actual := t.(type) {
+	case design.Primitive:
+		switch actual.Kind() {
+		case design.BooleanKind, design.IntegerKind, design.NumberKind:
+			return fmt.Sprintf("%v", val)
+		case design.StringKind:
+			return fmt.Sprintf("%q", val)
+		case design.DateTimeKind:
+			return dateTimeLiteral(val)
+		case design.UUIDKind:
+			return uuidLiteral(val)
+		case design.AnyKind:
+			return fmt.Sprintf("%#v", val)
+		default:
+			return ""
+		}
+	case *design.Array:
+		v := reflect.ValueOf(val)
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return ""
+		}
+		elems := make([]string, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elems[i] = GoLiteral(actual.ElemType.Type, v.Index(i).Interface())
+			if elems[i] == "" {
+				return ""
+			}
+		}
+		return fmt.Sprintf("%s{%s}", GoTypeName(actual, 0), strings.Join(elems, ", "))
+	case *design.Hash:
+		v := reflect.ValueOf(val)
+		if v.Kind() != reflect.Map {
+			return ""
+		}
+		elems := make([]string, v.Len())
+		for i, k := range v.MapKeys() {
+			key := GoLiteral(actual.KeyType.Type, k.Interface())
+			elem := GoLiteral(actual.ElemType.Type, v.MapIndex(k).Interface())
+			if key == "" || elem == "" {
+				return ""
+			}
+			elems[i] = fmt.Sprintf("%s: %s", key, elem)
+		}
+		sort.Strings(elems)
+		return fmt.Sprintf("%s{%s}", GoTypeName(actual, 0), strings.Join(elems, ", "))
+	case *design.UserTypeDefinition:
+		lit := GoLiteral(actual.Type, val)
+		if lit != "" && actual.IsPrimitive() {
+			return fmt.Sprintf("%s(%s)", GoTypeName(actual, 0), lit)
+		}
+		return lit
+	case *design.MediaTypeDefinition:
+		return GoLiteral(actual.UserTypeDefinition, val)
+	default:
+		return ""
+	}
+}
+
+// dateTimeLiteral returns the Go expression that initializes the time.Time value corresponding to
+// val. val is either a RFC3339 string or a time.Time.
+func dateTimeLiteral(val interface{}) string {
+	t, ok := val.(time.Time)
+	if !ok {
+		s, _ := val.(string)
+		var err error
+		if t, err = time.Parse(time.RFC3339, s); err != nil {
+			return ""
+		}
+	}
+	loc := "time.UTC"
+	if _, offset := t.Zone(); offset != 0 {
+		loc = fmt.Sprintf("time.FixedZone(\"\", %d)", offset)
+	}
+	return fmt.Sprintf("time.Date(%d, %d, %d, %d, %d, %d, %d, %s)",
+		t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// uuidLiteral returns the Go expression that initializes the goa.UUID value corresponding to val.
+// val is either the canonical string representation of a UUID or a [16]byte.
+func uuidLiteral(val interface{}) string {
+	b, ok := val.([16]byte)
+	if !ok {
+		s, _ := val.(string)
+		raw, err := hex.DecodeString(strings.Replace(s, "-", "", -1))
+		if err != nil || len(raw) != 16 {
+			return ""
+		}
+		copy(b[:], raw)
+	}
+	elems := make([]string, len(b))
+	for i, c := range b {
+		elems[i] = fmt.Sprintf("0x%02x", c)
+	}
+	return fmt.Sprintf("goa.UUID{%s}", strings.Join(elems, ", "))
+}
+
 // Goify makes a valid Go identifier out of any string.
 // It does that by removing any non letter and non digit character and by making sure the first
 // character is a letter or "_".
@@ -484,6 +600,28 @@ func userTypeMarshalerImpl(u *design.UserTypeDefinition) string {
 // mediaTypeMarshalerImpl implements the recursive function that marshals an instance of a media
 // type into a raw value.
 func mediaTypeMarshalerImpl(mt *design.MediaTypeDefinition, view string) string {
+	final, renderLinks := renderedAttribute(mt, view)
+	if view == "" {
+		view = "default"
+	}
+	var linkMarshaler string
+	if renderLinks && len(mt.Links) > 0 {
+		data := map[string]interface{}{
+			"links":   mt.Links,
+			"context": "",
+			"source":  "source",
+			"target":  "target",
+			"view":    view,
+			"depth":   1,
+		}
+		linkMarshaler = "\n" + RunTemplate(mLinkT, data)
+	}
+	return attributeMarshalerR(final, "", "source", "target", 1) + linkMarshaler
+}
+
+// renderedAttribute returns the attribute that describes the fields of the media type rendered
+// with the given view. It also returns true if the view renders the media type links.
+func renderedAttribute(mt *design.MediaTypeDefinition, view string) (*design.AttributeDefinition, bool) {
 	rendered := mt.AttributeDefinition
 	if view == "" {
 		view = "default"
@@ -529,18 +667,6 @@ func mediaTypeMarshalerImpl(mt *design.MediaTypeDefinition, view string) string
 			Validations: vals,
 		}
 	}
-	var linkMarshaler string
-	if renderLinks && len(mt.Links) > 0 {
-		data := map[string]interface{}{
-			"links":   mt.Links,
-			"context": "",
-			"source":  "source",
-			"target":  "target",
-			"view":    view,
-			"depth":   1,
-		}
-		linkMarshaler = "\n" + RunTemplate(mLinkT, data)
-	}
 	final := rendered.Dup()
 	o := rendered.Type.ToObject()
 	mtObj := mt.Type.ToObject()
@@ -556,7 +682,7 @@ func mediaTypeMarshalerImpl(mt *design.MediaTypeDefinition, view string) string
 		}
 	}
 	final.Type = newObj
-	return attributeMarshalerR(final, "", "source", "target", 1) + linkMarshaler
+	return final, renderLinks
 }
 
 func collectionMediaTypeMarshalerImpl(mt *design.MediaTypeDefinition, view string) string {
@@ -861,7 +987,7 @@ const (
 */}}{{if eq $at.Type.Kind 4}}{{tabs $ctx.depth}}if {{$ctx.source}}.{{$required}} == "" {
 {{tabs $ctx.depth}}	err = goa.MissingAttributeError(` + "`" + `{{$ctx.context}}` + "`" + `, "{{$r}}", err)
 {{tabs $ctx.depth}}}
-{{tabs $ctx.depth}}{{else if (not $at.Type.IsPrimitive)}}{{tabs $ctx.depth}}if {{$ctx.source}}.{{$required}} == nil {
+{{tabs $ctx.depth}}{{else if or (not $at.Type.IsPrimitive) (eq $at.Type.Kind 13)}}{{tabs $ctx.depth}}if {{$ctx.source}}.{{$required}} == nil {
 {{tabs $ctx.depth}}	err = goa.MissingAttributeError(` + "`" + `{{$ctx.context}}` + "`" + `, "{{$r}}", err)
 {{tabs $ctx.depth}}}
 {{end}}{{/* if eq $at.Type.Kind 4 */}}{{end}}{{/* range */}}{{/*
@@ -920,13 +1046,26 @@ func {{.Name}}(source {{gotyperef .Type 0}}, inErr error) (target {{gonative .Ty
 {{tabs .depth}}	err = goa.InvalidAttributeTypeError(` + "`" + `{{.context}}` + "`" + `, {{.source}}, "{{gonative .type}}", err)
 {{tabs .depth}}}`
 
-	unmPrimitiveTmpl = `{{if eq .type.Kind 2}}{{tabs .depth}}if f, ok := {{.source}}.(float64); ok {
+	unmPrimitiveTmpl = `{{if eq .type.Kind 13}}{{tabs .depth}}{{.target}} = {{.source}}{{else}}{{/*
+*/}}{{if eq .type.Kind 2}}{{tabs .depth}}if f, ok := {{.source}}.(float64); ok {
 {{tabs .depth}}	{{.target}} = int(f)
+{{else if eq .type.Kind 11}}{{tabs .depth}}if val, ok := {{.source}}.(string); ok {
+{{tabs .depth}}	var perr error
+{{tabs .depth}}	{{.target}}, perr = time.Parse(time.RFC3339, val)
+{{tabs .depth}}	if perr != nil {
+{{tabs .depth}}		err = goa.InvalidAttributeTypeError(` + "`" + `{{.context}}` + "`" + `, {{.source}}, "datetime", err)
+{{tabs .depth}}	}
+{{else if eq .type.Kind 12}}{{tabs .depth}}if val, ok := {{.source}}.(string); ok {
+{{tabs .depth}}	var perr error
+{{tabs .depth}}	{{.target}}, perr = goa.UUIDFromString(val)
+{{tabs .depth}}	if perr != nil {
+{{tabs .depth}}		err = goa.InvalidAttributeTypeError(` + "`" + `{{.context}}` + "`" + `, {{.source}}, "uuid", err)
+{{tabs .depth}}	}
 {{else}}{{tabs .depth}}if val, ok := {{.source}}.({{gotyperef .type (add .depth 1)}}); ok {
 {{tabs .depth}}	{{.target}} = val
 {{end}}{{tabs .depth}}} else {
 {{tabs .depth}}	err = goa.InvalidAttributeTypeError(` + "`" + `{{.context}}` + "`" + `, {{.source}}, "{{gotyperef .type (add .depth 1)}}", err)
-{{tabs .depth}}}`
+{{tabs .depth}}}{{end}}`
 
 	unmArrayTmpl = `{{tabs .depth}}if val, ok := {{.source}}.([]interface{}); ok {
 {{tabs .depth}}	{{.target}} = make([]{{gotyperef .elemType.Type (add .depth 2)}}, len(val))
@@ -945,7 +1084,9 @@ func {{.Name}}(source {{gotyperef .Type 0}}, inErr error) (target {{gonative .Ty
 {{tabs $depth}}		{{printf "%s.%s" $target (goify $name true)}} = {{$temp}}
 {{tabs $depth}}	}{{if (has $required $name)}} else {
 {{tabs $depth}}		err = goa.MissingAttributeError(` + "`" + `{{$context}}` + "`" + `, "{{$name}}", err)
-{{tabs $depth}}	}{{end}}
+{{tabs $depth}}	}{{else}}{{$default := literal $att.Type $att.DefaultValue}}{{if $default}} else {
+{{tabs $depth}}		{{printf "%s.%s" $target (goify $name true)}} = {{$default}}
+{{tabs $depth}}	}{{end}}{{end}}
 {{end}}{{tabs $depth}}} else {
 {{tabs .depth}}	err = goa.InvalidAttributeTypeError(` + "`" + `{{.context}}` + "`" + `, {{.source}}, "dictionary", err)
 {{tabs .depth}}}`
@@ -958,9 +1099,9 @@ func {{.Name}}(source {{gotyperef .Type 0}}, inErr error) (target {{gonative .Ty
 {{tabs .depth}}		if err != nil {
 {{tabs .depth}}			return
 {{tabs .depth}}		}
-{{tabs .depth}}		{{$k := tempvar}}var {{$k}} {{gotypename .type.KeyType.Type}}
+{{tabs .depth}}		{{$k := tempvar}}var {{$k}} {{gotypename .type.KeyType.Type (add .depth 2)}}
 {{tabs .depth}}		{{unmarshalAttribute .type.KeyType (printf "%s.keys[*]" .context) $ki $k (add .depth 2)}}
-{{tabs .depth}}		{{$v := tempvar}}var {{$v}} {{gotypename .type.ElemType.Type}}
+{{tabs .depth}}		{{$v := tempvar}}var {{$v}} {{gotypename .type.ElemType.Type (add .depth 2)}}
 {{tabs .depth}}		{{unmarshalAttribute .type.ElemType (printf "%s.values[*]" .context) "v" $v (add .depth 2)}}
 {{tabs .depth}}		{{$tmp}}[{{$k}}] = {{$v}}
 {{tabs .depth}}	}
@@ -254,6 +254,59 @@ var _ = Describe("code generation", func() {
 					Ω(unmarshaler).Should(Equal(expected))
 				})
 			})
+
+			Context("datetime", func() {
+				BeforeEach(func() {
+					p = Primitive(DateTimeKind)
+				})
+
+				It("generates the marshaler code", func() {
+					expected := `	p = raw`
+					Ω(marshaler).Should(Equal(expected))
+				})
+
+				It("generates the unmarshaler code", func() {
+					expected := `	if val, ok := raw.(string); ok {
+		var perr error
+		p, perr = time.Parse(time.RFC3339, val)
+		if perr != nil {
+			err = goa.InvalidAttributeTypeError(` + "``" + `, raw, "datetime", err)
+		}
+	} else {
+		err = goa.InvalidAttributeTypeError(` + "``" + `, raw, "time.Time", err)
+	}`
+					Ω(unmarshaler).Should(Equal(expected))
+				})
+			})
+
+			Context("uuid", func() {
+				BeforeEach(func() {
+					p = Primitive(UUIDKind)
+				})
+
+				It("generates the unmarshaler code", func() {
+					expected := `	if val, ok := raw.(string); ok {
+		var perr error
+		p, perr = goa.UUIDFromString(val)
+		if perr != nil {
+			err = goa.InvalidAttributeTypeError(` + "``" + `, raw, "uuid", err)
+		}
+	} else {
+		err = goa.InvalidAttributeTypeError(` + "``" + `, raw, "goa.UUID", err)
+	}`
+					Ω(unmarshaler).Should(Equal(expected))
+				})
+			})
+
+			Context("any", func() {
+				BeforeEach(func() {
+					p = Primitive(AnyKind)
+				})
+
+				It("generates the unmarshaler code", func() {
+					Ω(unmarshaler).Should(Equal(`	p = raw`))
+				})
+			})
 		})
 
 		Context("with an array of primitive types", func() {
@@ -305,6 +358,23 @@ var _ = Describe("code generation", func() {
 			})
 		})
 
+		Context("with an object with default values", func() {
+			var o Object
+
+			JustBeforeEach(func() {
+				unmarshaler = codegen.TypeUnmarshaler(o, context, source, target)
+			})
+
+			BeforeEach(func() {
+				intAtt := &AttributeDefinition{Type: Primitive(IntegerKind), DefaultValue: 42}
+				o = Object{"foo": intAtt}
+			})
+
+			It("generates the unmarshaler code", func() {
+				Ω(unmarshaler).Should(Equal(defaultUnmarshaled))
+			})
+		})
+
 		Context("with a complex object", func() {
 			var o Object
 
@@ -499,6 +569,39 @@ var _ = Describe("code generation", func() {
 	})
 })
 
+var _ = Describe("GoLiteral", func() {
+	It("renders primitive values", func() {
+		Ω(codegen.GoLiteral(Integer, 1)).Should(Equal("1"))
+		Ω(codegen.GoLiteral(Number, 1.5)).Should(Equal("1.5"))
+		Ω(codegen.GoLiteral(Boolean, true)).Should(Equal("true"))
+		Ω(codegen.GoLiteral(String, "foo")).Should(Equal(`"foo"`))
+	})
+
+	It("renders date times and UUIDs", func() {
+		Ω(codegen.GoLiteral(DateTime, "2015-10-21T07:28:00Z")).
+			Should(Equal("time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)"))
+		Ω(codegen.GoLiteral(DateTime, "2015-10-21T07:28:00+01:00")).
+			Should(Equal(`time.Date(2015, 10, 21, 7, 28, 0, 0, time.FixedZone("", 3600))`))
+		Ω(codegen.GoLiteral(UUID, "6ba7b810-9dad-11d1-80b4-00c04fd430c8")).
+			Should(Equal("goa.UUID{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}"))
+	})
+
+	It("renders arrays", func() {
+		a := &Array{ElemType: &AttributeDefinition{Type: String}}
+		Ω(codegen.GoLiteral(a, []interface{}{"a", "b"})).Should(Equal(`[]string{"a", "b"}`))
+	})
+
+	It("renders user types", func() {
+		ut := &UserTypeDefinition{TypeName: "foo", AttributeDefinition: &AttributeDefinition{Type: String}}
+		Ω(codegen.GoLiteral(ut, "bar")).Should(Equal(`Foo("bar")`))
+	})
+
+	It("does not render objects", func() {
+		o := Object{"foo": &AttributeDefinition{Type: String}}
+		Ω(codegen.GoLiteral(o, map[string]interface{}{"foo": "bar"})).Should(BeEmpty())
+	})
+})
+
 const (
 	arrayMarshaled = `	tmp1 := make([]int, len(raw))
 	for i, r := range raw {
@@ -541,6 +644,25 @@ const (
 		err = goa.InvalidAttributeTypeError(` + "``" + `, raw, "dictionary", err)
 	}`
 
+	defaultUnmarshaled = `	if val, ok := raw.(map[string]interface{}); ok {
+		p = new(struct {
+			Foo int
+		})
+		if v, ok := val["foo"]; ok {
+			var tmp1 int
+			if f, ok := v.(float64); ok {
+				tmp1 = int(f)
+			} else {
+				err = goa.InvalidAttributeTypeError(` + "`" + `.Foo` + "`" + `, v, "int", err)
+			}
+			p.Foo = tmp1
+		} else {
+			p.Foo = 42
+		}
+	} else {
+		err = goa.InvalidAttributeTypeError(` + "``" + `, raw, "dictionary", err)
+	}`
+
 	complexMarshaled = `	tmp1 := map[string]interface{}{
 		"faz": raw.Faz,
 	}
@@ -2,6 +2,8 @@ package codegen
 
 import (
 	"fmt"
+	"hash/fnv"
+	"math"
 	"strings"
 	"text/template"
 
@@ -9,13 +11,16 @@ import (
 )
 
 var (
-	arrayValT    *template.Template
-	enumValT     *template.Template
-	formatValT   *template.Template
-	patternValT  *template.Template
-	minMaxValT   *template.Template
-	lengthValT   *template.Template
-	requiredValT *template.Template
+	arrayValT       *template.Template
+	enumValT        *template.Template
+	formatValT      *template.Template
+	patternValT     *template.Template
+	minMaxValT      *template.Template
+	multipleOfValT  *template.Template
+	lengthValT      *template.Template
+	uniqueItemsValT *template.Template
+	propertiesValT  *template.Template
+	requiredValT    *template.Template
 )
 
 //  init instantiates the templates.
@@ -29,6 +34,8 @@ func init() {
 		"goify":            Goify,
 		"add":              func(a, b int) int { return a + b },
 		"recursiveChecker": RecursiveChecker,
+		"validator":        validator,
+		"patternVar":       PatternVar,
 	}
 	if arrayValT, err = template.New("array").Funcs(fm).Parse(arrayValTmpl); err != nil {
 		panic(err)
@@ -45,14 +52,92 @@ func init() {
 	if minMaxValT, err = template.New("minMax").Funcs(fm).Parse(minMaxValTmpl); err != nil {
 		panic(err)
 	}
+	if multipleOfValT, err = template.New("multipleOf").Funcs(fm).Parse(multipleOfValTmpl); err != nil {
+		panic(err)
+	}
 	if lengthValT, err = template.New("length").Funcs(fm).Parse(lengthValTmpl); err != nil {
 		panic(err)
 	}
+	if uniqueItemsValT, err = template.New("uniqueItems").Funcs(fm).Parse(uniqueItemsValTmpl); err != nil {
+		panic(err)
+	}
+	if propertiesValT, err = template.New("properties").Funcs(fm).Parse(propertiesValTmpl); err != nil {
+		panic(err)
+	}
 	if requiredValT, err = template.New("required").Funcs(fm).Parse(requiredValTmpl); err != nil {
 		panic(err)
 	}
 }
 
+// PatternVar returns the name of the package variable that holds the compiled regular expression p
+// in the generated code. The generated pattern validations refer to these variables so that the
+// regular expressions get compiled once. The name only depends on p so that generators can define
+// the variables using the table built by CollectPatterns without sharing any state.
+func PatternVar(p string) string {
+	h := fnv.New64a()
+	h.Write([]byte(p))
+	return fmt.Sprintf("pattern%x", h.Sum64())
+}
+
+// CollectPatterns adds the regular expressions used by the pattern validations of the given
+// attribute and of the attributes it contains to patterns, indexed by the name of the variable
+// that holds them (see PatternVar).
+func CollectPatterns(att *design.AttributeDefinition, patterns map[string]string) {
+	walkValidations(att, func(v design.ValidationDefinition) {
+		if pv, ok := v.(*design.PatternValidationDefinition); ok {
+			patterns[PatternVar(pv.Pattern)] = pv.Pattern
+		}
+	})
+}
+
+// CollectCustomFormats adds the names of the custom formats used by the format validations of the
+// given attribute and of the attributes it contains to formats. Custom formats are the formats
+// that the application registers with goa.RegisterFormat.
+func CollectCustomFormats(att *design.AttributeDefinition, formats map[string]bool) {
+	walkValidations(att, func(v design.ValidationDefinition) {
+		if fv, ok := v.(*design.FormatValidationDefinition); ok && validator(fv.Format) == "" {
+			formats[fv.Format] = true
+		}
+	})
+}
+
+// walkValidations calls fn with the validations of the given attribute and of the attributes it
+// contains. User types are only visited once so that recursive types get walked safely.
+func walkValidations(att *design.AttributeDefinition, fn func(design.ValidationDefinition)) {
+	seen := make(map[*design.UserTypeDefinition]bool)
+	var walk func(*design.AttributeDefinition)
+	walk = func(att *design.AttributeDefinition) {
+		if att == nil {
+			return
+		}
+		for _, v := range att.Validations {
+			fn(v)
+		}
+		switch actual := att.Type.(type) {
+		case design.Object:
+			for _, catt := range actual {
+				walk(catt)
+			}
+		case *design.Array:
+			walk(actual.ElemType)
+		case *design.Hash:
+			walk(actual.KeyType)
+			walk(actual.ElemType)
+		case *design.UserTypeDefinition:
+			if !seen[actual] {
+				seen[actual] = true
+				walk(actual.AttributeDefinition)
+			}
+		case *design.MediaTypeDefinition:
+			if !seen[actual.UserTypeDefinition] {
+				seen[actual.UserTypeDefinition] = true
+				walk(actual.AttributeDefinition)
+			}
+		}
+	}
+	walk(att)
+}
+
 // RecursiveChecker produces Go code that runs the validation checks recursively over the given
 // attribute.
 func RecursiveChecker(att *design.AttributeDefinition, required bool, target, context string, depth int) string {
@@ -124,17 +209,26 @@ func ValidationChecker(att *design.AttributeDefinition, required bool, target, c
 				res = append(res, val)
 			}
 		case *design.MinimumValidationDefinition:
-			data["min"] = actual.Min
-			delete(data, "max")
+			data["isMin"] = true
+			data["limit"] = actual.Min
+			data["exclusive"] = actual.Exclusive
 			if val := RunTemplate(minMaxValT, data); val != "" {
 				res = append(res, val)
 			}
 		case *design.MaximumValidationDefinition:
-			data["max"] = actual.Max
-			delete(data, "min")
+			data["isMin"] = false
+			data["limit"] = actual.Max
+			data["exclusive"] = actual.Exclusive
 			if val := RunTemplate(minMaxValT, data); val != "" {
 				res = append(res, val)
 			}
+		case *design.MultipleOfValidationDefinition:
+			data["multipleOf"] = actual.MultipleOf
+			data["integral"] = att.Type.Kind() == design.IntegerKind &&
+				actual.MultipleOf == math.Trunc(actual.MultipleOf)
+			if val := RunTemplate(multipleOfValT, data); val != "" {
+				res = append(res, val)
+			}
 		case *design.MinLengthValidationDefinition:
 			data["minLength"] = actual.MinLength
 			delete(data, "maxLength")
@@ -147,6 +241,22 @@ func ValidationChecker(att *design.AttributeDefinition, required bool, target, c
 			if val := RunTemplate(lengthValT, data); val != "" {
 				res = append(res, val)
 			}
+		case *design.UniqueItemsValidationDefinition:
+			if val := RunTemplate(uniqueItemsValT, data); val != "" {
+				res = append(res, val)
+			}
+		case *design.MinPropertiesValidationDefinition:
+			data["isMin"] = true
+			data["limit"] = actual.MinProperties
+			if val := RunTemplate(propertiesValT, data); val != "" {
+				res = append(res, val)
+			}
+		case *design.MaxPropertiesValidationDefinition:
+			data["isMin"] = false
+			data["limit"] = actual.MaxProperties
+			if val := RunTemplate(propertiesValT, data); val != "" {
+				res = append(res, val)
+			}
 		case *design.RequiredValidationDefinition:
 			data["required"] = actual.Names
 			if val := RunTemplate(requiredValT, data); val != "" {
@@ -167,7 +277,35 @@ func oneof(target string, vals []interface{}) string {
 	return strings.Join(elems, " || ")
 }
 
-// constant returns the Go constant name of the format with the given value.
+// validator returns the name of the goa function that validates values of the built-in format
+// with the given name, an empty string if the format is a custom format. Custom formats get
+// validated with goa.ValidateFormat which calls the validator registered by the application.
+func validator(formatName string) string {
+	switch formatName {
+	case "date-time":
+		return "goa.ValidateDateTime"
+	case "email":
+		return "goa.ValidateEmail"
+	case "hostname":
+		return "goa.ValidateHostname"
+	case "ipv4":
+		return "goa.ValidateIPv4"
+	case "ipv6":
+		return "goa.ValidateIPv6"
+	case "uri":
+		return "goa.ValidateURI"
+	case "mac":
+		return "goa.ValidateMAC"
+	case "cidr":
+		return "goa.ValidateCIDR"
+	case "regexp":
+		return "goa.ValidateRegexp"
+	}
+	return ""
+}
+
+// constant returns the Go constant name of the format with the given value or a conversion of
+// the value to goa.Format for custom formats.
 func constant(formatName string) string {
 	switch formatName {
 	case "date-time":
@@ -189,7 +327,7 @@ func constant(formatName string) string {
 	case "regexp":
 		return "goa.FormatRegexp"
 	}
-	panic("unknown format") // bug
+	return fmt.Sprintf("goa.Format(%q)", formatName)
 }
 
 const (
@@ -205,28 +343,42 @@ const (
 {{end}}{{tabs .depth}}}`
 
 	patternValTmpl = `{{$depth := or (and (not .required) (add .depth 1)) .depth}}{{if not .required}}{{tabs .depth}}if {{.target}} != "" {
-{{end}}{{tabs $depth}}if ok := goa.ValidatePattern(` + "`{{.pattern}}`" + `, {{.target}}); !ok {
+{{end}}{{tabs $depth}}if ok := {{patternVar .pattern}}.MatchString({{.target}}); !ok {
 {{tabs $depth}}	err = goa.InvalidPatternError(` + "`" + `{{.context}}` + "`" + `, {{.target}}, ` + "`{{.pattern}}`" + `, err)
 {{tabs $depth}}}{{if not .required}}
 {{tabs .depth}}}{{end}}`
 
 	formatValTmpl = `{{$depth := or (and (not .required) (add .depth 1)) .depth}}{{ if not .required}}{{tabs .depth}}if {{.target}} != "" {
-{{end}}{{tabs $depth}}if err2 := goa.ValidateFormat({{constant .format}}, {{.target}}); err2 != nil {
-{{tabs $depth}}		err = goa.InvalidFormatError(` + "`" + `{{.context}}` + "`" + `, {{.target}}, {{constant .format}}, err2, err)
-{{if not .required}}{{tabs $depth}}	}
+{{end}}{{tabs $depth}}if err2 := {{with validator .format}}{{.}}({{$.target}}){{else}}goa.ValidateFormat({{constant .format}}, {{.target}}){{end}}; err2 != nil {
+{{tabs $depth}}	err = goa.InvalidFormatError(` + "`" + `{{.context}}` + "`" + `, {{.target}}, {{constant .format}}, err2, err)
+{{if not .required}}{{tabs $depth}}}
 {{end}}{{tabs .depth}}}`
 
-	minMaxValTmpl = `{{$depth := or (and (not .required) (add .depth 1)) .depth}}{{tabs .depth}}if {{.target}} {{if .min}}<{{else}}>{{end}} {{if .min}}{{.min}}{{else}}{{.max}}{{end}} {
-{{tabs $depth}}	err = goa.InvalidRangeError(` + "`" + `{{.context}}` + "`" + `, {{.target}}, {{if .min}}{{.min}}, true{{else}}{{.max}}, false{{end}}, err)
+	minMaxValTmpl = `{{$depth := or (and (not .required) (add .depth 1)) .depth}}{{tabs .depth}}if {{.target}} {{if .isMin}}<{{else}}>{{end}}{{if .exclusive}}={{end}} {{.limit}} {
+{{tabs $depth}}	err = goa.{{if .exclusive}}InvalidExclusiveRangeError{{else}}InvalidRangeError{{end}}(` + "`" + `{{.context}}` + "`" + `, {{.target}}, {{.limit}}, {{.isMin}}, err)
+{{tabs .depth}}}`
+
+	multipleOfValTmpl = `{{tabs .depth}}if {{if .integral}}{{.target}}%{{.multipleOf}} != 0{{else}}!goa.IsMultipleOf({{if eq .attribute.Type.Kind 2}}float64({{.target}}){{else}}{{.target}}{{end}}, {{.multipleOf}}){{end}} {
+{{tabs .depth}}	err = goa.InvalidMultipleOfError(` + "`" + `{{.context}}` + "`" + `, {{.target}}, {{.multipleOf}}, err)
 {{tabs .depth}}}`
 
 	lengthValTmpl = `{{$depth := or (and (not .required) (add .depth 1)) .depth}}{{tabs .depth}}if len({{.target}}) {{if .minLength}}<{{else}}>{{end}} {{if .minLength}}{{.minLength}}{{else}}{{.maxLength}}{{end}} {
 {{tabs $depth}}	err = goa.InvalidLengthError(` + "`" + `{{.context}}` + "`" + `, {{.target}}, {{if .minLength}}{{.minLength}}, true{{else}}{{.maxLength}}, false{{end}}, err)
 {{tabs .depth}}}`
 
+	uniqueItemsValTmpl = `{{tabs .depth}}if !goa.HasUniqueItems({{.target}}) {
+{{tabs .depth}}	err = goa.InvalidUniqueItemsError(` + "`" + `{{.context}}` + "`" + `, {{.target}}, err)
+{{tabs .depth}}}`
+
+	propertiesValTmpl = `{{$depth := or (and (not .required) (add .depth 1)) .depth}}{{if not .required}}{{tabs .depth}}if {{.target}} != nil {
+{{end}}{{tabs $depth}}if len({{.target}}) {{if .isMin}}<{{else}}>{{end}} {{.limit}} {
+{{tabs $depth}}	err = goa.InvalidPropertyCountError(` + "`" + `{{.context}}` + "`" + `, {{.target}}, len({{.target}}), {{.limit}}, {{.isMin}}, err)
+{{tabs $depth}}}{{if not .required}}
+{{tabs .depth}}}{{end}}`
+
 	requiredValTmpl = `{{$ctx := .}}{{range $r := .required}}{{$catt := index $ctx.attribute.Type.ToObject $r}}{{if eq $catt.Type.Kind 4}}{{tabs $ctx.depth}}if {{$ctx.target}}.{{goify $r true}} == "" {
 {{tabs $ctx.depth}}	err = goa.MissingAttributeError(` + "`" + `{{$ctx.context}}` + "`" + `, "{{$r}}", err)
-{{tabs $ctx.depth}}}{{else if (not $catt.Type.IsPrimitive)}}{{tabs $ctx.depth}}if {{$ctx.target}}.{{goify $r true}} == nil {
+{{tabs $ctx.depth}}}{{else if or (not $catt.Type.IsPrimitive) (eq $catt.Type.Kind 13)}}{{tabs $ctx.depth}}if {{$ctx.target}}.{{goify $r true}} == nil {
 {{tabs $ctx.depth}}	err = goa.MissingAttributeError(` + "`" + `{{$ctx.context}}` + "`" + `, "{{$r}}", err)
 {{tabs $ctx.depth}}}{{end}}
 {{end}}`
@@ -54,6 +54,111 @@ var _ = Describe("validation code generation", func() {
 				It("produces the validation go code", func() {
 					Ω(code).Should(Equal(patternValCode))
 				})
+
+				It("names the pattern variables after the patterns", func() {
+					Ω(codegen.PatternVar(".*")).Should(Equal("pattern7da1607b4a03861"))
+					Ω(codegen.PatternVar("^a")).Should(Equal("pattern9572b07b5e46120"))
+				})
+			})
+
+			Context("of format", func() {
+				BeforeEach(func() {
+					attType = design.String
+					formatVal := &design.FormatValidationDefinition{
+						Format: "email",
+					}
+					validations = []design.ValidationDefinition{formatVal}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(formatValCode))
+				})
+
+				Context("using a custom format", func() {
+					BeforeEach(func() {
+						formatVal := &design.FormatValidationDefinition{
+							Format: "semver",
+						}
+						validations = []design.ValidationDefinition{formatVal}
+					})
+
+					It("calls the format registry", func() {
+						Ω(code).Should(Equal(customFormatValCode))
+					})
+				})
+			})
+
+			Context("of minimum", func() {
+				BeforeEach(func() {
+					attType = design.Integer
+					minVal := &design.MinimumValidationDefinition{Min: 0}
+					validations = []design.ValidationDefinition{minVal}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(minValCode))
+				})
+			})
+
+			Context("of exclusive maximum", func() {
+				BeforeEach(func() {
+					attType = design.Number
+					maxVal := &design.MaximumValidationDefinition{Max: 1.5, Exclusive: true}
+					validations = []design.ValidationDefinition{maxVal}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(exclusiveMaxValCode))
+				})
+			})
+
+			Context("of multiple of", func() {
+				BeforeEach(func() {
+					attType = design.Integer
+					multipleOfVal := &design.MultipleOfValidationDefinition{MultipleOf: 5}
+					validations = []design.ValidationDefinition{multipleOfVal}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(multipleOfValCode))
+				})
+
+				Context("with a fractional value", func() {
+					BeforeEach(func() {
+						multipleOfVal := &design.MultipleOfValidationDefinition{MultipleOf: 0.5}
+						validations = []design.ValidationDefinition{multipleOfVal}
+					})
+
+					It("produces the validation go code", func() {
+						Ω(code).Should(Equal(fractionalMultipleOfValCode))
+					})
+				})
+			})
+
+			Context("of unique items", func() {
+				BeforeEach(func() {
+					attType = &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}}
+					validations = []design.ValidationDefinition{&design.UniqueItemsValidationDefinition{}}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(uniqueItemsValCode))
+				})
+			})
+
+			Context("of min properties", func() {
+				BeforeEach(func() {
+					attType = &design.Hash{
+						KeyType:  &design.AttributeDefinition{Type: design.String},
+						ElemType: &design.AttributeDefinition{Type: design.Integer},
+					}
+					minPropsVal := &design.MinPropertiesValidationDefinition{MinProperties: 1}
+					validations = []design.ValidationDefinition{minPropsVal}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(minPropertiesValCode))
+				})
 			})
 		})
 	})
@@ -65,8 +170,46 @@ const (
 	}`
 
 	patternValCode = `	if val != "" {
-		if ok := goa.ValidatePattern(` + "`.*`" + `, val); !ok {
+		if ok := pattern7da1607b4a03861.MatchString(val); !ok {
 			err = goa.InvalidPatternError(` + "`context`" + `, val, ` + "`.*`" + `, err)
 		}
 	}`
+
+	formatValCode = `	if val != "" {
+		if err2 := goa.ValidateEmail(val); err2 != nil {
+			err = goa.InvalidFormatError(` + "`context`" + `, val, goa.FormatEmail, err2, err)
+		}
+	}`
+
+	customFormatValCode = `	if val != "" {
+		if err2 := goa.ValidateFormat(goa.Format("semver"), val); err2 != nil {
+			err = goa.InvalidFormatError(` + "`context`" + `, val, goa.Format("semver"), err2, err)
+		}
+	}`
+
+	minValCode = `	if val < 0 {
+			err = goa.InvalidRangeError(` + "`context`" + `, val, 0, true, err)
+	}`
+
+	exclusiveMaxValCode = `	if val >= 1.5 {
+			err = goa.InvalidExclusiveRangeError(` + "`context`" + `, val, 1.5, false, err)
+	}`
+
+	multipleOfValCode = `	if val%5 != 0 {
+		err = goa.InvalidMultipleOfError(` + "`context`" + `, val, 5, err)
+	}`
+
+	fractionalMultipleOfValCode = `	if !goa.IsMultipleOf(float64(val), 0.5) {
+		err = goa.InvalidMultipleOfError(` + "`context`" + `, val, 0.5, err)
+	}`
+
+	uniqueItemsValCode = `	if !goa.HasUniqueItems(val) {
+		err = goa.InvalidUniqueItemsError(` + "`context`" + `, val, err)
+	}`
+
+	minPropertiesValCode = `	if val != nil {
+		if len(val) < 1 {
+			err = goa.InvalidPropertyCountError(` + "`context`" + `, val, len(val), 1, true, err)
+		}
+	}`
 )
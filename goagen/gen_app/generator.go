@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/raphael/goa/design"
@@ -19,13 +21,20 @@ type Generator struct {
 	ContextsWriter      *ContextsWriter
 	ControllersWriter   *ControllersWriter
 	ResourcesWriter     *ResourcesWriter
+	ErrorsWriter        *ErrorsWriter
+	SecurityWriter      *SecurityWriter
 	MediaTypesWriter    *MediaTypesWriter
 	UserTypesWriter     *UserTypesWriter
+	ValidatorsWriter    *ValidatorsWriter
 	contextsFilename    string
 	controllersFilename string
 	resourcesFilename   string
+	errorsFilename      string
+	securityFilename    string
 	mediaTypesFilename  string
 	userTypesFilename   string
+	validatorsFilename  string
+	target              string
 	genfiles            []string
 }
 
@@ -50,14 +59,22 @@ func NewGenerator() (*Generator, error) {
 	}
 	outdir := AppOutputDir()
 	os.RemoveAll(outdir)
-	if err = os.MkdirAll(outdir, 0777); err != nil {
+	return newGenerator(outdir, TargetPackage)
+}
+
+// newGenerator returns a generator that writes the package with the given name in outdir.
+func newGenerator(outdir, target string) (*Generator, error) {
+	if err := os.MkdirAll(outdir, 0777); err != nil {
 		return nil, err
 	}
 	ctxFile := filepath.Join(outdir, "contexts.go")
 	ctlFile := filepath.Join(outdir, "controllers.go")
 	resFile := filepath.Join(outdir, "hrefs.go")
+	errFile := filepath.Join(outdir, "errors.go")
+	secFile := filepath.Join(outdir, "security.go")
 	mtFile := filepath.Join(outdir, "media_types.go")
 	utFile := filepath.Join(outdir, "user_types.go")
+	valFile := filepath.Join(outdir, "validators.go")
 
 	ctxWr, err := NewContextsWriter(ctxFile)
 	if err != nil {
@@ -71,6 +88,14 @@ func NewGenerator() (*Generator, error) {
 	if err != nil {
 		panic(err) // bug
 	}
+	errWr, err := NewErrorsWriter(errFile)
+	if err != nil {
+		panic(err) // bug
+	}
+	secWr, err := NewSecurityWriter(secFile)
+	if err != nil {
+		panic(err) // bug
+	}
 	mtWr, err := NewMediaTypesWriter(mtFile)
 	if err != nil {
 		panic(err) // bug
@@ -79,18 +104,29 @@ func NewGenerator() (*Generator, error) {
 	if err != nil {
 		panic(err) // bug
 	}
+	valWr, err := NewValidatorsWriter(valFile)
+	if err != nil {
+		panic(err) // bug
+	}
 	return &Generator{
 		GoGenerator:         codegen.NewGoGenerator(outdir),
 		ContextsWriter:      ctxWr,
 		ControllersWriter:   ctlWr,
 		ResourcesWriter:     resWr,
+		ErrorsWriter:        errWr,
+		SecurityWriter:      secWr,
 		MediaTypesWriter:    mtWr,
 		UserTypesWriter:     utWr,
+		ValidatorsWriter:    valWr,
 		contextsFilename:    ctxFile,
 		controllersFilename: ctlFile,
 		resourcesFilename:   resFile,
+		errorsFilename:      errFile,
+		securityFilename:    secFile,
 		mediaTypesFilename:  mtFile,
 		userTypesFilename:   utFile,
+		validatorsFilename:  valFile,
+		target:              target,
 		genfiles:            []string{outdir},
 	}, nil
 }
@@ -113,12 +149,35 @@ func (g *Generator) Generate(api *design.APIDefinition) (_ []string, err error)
 	if api == nil {
 		return nil, fmt.Errorf("missing API definition, make sure design.Design is properly initialized")
 	}
+	if err = g.generate(api); err != nil {
+		return
+	}
+	err = api.IterateVersions(func(v *design.APIDefinition) error {
+		pkg := codegen.VersionPackage(v.APIVersion.Name)
+		vg, err := newGenerator(filepath.Join(AppOutputDir(), pkg), pkg)
+		if err != nil {
+			return err
+		}
+		err = vg.generate(v)
+		g.genfiles = append(g.genfiles, vg.genfiles...)
+		return err
+	})
+	if err != nil {
+		return
+	}
+
+	return g.genfiles, nil
+}
+
+// generate writes the code of the given API or API version to the generator package.
+func (g *Generator) generate(api *design.APIDefinition) (err error) {
+	g.recordValidations(api)
 	title := fmt.Sprintf("%s: Application Contexts", api.Name)
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("github.com/raphael/goa"),
 		codegen.SimpleImport("strconv"),
 	}
-	g.ContextsWriter.WriteHeader(title, TargetPackage, imports)
+	g.ContextsWriter.WriteHeader(title, g.target, imports)
 	err = api.IterateResources(func(r *design.ResourceDefinition) error {
 		return r.IterateActions(func(a *design.ActionDefinition) error {
 			ctxName := codegen.Goify(a.Name, true) + codegen.Goify(a.Parent.Name, true) + "Context"
@@ -127,6 +186,8 @@ func (g *Generator) Generate(api *design.APIDefinition) (_ []string, err error)
 				ResourceName: r.Name,
 				ActionName:   a.Name,
 				Payload:      a.Payload,
+				Multipart:    a.PayloadMultipart,
+				TypedDecode:  typedDecode(a),
 				Params:       a.AllParams(),
 				Headers:      r.Headers.Merge(a.Headers),
 				Routes:       a.Routes,
@@ -149,16 +210,31 @@ func (g *Generator) Generate(api *design.APIDefinition) (_ []string, err error)
 		codegen.SimpleImport("github.com/julienschmidt/httprouter"),
 		codegen.SimpleImport("github.com/raphael/goa"),
 	}
-	g.ControllersWriter.WriteHeader(title, TargetPackage, imports)
+	g.ControllersWriter.WriteHeader(title, g.target, imports)
 	var controllersData []*ControllerTemplateData
 	api.IterateResources(func(r *design.ResourceDefinition) error {
-		data := &ControllerTemplateData{Resource: codegen.Goify(r.Name, true)}
+		data := &ControllerTemplateData{
+			Resource:     codegen.Goify(r.Name, true),
+			CheckFormats: len(g.ValidatorsWriter.Formats) > 0,
+		}
+		if v := api.APIVersion; v != nil {
+			data.Version = v.Name
+			data.VersionSelector = versionSelector(api)
+		}
 		err := r.IterateActions(func(a *design.ActionDefinition) error {
 			context := fmt.Sprintf("%s%sContext", codegen.Goify(a.Name, true), codegen.Goify(r.Name, true))
 			action := map[string]interface{}{
-				"Name":    codegen.Goify(a.Name, true),
-				"Routes":  a.Routes,
-				"Context": context,
+				"Name":        codegen.Goify(a.Name, true),
+				"Routes":      a.Routes,
+				"Context":     context,
+				"Consumes":    a.ConsumedMediaTypes(),
+				"Security":    a.SecurityRequirement(),
+				"Deprecation": a.Deprecated(),
+				"MaxBodySize": a.BodySizeLimit(),
+				"RawBody":     a.RawBody,
+			}
+			if typedDecode(a) {
+				action["DecodeFunc"] = "Decode" + codegen.GoTypeName(a.Payload, 0)
 			}
 			data.Actions = append(data.Actions, action)
 			return nil
@@ -180,7 +256,9 @@ func (g *Generator) Generate(api *design.APIDefinition) (_ []string, err error)
 	}
 
 	title = fmt.Sprintf("%s: Application Resource Href Factories", api.Name)
-	g.ResourcesWriter.WriteHeader(title, TargetPackage, nil)
+	imports = []*codegen.ImportSpec{codegen.SimpleImport("github.com/raphael/goa")}
+	g.ResourcesWriter.WriteHeader(title, g.target, imports)
+	var hrefRoutes []*ActionRouteData
 	err = api.IterateResources(func(r *design.ResourceDefinition) error {
 		m := api.MediaTypeWithIdentifier(r.MediaType)
 		var identifier string
@@ -205,6 +283,12 @@ func (g *Generator) Generate(api *design.APIDefinition) (_ []string, err error)
 			Type:              m,
 			CanonicalTemplate: canoTemplate,
 			CanonicalParams:   canoParams,
+			Routes:            routesData(r),
+		}
+		for _, route := range data.Routes {
+			if route.Verb == "GET" {
+				hrefRoutes = append(hrefRoutes, route)
+			}
 		}
 		return g.ResourcesWriter.Execute(&data)
 	})
@@ -212,16 +296,75 @@ func (g *Generator) Generate(api *design.APIDefinition) (_ []string, err error)
 	if err != nil {
 		return
 	}
+	sort.Stable(routesBySpecificity(hrefRoutes))
+	if err = g.ResourcesWriter.ExecuteParser(hrefRoutes); err != nil {
+		return
+	}
 	if err = g.ResourcesWriter.FormatCode(); err != nil {
 		return
 	}
 
+	var kinds []*ErrorKindTemplateData
+	api.IterateErrorKinds(func(k *design.ErrorKindDefinition) error {
+		data := ErrorKindTemplateData{Name: k.Name, Description: k.Description}
+		if resp := k.ResponseDefinition(); resp != nil {
+			data.Status = resp.Status
+			if mt := api.MediaTypeWithIdentifier(resp.MediaType); mt != nil && mt != api.ErrorMedia {
+				data.MediaType = mt
+			}
+		}
+		kinds = append(kinds, &data)
+		return nil
+	})
+	if len(kinds) > 0 {
+		title = fmt.Sprintf("%s: Application Errors", api.Name)
+		imports = []*codegen.ImportSpec{codegen.SimpleImport("github.com/raphael/goa")}
+		for _, k := range kinds {
+			if k.MediaType != nil {
+				imports = append(imports, codegen.SimpleImport("fmt"))
+				break
+			}
+		}
+		g.ErrorsWriter.WriteHeader(title, g.target, imports)
+		for _, k := range kinds {
+			if err = g.ErrorsWriter.Execute(k); err != nil {
+				break
+			}
+		}
+		g.genfiles = append(g.genfiles, g.errorsFilename)
+		if err != nil {
+			return
+		}
+		if err = g.ErrorsWriter.FormatCode(); err != nil {
+			return
+		}
+	}
+
+	if len(api.SecuritySchemes) > 0 {
+		title = fmt.Sprintf("%s: Application Security", api.Name)
+		imports = []*codegen.ImportSpec{
+			codegen.SimpleImport("fmt"),
+			codegen.SimpleImport("github.com/raphael/goa"),
+		}
+		g.SecurityWriter.WriteHeader(title, g.target, imports)
+		err = api.IterateSecuritySchemes(func(s *design.SecuritySchemeDefinition) error {
+			return g.SecurityWriter.Execute(s)
+		})
+		g.genfiles = append(g.genfiles, g.securityFilename)
+		if err != nil {
+			return
+		}
+		if err = g.SecurityWriter.FormatCode(); err != nil {
+			return
+		}
+	}
+
 	title = fmt.Sprintf("%s: Application Media Types", api.Name)
 	imports = []*codegen.ImportSpec{
 		codegen.SimpleImport("github.com/raphael/goa"),
 		codegen.SimpleImport("fmt"),
 	}
-	g.MediaTypesWriter.WriteHeader(title, TargetPackage, imports)
+	g.MediaTypesWriter.WriteHeader(title, g.target, imports)
 	err = api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
 		if mt.Type.IsObject() || mt.Type.IsArray() {
 			return g.MediaTypesWriter.Execute(mt)
@@ -237,7 +380,7 @@ func (g *Generator) Generate(api *design.APIDefinition) (_ []string, err error)
 	}
 
 	title = fmt.Sprintf("%s: Application User Types", api.Name)
-	g.UserTypesWriter.WriteHeader(title, TargetPackage, nil)
+	g.UserTypesWriter.WriteHeader(title, g.target, nil)
 	err = api.IterateUserTypes(func(t *design.UserTypeDefinition) error {
 		return g.UserTypesWriter.Execute(t)
 	})
@@ -249,7 +392,49 @@ func (g *Generator) Generate(api *design.APIDefinition) (_ []string, err error)
 		return
 	}
 
-	return g.genfiles, nil
+	if len(g.ValidatorsWriter.Patterns) > 0 || len(g.ValidatorsWriter.Formats) > 0 {
+		title = fmt.Sprintf("%s: Application Validators", api.Name)
+		imports = []*codegen.ImportSpec{
+			codegen.SimpleImport("regexp"),
+			codegen.SimpleImport("github.com/raphael/goa"),
+		}
+		g.ValidatorsWriter.WriteHeader(title, g.target, imports)
+		err = g.ValidatorsWriter.Execute()
+		g.genfiles = append(g.genfiles, g.validatorsFilename)
+		if err != nil {
+			return
+		}
+		if err = g.ValidatorsWriter.FormatCode(); err != nil {
+			return
+		}
+	}
+
+	return nil
+}
+
+// recordValidations records the regular expressions and custom formats used by the validations of
+// the attributes of the given API in the validators writer.
+func (g *Generator) recordValidations(api *design.APIDefinition) {
+	w := g.ValidatorsWriter
+	api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		w.AddValidations(ut.AttributeDefinition)
+		return nil
+	})
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		w.AddValidations(mt.AttributeDefinition)
+		return nil
+	})
+	api.IterateResources(func(r *design.ResourceDefinition) error {
+		w.AddValidations(r.Headers)
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			w.AddValidations(a.AllParams())
+			w.AddValidations(a.Headers)
+			if a.Payload != nil {
+				w.AddValidations(a.Payload.AttributeDefinition)
+			}
+			return nil
+		})
+	})
 }
 
 // Cleanup removes the entire "app" directory if it was created by this generator.
@@ -261,6 +446,61 @@ func (g *Generator) Cleanup() {
 	g.genfiles = nil
 }
 
+// versionSelector returns the code of the goa.SelectVersionFunc that selects the API versions as
+// described by the API design.
+func versionSelector(api *design.APIDefinition) string {
+	var funcs []string
+	if api.VersionPrefix != "" {
+		funcs = append(funcs, fmt.Sprintf("goa.PathSelectVersionFunc(%q)", api.VersionPathPrefix()))
+	}
+	if api.VersionHeader != "" {
+		funcs = append(funcs, fmt.Sprintf("goa.HeaderSelectVersionFunc(%q)", api.VersionHeader))
+	}
+	if api.VersionQuery != "" {
+		funcs = append(funcs, fmt.Sprintf("goa.QuerySelectVersionFunc(%q)", api.VersionQuery))
+	}
+	if len(funcs) == 1 {
+		return funcs[0]
+	}
+	return fmt.Sprintf("goa.CombineSelectVersionFunc(%s)", strings.Join(funcs, ", "))
+}
+
+// typedDecode returns true if the JSON request bodies of the given action can be decoded directly
+// into the action payload type. This is the case when the payload is an object whose attributes
+// are all primitive, date time, UUID, array or hash values. Payloads with attributes that are
+// objects (inline or user types) are decoded generically and coerced by New<Payload> instead.
+func typedDecode(a *design.ActionDefinition) bool {
+	if a.Payload == nil || a.PayloadMultipart || a.RawBody {
+		return false
+	}
+	o := a.Payload.ToObject()
+	if o == nil {
+		return false
+	}
+	for _, att := range o {
+		if !decodable(att.Type) {
+			return false
+		}
+	}
+	return true
+}
+
+// decodable returns true if values of the given type decode into their Go native type the same
+// way the generated unmarshalers coerce them.
+func decodable(t design.DataType) bool {
+	switch t.Kind() {
+	case design.BooleanKind, design.IntegerKind, design.NumberKind, design.StringKind,
+		design.DateTimeKind, design.UUIDKind, design.AnyKind:
+		return true
+	case design.ArrayKind:
+		return decodable(t.ToArray().ElemType.Type)
+	case design.HashKind:
+		h := t.ToHash()
+		return h.KeyType.Type.Kind() == design.StringKind && decodable(h.ElemType.Type)
+	}
+	return false
+}
+
 // MergeResponses merge the response maps overriding the first argument map entries with the
 // second argument map entries in case of collision.
 func MergeResponses(l, r map[string]*design.ResponseDefinition) map[string]*design.ResponseDefinition {
@@ -275,3 +515,102 @@ func MergeResponses(l, r map[string]*design.ResponseDefinition) map[string]*desi
 	}
 	return l
 }
+
+// routesData builds the data used to generate the path builders and href parser of the given
+// resource action routes. The path parameter types are looked up in the action parameters
+// including the resource and API base parameters, parameters that are not defined there are
+// strings.
+func routesData(r *design.ResourceDefinition) []*ActionRouteData {
+	var routes []*ActionRouteData
+	r.IterateActions(func(a *design.ActionDefinition) error {
+		params := a.AllParams().Type.ToObject()
+		for i, route := range a.Routes {
+			name := codegen.Goify(a.Name, true) + codegen.Goify(r.Name, true) + "Path"
+			if i > 0 {
+				name = fmt.Sprintf("%s%d", name, i+1)
+			}
+			path := route.FullPath()
+			data := &ActionRouteData{
+				Name:     name,
+				Resource: r.Name,
+				Action:   a.Name,
+				Verb:     route.Verb,
+				Path:     path,
+				Template: design.WildcardRegex.ReplaceAllStringFunc(path, pathFormat),
+				Pattern:  routePattern(path),
+			}
+			for _, m := range design.WildcardRegex.FindAllStringSubmatch(path, -1) {
+				att, ok := params[m[1]]
+				if !ok {
+					att = &design.AttributeDefinition{Type: design.String}
+				}
+				data.Params = append(data.Params, &PathParamData{
+					Name:      m[1],
+					Attribute: att,
+					CatchAll:  strings.HasPrefix(m[0], "/*"),
+				})
+			}
+			routes = append(routes, data)
+		}
+		return nil
+	})
+	return routes
+}
+
+// routesBySpecificity implements sort.Interface to sort routes so that the most specific routes
+// come first. At the first path segment where two routes differ a literal segment comes before a
+// parameter which comes before a catch-all parameter, e.g. "/bottles/latest" before "/bottles/:id".
+type routesBySpecificity []*ActionRouteData
+
+func (r routesBySpecificity) Len() int      { return len(r) }
+func (r routesBySpecificity) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r routesBySpecificity) Less(i, j int) bool {
+	si := strings.Split(strings.Trim(r[i].Path, "/"), "/")
+	sj := strings.Split(strings.Trim(r[j].Path, "/"), "/")
+	for k := 0; k < len(si) && k < len(sj); k++ {
+		if ri, rj := segmentRank(si[k]), segmentRank(sj[k]); ri != rj {
+			return ri < rj
+		}
+	}
+	return len(si) > len(sj)
+}
+
+// segmentRank returns 0 for literal path segments, 1 for parameters and 2 for catch-all
+// parameters.
+func segmentRank(segment string) int {
+	switch {
+	case strings.HasPrefix(segment, "*"):
+		return 2
+	case strings.HasPrefix(segment, ":"):
+		return 1
+	}
+	return 0
+}
+
+// pathFormat returns the fmt.Sprintf format that replaces the given path wildcard. The values of
+// catch-all wildcards include the leading slash.
+func pathFormat(wildcard string) string {
+	if strings.HasPrefix(wildcard, "/*") {
+		return "%s"
+	}
+	return "/%s"
+}
+
+// routePattern returns the regular expression that matches the escaped paths of the route with
+// the given full path and captures the path parameter values. The values of catch-all wildcards
+// include the leading slash.
+func routePattern(path string) string {
+	var pattern string
+	last := 0
+	for _, loc := range design.WildcardRegex.FindAllStringIndex(path, -1) {
+		pattern += regexp.QuoteMeta(path[last:loc[0]])
+		if path[loc[0]+1] == '*' {
+			pattern += "(/.*)"
+		} else {
+			pattern += "/([^/]+)"
+		}
+		last = loc[1]
+	}
+	pattern += regexp.QuoteMeta(path[last:])
+	return "^" + pattern + "/?$"
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"text/template"
@@ -205,6 +206,171 @@ var _ = Describe("Generate", func() {
 			isSource("media_types.go", mediaTypesCode)
 		})
 	})
+
+	Context("with actions that have payloads", func() {
+		BeforeEach(func() {
+			res := design.ResourceDefinition{
+				Name:      "Widget",
+				BasePath:  "/widgets",
+				MediaType: "plain/text",
+			}
+			createPayload := &design.UserTypeDefinition{
+				TypeName: "CreateWidgetPayload",
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{
+						"id":      {Type: design.UUID},
+						"created": {Type: design.DateTime},
+						"count":   {Type: design.Integer},
+						"tags":    {Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}}},
+						"labels": {Type: &design.Hash{
+							KeyType:  &design.AttributeDefinition{Type: design.String},
+							ElemType: &design.AttributeDefinition{Type: design.Integer},
+						}},
+					},
+				},
+			}
+			updatePayload := &design.UserTypeDefinition{
+				TypeName: "UpdateWidgetPayload",
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{
+						"name": {Type: design.String},
+						"part": {Type: design.Object{"code": {
+							Type: design.String,
+							Validations: []design.ValidationDefinition{
+								&design.PatternValidationDefinition{Pattern: "^[a-z]+$"},
+							},
+						}}},
+					},
+				},
+			}
+			create := design.ActionDefinition{Name: "create", Parent: &res, Payload: createPayload}
+			create.Routes = []*design.RouteDefinition{{Verb: "POST", Path: "", Parent: &create}}
+			update := design.ActionDefinition{Name: "update", Parent: &res, Payload: updatePayload}
+			update.Routes = []*design.RouteDefinition{{Verb: "PUT", Path: "/:id", Parent: &update}}
+			res.Actions = map[string]*design.ActionDefinition{"create": &create, "update": &update}
+			design.Design = &design.APIDefinition{
+				Name:      "test api",
+				Resources: map[string]*design.ResourceDefinition{"Widget": &res},
+			}
+		})
+
+		It("decodes the payloads without object attributes directly", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "contexts.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).Should(ContainSubstring("func DecodeCreateWidgetPayload(dec goa.Decoder) (interface{}, error) {"))
+			Ω(code).Should(MatchRegexp(`Created \*time\.Time +` + "`json:\"created\"`"))
+			Ω(code).Should(MatchRegexp(`ID +\*goa\.UUID +` + "`json:\"id\"`"))
+			Ω(code).Should(MatchRegexp(`Labels +\*map\[string\]int ` + "`json:\"labels\"`"))
+			content, err = ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`ctrl.SetDecodeFunc("Create", DecodeCreateWidgetPayload)`))
+		})
+
+		It("falls back to generic decoding for payloads with object attributes", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "contexts.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).Should(ContainSubstring("func NewUpdateWidgetPayload(raw interface{}) (p *UpdateWidgetPayload, err error) {"))
+			Ω(code).ShouldNot(ContainSubstring("DecodeUpdateWidgetPayload"))
+			content, err = ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).ShouldNot(ContainSubstring(`ctrl.SetDecodeFunc("Update"`))
+		})
+
+		It("reports the same errors when decoding payloads directly or generically", func() {
+			Ω(genErr).Should(BeNil())
+			appDir := filepath.Join(outDir, "app")
+			err := ioutil.WriteFile(filepath.Join(appDir, "parity_test.go"), []byte(decodeParityTest), 0644)
+			Ω(err).ShouldNot(HaveOccurred())
+			cmd := exec.Command("go", "test", "-run", "TestDecodeParity")
+			cmd.Dir = appDir
+			out, err := cmd.CombinedOutput()
+			Ω(err).ShouldNot(HaveOccurred(), string(out))
+		})
+
+		It("compiles the patterns used by nested attributes", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "validators.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("patternc182f89fdb221836 = regexp.MustCompile(`^[a-z]+$`)"))
+		})
+	})
+
+	Context("with routes that differ by a literal and a wildcard segment", func() {
+		BeforeEach(func() {
+			res := design.ResourceDefinition{
+				Name:      "Bottle",
+				BasePath:  "/bottles",
+				MediaType: "plain/text",
+			}
+			get := design.ActionDefinition{Name: "get", Parent: &res}
+			get.Routes = []*design.RouteDefinition{{Verb: "GET", Path: "/:id", Parent: &get}}
+			latest := design.ActionDefinition{Name: "latest", Parent: &res}
+			latest.Routes = []*design.RouteDefinition{{Verb: "GET", Path: "/latest", Parent: &latest}}
+			res.Actions = map[string]*design.ActionDefinition{"get": &get, "latest": &latest}
+			design.Design = &design.APIDefinition{
+				Name:      "test api",
+				Resources: map[string]*design.ResourceDefinition{"Bottle": &res},
+			}
+		})
+
+		It("matches hrefs against the literal route first", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "hrefs.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			latest := strings.Index(code, `{"Bottle", "latest", `)
+			get := strings.Index(code, `{"Bottle", "get", `)
+			Ω(latest).Should(BeNumerically(">", 0))
+			Ω(get).Should(BeNumerically(">", latest))
+		})
+	})
+
+	Context("with a versioned API", func() {
+		BeforeEach(func() {
+			res := design.ResourceDefinition{
+				Name:      "Widget",
+				BasePath:  "/widgets",
+				MediaType: "plain/text",
+				Version:   "1.0",
+			}
+			list := design.ActionDefinition{
+				Name:   "list",
+				Parent: &res,
+			}
+			list.Routes = []*design.RouteDefinition{{Verb: "GET", Path: "", Parent: &list}}
+			res.Actions = map[string]*design.ActionDefinition{"list": &list}
+			design.Design = &design.APIDefinition{
+				Name:          "test api",
+				VersionPrefix: "/v",
+				VersionHeader: "X-API-Version",
+				Versions: map[string]*design.APIVersionDefinition{
+					"1.0": {
+						Name:      "1.0",
+						Resources: map[string]*design.ResourceDefinition{"Widget": &res},
+					},
+				},
+			}
+		})
+
+		It("generates the version code in its own package", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(ContainElement(filepath.Join(outDir, "app", "v1_0", "controllers.go")))
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "v1_0", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).Should(ContainSubstring("package v1_0"))
+			Ω(code).Should(ContainSubstring(`service.SetVersionSelector(goa.CombineSelectVersionFunc(goa.PathSelectVersionFunc("/v"), goa.HeaderSelectVersionFunc("X-API-Version")))`))
+			Ω(code).Should(ContainSubstring(`service = service.Version("1.0")`))
+			Ω(code).Should(ContainSubstring(`router.Handle("GET", "/v1.0/widgets", ctrl.NewHTTPRouterHandle("List", h))`))
+			content, err = ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).ShouldNot(ContainSubstring("Widget"))
+		})
+	})
 })
 
 const contextsCodeTmpl = `//************************************************************************//
@@ -220,11 +386,7 @@ const contextsCodeTmpl = `//****************************************************
 
 package app
 
-import (
-	"fmt"
-
-	"github.com/raphael/goa"
-)
+import "github.com/raphael/goa"
 
 // GetWidgetContext provides the Widget get action context.
 type GetWidgetContext struct {
@@ -246,12 +408,7 @@ func NewGetWidgetContext(c *goa.Context) (*GetWidgetContext, error) {
 
 // OK sends a HTTP response with status code 200.
 func (ctx *GetWidgetContext) OK(resp ID) error {
-	r, err := resp.Dump()
-	if err != nil {
-		return fmt.Errorf("invalid response: %s", err)
-	}
-	ctx.Header().Set("Content-Type", "vnd.rightscale.codegen.test.widgets; charset=utf-8")
-	return ctx.JSON(200, r)
+	return ctx.SendMedia(200, "vnd.rightscale.codegen.test.widgets", resp, "")
 }
 `
 
@@ -308,12 +465,77 @@ const hrefsCodeTmpl = `//*******************************************************
 
 package app
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"github.com/raphael/goa"
+)
 
 // WidgetHref returns the resource href.
 func WidgetHref(id interface{}) string {
 	return fmt.Sprintf("/%v", id)
 }
+
+// GetWidgetPath returns the URL path to the Widget get action (GET).
+func GetWidgetPath(id string) string {
+	param0 := goa.EscapePathSegment(id)
+	return fmt.Sprintf("/%s", param0)
+}
+
+// Href describes the resource action identified by an href, see ParseHref.
+type Href struct {
+	// Resource is the name of the resource.
+	Resource string
+	// Action is the name of the action.
+	Action string
+	// Params maps the names of the action path parameters to their values.
+	Params map[string]interface{}
+}
+
+// hrefRoutes lists the action routes ParseHref matches hrefs against, most specific routes first.
+var hrefRoutes = []struct {
+	resource string
+	action   string
+	pattern  *regexp.Regexp
+	parse    func([]string) (map[string]interface{}, error)
+}{
+	{"Widget", "get", regexp.MustCompile(` + "`^/([^/]+)/?$`" + `), parseGetWidgetPath},
+}
+
+// ParseHref returns the resource action identified by the given href together with the values of
+// the action path parameters coerced to their design types. ParseHref only considers the action
+// routes that use the GET method.
+func ParseHref(href string) (*Href, error) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return nil, err
+	}
+	path := u.EscapedPath()
+	for _, r := range hrefRoutes {
+		if m := r.pattern.FindStringSubmatch(path); m != nil {
+			params, err := r.parse(m[1:])
+			if err != nil {
+				return nil, err
+			}
+			return &Href{Resource: r.resource, Action: r.action, Params: params}, nil
+		}
+	}
+	return nil, fmt.Errorf("no action matches href %#v", href)
+}
+
+// parseGetWidgetPath coerces the values of the Widget get action path parameters.
+func parseGetWidgetPath(raw []string) (map[string]interface{}, error) {
+	var err error
+	hrefParams := make(map[string]interface{}, 1)
+	rawID, err2 := goa.UnescapePathSegment(raw[0])
+	if err2 != nil {
+		return nil, err2
+	}
+	hrefParams["id"] = rawID
+	return hrefParams, err
+}
 `
 
 const mediaTypesCodeTmpl = `//************************************************************************//
@@ -329,3 +551,54 @@ const mediaTypesCodeTmpl = `//**************************************************
 
 package app
 `
+
+// decodeParityTest is a test run against the generated app package. It checks that decoding bad
+// bodies directly and generically produces the same error documents.
+const decodeParityTest = `package app
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/raphael/goa"
+)
+
+func TestDecodeParity(t *testing.T) {
+	bodies := []string{
+		` + "`" + `{"created":"yesterday"}` + "`" + `,
+		` + "`" + `{"id":"not-a-uuid"}` + "`" + `,
+		` + "`" + `{"count":"ten"}` + "`" + `,
+		` + "`" + `{"count":1.0}` + "`" + `,
+		` + "`" + `{"tags":["red",1]}` + "`" + `,
+		` + "`" + `"widget"` + "`" + `,
+	}
+	for _, body := range bodies {
+		typed, terr := DecodeCreateWidgetPayload(json.NewDecoder(strings.NewReader(body)))
+		var raw interface{}
+		if err := json.Unmarshal([]byte(body), &raw); err != nil {
+			t.Fatal(err)
+		}
+		generic, gerr := NewCreateWidgetPayload(raw)
+		if gerr != nil {
+			gerr = goa.NewBadRequestError(gerr)
+		}
+		if (terr == nil) != (gerr == nil) {
+			t.Errorf("%s: typed error %v, generic error %v", body, terr, gerr)
+			continue
+		}
+		if terr == nil {
+			if !reflect.DeepEqual(typed, generic) {
+				t.Errorf("%s: typed payload %#v, generic payload %#v", body, typed, generic)
+			}
+			continue
+		}
+		tdoc, _ := json.Marshal(goa.NewErrorDocument(400, terr))
+		gdoc, _ := json.Marshal(goa.NewErrorDocument(400, gerr))
+		if string(tdoc) != string(gdoc) {
+			t.Errorf("%s: typed error document %s, generic error document %s", body, tdoc, gdoc)
+		}
+	}
+}
+`
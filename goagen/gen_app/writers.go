@@ -1,9 +1,13 @@
 package genapp
 
 import (
+	"fmt"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/raphael/goa/design"
 	"github.com/raphael/goa/goagen/codegen"
@@ -21,6 +25,10 @@ type (
 		CtxRespTmpl    *template.Template
 		PayloadTmpl    *template.Template
 		NewPayloadTmpl *template.Template
+		// NewMultipartPayloadTmpl generates the factory of multipart payloads.
+		NewMultipartPayloadTmpl *template.Template
+		// DecodePayloadTmpl generates the function that decodes JSON bodies into the payload.
+		DecodePayloadTmpl *template.Template
 	}
 
 	// ControllersWriter generate code for a goa application handlers.
@@ -37,7 +45,24 @@ type (
 	// actions.
 	ResourcesWriter struct {
 		*codegen.GoGenerator
-		ResourceTmpl *template.Template
+		ResourceTmpl   *template.Template
+		HrefParserTmpl *template.Template
+	}
+
+	// ErrorsWriter generate code for a goa application error kinds.
+	// The generated constructors create errors that the controllers send using the responses
+	// associated with the error kinds in the design.
+	ErrorsWriter struct {
+		*codegen.GoGenerator
+		ErrorTmpl *template.Template
+	}
+
+	// SecurityWriter generate code for a goa application security schemes.
+	// The generated middlewares validate the request credentials using the functions registered
+	// by the application.
+	SecurityWriter struct {
+		*codegen.GoGenerator
+		SecurityTmpl *template.Template
 	}
 
 	// MediaTypesWriter generate code for a goa application media types.
@@ -54,6 +79,20 @@ type (
 		UserTypeTmpl *template.Template
 	}
 
+	// ValidatorsWriter generate code for the regular expressions used by the pattern validations
+	// of a goa application. The generated package compiles them once when it gets initialized.
+	// It also generates the function that checks that the application registered the custom
+	// formats used by the format validations.
+	ValidatorsWriter struct {
+		*codegen.GoGenerator
+		ValidatorsTmpl *template.Template
+		// Patterns lists the regular expressions used by the pattern validations indexed by
+		// the name of the variable that holds them, see codegen.PatternVar.
+		Patterns map[string]string
+		// Formats lists the custom formats used by the format validations.
+		Formats map[string]bool
+	}
+
 	// ContextTemplateData contains all the information used by the template to render the context
 	// code for an action.
 	ContextTemplateData struct {
@@ -62,6 +101,8 @@ type (
 		ActionName   string // e.g. "list"
 		Params       *design.AttributeDefinition
 		Payload      *design.UserTypeDefinition
+		Multipart    bool // true if the payload is sent using a multipart/form-data body
+		TypedDecode  bool // true if JSON bodies are decoded directly into the payload type
 		Headers      *design.AttributeDefinition
 		Routes       []*design.RouteDefinition
 		Responses    map[string]*design.ResponseDefinition
@@ -70,8 +111,20 @@ type (
 
 	// ControllerTemplateData contains the information required to generate an action handler.
 	ControllerTemplateData struct {
-		Resource string                   // Lower case plural resource name, e.g. "bottles"
-		Actions  []map[string]interface{} // Array of actions, each action has keys "Name", "Routes" and "Context"
+		Resource        string                   // Lower case plural resource name, e.g. "bottles"
+		Actions         []map[string]interface{} // Array of actions, each action has keys "Name", "Routes", "Context", "Consumes", "Security", "Deprecation", "MaxBodySize", "RawBody" and "DecodeFunc"
+		Version         string                   // Name of the API version that defines the resource if any
+		VersionSelector string                   // Code of the goa.SelectVersionFunc used to select the API version
+		CheckFormats    bool                     // Whether mounting checks the registration of the custom formats
+	}
+
+	// ErrorKindTemplateData contains the information required to generate the constructor of an
+	// error kind.
+	ErrorKindTemplateData struct {
+		Name        string                      // Name of error kind, e.g. "BottleNotFound"
+		Description string                      // Description of error kind
+		Status      int                         // Status code of error kind response
+		MediaType   *design.MediaTypeDefinition // Media type of error kind response body if any
 	}
 
 	// ResourceData contains the information required to generate the resource GoGenerator
@@ -82,6 +135,27 @@ type (
 		Type              *design.MediaTypeDefinition // Type of resource media type
 		CanonicalTemplate string                      // CanonicalFormat represents the resource canonical path in the form of a fmt.Sprintf format.
 		CanonicalParams   []string                    // CanonicalParams is the list of parameter names that appear in the resource canonical path in order.
+		Routes            []*ActionRouteData          // Routes lists the resource action routes.
+	}
+
+	// ActionRouteData contains the information required to generate the path builder of an
+	// action route and to parse the hrefs that match the route.
+	ActionRouteData struct {
+		Name     string           // Name of path builder function, e.g. "ShowBottlePath"
+		Resource string           // Name of resource
+		Action   string           // Name of action
+		Verb     string           // HTTP method of route
+		Path     string           // Path is the route full path, e.g. "/bottles/:id"
+		Template string           // Template is the route full path in the form of a fmt.Sprintf format.
+		Pattern  string           // Pattern is the regular expression that matches the route escaped paths.
+		Params   []*PathParamData // Params lists the route path parameters in order.
+	}
+
+	// PathParamData describes a route path parameter.
+	PathParamData struct {
+		Name      string                      // Name of parameter
+		Attribute *design.AttributeDefinition // Attribute describes the parameter type.
+		CatchAll  bool                        // CatchAll is true for "*name" parameters.
 	}
 )
 
@@ -133,6 +207,8 @@ func NewContextsWriter(filename string) (*ContextsWriter, error) {
 	funcMap["validationChecker"] = codegen.ValidationChecker
 	funcMap["tabs"] = codegen.Tabs
 	funcMap["add"] = func(a, b int) int { return a + b }
+	funcMap["literal"] = codegen.GoLiteral
+	funcMap["recursiveValidate"] = codegen.RecursiveChecker
 	ctxTmpl, err := template.New("context").Funcs(funcMap).Parse(ctxT)
 	if err != nil {
 		return nil, err
@@ -158,13 +234,28 @@ func NewContextsWriter(filename string) (*ContextsWriter, error) {
 	if err != nil {
 		return nil, err
 	}
+	decodePayloadTmpl, err := template.New("decodepayload").Funcs(cw.FuncMap).Parse(decodePayloadT)
+	if err != nil {
+		return nil, err
+	}
+	newMultipartPayloadTmpl, err := template.New("newmultipartpayload").
+		Funcs(cw.FuncMap).
+		Funcs(template.FuncMap{
+		"newCoerceData":  newCoerceData,
+		"arrayAttribute": arrayAttribute,
+	}).Parse(newMultipartPayloadT)
+	if err != nil {
+		return nil, err
+	}
 	w := ContextsWriter{
-		GoGenerator:    cw,
-		CtxTmpl:        ctxTmpl,
-		CtxNewTmpl:     ctxNewTmpl,
-		CtxRespTmpl:    ctxRespTmpl,
-		PayloadTmpl:    payloadTmpl,
-		NewPayloadTmpl: newPayloadTmpl,
+		GoGenerator:             cw,
+		CtxTmpl:                 ctxTmpl,
+		CtxNewTmpl:              ctxNewTmpl,
+		CtxRespTmpl:             ctxRespTmpl,
+		PayloadTmpl:             payloadTmpl,
+		NewPayloadTmpl:          newPayloadTmpl,
+		DecodePayloadTmpl:       decodePayloadTmpl,
+		NewMultipartPayloadTmpl: newMultipartPayloadTmpl,
 	}
 	return &w, nil
 }
@@ -181,9 +272,18 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 		if err := w.PayloadTmpl.Execute(w, data); err != nil {
 			return err
 		}
-		if err := w.NewPayloadTmpl.Execute(w, data); err != nil {
+		newPayloadTmpl := w.NewPayloadTmpl
+		if data.Multipart {
+			newPayloadTmpl = w.NewMultipartPayloadTmpl
+		}
+		if err := newPayloadTmpl.Execute(w, data); err != nil {
 			return err
 		}
+		if data.TypedDecode {
+			if err := w.DecodePayloadTmpl.Execute(w, data); err != nil {
+				return err
+			}
+		}
 	}
 	if len(data.Responses) > 0 {
 		if err := w.CtxRespTmpl.Execute(w, data); err != nil {
@@ -199,6 +299,8 @@ func NewControllersWriter(filename string) (*ControllersWriter, error) {
 	cw := codegen.NewGoGenerator(filename)
 	funcMap := cw.FuncMap
 	funcMap["add"] = func(a, b int) int { return a + b }
+	funcMap["goify"] = codegen.Goify
+	funcMap["timeLiteral"] = timeLiteral
 	ctrlTmpl, err := template.New("controller").Funcs(funcMap).Parse(ctrlT)
 	if err != nil {
 		return nil, err
@@ -235,13 +337,25 @@ func NewResourcesWriter(filename string) (*ResourcesWriter, error) {
 	funcMap := cw.FuncMap
 	funcMap["join"] = strings.Join
 	funcMap["goresdef"] = codegen.GoResDef
+	funcMap["goify"] = codegen.Goify
+	funcMap["gotyperef"] = codegen.GoTypeRef
+	funcMap["tabs"] = codegen.Tabs
+	funcMap["add"] = func(a, b int) int { return a + b }
+	funcMap["pathParamString"] = pathParamString
+	funcMap["newCoerceData"] = newCoerceData
+	funcMap["arrayAttribute"] = arrayAttribute
 	resourceTmpl, err := template.New("resource").Funcs(cw.FuncMap).Parse(resourceT)
 	if err != nil {
 		return nil, err
 	}
+	hrefParserTmpl, err := template.New("hrefParser").Funcs(cw.FuncMap).Parse(hrefParserT)
+	if err != nil {
+		return nil, err
+	}
 	w := ResourcesWriter{
-		GoGenerator:  cw,
-		ResourceTmpl: resourceTmpl,
+		GoGenerator:    cw,
+		ResourceTmpl:   resourceTmpl,
+		HrefParserTmpl: hrefParserTmpl,
 	}
 	return &w, nil
 }
@@ -251,6 +365,71 @@ func (w *ResourcesWriter) Execute(data *ResourceData) error {
 	return w.ResourceTmpl.Execute(w, data)
 }
 
+// ExecuteParser writes the code of the href parser that matches hrefs against the given routes.
+func (w *ResourcesWriter) ExecuteParser(routes []*ActionRouteData) error {
+	return w.HrefParserTmpl.Execute(w, routes)
+}
+
+// NewErrorsWriter returns an error kinds code writer.
+// Error kinds map the errors returned by the controller actions to responses.
+func NewErrorsWriter(filename string) (*ErrorsWriter, error) {
+	cw := codegen.NewGoGenerator(filename)
+	funcMap := cw.FuncMap
+	funcMap["goify"] = codegen.Goify
+	funcMap["gotyperef"] = codegen.GoTypeRef
+	funcMap["gotypename"] = codegen.GoTypeName
+	errorTmpl, err := template.New("error").Funcs(cw.FuncMap).Parse(errorT)
+	if err != nil {
+		return nil, err
+	}
+	w := ErrorsWriter{
+		GoGenerator: cw,
+		ErrorTmpl:   errorTmpl,
+	}
+	return &w, nil
+}
+
+// Execute writes the code for the error kind constructor to the writer.
+func (w *ErrorsWriter) Execute(data *ErrorKindTemplateData) error {
+	return w.ErrorTmpl.Execute(w, data)
+}
+
+// NewSecurityWriter returns a security schemes code writer.
+// Security schemes validate the credentials of requests made to secured actions.
+func NewSecurityWriter(filename string) (*SecurityWriter, error) {
+	cw := codegen.NewGoGenerator(filename)
+	funcMap := cw.FuncMap
+	funcMap["goify"] = codegen.Goify
+	funcMap["validatorType"] = validatorType
+	securityTmpl, err := template.New("security").Funcs(cw.FuncMap).Parse(securityT)
+	if err != nil {
+		return nil, err
+	}
+	w := SecurityWriter{
+		GoGenerator:  cw,
+		SecurityTmpl: securityTmpl,
+	}
+	return &w, nil
+}
+
+// Execute writes the code for the security scheme middleware to the writer.
+func (w *SecurityWriter) Execute(data *design.SecuritySchemeDefinition) error {
+	return w.SecurityTmpl.Execute(w, data)
+}
+
+// validatorType returns the name of the goa type of the functions that validate the credentials
+// of the given security scheme kind.
+func validatorType(kind design.SecuritySchemeKind) string {
+	switch kind {
+	case design.BasicAuthSecurityKind:
+		return "goa.BasicAuthValidator"
+	case design.APIKeySecurityKind:
+		return "goa.APIKeyValidator"
+	default:
+		return "goa.TokenValidator"
+	}
+}
+
 // NewMediaTypesWriter returns a contexts code writer.
 // Media types contain the data used to render response bodies.
 func NewMediaTypesWriter(filename string) (*MediaTypesWriter, error) {
@@ -268,6 +447,8 @@ func NewMediaTypesWriter(filename string) (*MediaTypesWriter, error) {
 	funcMap["newDumpData"] = newDumpData
 	funcMap["userTypeUnmarshalerImpl"] = codegen.UserTypeUnmarshalerImpl
 	funcMap["mediaTypeMarshalerImpl"] = codegen.MediaTypeMarshalerImpl
+	funcMap["mediaTypeJSONWriterImpl"] = codegen.MediaTypeJSONWriterImpl
+	funcMap["mediaTypeJSONWriterName"] = codegen.MediaTypeJSONWriterName
 	mediaTypeTmpl, err := template.New("media type").Funcs(funcMap).Parse(mediaTypeT)
 	if err != nil {
 		return nil, err
@@ -294,6 +475,7 @@ func NewUserTypesWriter(filename string) (*UserTypesWriter, error) {
 	funcMap["gotypename"] = codegen.GoTypeName
 	funcMap["userTypeUnmarshalerImpl"] = codegen.UserTypeUnmarshalerImpl
 	funcMap["userTypeMarshalerImpl"] = codegen.UserTypeMarshalerImpl
+	funcMap["userTypeJSONWriterImpl"] = codegen.UserTypeJSONWriterImpl
 	userTypeTmpl, err := template.New("user type").Funcs(funcMap).Parse(userTypeT)
 	if err != nil {
 		return nil, err
@@ -310,14 +492,100 @@ func (w *UserTypesWriter) Execute(ut *design.UserTypeDefinition) error {
 	return w.UserTypeTmpl.Execute(w, ut)
 }
 
+// NewValidatorsWriter returns a validators code writer.
+// Validators hold the compiled regular expressions used by the generated pattern validations.
+func NewValidatorsWriter(filename string) (*ValidatorsWriter, error) {
+	cw := codegen.NewGoGenerator(filename)
+	funcMap := cw.FuncMap
+	funcMap["rawString"] = rawString
+	validatorsTmpl, err := template.New("validators").Funcs(funcMap).Parse(validatorsT)
+	if err != nil {
+		return nil, err
+	}
+	w := ValidatorsWriter{
+		GoGenerator:    cw,
+		ValidatorsTmpl: validatorsTmpl,
+		Patterns:       make(map[string]string),
+		Formats:        make(map[string]bool),
+	}
+	return &w, nil
+}
+
+// AddValidations records the regular expressions and custom formats used by the validations of
+// the given attribute and of the attributes it contains.
+func (w *ValidatorsWriter) AddValidations(att *design.AttributeDefinition) {
+	codegen.CollectPatterns(att, w.Patterns)
+	codegen.CollectCustomFormats(att, w.Formats)
+}
+
+// Execute writes the code for the recorded regular expressions and custom formats to the writer.
+func (w *ValidatorsWriter) Execute() error {
+	formats := make([]string, 0, len(w.Formats))
+	for f := range w.Formats {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+	data := map[string]interface{}{"Patterns": w.Patterns, "Formats": formats}
+	return w.ValidatorsTmpl.Execute(w, data)
+}
+
+// rawString returns the Go literal for s, a raw string literal if possible.
+func rawString(s string) string {
+	if strconv.CanBackquote(s) {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
 // newCoerceData is a helper function that creates a map that can be given to the "Coerce" template.
-func newCoerceData(name string, att *design.AttributeDefinition, pkg string, depth int) map[string]interface{} {
+// errFunc is the name of the goa function that builds the error reported when the value is invalid,
+// either "InvalidParamTypeError" or "InvalidHeaderTypeError".
+func newCoerceData(
+	name string,
+	att *design.AttributeDefinition,
+	pkg string,
+	depth int,
+	errFunc string) map[string]interface{} {
+
 	return map[string]interface{}{
 		"Name":      name,
 		"VarName":   codegen.Goify(name, false),
 		"Attribute": att,
 		"Pkg":       pkg,
 		"Depth":     depth,
+		"ErrFunc":   errFunc,
+	}
+}
+
+// timeLiteral returns the Go expression that builds the given time, "time.Time{}" if t is the zero
+// time.
+func timeLiteral(t time.Time) string {
+	if t.IsZero() {
+		return "time.Time{}"
+	}
+	t = t.UTC()
+	return fmt.Sprintf("time.Date(%d, time.%s, %d, %d, %d, %d, 0, time.UTC)",
+		t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second())
+}
+
+// pathParamString returns the Go expression that converts the value of the given variable holding
+// a primitive path parameter value to a string.
+func pathParamString(varName string, att *design.AttributeDefinition) string {
+	switch att.Type.Kind() {
+	case design.BooleanKind:
+		return fmt.Sprintf("strconv.FormatBool(%s)", varName)
+	case design.IntegerKind:
+		return fmt.Sprintf("strconv.Itoa(%s)", varName)
+	case design.NumberKind:
+		return fmt.Sprintf("strconv.FormatFloat(%s, 'f', -1, 64)", varName)
+	case design.StringKind:
+		return varName
+	case design.DateTimeKind:
+		return fmt.Sprintf("%s.Format(time.RFC3339)", varName)
+	case design.UUIDKind:
+		return fmt.Sprintf("%s.String()", varName)
+	default:
+		return fmt.Sprintf("fmt.Sprintf(\"%%v\", %s)", varName)
 	}
 }
 
@@ -346,7 +614,8 @@ type {{.Name}} struct {
 {{if .Params}}{{$ctx := .}}{{range $name, $att := .Params.Type.ToObject}}	{{goify $name true}} {{gotyperef .Type 0}}
 {{if $ctx.MustSetHas $name}}
 	Has{{goify $name true}} bool
-{{end}}{{end}}{{end}}{{if .Payload}}	Payload {{gotyperef .Payload 0}}
+{{end}}{{end}}{{end}}{{if .Headers}}{{range $name, $att := .Headers.Type.ToObject}}	{{goify $name true}} {{gotyperef .Type 0}}
+{{end}}{{end}}{{if .Payload}}	Payload {{gotyperef .Payload 0}}
 {{end}}}
 `
 	// coerceT generates the code that coerces the generic deserialized
@@ -355,24 +624,35 @@ type {{.Name}} struct {
 	coerceT = `{{if eq .Attribute.Type.Kind 1}}{{/* BooleanType */}}{{tabs .Depth}}if {{.VarName}}, err2 := strconv.ParseBool(raw{{goify .Name true}}); err2 == nil {
 {{tabs .Depth}}	{{.Pkg}} = {{.VarName}}
 {{tabs .Depth}}} else {
-{{tabs .Depth}}	err = goa.InvalidParamTypeError("{{.Name}}", raw{{goify .Name true}}, "boolean", err)
+{{tabs .Depth}}	err = goa.{{.ErrFunc}}("{{.Name}}", raw{{goify .Name true}}, "boolean", err)
 {{tabs .Depth}}}
 {{end}}{{if eq .Attribute.Type.Kind 2}}{{/* IntegerType */}}{{tabs .Depth}}if {{.VarName}}, err2 := strconv.Atoi(raw{{goify .Name true}}); err2 == nil {
 {{tabs .Depth}}	{{.Pkg}} = int({{.VarName}})
 {{tabs .Depth}}} else {
-{{tabs .Depth}}	err = goa.InvalidParamTypeError("{{.Name}}", raw{{goify .Name true}}, "integer", err)
+{{tabs .Depth}}	err = goa.{{.ErrFunc}}("{{.Name}}", raw{{goify .Name true}}, "integer", err)
 {{tabs .Depth}}}
 {{end}}{{if eq .Attribute.Type.Kind 3}}{{/* NumberType */}}{{tabs .Depth}}if {{.VarName}}, err2 := strconv.ParseFloat(raw{{goify .Name true}}, 64); err2 == nil {
 {{tabs .Depth}}	{{.Pkg}} = {{.VarName}}
 {{tabs .Depth}}} else {
-{{tabs .Depth}}	err = goa.InvalidParamTypeError("{{.Name}}", raw{{goify .Name true}}, "number", err)
+{{tabs .Depth}}	err = goa.{{.ErrFunc}}("{{.Name}}", raw{{goify .Name true}}, "number", err)
 {{tabs .Depth}}}
 {{end}}{{if eq .Attribute.Type.Kind 4}}{{/* StringType */}}{{tabs .Depth}}{{.Pkg}} = raw{{goify .Name true}}
-{{end}}{{if eq .Attribute.Type.Kind 5}}{{/* ArrayType */}}{{tabs .Depth}}elems{{goify .Name true}} := strings.Split(raw{{goify .Name true}}, ",")
+{{end}}{{if eq .Attribute.Type.Kind 11}}{{/* DateTimeType */}}{{tabs .Depth}}if {{.VarName}}, err2 := time.Parse(time.RFC3339, raw{{goify .Name true}}); err2 == nil {
+{{tabs .Depth}}	{{.Pkg}} = {{.VarName}}
+{{tabs .Depth}}} else {
+{{tabs .Depth}}	err = goa.{{.ErrFunc}}("{{.Name}}", raw{{goify .Name true}}, "datetime", err)
+{{tabs .Depth}}}
+{{end}}{{if eq .Attribute.Type.Kind 12}}{{/* UUIDType */}}{{tabs .Depth}}if {{.VarName}}, err2 := goa.UUIDFromString(raw{{goify .Name true}}); err2 == nil {
+{{tabs .Depth}}	{{.Pkg}} = {{.VarName}}
+{{tabs .Depth}}} else {
+{{tabs .Depth}}	err = goa.{{.ErrFunc}}("{{.Name}}", raw{{goify .Name true}}, "uuid", err)
+{{tabs .Depth}}}
+{{end}}{{if eq .Attribute.Type.Kind 13}}{{/* AnyType */}}{{tabs .Depth}}{{.Pkg}} = raw{{goify .Name true}}
+{{end}}{{if eq .Attribute.Type.Kind 5}}{{/* ArrayType */}}{{tabs .Depth}}{{if eq .Attribute.CollectionFormat "multi"}}elems{{goify .Name true}} := raw{{goify .Name true}}{{else}}elems{{goify .Name true}} := strings.Split(raw{{goify .Name true}}, {{printf "%q" .Attribute.CollectionSeparator}}){{end}}
 {{if eq (arrayAttribute .Attribute).Type.Kind 4}}{{tabs .Depth}}{{.Pkg}} = elems{{goify .Name true}}
 {{else}}{{tabs .Depth}}elems{{goify .Name true}}2 := make({{gotyperef .Attribute.Type .Depth}}, len(elems{{goify .Name true}}))
 {{tabs .Depth}}for i, rawElem := range elems{{goify .Name true}} {
-{{template "Coerce" (newCoerceData "elem" (arrayAttribute .Attribute) (printf "elems%s2[i]" (goify .Name true)) (add .Depth 1))}}{{tabs .Depth}}}
+{{template "Coerce" (newCoerceData "elem" (arrayAttribute .Attribute) (printf "elems%s2[i]" (goify .Name true)) (add .Depth 1) .ErrFunc)}}{{tabs .Depth}}}
 {{tabs .Depth}}{{.Pkg}} = elems{{goify .Name true}}2
 {{end}}{{end}}`
 
@@ -384,18 +664,37 @@ type {{.Name}} struct {
 func New{{.Name}}(c *goa.Context) (*{{.Name}}, error) {
 	var err error
 	ctx := {{.Name}}{Context: c}
-{{if .Headers}}{{$headers := .Headers}}{{range $name, $_ := $headers.Type.ToObject}}{{if ($headers.IsRequired $name)}}	if c.Request().Header.Get("{{$name}}") == "" {
+{{if .Headers}}{{$headers := .Headers}}{{range $name, $att := $headers.Type.ToObject}}	raw{{goify $name true}} := c.Request().Header.Get("{{$name}}")
+{{if ($headers.IsRequired $name)}}	if raw{{goify $name true}} == "" {
 		err = goa.MissingHeaderError("{{$name}}", err)
+	} else {
+{{else}}	if raw{{goify $name true}} != "" {
+{{end}}{{template "Coerce" (newCoerceData $name $att (printf "ctx.%s" (goify $name true)) 2 "InvalidHeaderTypeError")}}{{/*
+*/}}{{$validation := validationChecker $att ($headers.IsRequired $name) (printf "ctx.%s" (goify $name true)) $name 1}}{{if $validation}}{{$validation}}
+{{end}}	}{{if not ($headers.IsRequired $name)}}{{$default := literal $att.Type $att.DefaultValue}}{{if $default}} else {
+		ctx.{{goify $name true}} = {{$default}}
 	}{{end}}{{end}}
-{{end}}{{if.Params}}{{$ctx := .}}{{range $name, $att := .Params.Type.ToObject}}	raw{{goify $name true}}, ok := c.Get("{{$name}}")
-{{if ($ctx.MustValidate $name)}}	if !ok {
+{{end}}{{end}}{{if.Params}}{{$ctx := .}}{{range $name, $att := .Params.Type.ToObject}}{{$multi := eq $att.CollectionFormat "multi"}}{{/*
+*/}}{{if $multi}}	raw{{goify $name true}} := c.GetMany("{{$name}}")
+{{else}}	raw{{goify $name true}}, ok := c.Get("{{$name}}")
+{{end}}{{if ($ctx.MustValidate $name)}}	if {{if $multi}}len(raw{{goify $name true}}) == 0{{else}}!ok{{end}} {
 		err = goa.MissingParamError("{{$name}}", err)
 	} else {
-{{else}}	if ok {
-{{end}}{{template "Coerce" (newCoerceData $name $att (printf "ctx.%s" (goify $name true)) 2)}}{{if $ctx.MustSetHas $name}}		ctx.Has{{goify $name true}} = true
+{{else}}	if {{if $multi}}len(raw{{goify $name true}}) > 0{{else}}ok{{end}} {
+{{end}}{{template "Coerce" (newCoerceData $name $att (printf "ctx.%s" (goify $name true)) 2 "InvalidParamTypeError")}}{{if $ctx.MustSetHas $name}}		ctx.Has{{goify $name true}} = true
 {{end}}{{$validation := validationChecker $att ($ctx.Params.IsRequired $name) (printf "ctx.%s" (goify $name true)) $name 1}}{{if $validation}}{{$validation}}
-{{end}}	}
-{{end}}{{end}}{{/* if .Params */}}{{if .Payload}}	p, err := New{{gotypename .Payload 0}}(c.Payload())
+{{end}}	}{{if not ($ctx.MustValidate $name)}}{{$default := literal $att.Type $att.DefaultValue}}{{if $default}} else {
+		ctx.{{goify $name true}} = {{$default}}
+	}{{end}}{{end}}
+{{end}}{{end}}{{/* if .Params */}}{{if .TypedDecode}}	p, ok := c.Payload().({{gotyperef .Payload 0}})
+	if !ok {
+		p, err = New{{gotypename .Payload 0}}(c.Payload())
+		if err != nil {
+			return nil, err
+		}
+	}
+	ctx.Payload = p
+{{else if .Payload}}	p, err := New{{gotypename .Payload 0}}(c.Payload())
 	if err != nil {
 		return nil, err
 	}
@@ -408,14 +707,15 @@ func New{{.Name}}(c *goa.Context) (*{{.Name}}, error) {
 	// template input: *ContextTemplateData
 	ctxRespT = `{{$ctx := .}}{{range .Responses}}// {{goify .Name true}} sends a HTTP response with status code {{.Status}}.
 	func (ctx *{{$ctx.Name}}) {{goify .Name true}}({{$mt := ($ctx.API.MediaTypeWithIdentifier .MediaType)}}{{if $mt}}resp {{gotyperef $mt 0}}{{if gt (len $mt.ComputeViews) 1}}, view {{gotypename $mt 0}}ViewEnum{{end}}{{else if .MediaType}}resp []byte{{end}}) error {
-{{if $mt}}	r, err := resp.Dump({{if gt (len $mt.ComputeViews) 1}}view{{end}})
-	if err != nil {
-		return fmt.Errorf("invalid response: %s", err)
-	}
-	ctx.Header().Set("Content-Type", "{{$mt.Identifier}}; charset=utf-8")
-	return ctx.JSON({{.Status}}, r){{else}}return ctx.Respond({{.Status}}, {{if and (not $mt) .MediaType}}resp{{else}}nil{{end}}){{end}}
+{{if $mt}}	return ctx.SendMedia({{.Status}}, "{{$mt.Identifier}}", resp, {{if gt (len $mt.ComputeViews) 1}}string(view){{else}}""{{end}}){{else}}return ctx.Respond({{.Status}}, {{if and (not $mt) .MediaType}}resp{{else}}nil{{end}}){{end}}
 }
-{{end}}`
+{{if $mt}}{{if eq $mt.Type.Kind 5}}{{if eq $mt.Type.ToArray.ElemType.Type.Kind 9}}
+// {{goify .Name true}}Stream sends a HTTP response with status code {{.Status}}, it writes the
+// elements of the collection to the response body as they get rendered.
+func (ctx *{{$ctx.Name}}) {{goify .Name true}}Stream(resp {{gotyperef $mt 0}}{{if gt (len $mt.ComputeViews) 1}}, view {{gotypename $mt 0}}ViewEnum{{end}}) error {
+	return ctx.StreamMedia({{.Status}}, "{{$mt.Identifier}}", resp, {{if gt (len $mt.ComputeViews) 1}}string(view){{else}}""{{end}})
+}
+{{end}}{{end}}{{end}}{{end}}`
 
 	// payloadT generates the payload type definition GoGenerator
 	// template input: *ContextTemplateData
@@ -432,6 +732,87 @@ func New{{gotypename .Payload 0}}(raw interface{}) (p {{gotyperef .Payload 0}},
 }{{if (not .Payload.IsPrimitive)}}
 
 {{userTypeUnmarshalerImpl .Payload "payload"}}{{end}}
+`
+
+	// decodePayloadT generates the code that decodes JSON request bodies directly into the payload.
+	// template input: *ContextTemplateData
+	decodePayloadT = `{{$payload := .Payload}}{{$name := gotypename .Payload 0}}{{$body := printf "%sBody" (goify $name false)}}
+// {{$body}} is the type the JSON request bodies of the {{.ResourceName}} {{.ActionName}} action are
+// decoded into, its pointer fields make it possible to tell missing attributes from zero values.
+type {{$body}} struct {
+{{range $n, $att := .Payload.Type.ToObject}}	{{goify $n true}} *{{gotyperef $att.Type 1}} ` + "`" + `json:"{{$n}}"` + "`" + `
+{{end}}}
+
+// Decode{{$name}} decodes a JSON request body directly into a {{$name}}.
+// It validates each field and returns a BadRequestError if any validation fails.
+// Bodies that do not decode into the payload type are coerced by New{{$name}} instead
+// so that they produce the same errors as generically decoded bodies.
+func Decode{{$name}}(dec goa.Decoder) (interface{}, error) {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	var body {{$body}}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		var generic interface{}
+		if err := json.Unmarshal(raw, &generic); err != nil {
+			return nil, err
+		}
+		p, err := New{{$name}}(generic)
+		if err != nil {
+			return nil, goa.NewBadRequestError(err)
+		}
+		return p, nil
+	}
+	var err error
+	p := new({{$name}})
+{{range $n, $att := .Payload.Type.ToObject}}	if body.{{goify $n true}} != nil {
+		p.{{goify $n true}} = *body.{{goify $n true}}
+{{$validation := recursiveValidate $att false (printf "p.%s" (goify $n true)) (printf "payload.%s" (goify $n true)) 2}}{{if $validation}}{{$validation}}
+{{end}}	}{{if $payload.IsRequired $n}} else {
+		err = goa.MissingAttributeError(` + "`payload`" + `, "{{$n}}", err)
+	}{{else}}{{$default := literal $att.Type $att.DefaultValue}}{{if $default}} else {
+		p.{{goify $n true}} = {{$default}}
+	}{{end}}{{end}}
+{{end}}	if err != nil {
+		return nil, goa.NewBadRequestError(err)
+	}
+	return p, nil
+}
+`
+
+	// newMultipartPayloadT generates the code for the factory method of multipart payloads.
+	// template input: *ContextTemplateData
+	newMultipartPayloadT = `{{define "Coerce"}}` + coerceT + `{{end}}` + `// New{{gotypename .Payload 0}} instantiates a {{gotypename .Payload 0}} from a multipart/form-data request body.
+// It validates each field and returns an error if any validation fails.
+func New{{gotypename .Payload 0}}(raw interface{}) (p {{gotyperef .Payload 0}}, err error) {
+	form, ok := raw.(*multipart.Form)
+	if !ok {
+		err = goa.InvalidAttributeTypeError("payload", raw, "multipart form", err)
+		return
+	}
+	p = new({{gotypename .Payload 0}})
+{{$payload := .Payload}}{{range $name, $att := .Payload.Type.ToObject}}{{if eq $att.Type.Kind 10}}{{/* FileType */}}	if files := form.File["{{$name}}"]; len(files) > 0 {
+		p.{{goify $name true}} = files[0]
+	}{{else if eq $att.Type.Kind 5}}{{if eq (arrayAttribute $att).Type.Kind 10}}{{/* Array of FileType */}}	if files := form.File["{{$name}}"]; len(files) > 0 {
+		p.{{goify $name true}} = files
+	}{{else}}	if vals := form.Value["{{$name}}"]; len(vals) > 0 {
+		elems := make({{gotyperef $att.Type 2}}, len(vals))
+		for i, rawElem := range vals {
+{{template "Coerce" (newCoerceData "elem" (arrayAttribute $att) "elems[i]" 3 "InvalidParamTypeError")}}		}
+		p.{{goify $name true}} = elems
+{{$validation := validationChecker $att ($payload.IsRequired $name) (printf "p.%s" (goify $name true)) $name 2}}{{if $validation}}{{$validation}}
+{{end}}	}{{end}}{{else}}	if vals := form.Value["{{$name}}"]; len(vals) > 0 {
+		raw{{goify $name true}} := vals[0]
+{{template "Coerce" (newCoerceData $name $att (printf "p.%s" (goify $name true)) 2 "InvalidParamTypeError")}}{{/*
+*/}}{{$validation := validationChecker $att ($payload.IsRequired $name) (printf "p.%s" (goify $name true)) $name 2}}{{if $validation}}{{$validation}}
+{{end}}	}{{end}}{{if $payload.IsRequired $name}} else {
+		err = goa.MissingAttributeError("payload", "{{$name}}", err)
+	}{{else}}{{$default := literal $att.Type $att.DefaultValue}}{{if $default}} else {
+		p.{{goify $name true}} = {{$default}}
+	}{{end}}{{end}}
+{{end}}	return
+}
 `
 
 	// ctrlT generates the controller interface for a given resource.
@@ -448,7 +829,10 @@ type {{.Resource}}Controller interface {
 	mountT = `
 // Mount{{.Resource}}Controller "mounts" a {{.Resource}} resource controller on the given service.
 func Mount{{.Resource}}Controller(service goa.Service, ctrl {{.Resource}}Controller) {
-	router := service.HTTPHandler().(*httprouter.Router)
+{{if .Version}}	service.SetVersionSelector({{.VersionSelector}})
+	service = service.Version({{printf "%q" .Version}})
+{{end}}{{if .CheckFormats}}	checkFormats(service)
+{{end}}	router := service.HTTPHandler().(*httprouter.Router)
 	var h goa.Handler
 {{$res := .Resource}}{{range .Actions}}{{$action := .}}	h = func(c *goa.Context) error {
 		ctx, err := New{{.Context}}(c)
@@ -457,7 +841,13 @@ func Mount{{.Resource}}Controller(service goa.Service, ctrl {{.Resource}}Control
 		}
 		return ctrl.{{.Name}}(ctx)
 	}
-{{range .Routes}}	router.Handle("{{.Verb}}", "{{.FullPath}}", ctrl.NewHTTPRouterHandle("{{$action.Name}}", h))
+{{with .Security}}	h = {{goify .Scheme false}}Security({{range $i, $s := .Scopes}}{{if $i}}, {{end}}"{{$s}}"{{end}})(h)
+{{end}}{{with .Deprecation}}	ctrl.SetDeprecated("{{$action.Name}}", {{timeLiteral .Sunset}})
+{{end}}{{if .Consumes}}	ctrl.SetConsumes("{{.Name}}"{{range .Consumes}}, "{{.}}"{{end}})
+{{end}}{{if .MaxBodySize}}	ctrl.SetMaxBodySize("{{.Name}}", {{.MaxBodySize}})
+{{end}}{{if .RawBody}}	ctrl.SetRawBody("{{.Name}}")
+{{end}}{{if .DecodeFunc}}	ctrl.SetDecodeFunc("{{.Name}}", {{.DecodeFunc}})
+{{end}}{{range .Routes}}	router.Handle("{{.Verb}}", "{{.FullPath}}", ctrl.NewHTTPRouterHandle("{{$action.Name}}", h))
 	service.Info("mount", "ctrl", "{{$res}}", "action", "{{$action.Name}}", "route", "{{.Verb}} {{.FullPath}}")
 {{end}}{{end}}}
 `
@@ -468,6 +858,141 @@ func Mount{{.Resource}}Controller(service goa.Service, ctrl {{.Resource}}Control
 func {{.Name}}Href({{if .CanonicalParams}}{{join .CanonicalParams ", "}} interface{}{{end}}) string {
 	return fmt.Sprintf("{{.CanonicalTemplate}}", {{join .CanonicalParams ", "}})
 }
+{{end}}{{range .Routes}}
+// {{.Name}} returns the URL path to the {{.Resource}} {{.Action}} action ({{.Verb}}).
+func {{.Name}}({{range $i, $p := .Params}}{{if $i}}, {{end}}{{goify $p.Name false}} {{gotyperef $p.Attribute.Type 0}}{{end}}) string {
+{{if .Params}}{{range $i, $p := .Params}}{{$v := goify $p.Name false}}{{if eq $p.Attribute.Type.Kind 5}}{{$elem := arrayAttribute $p.Attribute}}{{/*
+*/}}	elems{{$i}} := make([]string, len({{$v}}))
+	for i, e := range {{$v}} {
+		elems{{$i}}[i] = {{pathParamString "e" $elem}}
+	}
+	param{{$i}} := goa.EscapePathSegment(strings.Join(elems{{$i}}, {{printf "%q" $p.Attribute.CollectionSeparator}}))
+{{else if $p.CatchAll}}	param{{$i}} := goa.EscapePath("/" + strings.TrimPrefix({{pathParamString $v $p.Attribute}}, "/"))
+{{else}}	param{{$i}} := goa.EscapePathSegment({{pathParamString $v $p.Attribute}})
+{{end}}{{end}}	return fmt.Sprintf("{{.Template}}"{{range $i, $p := .Params}}, param{{$i}}{{end}})
+{{else}}	return "{{.Template}}"
+{{end}}}
+{{end}}`
+
+	// hrefParserT generates the href parser.
+	// template input: []*ActionRouteData sorted by path specificity
+	hrefParserT = `{{define "Coerce"}}` + coerceT + `{{end}}` + `
+// Href describes the resource action identified by an href, see ParseHref.
+type Href struct {
+	// Resource is the name of the resource.
+	Resource string
+	// Action is the name of the action.
+	Action string
+	// Params maps the names of the action path parameters to their values.
+	Params map[string]interface{}
+}
+
+// hrefRoutes lists the action routes ParseHref matches hrefs against, most specific routes first.
+var hrefRoutes = []struct {
+	resource string
+	action   string
+	pattern  *regexp.Regexp
+	parse    func([]string) (map[string]interface{}, error)
+}{
+{{range .}}	{"{{.Resource}}", "{{.Action}}", regexp.MustCompile(` + "`{{.Pattern}}`" + `), parse{{.Name}}},
+{{end}}}
+
+// ParseHref returns the resource action identified by the given href together with the values of
+// the action path parameters coerced to their design types. ParseHref only considers the action
+// routes that use the GET method.
+func ParseHref(href string) (*Href, error) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return nil, err
+	}
+	path := u.EscapedPath()
+	for _, r := range hrefRoutes {
+		if m := r.pattern.FindStringSubmatch(path); m != nil {
+			params, err := r.parse(m[1:])
+			if err != nil {
+				return nil, err
+			}
+			return &Href{Resource: r.resource, Action: r.action, Params: params}, nil
+		}
+	}
+	return nil, fmt.Errorf("no action matches href %#v", href)
+}
+{{range .}}
+// parse{{.Name}} coerces the values of the {{.Resource}} {{.Action}} action path parameters.
+func parse{{.Name}}(raw []string) (map[string]interface{}, error) {
+	var err error
+	hrefParams := make(map[string]interface{}, {{len .Params}})
+{{range $i, $p := .Params}}	raw{{goify $p.Name true}}, err2 := goa.UnescapePathSegment(raw[{{$i}}])
+	if err2 != nil {
+		return nil, err2
+	}
+{{template "Coerce" (newCoerceData $p.Name $p.Attribute (printf "hrefParams[%q]" $p.Name) 1 "InvalidParamTypeError")}}{{end}}	return hrefParams, err
+}
+{{end}}`
+
+	// errorT generates the code for an error kind constructor.
+	// template input: *ErrorKindTemplateData
+	errorT = `{{$mt := .MediaType}}// New{{goify .Name true}}Error creates an error of kind "{{.Name}}".{{if .Description}}
+// {{.Description}}{{end}}
+// Actions that return the error send a response with status code {{.Status}}.
+func New{{goify .Name true}}Error({{if $mt}}resp {{gotyperef $mt 0}}{{if gt (len $mt.ComputeViews) 1}}, view {{gotypename $mt 0}}ViewEnum{{end}}{{else}}err error{{end}}) error {
+{{if $mt}}	r, err := resp.Dump({{if gt (len $mt.ComputeViews) 1}}view{{end}})
+	if err != nil {
+		return fmt.Errorf("invalid response: %s", err)
+	}
+	return &goa.ResponseError{
+		Kind:      "{{.Name}}",
+		Status:    {{.Status}},
+		MediaType: "{{$mt.Identifier}}",
+		Body:      r,
+	}
+{{else}}	return &goa.ResponseError{
+		Kind:   "{{.Name}}",
+		Status: {{.Status}},
+		Actual: err,
+	}
+{{end}}}
+`
+
+	// securityT generates the code for a security scheme middleware.
+	// template input: *design.SecuritySchemeDefinition
+	securityT = `{{$name := goify .Name false}}{{$validator := printf "%sValidator" $name}}{{$type := validatorType .Kind}}// {{$validator}} validates the credentials of requests made to actions secured with the
+// "{{.Name}}" security scheme.
+var {{$validator}} {{$type}}
+
+// Set{{goify .Name true}}Validator sets the function used to validate the credentials of requests made
+// to actions secured with the "{{.Name}}" security scheme.{{if .Description}}
+// {{.Description}}{{end}}
+// These requests are rejected with status code 401 until a validator is set.
+func Set{{goify .Name true}}Validator(f {{$type}}) {
+	{{$validator}} = f
+}
+
+// {{$name}}Security returns the middleware that enforces the "{{.Name}}" security scheme.
+{{if eq .Kind "basic"}}func {{$name}}Security() goa.Middleware {
+	return goa.BasicAuthSecurity(func(ctx *goa.Context, username, password string) error {
+		if {{$validator}} == nil {
+			return fmt.Errorf("no validator set for security scheme %#v", "{{.Name}}")
+		}
+		return {{$validator}}(ctx, username, password)
+	})
+}
+{{else if eq .Kind "apiKey"}}func {{$name}}Security() goa.Middleware {
+	return goa.APIKeySecurity("{{.In}}", "{{.ParamName}}", func(ctx *goa.Context, key string) error {
+		if {{$validator}} == nil {
+			return fmt.Errorf("no validator set for security scheme %#v", "{{.Name}}")
+		}
+		return {{$validator}}(ctx, key)
+	})
+}
+{{else}}func {{$name}}Security(scopes ...string) goa.Middleware {
+	return goa.TokenSecurity("{{.In}}", "{{.ParamName}}", scopes, func(ctx *goa.Context, token string, scopes []string) error {
+		if {{$validator}} == nil {
+			return fmt.Errorf("no validator set for security scheme %#v", "{{.Name}}")
+		}
+		return {{$validator}}(ctx, token, scopes)
+	})
+}
 {{end}}`
 
 	// mediaTypeT generates the code for a media type.
@@ -507,8 +1032,46 @@ func (mt {{gotyperef . 0}}) Validate() (err error) {
 {{$validation := recursiveValidate .AttributeDefinition false "mt" "response" 1}}{{if $validation}}{{$validation}}
 {{end}} return
 }
-{{range $computedViews}}
+
+// DumpView produces raw data from an instance of {{$typeName}} using the view with the given name.
+// It implements goa.MediaTypeRenderer.
+func (mt {{gotyperef . 0}}) DumpView(view string) (interface{}, error) {
+	res, err := mt.Dump({{if gt (len $computedViews) 1}}{{$typeName}}ViewEnum(view){{end}})
+	return res, err
+}
+
+// WriteJSON validates the media type instance and writes its JSON representation using the view
+// with the given name. It implements goa.MediaTypeRenderer.
+func (mt {{gotyperef . 0}}) WriteJSON(w *goa.JSONWriter, view string) (err error) {
+{{if gt (len $computedViews) 1}}	switch view {
+{{range $computedViews}}	case "{{.Name}}":
+		err = {{mediaTypeJSONWriterName $mt .Name}}(w, mt, err)
+{{end}}	default:
+		err = fmt.Errorf("unknown view %#v", view)
+	}
+{{else}}{{range $computedViews}}	err = {{mediaTypeJSONWriterName $mt .Name}}(w, mt, err)
+{{else}}	w.Null()
+{{end}}{{end}}	return
+}
+{{if eq .Type.Kind 5}}{{if eq .Type.ToArray.ElemType.Type.Kind 9}}
+// Len returns the number of elements in the collection. It implements goa.CollectionRenderer.
+func (mt {{gotyperef . 0}}) Len() int {
+	return len(mt)
+}
+
+// WriteElemJSON validates the element of the collection at index i and writes its JSON
+// representation using the view with the given name. It implements goa.CollectionRenderer.
+func (mt {{gotyperef . 0}}) WriteElemJSON(w *goa.JSONWriter, i int, view string) error {
+	if mt[i] == nil {
+		w.Null()
+		return nil
+	}
+	return mt[i].WriteJSON(w, view)
+}
+{{end}}{{end}}{{range $computedViews}}
 {{mediaTypeMarshalerImpl $mt .Name}}
+
+{{mediaTypeJSONWriterImpl $mt .Name}}
 {{end}}
 {{userTypeUnmarshalerImpl .UserTypeDefinition "load"}}
 `
@@ -521,13 +1084,39 @@ func (mt {{gotyperef . 0}}) Validate() (err error) {
 		{{.Target}}[i] = {{$tmpel}}
 	}{{else}}{{typeMarshaler .MediaType .Context .Source .Target .View}}{{end}}`
 
+	// validatorsT generates the variables holding the compiled regular expressions and the
+	// function that checks the registration of the custom formats.
+	// template input: map[string]interface{}
+	validatorsT = `{{if .Patterns}}// Regular expressions used by the pattern validations, they get compiled once when the package
+// is initialized.
+var (
+{{range $name, $p := .Patterns}}	{{$name}} = regexp.MustCompile({{rawString $p}})
+{{end}})
+{{end}}{{if .Formats}}
+// checkFormats logs an error for each custom format used by the validations that has no validator
+// registered with goa.RegisterFormat. The controller mount functions call it so that a missing
+// registration shows up when the application starts rather than when a request gets validated.
+func checkFormats(service goa.Service) {
+	formats := []goa.Format{
+{{range .Formats}}		{{printf "%q" .}},
+{{end}}	}
+	for _, f := range formats {
+		if goa.LookupFormat(f) == nil {
+			service.Error("custom format has no registered validator", "format", f)
+		}
+	}
+}
+{{end}}`
+
 	// userTypeT generates the code for a user type.
 	// template input: *design.UserTypeDefinition
 	userTypeT = `// {{if .Description}}{{.Description}}{{else}}{{gotypename . 0}} type{{end}}
 type {{gotypename . 0}} {{gotypedef . 0 false false}}
 
 {{userTypeMarshalerImpl .}}
-
+{{$writer := userTypeJSONWriterImpl .}}{{if $writer}}
+{{$writer}}
+{{end}}
 {{userTypeUnmarshalerImpl . "load"}}
 `
 )
@@ -3,6 +3,7 @@ package genapp_test
 import (
 	"io/ioutil"
 	"os"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -34,6 +35,8 @@ var _ = Describe("ContextsWriter", func() {
 		Context("with data", func() {
 			var params, headers *design.AttributeDefinition
 			var payload *design.UserTypeDefinition
+			var multipart bool
+			var typedDecode bool
 			var responses map[string]*design.ResponseDefinition
 			var mediaTypes map[string]*design.MediaTypeDefinition
 
@@ -43,6 +46,8 @@ var _ = Describe("ContextsWriter", func() {
 				params = nil
 				headers = nil
 				payload = nil
+				multipart = false
+				typedDecode = false
 				responses = nil
 				mediaTypes = nil
 				data = nil
@@ -55,6 +60,8 @@ var _ = Describe("ContextsWriter", func() {
 					ActionName:   "list",
 					Params:       params,
 					Payload:      payload,
+					Multipart:    multipart,
+					TypedDecode:  typedDecode,
 					Headers:      headers,
 					Responses:    responses,
 					API:          design.Design,
@@ -143,6 +150,74 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with a datetime param", func() {
+				BeforeEach(func() {
+					dateTimeParam := &design.AttributeDefinition{Type: design.DateTime}
+					dataType := design.Object{
+						"param": dateTimeParam,
+					}
+					params = &design.AttributeDefinition{
+						Type: dataType,
+					}
+				})
+
+				It("writes the contexts code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(dateTimeContext))
+					Ω(written).Should(ContainSubstring(dateTimeContextFactory))
+				})
+			})
+
+			Context("with a UUID param", func() {
+				BeforeEach(func() {
+					uuidParam := &design.AttributeDefinition{Type: design.UUID}
+					dataType := design.Object{
+						"param": uuidParam,
+					}
+					params = &design.AttributeDefinition{
+						Type: dataType,
+					}
+				})
+
+				It("writes the contexts code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(uuidContext))
+					Ω(written).Should(ContainSubstring(uuidContextFactory))
+				})
+			})
+
+			Context("with a param with a default value", func() {
+				BeforeEach(func() {
+					intParam := &design.AttributeDefinition{Type: design.Integer, DefaultValue: 10}
+					dataType := design.Object{
+						"param": intParam,
+					}
+					params = &design.AttributeDefinition{
+						Type: dataType,
+					}
+				})
+
+				It("writes the contexts code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(defaultContextFactory))
+				})
+			})
+
 			Context("with a boolean param", func() {
 				BeforeEach(func() {
 					boolParam := &design.AttributeDefinition{Type: design.Boolean}
@@ -218,6 +293,60 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with a multi array param", func() {
+				BeforeEach(func() {
+					str := &design.AttributeDefinition{Type: design.String}
+					arrayParam := &design.AttributeDefinition{
+						Type:             &design.Array{ElemType: str},
+						CollectionFormat: "multi",
+					}
+					dataType := design.Object{
+						"param": arrayParam,
+					}
+					params = &design.AttributeDefinition{
+						Type: dataType,
+					}
+				})
+
+				It("writes the contexts code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(arrayContext))
+					Ω(written).Should(ContainSubstring(multiArrayContextFactory))
+				})
+			})
+
+			Context("with a pipes integer array param", func() {
+				BeforeEach(func() {
+					i := &design.AttributeDefinition{Type: design.Integer}
+					intArrayParam := &design.AttributeDefinition{
+						Type:             &design.Array{ElemType: i},
+						CollectionFormat: "pipes",
+					}
+					dataType := design.Object{
+						"param": intArrayParam,
+					}
+					params = &design.AttributeDefinition{
+						Type: dataType,
+					}
+				})
+
+				It("writes the contexts code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(intArrayContext))
+					Ω(written).Should(ContainSubstring(pipesIntArrayContextFactory))
+				})
+			})
+
 			Context("with an param using a reserved keyword as name", func() {
 				BeforeEach(func() {
 					intParam := &design.AttributeDefinition{Type: design.Integer}
@@ -268,6 +397,38 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with typed headers", func() {
+				BeforeEach(func() {
+					count := &design.AttributeDefinition{
+						Type: design.Integer,
+						Validations: []design.ValidationDefinition{
+							&design.MinimumValidationDefinition{Min: 1},
+						},
+					}
+					mode := &design.AttributeDefinition{Type: design.String}
+					headers = &design.AttributeDefinition{
+						Type: design.Object{
+							"X-Count": count,
+							"X-Mode":  mode,
+						},
+						Validations: []design.ValidationDefinition{
+							&design.RequiredValidationDefinition{Names: []string{"X-Mode"}},
+						},
+					}
+				})
+
+				It("writes the contexts code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(headersContext))
+					Ω(written).Should(ContainSubstring(headersContextFactory))
+				})
+			})
+
 			Context("with a simple payload", func() {
 				BeforeEach(func() {
 					payload = &design.UserTypeDefinition{
@@ -320,6 +481,77 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with a payload decoded directly", func() {
+				BeforeEach(func() {
+					dataType := design.Object{
+						"int": &design.AttributeDefinition{
+							Type: design.Integer,
+							Validations: []design.ValidationDefinition{
+								&design.MinimumValidationDefinition{Min: 1},
+							},
+						},
+						"str": &design.AttributeDefinition{
+							Type:         design.String,
+							DefaultValue: "foo",
+						},
+					}
+					required := design.RequiredValidationDefinition{
+						Names: []string{"int"},
+					}
+					payload = &design.UserTypeDefinition{
+						AttributeDefinition: &design.AttributeDefinition{
+							Type:        dataType,
+							Validations: []design.ValidationDefinition{&required},
+						},
+						TypeName: "ListBottlePayload",
+					}
+					typedDecode = true
+				})
+
+				It("writes the contexts code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(payloadObjContext))
+					Ω(written).Should(ContainSubstring(payloadTypedContextFactory))
+					Ω(written).Should(ContainSubstring(payloadDecoder))
+				})
+			})
+
+			Context("with a multipart payload", func() {
+				BeforeEach(func() {
+					dataType := design.Object{
+						"label": &design.AttributeDefinition{Type: design.File},
+						"year":  &design.AttributeDefinition{Type: design.Integer},
+					}
+					required := design.RequiredValidationDefinition{
+						Names: []string{"label"},
+					}
+					payload = &design.UserTypeDefinition{
+						AttributeDefinition: &design.AttributeDefinition{
+							Type:        dataType,
+							Validations: []design.ValidationDefinition{&required},
+						},
+						TypeName: "ListBottlePayload",
+					}
+					multipart = true
+				})
+
+				It("writes the contexts code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(payloadObjContext))
+					Ω(written).Should(ContainSubstring(payloadMultipartFactory))
+				})
+			})
+
 		})
 	})
 })
@@ -347,6 +579,13 @@ var _ = Describe("ControllersWriter", func() {
 
 		Context("with data", func() {
 			var actions, verbs, paths, contexts []string
+			var consumes []string
+			var security *design.SecurityDefinition
+			var deprecation *design.DeprecationDefinition
+			var maxBodySize int64
+			var rawBody bool
+			var decodeFunc string
+			var checkFormats bool
 
 			var data []*genapp.ControllerTemplateData
 
@@ -355,11 +594,19 @@ var _ = Describe("ControllersWriter", func() {
 				verbs = nil
 				paths = nil
 				contexts = nil
+				consumes = nil
+				security = nil
+				deprecation = nil
+				maxBodySize = 0
+				rawBody = false
+				decodeFunc = ""
+				checkFormats = false
 			})
 
 			JustBeforeEach(func() {
 				d := &genapp.ControllerTemplateData{
-					Resource: "Bottles",
+					Resource:     "Bottles",
+					CheckFormats: checkFormats,
 				}
 				as := make([]map[string]interface{}, len(actions))
 				for i, a := range actions {
@@ -370,7 +617,13 @@ var _ = Describe("ControllersWriter", func() {
 								Verb: verbs[i],
 								Path: paths[i],
 							}},
-						"Context": contexts[i],
+						"Context":     contexts[i],
+						"Consumes":    consumes,
+						"Security":    security,
+						"Deprecation": deprecation,
+						"MaxBodySize": maxBodySize,
+						"RawBody":     rawBody,
+						"DecodeFunc":  decodeFunc,
 					}
 				}
 				if len(as) > 0 {
@@ -420,17 +673,336 @@ var _ = Describe("ControllersWriter", func() {
 					contexts = []string{"ListBottleContext", "ShowBottleContext"}
 				})
 
-				It("writes the controllers code", func() {
-					err := writer.Execute(data)
-					Ω(err).ShouldNot(HaveOccurred())
-					b, err := ioutil.ReadFile(filename)
-					Ω(err).ShouldNot(HaveOccurred())
-					written := string(b)
-					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(multiController))
-					Ω(written).Should(ContainSubstring(multiMount))
-				})
+				It("writes the controllers code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(multiController))
+					Ω(written).Should(ContainSubstring(multiMount))
+				})
+			})
+
+			Context("with consumed media types", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					consumes = []string{"application/json", "application/xml"}
+				})
+
+				It("restricts the content types accepted by the action", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(consumesMount))
+				})
+			})
+
+			Context("with a raw body and a maximum body size", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					maxBodySize = 1024
+					rawBody = true
+				})
+
+				It("configures the action body handling", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(rawBodyMount))
+				})
+			})
+
+			Context("with a payload decode function", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"POST"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					decodeFunc = "DecodeListBottlePayload"
+				})
+
+				It("registers the decode function", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(decodeFuncMount))
+				})
+			})
+
+			Context("with custom formats", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					checkFormats = true
+				})
+
+				It("checks the registration of the custom formats", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(checkFormatsMount))
+				})
+			})
+
+			Context("with a security requirement", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					security = &design.SecurityDefinition{Scheme: "jwt", Scopes: []string{"read", "write"}}
+				})
+
+				It("wraps the action handler with the security middleware", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(securedMount))
+				})
+			})
+
+			Context("with a deprecated action", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					deprecation = &design.DeprecationDefinition{
+						Sunset: time.Date(2016, time.December, 31, 0, 0, 0, 0, time.UTC),
+					}
+				})
+
+				It("flags the action as deprecated", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(deprecatedMount))
+				})
+			})
+		})
+	})
+})
+
+var _ = Describe("ErrorsWriter", func() {
+	var writer *genapp.ErrorsWriter
+	var filename string
+
+	JustBeforeEach(func() {
+		var err error
+		writer, err = genapp.NewErrorsWriter(filename)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	Context("correctly configured", func() {
+		var f *os.File
+		BeforeEach(func() {
+			f, _ = ioutil.TempFile("", "")
+			filename = f.Name()
+		})
+
+		AfterEach(func() {
+			os.Remove(filename)
+		})
+
+		Context("with data", func() {
+			var mediaType *design.MediaTypeDefinition
+
+			var data *genapp.ErrorKindTemplateData
+
+			BeforeEach(func() {
+				mediaType = nil
+			})
+
+			JustBeforeEach(func() {
+				data = &genapp.ErrorKindTemplateData{
+					Name:        "bottle_not_found",
+					Description: "The bottle does not exist",
+					Status:      404,
+					MediaType:   mediaType,
+				}
+			})
+
+			It("writes the error constructor code", func() {
+				err := writer.Execute(data)
+				Ω(err).ShouldNot(HaveOccurred())
+				b, err := ioutil.ReadFile(filename)
+				Ω(err).ShouldNot(HaveOccurred())
+				written := string(b)
+				Ω(written).Should(ContainSubstring(simpleError))
+			})
+
+			Context("with a media type", func() {
+				BeforeEach(func() {
+					attDef := &design.AttributeDefinition{
+						Type: design.Object{"name": &design.AttributeDefinition{Type: design.String}},
+					}
+					mediaType = &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: attDef,
+							TypeName:            "Bottle",
+						},
+						Identifier: "application/vnd.bottle+json",
+						Views: map[string]*design.ViewDefinition{
+							"default": &design.ViewDefinition{AttributeDefinition: attDef, Name: "default"},
+						},
+					}
+				})
+
+				It("writes the error constructor code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(mediaTypeError))
+				})
+			})
+		})
+	})
+})
+
+var _ = Describe("SecurityWriter", func() {
+	var writer *genapp.SecurityWriter
+	var filename string
+
+	JustBeforeEach(func() {
+		var err error
+		writer, err = genapp.NewSecurityWriter(filename)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	Context("correctly configured", func() {
+		var f *os.File
+		BeforeEach(func() {
+			f, _ = ioutil.TempFile("", "")
+			filename = f.Name()
+		})
+
+		AfterEach(func() {
+			os.Remove(filename)
+		})
+
+		Context("with a basic auth scheme", func() {
+			It("writes the security middleware code", func() {
+				scheme := &design.SecuritySchemeDefinition{Name: "basic", Kind: design.BasicAuthSecurityKind}
+				err := writer.Execute(scheme)
+				Ω(err).ShouldNot(HaveOccurred())
+				b, err := ioutil.ReadFile(filename)
+				Ω(err).ShouldNot(HaveOccurred())
+				written := string(b)
+				Ω(written).Should(ContainSubstring(basicSecurity))
+			})
+		})
+
+		Context("with a JWT scheme", func() {
+			It("writes the security middleware code", func() {
+				scheme := &design.SecuritySchemeDefinition{
+					Name:      "jwt",
+					Kind:      design.JWTSecurityKind,
+					In:        "header",
+					ParamName: "Authorization",
+				}
+				err := writer.Execute(scheme)
+				Ω(err).ShouldNot(HaveOccurred())
+				b, err := ioutil.ReadFile(filename)
+				Ω(err).ShouldNot(HaveOccurred())
+				written := string(b)
+				Ω(written).Should(ContainSubstring(jwtSecurity))
+			})
+		})
+	})
+})
+
+var _ = Describe("ValidatorsWriter", func() {
+	var writer *genapp.ValidatorsWriter
+	var filename string
+
+	JustBeforeEach(func() {
+		var err error
+		writer, err = genapp.NewValidatorsWriter(filename)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	Context("correctly configured", func() {
+		var f *os.File
+		BeforeEach(func() {
+			f, _ = ioutil.TempFile("", "")
+			filename = f.Name()
+		})
+
+		AfterEach(func() {
+			os.Remove(filename)
+		})
+
+		It("writes the compiled patterns", func() {
+			writer.AddValidations(&design.AttributeDefinition{
+				Type: design.Object{
+					"name": {
+						Type: design.String,
+						Validations: []design.ValidationDefinition{
+							&design.PatternValidationDefinition{Pattern: `^[a-z]+$`},
+						},
+					},
+					"tags": {
+						Type: &design.Array{ElemType: &design.AttributeDefinition{
+							Type: design.String,
+							Validations: []design.ValidationDefinition{
+								&design.PatternValidationDefinition{Pattern: "^`$"},
+							},
+						}},
+					},
+				},
+			})
+			err := writer.Execute()
+			Ω(err).ShouldNot(HaveOccurred())
+			b, err := ioutil.ReadFile(filename)
+			Ω(err).ShouldNot(HaveOccurred())
+			written := string(b)
+			Ω(written).Should(ContainSubstring(compiledPatterns))
+		})
+
+		It("writes the custom formats check", func() {
+			writer.AddValidations(&design.AttributeDefinition{
+				Type: design.String,
+				Validations: []design.ValidationDefinition{
+					&design.FormatValidationDefinition{Format: "semver"},
+				},
+			})
+			writer.AddValidations(&design.AttributeDefinition{
+				Type: design.String,
+				Validations: []design.ValidationDefinition{
+					&design.FormatValidationDefinition{Format: "email"},
+				},
 			})
+			err := writer.Execute()
+			Ω(err).ShouldNot(HaveOccurred())
+			b, err := ioutil.ReadFile(filename)
+			Ω(err).ShouldNot(HaveOccurred())
+			written := string(b)
+			Ω(written).ShouldNot(ContainSubstring("regexp"))
+			Ω(written).Should(ContainSubstring(formatsCheck))
 		})
 	})
 })
@@ -542,6 +1114,59 @@ var _ = Describe("HrefWriter", func() {
 					})
 				})
 			})
+
+			Context("with action routes", func() {
+				var routes []*genapp.ActionRouteData
+
+				BeforeEach(func() {
+					routes = []*genapp.ActionRouteData{
+						{
+							Name:     "ShowBottlePath",
+							Resource: "bottle",
+							Action:   "show",
+							Verb:     "GET",
+							Template: "/bottles/%s%s",
+							Pattern:  "^/bottles/([^/]+)(/.*)/?$",
+							Params: []*genapp.PathParamData{
+								{Name: "id", Attribute: &design.AttributeDefinition{Type: design.Integer}},
+								{Name: "rest", Attribute: &design.AttributeDefinition{Type: design.String}, CatchAll: true},
+							},
+						},
+						{
+							Name:     "ListBottlePath",
+							Resource: "bottle",
+							Action:   "list",
+							Verb:     "GET",
+							Template: "/bottles",
+							Pattern:  "^/bottles/?$",
+						},
+					}
+				})
+
+				JustBeforeEach(func() {
+					data.Routes = routes
+				})
+
+				It("writes the path builders", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(showPathBuilder))
+					Ω(written).Should(ContainSubstring(listPathBuilder))
+				})
+
+				It("writes the href parser", func() {
+					err := writer.ExecuteParser(routes)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(hrefRoutes))
+					Ω(written).Should(ContainSubstring(showHrefParser))
+				})
+			})
 		})
 	})
 })
@@ -634,6 +1259,108 @@ func NewListBottleContext(c *goa.Context) (*ListBottleContext, error) {
 	return &ctx, err
 }
 `
+	dateTimeContext = `
+type ListBottleContext struct {
+	*goa.Context
+	Param time.Time
+
+	HasParam bool
+}
+`
+
+	dateTimeContextFactory = `
+func NewListBottleContext(c *goa.Context) (*ListBottleContext, error) {
+	var err error
+	ctx := ListBottleContext{Context: c}
+	rawParam, ok := c.Get("param")
+	if ok {
+		if param, err2 := time.Parse(time.RFC3339, rawParam); err2 == nil {
+			ctx.Param = param
+		} else {
+			err = goa.InvalidParamTypeError("param", rawParam, "datetime", err)
+		}
+		ctx.HasParam = true
+	}
+	return &ctx, err
+}
+`
+	uuidContext = `
+type ListBottleContext struct {
+	*goa.Context
+	Param goa.UUID
+
+	HasParam bool
+}
+`
+
+	uuidContextFactory = `
+func NewListBottleContext(c *goa.Context) (*ListBottleContext, error) {
+	var err error
+	ctx := ListBottleContext{Context: c}
+	rawParam, ok := c.Get("param")
+	if ok {
+		if param, err2 := goa.UUIDFromString(rawParam); err2 == nil {
+			ctx.Param = param
+		} else {
+			err = goa.InvalidParamTypeError("param", rawParam, "uuid", err)
+		}
+		ctx.HasParam = true
+	}
+	return &ctx, err
+}
+`
+	defaultContextFactory = `
+func NewListBottleContext(c *goa.Context) (*ListBottleContext, error) {
+	var err error
+	ctx := ListBottleContext{Context: c}
+	rawParam, ok := c.Get("param")
+	if ok {
+		if param, err2 := strconv.Atoi(rawParam); err2 == nil {
+			ctx.Param = int(param)
+		} else {
+			err = goa.InvalidParamTypeError("param", rawParam, "integer", err)
+		}
+		ctx.HasParam = true
+	} else {
+		ctx.Param = 10
+	}
+	return &ctx, err
+}
+`
+
+	headersContext = `
+type ListBottleContext struct {
+	*goa.Context
+	XCount int
+	XMode string
+}
+`
+
+	headersContextFactory = `
+func NewListBottleContext(c *goa.Context) (*ListBottleContext, error) {
+	var err error
+	ctx := ListBottleContext{Context: c}
+	rawXCount := c.Request().Header.Get("X-Count")
+	if rawXCount != "" {
+		if xCount, err2 := strconv.Atoi(rawXCount); err2 == nil {
+			ctx.XCount = int(xCount)
+		} else {
+			err = goa.InvalidHeaderTypeError("X-Count", rawXCount, "integer", err)
+		}
+	if ctx.XCount < 1 {
+			err = goa.InvalidRangeError(` + "`" + `X-Count` + "`" + `, ctx.XCount, 1, true, err)
+	}
+	}
+	rawXMode := c.Request().Header.Get("X-Mode")
+	if rawXMode == "" {
+		err = goa.MissingHeaderError("X-Mode", err)
+	} else {
+		ctx.XMode = rawXMode
+	}
+	return &ctx, err
+}
+`
+
 	boolContext = `
 type ListBottleContext struct {
 	*goa.Context
@@ -712,6 +1439,42 @@ func NewListBottleContext(c *goa.Context) (*ListBottleContext, error) {
 	}
 	return &ctx, err
 }
+`
+
+	multiArrayContextFactory = `
+func NewListBottleContext(c *goa.Context) (*ListBottleContext, error) {
+	var err error
+	ctx := ListBottleContext{Context: c}
+	rawParam := c.GetMany("param")
+	if len(rawParam) > 0 {
+		elemsParam := rawParam
+		ctx.Param = elemsParam
+		ctx.HasParam = true
+	}
+	return &ctx, err
+}
+`
+
+	pipesIntArrayContextFactory = `
+func NewListBottleContext(c *goa.Context) (*ListBottleContext, error) {
+	var err error
+	ctx := ListBottleContext{Context: c}
+	rawParam, ok := c.Get("param")
+	if ok {
+		elemsParam := strings.Split(rawParam, "|")
+		elemsParam2 := make([]int, len(elemsParam))
+		for i, rawElem := range elemsParam {
+			if elem, err2 := strconv.Atoi(rawElem); err2 == nil {
+				elemsParam2[i] = int(elem)
+			} else {
+				err = goa.InvalidParamTypeError("elem", rawElem, "integer", err)
+			}
+		}
+		ctx.Param = elemsParam2
+		ctx.HasParam = true
+	}
+	return &ctx, err
+}
 `
 
 	resContext = `
@@ -789,6 +1552,73 @@ type ListBottleContext struct {
 	*goa.Context
 	Payload *ListBottlePayload
 }
+`
+
+	payloadTypedContextFactory = `
+func NewListBottleContext(c *goa.Context) (*ListBottleContext, error) {
+	var err error
+	ctx := ListBottleContext{Context: c}
+	p, ok := c.Payload().(*ListBottlePayload)
+	if !ok {
+		p, err = NewListBottlePayload(c.Payload())
+		if err != nil {
+			return nil, err
+		}
+	}
+	ctx.Payload = p
+	return &ctx, err
+}
+`
+
+	payloadDecoder = `
+// listBottlePayloadBody is the type the JSON request bodies of the bottles list action are
+// decoded into, its pointer fields make it possible to tell missing attributes from zero values.
+type listBottlePayloadBody struct {
+	Int *int ` + "`json:\"int\"`" + `
+	Str *string ` + "`json:\"str\"`" + `
+}
+
+// DecodeListBottlePayload decodes a JSON request body directly into a ListBottlePayload.
+// It validates each field and returns a BadRequestError if any validation fails.
+// Bodies that do not decode into the payload type are coerced by NewListBottlePayload instead
+// so that they produce the same errors as generically decoded bodies.
+func DecodeListBottlePayload(dec goa.Decoder) (interface{}, error) {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	var body listBottlePayloadBody
+	if err := json.Unmarshal(raw, &body); err != nil {
+		var generic interface{}
+		if err := json.Unmarshal(raw, &generic); err != nil {
+			return nil, err
+		}
+		p, err := NewListBottlePayload(generic)
+		if err != nil {
+			return nil, goa.NewBadRequestError(err)
+		}
+		return p, nil
+	}
+	var err error
+	p := new(ListBottlePayload)
+	if body.Int != nil {
+		p.Int = *body.Int
+		if p.Int < 1 {
+				err = goa.InvalidRangeError(` + "`payload.Int`" + `, p.Int, 1, true, err)
+		}
+	} else {
+		err = goa.MissingAttributeError(` + "`payload`" + `, "int", err)
+	}
+	if body.Str != nil {
+		p.Str = *body.Str
+	} else {
+		p.Str = "foo"
+	}
+	if err != nil {
+		return nil, goa.NewBadRequestError(err)
+	}
+	return p, nil
+}
 `
 
 	payloadObjContextFactory = `
@@ -802,6 +1632,31 @@ func NewListBottleContext(c *goa.Context) (*ListBottleContext, error) {
 	ctx.Payload = p
 	return &ctx, err
 }
+`
+
+	payloadMultipartFactory = `
+func NewListBottlePayload(raw interface{}) (p *ListBottlePayload, err error) {
+	form, ok := raw.(*multipart.Form)
+	if !ok {
+		err = goa.InvalidAttributeTypeError("payload", raw, "multipart form", err)
+		return
+	}
+	p = new(ListBottlePayload)
+	if files := form.File["label"]; len(files) > 0 {
+		p.Label = files[0]
+	} else {
+		err = goa.MissingAttributeError("payload", "label", err)
+	}
+	if vals := form.Value["year"]; len(vals) > 0 {
+		rawYear := vals[0]
+		if year, err2 := strconv.Atoi(rawYear); err2 == nil {
+			p.Year = int(year)
+		} else {
+			err = goa.InvalidParamTypeError("year", rawYear, "integer", err)
+		}
+	}
+	return
+}
 `
 
 	simpleController = `// BottlesController is the controller interface for the Bottles actions.
@@ -826,6 +1681,91 @@ type BottlesController interface {
 }
 `
 
+	securedMount = `		return ctrl.list(ctx)
+	}
+	h = jwtSecurity("read", "write")(h)
+	router.Handle("GET", "/accounts/:accountID/bottles", ctrl.NewHTTPRouterHandle("list", h))
+`
+
+	deprecatedMount = `		return ctrl.list(ctx)
+	}
+	ctrl.SetDeprecated("list", time.Date(2016, time.December, 31, 0, 0, 0, 0, time.UTC))
+	router.Handle("GET", "/accounts/:accountID/bottles", ctrl.NewHTTPRouterHandle("list", h))
+`
+
+	basicSecurity = `// basicValidator validates the credentials of requests made to actions secured with the
+// "basic" security scheme.
+var basicValidator goa.BasicAuthValidator
+
+// SetBasicValidator sets the function used to validate the credentials of requests made
+// to actions secured with the "basic" security scheme.
+// These requests are rejected with status code 401 until a validator is set.
+func SetBasicValidator(f goa.BasicAuthValidator) {
+	basicValidator = f
+}
+
+// basicSecurity returns the middleware that enforces the "basic" security scheme.
+func basicSecurity() goa.Middleware {
+	return goa.BasicAuthSecurity(func(ctx *goa.Context, username, password string) error {
+		if basicValidator == nil {
+			return fmt.Errorf("no validator set for security scheme %#v", "basic")
+		}
+		return basicValidator(ctx, username, password)
+	})
+}
+`
+
+	jwtSecurity = `// jwtSecurity returns the middleware that enforces the "jwt" security scheme.
+func jwtSecurity(scopes ...string) goa.Middleware {
+	return goa.TokenSecurity("header", "Authorization", scopes, func(ctx *goa.Context, token string, scopes []string) error {
+		if jwtValidator == nil {
+			return fmt.Errorf("no validator set for security scheme %#v", "jwt")
+		}
+		return jwtValidator(ctx, token, scopes)
+	})
+}
+`
+
+	consumesMount = `		return ctrl.list(ctx)
+	}
+	ctrl.SetConsumes("list", "application/json", "application/xml")
+	router.Handle("GET", "/accounts/:accountID/bottles", ctrl.NewHTTPRouterHandle("list", h))
+`
+
+	decodeFuncMount = `		return ctrl.list(ctx)
+	}
+	ctrl.SetDecodeFunc("list", DecodeListBottlePayload)
+	router.Handle("POST", "/accounts/:accountID/bottles", ctrl.NewHTTPRouterHandle("list", h))
+`
+
+	checkFormatsMount = `func MountBottlesController(service goa.Service, ctrl BottlesController) {
+	checkFormats(service)
+	router := service.HTTPHandler().(*httprouter.Router)
+`
+
+	formatsCheck = `
+// checkFormats logs an error for each custom format used by the validations that has no validator
+// registered with goa.RegisterFormat. The controller mount functions call it so that a missing
+// registration shows up when the application starts rather than when a request gets validated.
+func checkFormats(service goa.Service) {
+	formats := []goa.Format{
+		"semver",
+	}
+	for _, f := range formats {
+		if goa.LookupFormat(f) == nil {
+			service.Error("custom format has no registered validator", "format", f)
+		}
+	}
+}
+`
+
+	rawBodyMount = `		return ctrl.list(ctx)
+	}
+	ctrl.SetMaxBodySize("list", 1024)
+	ctrl.SetRawBody("list")
+	router.Handle("GET", "/accounts/:accountID/bottles", ctrl.NewHTTPRouterHandle("list", h))
+`
+
 	multiController = `// BottlesController is the controller interface for the Bottles actions.
 type BottlesController interface {
 	goa.Controller
@@ -856,6 +1796,88 @@ type BottlesController interface {
 	router.Handle("GET", "/accounts/:accountID/bottles/:id", ctrl.NewHTTPRouterHandle("show", h))
 	service.Info("mount", "ctrl", "Bottles", "action", "show", "route", "GET /accounts/:accountID/bottles/:id")
 }
+`
+
+	compiledPatterns = `var (
+	patternc182f89fdb221836 = regexp.MustCompile(` + "`^[a-z]+$`" + `)
+	patternc382c81a131429b5 = regexp.MustCompile("^` + "`" + `$")
+)
+`
+
+	simpleError = `// NewBottleNotFoundError creates an error of kind "bottle_not_found".
+// The bottle does not exist
+// Actions that return the error send a response with status code 404.
+func NewBottleNotFoundError(err error) error {
+	return &goa.ResponseError{
+		Kind:   "bottle_not_found",
+		Status: 404,
+		Actual: err,
+	}
+}
+`
+
+	mediaTypeError = `func NewBottleNotFoundError(resp *Bottle) error {
+	r, err := resp.Dump()
+	if err != nil {
+		return fmt.Errorf("invalid response: %s", err)
+	}
+	return &goa.ResponseError{
+		Kind:      "bottle_not_found",
+		Status:    404,
+		MediaType: "application/vnd.bottle+json",
+		Body:      r,
+	}
+}
+`
+
+	showPathBuilder = `
+// ShowBottlePath returns the URL path to the bottle show action (GET).
+func ShowBottlePath(id int, rest string) string {
+	param0 := goa.EscapePathSegment(strconv.Itoa(id))
+	param1 := goa.EscapePath("/" + strings.TrimPrefix(rest, "/"))
+	return fmt.Sprintf("/bottles/%s%s", param0, param1)
+}
+`
+
+	listPathBuilder = `
+// ListBottlePath returns the URL path to the bottle list action (GET).
+func ListBottlePath() string {
+	return "/bottles"
+}
+`
+
+	hrefRoutes = `
+var hrefRoutes = []struct {
+	resource string
+	action   string
+	pattern  *regexp.Regexp
+	parse    func([]string) (map[string]interface{}, error)
+}{
+	{"bottle", "show", regexp.MustCompile(` + "`^/bottles/([^/]+)(/.*)/?$`" + `), parseShowBottlePath},
+	{"bottle", "list", regexp.MustCompile(` + "`^/bottles/?$`" + `), parseListBottlePath},
+}
+`
+
+	showHrefParser = `
+func parseShowBottlePath(raw []string) (map[string]interface{}, error) {
+	var err error
+	hrefParams := make(map[string]interface{}, 2)
+	rawID, err2 := goa.UnescapePathSegment(raw[0])
+	if err2 != nil {
+		return nil, err2
+	}
+	if id, err2 := strconv.Atoi(rawID); err2 == nil {
+		hrefParams["id"] = int(id)
+	} else {
+		err = goa.InvalidParamTypeError("id", rawID, "integer", err)
+	}
+	rawRest, err2 := goa.UnescapePathSegment(raw[1])
+	if err2 != nil {
+		return nil, err2
+	}
+	hrefParams["rest"] = rawRest
+	return hrefParams, err
+}
 `
 
 	simpleResourceHref = `func BottleHref(id interface{}) string {
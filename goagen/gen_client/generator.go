@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strings"
 	"text/template"
+	"time"
 	"unicode"
 
 	"github.com/raphael/goa/design"
@@ -68,7 +69,7 @@ func (g *Generator) Generate(api *design.APIDefinition) (_ []string, err error)
 		"goify":        codegen.Goify,
 		"gotypedef":    codegen.GoTypeDef,
 		"gotyperefext": goTypeRefExt,
-		"nativeType":   codegen.GoNativeType,
+		"nativeType":   cmdFieldType,
 		"joinNames":    joinNames,
 		"join":         join,
 		"toString":     toString,
@@ -77,6 +78,9 @@ func (g *Generator) Generate(api *design.APIDefinition) (_ []string, err error)
 		"flagType":     flagType,
 		"enumOptions":  enumOptions,
 		"defaultPath":  defaultPath,
+		"multipartDef": multipartPayloadDef,
+		"formType":     formFieldType,
+		"deprecation":  deprecationWarning,
 	}
 	clientPkg, err := filepath.Rel(os.Getenv("GOPATH"), codegen.OutputDir)
 	clientPkg = strings.TrimPrefix(clientPkg, "src/")
@@ -94,6 +98,7 @@ func (g *Generator) Generate(api *design.APIDefinition) (_ []string, err error)
 	g.genfiles = append(g.genfiles, mainFile)
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("os"),
+		codegen.SimpleImport("github.com/raphael/goa"),
 		codegen.SimpleImport(clientPkg),
 		codegen.SimpleImport("gopkg.in/alecthomas/kingpin.v2"),
 	}
@@ -101,9 +106,14 @@ func (g *Generator) Generate(api *design.APIDefinition) (_ []string, err error)
 		imports = append(imports, codegen.SimpleImport(pkg))
 	}
 	gg.WriteHeader("", "main", imports)
+	signers := make([]string, len(Signers))
+	for i, s := range Signers {
+		signers[i] = s + "{}"
+	}
+	signers = append(signers, securitySigners(api, Signers)...)
 	data := map[string]interface{}{
 		"API":     api,
-		"Signers": Signers,
+		"Signers": signers,
 		"Version": Version,
 	}
 	if err = tmpl.Execute(gg, data); err != nil {
@@ -283,7 +293,7 @@ func join(att *design.AttributeDefinition) string {
 	elems := make([]string, len(obj))
 	i := 0
 	for n, a := range obj {
-		elems[i] = fmt.Sprintf("%s %s", n, codegen.GoNativeType(a.Type))
+		elems[i] = fmt.Sprintf("%s %s", n, cmdFieldType(a.Type))
 		i++
 	}
 	sort.Strings(elems)
@@ -314,16 +324,17 @@ func toString(name, target string, att *design.AttributeDefinition) string {
 			return fmt.Sprintf("%s := strconv.FormatBool(%s)", target, name)
 		case design.NumberKind:
 			return fmt.Sprintf("%s := strconv.FormatFloat(%s, 'f', -1, 64)", target, name)
-		case design.StringKind:
+		case design.StringKind, design.DateTimeKind, design.UUIDKind, design.AnyKind:
 			return fmt.Sprintf("%s := %s", target, name)
 		default:
 			panic("unknown primitive type")
 		}
 	case *design.Array:
 		data := map[string]interface{}{
-			"Name":     name,
-			"Target":   target,
-			"ElemType": actual.ElemType,
+			"Name":      name,
+			"Target":    target,
+			"ElemType":  actual.ElemType,
+			"Separator": att.CollectionSeparator(),
 		}
 		return codegen.RunTemplate(arrayToStringTmpl, data)
 	default:
@@ -331,6 +342,23 @@ func toString(name, target string, att *design.AttributeDefinition) string {
 	}
 }
 
+// deprecationWarning returns the warning printed by the command line tool when the given action
+// is deprecated, the empty string if it is not.
+func deprecationWarning(action *design.ActionDefinition) string {
+	dep := action.Deprecated()
+	if dep == nil {
+		return ""
+	}
+	warning := fmt.Sprintf("warning: the %s action of %s is deprecated", action.Name, action.Parent.Name)
+	if !dep.Sunset.IsZero() {
+		warning += fmt.Sprintf(" and may stop being available after %s", dep.Sunset.Format(time.RFC3339))
+	}
+	if dep.Description != "" {
+		warning += ": " + dep.Description
+	}
+	return warning
+}
+
 // flagType returns the kingpin flag type for the given (basic type) attribute definition.
 func flagType(att *design.AttributeDefinition) string {
 	var enum *design.EnumValidationDefinition
@@ -353,7 +381,7 @@ func flagType(att *design.AttributeDefinition) string {
 		return "Float64"
 	case design.BooleanKind:
 		return "Bool"
-	case design.StringKind:
+	case design.StringKind, design.FileKind, design.DateTimeKind, design.UUIDKind, design.AnyKind:
 		return "String"
 	case design.ArrayKind:
 		return flagType(att.Type.(*design.Array).ElemType) + "s"
@@ -366,6 +394,49 @@ func flagType(att *design.AttributeDefinition) string {
 	}
 }
 
+// formFieldType returns the Go type of the client field that holds the value of the given multipart
+// payload attribute type. Files are represented by the path to the file to upload.
+func formFieldType(t design.DataType) string {
+	if t.Kind() == design.FileKind {
+		return "string"
+	}
+	if arr, ok := t.(*design.Array); ok {
+		return "[]" + formFieldType(arr.ElemType.Type)
+	}
+	return cmdFieldType(t)
+}
+
+// cmdFieldType returns the Go type of the command field and client method argument that holds the
+// value of a parameter or header of the given type. Date times, UUIDs and values of type Any are
+// given on the command line in their string representation.
+func cmdFieldType(t design.DataType) string {
+	switch t.Kind() {
+	case design.DateTimeKind, design.UUIDKind, design.AnyKind:
+		return "string"
+	case design.ArrayKind:
+		return "[]" + cmdFieldType(t.ToArray().ElemType.Type)
+	}
+	return codegen.GoNativeType(t)
+}
+
+// multipartPayloadDef returns the definition of the struct used by the client to initialize the
+// given multipart payload.
+func multipartPayloadDef(payload *design.AttributeDefinition) string {
+	var buffer bytes.Buffer
+	buffer.WriteString("struct {\n")
+	payload.Type.ToObject().IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+		if at.Description != "" {
+			buffer.WriteString(fmt.Sprintf("\t// %s\n", at.Description))
+		} else if at.Type.Kind() == design.FileKind {
+			buffer.WriteString("\t// Path to the file to upload\n")
+		}
+		buffer.WriteString(fmt.Sprintf("\t%s %s\n", codegen.Goify(n, true), formFieldType(at.Type)))
+		return nil
+	})
+	buffer.WriteString("}")
+	return buffer.String()
+}
+
 // enumOptions returns the enum values for the given attribute if any, empty string otherwise.
 func enumOptions(att *design.AttributeDefinition) string {
 	var enum *design.EnumValidationDefinition
@@ -397,6 +468,53 @@ func defaultPath(action *design.ActionDefinition) string {
 	return ""
 }
 
+// securitySigners returns the composite literals of the signers that implement the security
+// schemes defined in the API design, one per scheme. Schemes whose signer is identical to one
+// listed in signers or created for a previous scheme are skipped. Signers of API key and JWT
+// schemes whose type is already used get a flag named after the scheme so that the flags of the
+// client tool do not conflict.
+func securitySigners(api *design.APIDefinition, signers []string) []string {
+	types := make(map[string]bool, len(signers))
+	literals := make(map[string]bool, len(signers))
+	for _, s := range signers {
+		types[s] = true
+		literals[s+"{}"] = true
+	}
+	var res []string
+	api.IterateSecuritySchemes(func(s *design.SecuritySchemeDefinition) error {
+		var typ, fields string
+		switch s.Kind {
+		case design.BasicAuthSecurityKind:
+			typ = "goa.BasicSigner"
+		case design.APIKeySecurityKind:
+			typ = "goa.APIKeySigner"
+			fields = fmt.Sprintf("In: %q, Name: %q", s.In, s.ParamName)
+		case design.JWTSecurityKind:
+			if s.In == "query" {
+				typ = "goa.APIKeySigner"
+				fields = fmt.Sprintf("In: %q, Name: %q", s.In, s.ParamName)
+			} else {
+				typ = "goa.JWTSigner"
+				fields = fmt.Sprintf("Header: %q, Format: %q", s.ParamName, "Bearer %s")
+			}
+		case design.OAuth2SecurityKind:
+			typ = "goa.OAuth2Signer"
+		}
+		literal := typ + "{" + fields + "}"
+		if typ == "" || literals[literal] {
+			return nil
+		}
+		literals[literal] = true
+		if types[typ] && fields != "" {
+			literal = fmt.Sprintf("%s{%s, Flag: %q}", typ, fields, s.Name)
+		}
+		types[typ] = true
+		res = append(res, literal)
+		return nil
+	})
+	return res
+}
+
 const mainTmpl = `
 // PrettyPrint is true if the tool output should be formatted for human consumption.
 var PrettyPrint bool
@@ -439,7 +557,9 @@ func main() {
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
 		// Let user know if something went wrong
 		var sbody string
-		if len(body) > 0 {
+		if doc := goa.DecodeErrorDocument(resp, body); doc != nil {
+			sbody = ": " + doc.Error()
+		} else if len(body) > 0 {
 			sbody = ": " + string(body)
 		}
 		fmt.Printf("error: %d%s", resp.StatusCode, sbody)
@@ -484,7 +604,7 @@ func main() {
 
 {{if .Signers}}// RegisterSigners adds the supported signers to the command line.
 func RegisterSigners(app *kingpin.Application) (signers []goa.Signer) {
-{{range $signers := .Signers}}{{$tmp := tempvar}}	{{$tmp}} := &{{$signers}}{}
+{{range $signers := .Signers}}{{$tmp := tempvar}}	{{$tmp}} := &{{$signers}}
 	{{$tmp}}.RegisterFlags(app)
 	signers = append(signers, {{$tmp}})
 {{end}}	return
@@ -497,14 +617,16 @@ const arrayToStringT = `	{{$tmp := tempvar}}{{$tmp}} := make([]string, len({{.Na
 		{{$tmp2 := tempvar}}{{toString "e" $tmp2 .ElemType}}
 		{{$tmp}}[i] = {{$tmp2}}
 	}
-	{{.Target}} := strings.Join({{$tmp}}, ",")`
+	{{.Target}} := strings.Join({{$tmp}}, {{printf "%q" .Separator}})`
 
 const commandTypesTmpl = `{{$cmdName := goify (printf "%s%s%s" .Name (title .Parent.Name) "Command") true}}	// {{$cmdName}} is the command line data structure for the {{.Name}} action of {{.Parent.Name}}
 	{{$cmdName}} struct {
 		// Path is the HTTP request path.
 		Path string
-{{if .Payload}}		Payload string
-{{end}}{{$params := .QueryParams}}{{if $params}}{{range $name, $att := $params.Type.ToObject}}{{if $att.Description}}		// {{$att.Description}}
+{{if .Payload}}{{if .PayloadMultipart}}{{range $name, $att := .Payload.Type.ToObject}}{{if $att.Description}}		// {{$att.Description}}
+{{end}}		{{goify $name true}} {{formType $att.Type}}
+{{end}}{{else}}		Payload string
+{{end}}{{end}}{{$params := .QueryParams}}{{if $params}}{{range $name, $att := $params.Type.ToObject}}{{if $att.Description}}		// {{$att.Description}}
 {{end}}		{{goify $name true}} {{nativeType $att.Type}}
 {{end}}{{end}}{{$headers := .Headers}}{{if $headers}}{{range $name, $att := $headers.Type.ToObject}}{{if $att.Description}}		// {{$att.Description}}
 {{end}}		{{goify $name true}} string
@@ -514,7 +636,11 @@ const commandTypesTmpl = `{{$cmdName := goify (printf "%s%s%s" .Name (title .Par
 const commandsTmpl = `
 {{$cmdName := goify (printf "%s%sCommand" .Name (title .Parent.Name)) true}}// Run makes the HTTP request corresponding to the {{$cmdName}} command.
 func (cmd *{{$cmdName}}) Run(c *client.Client) (*http.Response, error) {
-{{if .Payload}}var payload {{gotyperefext .Payload 2 "client"}}
+{{$warning := deprecation .}}{{if $warning}}	fmt.Fprintln(os.Stderr, {{printf "%q" $warning}})
+{{end}}{{if .PayloadMultipart}}payload := client.{{goify (printf "%s%sPayload" .Name (title .Parent.Name)) true}}{
+{{range $name, $att := .Payload.Type.ToObject}}		{{goify $name true}}: cmd.{{goify $name true}},
+{{end}}	}
+{{else if .Payload}}var payload {{gotyperefext .Payload 2 "client"}}
 	if cmd.Payload != "" {
 		err := json.Unmarshal([]byte(cmd.Payload), &payload)
 		if err != nil {
@@ -530,19 +656,24 @@ func (cmd *{{$cmdName}}) Run(c *client.Client) (*http.Response, error) {
 // RegisterFlags registers the command flags with the command line.
 func (cmd *{{$cmdName}}) RegisterFlags(cc *kingpin.CmdClause) {
 {{$default := defaultPath .}}	cc.Arg("path", ` + "`" + `Request path{{if $default}}, default is "{{$default}}"{{else}}, format is {{(index .Routes 0).FullPath}}{{end}}` + "`" + `){{if $default}}.Default("{{$default}}"){{else}}.Required(){{end}}.StringVar(&cmd.Path)
-{{if .Payload}}	cc.Flag("payload", "Request JSON body").StringVar(&cmd.Payload)
-{{end}}{{$params := .QueryParams}}{{if $params}}{{range $name, $param := $params.Type.ToObject}}	cc.Flag("{{$name}}", "{{$param.Description}}"){{/*
+{{if .PayloadMultipart}}{{$payload := .Payload}}{{range $name, $att := .Payload.Type.ToObject}}	cc.Flag("{{$name}}", "{{if $att.Description}}{{$att.Description}}{{else if eq $att.Type.Kind 10}}Path to the file to upload{{end}}"){{/*
+	*/}}{{if $payload.IsRequired $name}}.Required(){{end}}{{/*
+	*/}}.{{flagType $att}}Var(&cmd.{{goify $name true}}{{enumOptions $att}})
+{{end}}{{else if .Payload}}	cc.Flag("payload", "Request JSON body").StringVar(&cmd.Payload)
+{{end}}{{$params := .QueryParams}}{{if $params}}{{range $name, $param := $params.Type.ToObject}}	cc.Flag("{{$name}}", "{{$param.Description}}{{if $param.Deprecation}}{{if $param.Description}} {{end}}(deprecated){{end}}"){{/*
 	*/}}{{if $params.IsRequired $name}}.Required(){{end}}{{/*
 	*/}}{{if $param.DefaultValue}}.Default({{printf "%#v" $param.DefaultValue}}){{end}}{{/*
 	*/}}.{{flagType $param}}Var(&cmd.{{goify $name true}}{{enumOptions $param}})
-{{end}}{{end}}{{$headers := .Headers}}{{if $headers}}{{range $name, $header := $headers.Type.ToObject}}	cc.Flag("{{$name}}", "{{$header.Description}}"){{/*
+{{end}}{{end}}{{$headers := .Headers}}{{if $headers}}{{range $name, $header := $headers.Type.ToObject}}	cc.Flag("{{$name}}", "{{$header.Description}}{{if $header.Deprecation}}{{if $header.Description}} {{end}}(deprecated){{end}}"){{/*
 	*/}}{{if $headers.IsRequired $name}}.Required(){{end}}{{/*
 	*/}}{{if $header.DefaultValue}}.Default({{printf "%#v" $header.DefaultValue}}){{end}}{{/*
 	*/}}.StringVar(&cmd.{{goify $name true}})
 {{end}}{{end}}}
 `
 
-const clientsTmpl = `{{$payload := goify (printf "%s%sPayload" .Name (title .Parent.Name)) true}}{{if .Payload}}// {{$payload}} is the data structure used to initialize the {{.Parent.Name}} {{.Name}} request body.
+const clientsTmpl = `{{$payload := goify (printf "%s%sPayload" .Name (title .Parent.Name)) true}}{{if .PayloadMultipart}}// {{$payload}} is the data structure used to initialize the {{.Parent.Name}} {{.Name}} multipart request body.
+type {{$payload}} {{multipartDef .Payload.AttributeDefinition}}
+{{else if .Payload}}// {{$payload}} is the data structure used to initialize the {{.Parent.Name}} {{.Name}} request body.
 type {{$payload}} {{gotypedef .Payload 1 true false}}
 
 {{end}}{{$funcName := goify (printf "%s%s" .Name (title .Parent.Name)) true}}{{$desc := .Description}}{{if $desc}}// {{$desc}}{{else}}// {{$funcName}} makes a request to the {{.Name}} action endpoint of the {{.Parent.Name}} resource{{end}}
@@ -550,17 +681,50 @@ func (c *Client) {{$funcName}}(path string{{if .Payload}}, payload {{if .Payload
 	*/}}{{$params := join .QueryParams}}{{if $params}}, {{$params}}{{end}}{{/*
 	*/}}{{$headers := join .Headers}}{{if $headers}}, {{$headers}}{{end}}) (*http.Response, error) {
 	var body io.Reader
-{{if .Payload}}	b, err := json.Marshal(payload)
+{{if .PayloadMultipart}}	mbody := new(bytes.Buffer)
+	mw := multipart.NewWriter(mbody)
+{{$mp := .Payload}}{{range $name, $att := .Payload.Type.ToObject}}{{$field := printf "payload.%s" (goify $name true)}}{{/*
+*/}}{{if eq $att.Type.Kind 10}}	if {{$field}} != "" {
+		if err := goa.WriteMultipartFile(mw, "{{$name}}", {{$field}}); err != nil {
+			return nil, fmt.Errorf("failed to upload file: %s", err)
+		}
+	}
+{{else if eq $att.Type.Kind 5}}	for _, e := range {{$field}} {
+{{if eq $att.Type.ToArray.ElemType.Type.Kind 10}}		if err := goa.WriteMultipartFile(mw, "{{$name}}", e); err != nil {
+			return nil, fmt.Errorf("failed to upload file: %s", err)
+		}
+{{else}}		{{$tmp := tempvar}}{{toString "e" $tmp $att.Type.ToArray.ElemType}}
+		mw.WriteField("{{$name}}", {{$tmp}})
+{{end}}	}
+{{else if $mp.IsRequired $name}}	{{$tmp := tempvar}}{{toString $field $tmp $att}}
+	mw.WriteField("{{$name}}", {{$tmp}})
+{{else}}	if {{$field}} != {{if eq $att.Type.Kind 4 11 12 13}}""{{else if eq $att.Type.Kind 1}}false{{else}}0{{end}} {
+		{{$tmp := tempvar}}{{toString $field $tmp $att}}
+		mw.WriteField("{{$name}}", {{$tmp}})
+	}
+{{end}}{{end}}	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to serialize body: %s", err)
+	}
+	body = mbody
+{{else if .Payload}}	b, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to serialize body: %s", err)
 	}
 	body = bytes.NewBuffer(b)
 {{end}}	u := url.URL{Host: c.Host, Scheme: c.Scheme, Path: path}
 {{$params := .QueryParams}}{{if $params}}{{if gt (len $params.Type.ToObject) 0}}	values := u.Query()
-{{range $name, $att := $params.Type.ToObject}}{{if (eq $att.Type.Kind 4)}}	values.Set("{{$name}}", {{goify $name false}})
-{{else}}{{$tmp := tempvar}}{{toString (goify $name false) $tmp $att}}
+{{range $name, $att := $params.Type.ToObject}}{{$v := goify $name false}}{{/*
+*/}}{{if eq $att.CollectionFormat "multi"}}	for _, e := range {{$v}} {
+		{{$tmp := tempvar}}{{toString "e" $tmp $att.Type.ToArray.ElemType}}
+		values.Add("{{$name}}", {{$tmp}})
+	}
+{{else}}{{$optional := and (eq $att.Type.Kind 5) (not ($params.IsRequired $name))}}{{/*
+*/}}{{if $optional}}	if len({{$v}}) > 0 {
+{{end}}	{{$tmp := tempvar}}{{toString $v $tmp $att}}
 	values.Set("{{$name}}", {{$tmp}})
-{{end}}{{end}}{{end}}{{end}}req, err := http.NewRequest({{$route := index .Routes 0}}"{{$route.Verb}}", u.String(), body)
+{{if $optional}}	}
+{{end}}{{end}}{{end}}	u.RawQuery = values.Encode()
+{{end}}{{end}}req, err := http.NewRequest({{$route := index .Routes 0}}"{{$route.Verb}}", u.String(), body)
 	if err != nil {
 		return nil, err
 	}
@@ -568,7 +732,7 @@ func (c *Client) {{$funcName}}(path string{{if .Payload}}, payload {{if .Payload
 {{if $headers}}{{range $name, $att := $params.Type.ToObject}}{{if (eq $att.Type.Kind 4)}}	header.Set("{{$name}}", {{goify $name false}})
 {{else}}{{$tmp := tempvar}}{{toString (goify $name false) $tmp $att}}
 	header.Set("{{$name}}", {{$tmp}})
-{{end}}{{end}}{{end}}	header.Set("Content-Type", "application/json")
+{{end}}{{end}}{{end}}	header.Set("Content-Type", {{if .PayloadMultipart}}mw.FormDataContentType(){{else}}"application/json"{{end}})
 	return c.Client.Do(req)
 }
 `
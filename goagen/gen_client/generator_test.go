@@ -95,4 +95,72 @@ var _ = Describe("Generate", func() {
 			Ω(err).ShouldNot(HaveOccurred())
 		})
 	})
+
+	Context("with optional query parameters", func() {
+		BeforeEach(func() {
+			years := &design.AttributeDefinition{
+				Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.Integer}},
+			}
+			action := &design.ActionDefinition{
+				Name: "list",
+				QueryParams: &design.AttributeDefinition{
+					Type: design.Object{"count": {Type: design.Integer}, "years": years},
+				},
+			}
+			action.Routes = []*design.RouteDefinition{{Verb: "GET", Path: "", Parent: action}}
+			res := &design.ResourceDefinition{
+				Name:     "bottle",
+				BasePath: "/bottles",
+				Actions:  map[string]*design.ActionDefinition{"list": action},
+			}
+			action.Parent = res
+			design.Design = &design.APIDefinition{
+				Name:      "testapi",
+				Resources: map[string]*design.ResourceDefinition{"bottle": res},
+			}
+		})
+
+		It("always sends scalar values and skips empty arrays", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "bottle.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).ShouldNot(ContainSubstring("if count != 0"))
+			Ω(string(content)).Should(ContainSubstring("if len(years) > 0 {"))
+			_, err = gexec.Build(filepath.Join(testgenPackagePath, "client", "testapi-cli"))
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("with security schemes that use the same signer", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name: "testapi",
+				SecuritySchemes: map[string]*design.SecuritySchemeDefinition{
+					"header_key": {
+						Name:      "header_key",
+						Kind:      design.APIKeySecurityKind,
+						In:        "header",
+						ParamName: "X-Key",
+					},
+					"query_key": {
+						Name:      "query_key",
+						Kind:      design.APIKeySecurityKind,
+						In:        "query",
+						ParamName: "key",
+					},
+				},
+			}
+		})
+
+		It("generates one signer per scheme", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "testapi-cli", "main.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`&goa.APIKeySigner{In: "header", Name: "X-Key"}`))
+			querySigner := `&goa.APIKeySigner{In: "query", Name: "key", Flag: "query_key"}`
+			Ω(string(content)).Should(ContainSubstring(querySigner))
+			_, err = gexec.Build(filepath.Join(testgenPackagePath, "client", "testapi-cli"))
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+	})
 })
@@ -68,10 +68,13 @@ func (g *Generator) Generate(api *design.APIDefinition) (_ []string, err error)
 	}
 	g.genfiles = append(g.genfiles, codegen.OutputDir)
 	funcs := template.FuncMap{
-		"title":   strings.Title,
-		"join":    strings.Join,
-		"toLower": strings.ToLower,
-		"params":  params,
+		"title":       strings.Title,
+		"join":        strings.Join,
+		"toLower":     strings.ToLower,
+		"params":      params,
+		"queryParams": queryParams,
+		"multiParams": multiParams,
+		"paramValue":  paramValue,
 	}
 	filePath := filepath.Join(codegen.OutputDir, "client.js")
 	tmpl, err := template.New("module").Funcs(funcs).Parse(moduleT)
@@ -82,20 +85,8 @@ func (g *Generator) Generate(api *design.APIDefinition) (_ []string, err error)
 	if Scheme == "" && len(api.Schemes) > 0 {
 		Scheme = api.Schemes[0]
 	}
-	data := map[string]interface{}{
-		"API":     api,
-		"Host":    Host,
-		"Scheme":  Scheme,
-		"Timeout": int64(Timeout / time.Millisecond),
-	}
-	var file *os.File
-	if file, err = os.Create(filePath); err != nil {
-		return
-	}
-	if err = tmpl.Execute(file, data); err != nil {
-		return
-	}
 	actions := make(map[string][]*design.ActionDefinition)
+	hasMulti := false
 	api.IterateResources(func(res *design.ResourceDefinition) error {
 		return res.IterateActions(func(action *design.ActionDefinition) error {
 			if as, ok := actions[action.Name]; ok {
@@ -103,9 +94,26 @@ func (g *Generator) Generate(api *design.APIDefinition) (_ []string, err error)
 			} else {
 				actions[action.Name] = []*design.ActionDefinition{action}
 			}
+			if len(multiParams(action)) > 0 {
+				hasMulti = true
+			}
 			return nil
 		})
 	})
+	data := map[string]interface{}{
+		"API":      api,
+		"Host":     Host,
+		"Scheme":   Scheme,
+		"Timeout":  int64(Timeout / time.Millisecond),
+		"HasMulti": hasMulti,
+	}
+	var file *os.File
+	if file, err = os.Create(filePath); err != nil {
+		return
+	}
+	if err = tmpl.Execute(file, data); err != nil {
+		return
+	}
 	if tmpl, err = template.New("jsFuncs").Funcs(funcs).Parse(jsFuncsT); err != nil {
 		panic(err.Error()) // bug
 	}
@@ -148,7 +156,7 @@ func (g *Generator) Generate(api *design.APIDefinition) (_ []string, err error)
 			for i, n := range argNames {
 				q := query[n].Type.ToArray().ElemType
 				// below works because we deal with simple types in query strings
-				argValues[i] = fmt.Sprintf("%v", api.Example(q.Type))
+				argValues[i] = exampleString(api.AttributeExample(q), n)
 			}
 			args = strings.Join(argValues, ", ")
 		}
@@ -158,7 +166,7 @@ func (g *Generator) Generate(api *design.APIDefinition) (_ []string, err error)
 			pathVars := exampleAction.AllParams().Type.ToObject()
 			pathValues := make([]interface{}, len(pathParams))
 			for i, n := range pathParams {
-				pathValues[i] = api.Example(pathVars[n].Type)
+				pathValues[i] = exampleString(api.AttributeExample(pathVars[n]), n)
 			}
 			format := design.WildcardRegex.ReplaceAllLiteralString(examplePath, "/%v")
 			examplePath = fmt.Sprintf(format, pathValues...)
@@ -232,6 +240,51 @@ func params(action *design.ActionDefinition) []string {
 	return params
 }
 
+// queryParams returns the names of the action query string parameters that are sent using the
+// axios "params" config field, that is all query parameters that do not use the "multi"
+// collection format.
+func queryParams(action *design.ActionDefinition) []string {
+	var res []string
+	for _, n := range params(action) {
+		if action.QueryParams.Type.ToObject()[n].CollectionFormat != "multi" {
+			res = append(res, n)
+		}
+	}
+	return res
+}
+
+// multiParams returns the names of the action query string parameters that use the "multi"
+// collection format.
+func multiParams(action *design.ActionDefinition) []string {
+	var res []string
+	for _, n := range params(action) {
+		if action.QueryParams.Type.ToObject()[n].CollectionFormat == "multi" {
+			res = append(res, n)
+		}
+	}
+	return res
+}
+
+// paramValue returns the JavaScript expression that computes the value of the given query string
+// parameter. The elements of array parameters are joined using the parameter collection format
+// separator.
+func paramValue(action *design.ActionDefinition, name string) string {
+	att := action.QueryParams.Type.ToObject()[name]
+	if !att.Type.IsArray() {
+		return name
+	}
+	return fmt.Sprintf("join(%s, %q)", name, att.CollectionSeparator())
+}
+
+// exampleString returns the string representation of the example value of a parameter, the name
+// of the parameter if it has no example value (e.g. because it uses a custom format).
+func exampleString(example interface{}, name string) string {
+	if example == nil {
+		return name
+	}
+	return fmt.Sprintf("%v", example)
+}
+
 const moduleT = `// This module exports functions that give access to the {{.API.Name}} API hosted at {{.API.Host}}.
 // It uses the axios javascript library for making the actual HTTP requests.
 define(['axios'] , function (axios) {
@@ -242,6 +295,23 @@ define(['axios'] , function (axios) {
     return obj3;
   }
 
+  // join serializes the elements of an array parameter using the given separator.
+  function join(vals, sep) {
+    return vals && vals.join ? vals.join(sep) : vals;
+  }
+{{if .HasMulti}}
+  // multi builds the query string for array parameters that use the "multi" collection format.
+  function multi(params) {
+    var q = [];
+    for (var name in params) {
+      var vals = params[name] || [];
+      for (var i = 0; i < vals.length; i++) {
+        q.push(encodeURIComponent(name) + '=' + encodeURIComponent(vals[i]));
+      }
+    }
+    return q.length ? '?' + q.join('&') : '';
+  }
+{{end}}
   return function (scheme, host, timeout) {
     scheme = scheme || '{{.Scheme}}';
     host = host || '{{.Host}}';
@@ -259,7 +329,7 @@ const moduleTend = `  return client;
 });
 `
 
-const jsFuncsT = `{{$params := params .}}
+const jsFuncsT = `{{$params := params .}}{{$query := queryParams .}}{{$multi := multiParams .}}
   {{$name := printf "%s%s" .Name (title .Parent.Name)}}// {{if .Description}}{{.Description}}{{else}}{{$name}} calls the {{.Name}} action of the {{.Parent.Name}} resource.{{end}}
   // path is the request path, the format is "{{(index .Routes 0).FullPath}}"
   {{if .Payload}}// data contains the action payload (request body)
@@ -270,11 +340,11 @@ const jsFuncsT = `{{$params := params .}}
   client.{{$name}} = function (path{{if .Payload}}, data{{end}}{{if $params}}, {{join $params ", "}}{{end}}, config) {
     cfg = {
       timeout: timeout,
-      url: urlPrefix + path,
+      url: urlPrefix + path{{if $multi}} + multi({ {{range $index, $param := $multi}}{{if $index}}, {{end}}{{$param}}: {{$param}}{{end}} }){{end}},
       method: '{{toLower (index .Routes 0).Verb}}',
-{{if $params}}      params: {
-{{range $index, $param := $params}}{{if $index}},
-{{end}}        {{$param}}: {{$param}}{{end}}
+{{if $query}}      params: {
+{{range $index, $param := $query}}{{if $index}},
+{{end}}        {{$param}}: {{paramValue $ $param}}{{end}}
       },
 {{end}}{{if .Payload}}    data: data,
 {{end}}      responseType: 'json'
@@ -90,6 +90,38 @@ var _ = Describe("Generate", func() {
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "js", "client.js"))
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(len(strings.Split(string(content), "\n"))).Should(BeNumerically(">=", 13))
+			Ω(string(content)).ShouldNot(ContainSubstring("function multi("))
+		})
+	})
+
+	Context("with an action using the multi collection format", func() {
+		BeforeEach(func() {
+			ids := &design.AttributeDefinition{
+				Type:             &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}},
+				CollectionFormat: "multi",
+			}
+			action := &design.ActionDefinition{
+				Name:        "update",
+				QueryParams: &design.AttributeDefinition{Type: design.Object{"ids": ids}},
+			}
+			action.Routes = []*design.RouteDefinition{{Verb: "PUT", Path: "/bottles", Parent: action}}
+			res := &design.ResourceDefinition{
+				Name:    "bottle",
+				Actions: map[string]*design.ActionDefinition{"update": action},
+			}
+			action.Parent = res
+			design.Design = &design.APIDefinition{
+				Name:      "testapi",
+				Resources: map[string]*design.ResourceDefinition{"bottle": res},
+			}
+		})
+
+		It("generates the multi helper", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "js", "client.js"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("function multi("))
+			Ω(string(content)).Should(ContainSubstring("multi({ ids: ids })"))
 		})
 	})
 })
@@ -21,6 +21,10 @@ type (
 		Definitions  map[string]*JSONSchema `json:"definitions,omitempty"`
 		Description  string                 `json:"description,omitempty"`
 		DefaultValue interface{}            `json:"defaultValue,omitempty"`
+		Example      interface{}            `json:"example,omitempty"`
+		// Deprecated uses a vendor extension as the JSON schema draft does not define a
+		// deprecation keyword.
+		Deprecated bool `json:"x-deprecated,omitempty"`
 
 		// Hyper schema
 		Media     *JSONMedia  `json:"media,omitempty"`
@@ -34,9 +38,15 @@ type (
 		Format               string        `json:"format,omitempty"`
 		Pattern              string        `json:"pattern,omitempty"`
 		Minimum              float64       `json:"minimum,omitempty"`
+		ExclusiveMinimum     bool          `json:"exclusiveMinimum,omitempty"`
 		Maximum              float64       `json:"maximum,omitempty"`
+		ExclusiveMaximum     bool          `json:"exclusiveMaximum,omitempty"`
+		MultipleOf           float64       `json:"multipleOf,omitempty"`
 		MinLength            int           `json:"minLength,omitempty"`
 		MaxLength            int           `json:"maxLength,omitempty"`
+		UniqueItems          bool          `json:"uniqueItems,omitempty"`
+		MinProperties        int           `json:"minProperties,omitempty"`
+		MaxProperties        int           `json:"maxProperties,omitempty"`
 		Required             []string      `json:"required,omitempty"`
 		AdditionalProperties bool          `json:"additionalProperties,omitempty"`
 
@@ -64,6 +74,7 @@ type (
 		TargetSchema *JSONSchema `json:"targetSchema,omitempty"`
 		MediaType    string      `json:"mediaType,omitempty"`
 		EncType      string      `json:"encType,omitempty"`
+		Deprecated   bool        `json:"x-deprecated,omitempty"`
 	}
 )
 
@@ -157,7 +168,7 @@ func GenerateResourceDefinition(api *design.APIDefinition, r *design.ResourceDef
 	s.Type = JSONObject
 	s.Title = r.Name
 	Definitions[r.Name] = s
-	if mt, ok := api.MediaTypes[r.MediaType]; ok {
+	if mt := api.MediaTypeWithIdentifier(r.MediaType); mt != nil {
 		buildMediaTypeSchema(api, mt, s)
 	}
 	r.IterateActions(func(a *design.ActionDefinition) error {
@@ -178,7 +189,7 @@ func GenerateResourceDefinition(api *design.APIDefinition, r *design.ResourceDef
 		var targetSchema *JSONSchema
 		var identifier string
 		for _, resp := range a.Responses {
-			if mt, ok := api.MediaTypes[resp.MediaType]; ok {
+			if mt := api.MediaTypeWithIdentifier(resp.MediaType); mt != nil {
 				if identifier == "" {
 					identifier = mt.Identifier
 				} else {
@@ -195,6 +206,17 @@ func GenerateResourceDefinition(api *design.APIDefinition, r *design.ResourceDef
 				}
 			}
 		}
+		if identifier == "" {
+			if mts := a.ProducedMediaTypes(); len(mts) == 1 {
+				identifier = mts[0]
+			}
+		}
+		var encType string
+		if a.Payload != nil {
+			if mts := a.ConsumedMediaTypes(); len(mts) > 0 {
+				encType = mts[0]
+			}
+		}
 		for i, r := range a.Routes {
 			link := JSONLink{
 				Title:        a.Name,
@@ -204,6 +226,8 @@ func GenerateResourceDefinition(api *design.APIDefinition, r *design.ResourceDef
 				Schema:       requestSchema,
 				TargetSchema: targetSchema,
 				MediaType:    identifier,
+				EncType:      encType,
+				Deprecated:   a.Deprecated() != nil,
 			}
 			if i == 0 {
 				if ca := a.Parent.CanonicalAction(); ca != nil {
@@ -261,7 +285,21 @@ func TypeSchema(api *design.APIDefinition, t design.DataType) *JSONSchema {
 	s := NewJSONSchema()
 	switch actual := t.(type) {
 	case design.Primitive:
-		s.Type = JSONType(actual.Name())
+		switch actual.Kind() {
+		case design.FileKind:
+			s.Type = JSONString
+			s.Format = "binary"
+		case design.DateTimeKind:
+			s.Type = JSONString
+			s.Format = "date-time"
+		case design.UUIDKind:
+			s.Type = JSONString
+			s.Format = "uuid"
+		case design.AnyKind:
+			// Any value is valid, leave the type unspecified.
+		default:
+			s.Type = JSONType(actual.Name())
+		}
 	case *design.Array:
 		s.Type = JSONArray
 		s.Items = NewJSONSchema()
@@ -292,6 +330,9 @@ func (s *JSONSchema) Merge(other *JSONSchema) {
 	if s.Type == "" {
 		s.Type = other.Type
 	}
+	if s.Format == "" {
+		s.Format = other.Format
+	}
 	if s.Ref == "" {
 		s.Ref = other.Ref
 	}
@@ -314,6 +355,12 @@ func (s *JSONSchema) Merge(other *JSONSchema) {
 	if s.DefaultValue == nil {
 		s.DefaultValue = other.DefaultValue
 	}
+	if s.Example == nil {
+		s.Example = other.Example
+	}
+	if !s.Deprecated {
+		s.Deprecated = other.Deprecated
+	}
 	if s.Title == "" {
 		s.Title = other.Title
 	}
@@ -350,6 +397,24 @@ func (s *JSONSchema) Merge(other *JSONSchema) {
 	if s.MaxLength < other.MaxLength {
 		s.MaxLength = other.MaxLength
 	}
+	if !s.ExclusiveMinimum {
+		s.ExclusiveMinimum = other.ExclusiveMinimum
+	}
+	if !s.ExclusiveMaximum {
+		s.ExclusiveMaximum = other.ExclusiveMaximum
+	}
+	if s.MultipleOf == 0 {
+		s.MultipleOf = other.MultipleOf
+	}
+	if !s.UniqueItems {
+		s.UniqueItems = other.UniqueItems
+	}
+	if s.MinProperties > other.MinProperties {
+		s.MinProperties = other.MinProperties
+	}
+	if s.MaxProperties < other.MaxProperties {
+		s.MaxProperties = other.MaxProperties
+	}
 	for _, r := range other.Required {
 		s.Required = append(s.Required, r)
 	}
@@ -366,6 +431,8 @@ func (s *JSONSchema) Dup() *JSONSchema {
 		Schema:               s.Schema,
 		Type:                 s.Type,
 		DefaultValue:         s.DefaultValue,
+		Example:              s.Example,
+		Deprecated:           s.Deprecated,
 		Title:                s.Title,
 		Media:                s.Media,
 		ReadOnly:             s.ReadOnly,
@@ -376,9 +443,15 @@ func (s *JSONSchema) Dup() *JSONSchema {
 		Format:               s.Format,
 		Pattern:              s.Pattern,
 		Minimum:              s.Minimum,
+		ExclusiveMinimum:     s.ExclusiveMinimum,
 		Maximum:              s.Maximum,
+		ExclusiveMaximum:     s.ExclusiveMaximum,
+		MultipleOf:           s.MultipleOf,
 		MinLength:            s.MinLength,
 		MaxLength:            s.MaxLength,
+		UniqueItems:          s.UniqueItems,
+		MinProperties:        s.MinProperties,
+		MaxProperties:        s.MaxProperties,
 		Required:             s.Required,
 		AdditionalProperties: s.AdditionalProperties,
 	}
@@ -398,6 +471,8 @@ func (s *JSONSchema) Dup() *JSONSchema {
 func buildAttributeSchema(api *design.APIDefinition, s *JSONSchema, at *design.AttributeDefinition) *JSONSchema {
 	s.Merge(TypeSchema(api, at.Type))
 	s.DefaultValue = at.DefaultValue
+	s.Example = at.ExampleValue
+	s.Deprecated = at.Deprecation != nil
 	s.Description = at.Description
 	for _, val := range at.Validations {
 		switch actual := val.(type) {
@@ -409,12 +484,22 @@ func buildAttributeSchema(api *design.APIDefinition, s *JSONSchema, at *design.A
 			s.Pattern = actual.Pattern
 		case *design.MinimumValidationDefinition:
 			s.Minimum = actual.Min
+			s.ExclusiveMinimum = actual.Exclusive
 		case *design.MaximumValidationDefinition:
 			s.Maximum = actual.Max
+			s.ExclusiveMaximum = actual.Exclusive
+		case *design.MultipleOfValidationDefinition:
+			s.MultipleOf = actual.MultipleOf
 		case *design.MinLengthValidationDefinition:
 			s.MinLength = actual.MinLength
 		case *design.MaxLengthValidationDefinition:
 			s.MaxLength = actual.MaxLength
+		case *design.UniqueItemsValidationDefinition:
+			s.UniqueItems = true
+		case *design.MinPropertiesValidationDefinition:
+			s.MinProperties = actual.MinProperties
+		case *design.MaxPropertiesValidationDefinition:
+			s.MaxProperties = actual.MaxProperties
 		case *design.RequiredValidationDefinition:
 			s.Required = actual.Names
 		}
@@ -0,0 +1,99 @@
+package genschema_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/raphael/goa/design"
+	"github.com/raphael/goa/goagen/gen_schema"
+)
+
+var _ = Describe("TypeSchema", func() {
+	var t design.DataType
+	var s *genschema.JSONSchema
+
+	JustBeforeEach(func() {
+		s = genschema.TypeSchema(&design.APIDefinition{}, t)
+	})
+
+	Context("with a DateTime", func() {
+		BeforeEach(func() {
+			t = design.DateTime
+		})
+
+		It("produces a date-time string schema", func() {
+			Ω(s.Type).Should(Equal(genschema.JSONType(genschema.JSONString)))
+			Ω(s.Format).Should(Equal("date-time"))
+		})
+	})
+
+	Context("with a UUID", func() {
+		BeforeEach(func() {
+			t = design.UUID
+		})
+
+		It("produces a uuid string schema", func() {
+			Ω(s.Type).Should(Equal(genschema.JSONType(genschema.JSONString)))
+			Ω(s.Format).Should(Equal("uuid"))
+		})
+	})
+
+	Context("with Any", func() {
+		BeforeEach(func() {
+			t = design.Any
+		})
+
+		It("does not constrain the type", func() {
+			Ω(s.Type).Should(BeEmpty())
+		})
+	})
+
+	Context("with an object whose attributes define validations", func() {
+		BeforeEach(func() {
+			t = design.Object{
+				"rating": &design.AttributeDefinition{
+					Type: design.Number,
+					Validations: []design.ValidationDefinition{
+						&design.MinimumValidationDefinition{Min: 0, Exclusive: true},
+						&design.MaximumValidationDefinition{Max: 5},
+						&design.MultipleOfValidationDefinition{MultipleOf: 0.5},
+					},
+				},
+				"version": &design.AttributeDefinition{
+					Type: design.String,
+					Validations: []design.ValidationDefinition{
+						&design.FormatValidationDefinition{Format: "semver"},
+					},
+				},
+				"tags": &design.AttributeDefinition{
+					Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}},
+					Validations: []design.ValidationDefinition{
+						&design.UniqueItemsValidationDefinition{},
+					},
+				},
+				"counts": &design.AttributeDefinition{
+					Type: &design.Hash{
+						KeyType:  &design.AttributeDefinition{Type: design.String},
+						ElemType: &design.AttributeDefinition{Type: design.Integer},
+					},
+					Validations: []design.ValidationDefinition{
+						&design.MinPropertiesValidationDefinition{MinProperties: 1},
+						&design.MaxPropertiesValidationDefinition{MaxProperties: 10},
+					},
+				},
+			}
+		})
+
+		It("sets the corresponding schema keywords", func() {
+			rating := s.Properties["rating"]
+			Ω(rating.Minimum).Should(Equal(0.0))
+			Ω(rating.ExclusiveMinimum).Should(BeTrue())
+			Ω(rating.Maximum).Should(Equal(5.0))
+			Ω(rating.ExclusiveMaximum).Should(BeFalse())
+			Ω(rating.MultipleOf).Should(Equal(0.5))
+			Ω(s.Properties["version"].Format).Should(Equal("semver"))
+			Ω(s.Properties["tags"].UniqueItems).Should(BeTrue())
+			Ω(s.Properties["counts"].MinProperties).Should(Equal(1))
+			Ω(s.Properties["counts"].MaxProperties).Should(Equal(10))
+		})
+	})
+})
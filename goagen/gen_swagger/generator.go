@@ -13,6 +13,7 @@ import (
 
 	"github.com/raphael/goa/design"
 	"github.com/raphael/goa/goagen/codegen"
+	"github.com/raphael/goa/goagen/gen_schema"
 	"github.com/raphael/goa/goagen/utils"
 )
 
@@ -61,6 +62,37 @@ func Generate(api *design.APIDefinition) (_ []string, err error) {
 		return
 	}
 	genfiles = append(genfiles, swaggerFile)
+	var versions []map[string]string
+	err = api.IterateVersions(func(v *design.APIDefinition) error {
+		// Versions may define media types with the same names as the API media types.
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+		vs, err := New(v)
+		if err != nil {
+			return err
+		}
+		vb, err := json.Marshal(vs)
+		if err != nil {
+			return err
+		}
+		versionDir := filepath.Join(swaggerDir, v.APIVersion.Name)
+		if err := os.MkdirAll(versionDir, 0755); err != nil {
+			return err
+		}
+		versionFile := filepath.Join(versionDir, "swagger.json")
+		if err := ioutil.WriteFile(versionFile, vb, 0644); err != nil {
+			return err
+		}
+		genfiles = append(genfiles, versionDir, versionFile)
+		versions = append(versions, map[string]string{
+			"Name":   v.APIVersion.Name,
+			"Suffix": strings.Title(codegen.VersionPackage(v.APIVersion.Name)),
+			"spec":   string(vb),
+		})
+		return nil
+	})
+	if err != nil {
+		return
+	}
 	controllerFile := filepath.Join(swaggerDir, "swagger.go")
 	tmpl, err := template.New("swagger").Parse(swaggerTmpl)
 	if err != nil {
@@ -73,7 +105,8 @@ func Generate(api *design.APIDefinition) (_ []string, err error) {
 	}
 	gg.WriteHeader(fmt.Sprintf("%s Swagger Spec", api.Name), "swagger", imports)
 	data := map[string]interface{}{
-		"spec": string(b),
+		"spec":     string(b),
+		"versions": versions,
 	}
 	if err = tmpl.Execute(gg, data); err != nil {
 		return
@@ -86,13 +119,17 @@ func Generate(api *design.APIDefinition) (_ []string, err error) {
 }
 
 const swaggerTmpl = `
-// MountController mounts the swagger spec controller under "/swagger.json".
+// MountController mounts the swagger spec controller under "/swagger.json"{{if .versions}} and the
+// specs of the API versions under "/swagger/<version>.json"{{end}}.
 func MountController(service goa.Service) {
 	ctrl := service.NewController("Swagger")
 	service.Info("mount", "ctrl", "Swagger", "action", "Show", "route", "GET /swagger.json")
 	h := ctrl.NewHTTPRouterHandle("Show", getSwagger)
 	service.HTTPHandler().(*httprouter.Router).Handle("GET", "/swagger.json", h)
-}
+{{range .versions}}	service.Info("mount", "ctrl", "Swagger", "action", "Show{{.Suffix}}", "route", "GET /swagger/{{.Name}}.json")
+	h = ctrl.NewHTTPRouterHandle("Show{{.Suffix}}", getSwagger{{.Suffix}})
+	service.HTTPHandler().(*httprouter.Router).Handle("GET", "/swagger/{{.Name}}.json", h)
+{{end}}}
 
 // getSwagger is the httprouter handle that returns the Swagger spec.
 // func getSwagger(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
@@ -101,7 +138,17 @@ func getSwagger(ctx *goa.Context) error {
 	ctx.Header().Set("Cache-Control", "public, max-age=3600")
 	return ctx.Respond(200, []byte(spec))
 }
-
+{{range .versions}}
+// getSwagger{{.Suffix}} is the handler that returns the Swagger spec of the API version {{.Name}}.
+func getSwagger{{.Suffix}}(ctx *goa.Context) error {
+	ctx.Header().Set("Content-Type", "application/swagger+json")
+	ctx.Header().Set("Cache-Control", "public, max-age=3600")
+	return ctx.Respond(200, []byte(spec{{.Suffix}}))
+}
+{{end}}
 // Generated spec
 const spec = ` + "`" + `{{.spec}} ` + "`" + `
-`
+{{range .versions}}
+// Generated spec of the API version {{.Name}}
+const spec{{.Suffix}} = ` + "`" + `{{.spec}} ` + "`" + `
+{{end}}`
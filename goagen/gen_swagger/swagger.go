@@ -1,6 +1,7 @@
 package genswagger
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -130,7 +131,13 @@ type (
 		// Default declares the value of the parameter that the server will use if none is
 		// provided, for example a "count" to control the number of results per page might
 		// default to 100 if not supplied by the client in the request.
-		Default          interface{}   `json:"default,omitempty"`
+		Default interface{} `json:"default,omitempty"`
+		// Example is an example value of the parameter. Swagger only supports examples in
+		// schemas and responses so the value is rendered using the "x-example" extension.
+		Example interface{} `json:"x-example,omitempty"`
+		// Deprecated declares this parameter to be deprecated. Swagger does not support
+		// deprecating parameters so the value is rendered using the "x-deprecated" extension.
+		Deprecated       bool          `json:"x-deprecated,omitempty"`
 		Maximum          float64       `json:"maximum,omitempty"`
 		ExclusiveMaximum bool          `json:"exclusiveMaximum,omitempty"`
 		Minimum          float64       `json:"minimum,omitempty"`
@@ -156,6 +163,8 @@ type (
 		Schema *genschema.JSONSchema `json:"schema,omitempty"`
 		// Headers is a list of headers that are sent with the response.
 		Headers map[string]*Header `json:"headers,omitempty"`
+		// Examples of the response message indexed by mime type.
+		Examples map[string]interface{} `json:"examples,omitempty"`
 	}
 
 	// Header represents a header parameter.
@@ -203,7 +212,7 @@ type (
 		Name string `json:"name,omitempty"`
 		// In is the location of the API key when type is "apiKey".
 		// Valid values are "query" or "header".
-		In string `json:"in"`
+		In string `json:"in,omitempty"`
 		// Flow is the flow used by the OAuth2 security scheme when type is "oauth2"
 		// Valid values are "implicit", "password", "application" or "accessCode".
 		Flow string `json:"flow,omitempty"`
@@ -211,11 +220,12 @@ type (
 		AuthorizationURL string `json:"authorizationUrl,omitempty"`
 		// TokenURL  is the token URL to be used for this flow.
 		TokenURL string `json:"tokenUrl,omitempty"`
-		// Scopes list the  available scopes for the OAuth2 security scheme.
+		// Scopes list the available scopes for the OAuth2 security scheme.
 		Scopes map[string]*Scope `json:"scopes,omitempty"`
 	}
 
 	// Scope corresponds to an available scope for an OAuth2 security scheme.
+	// It serializes into its description as required by the swagger specification.
 	Scope struct {
 		// Description for scope
 		Description string `json:"description,omitempty"`
@@ -303,11 +313,21 @@ func New(api *design.APIDefinition) (*Swagger, error) {
 		BasePath:     api.BasePath,
 		Paths:        make(map[string]*Path),
 		Schemes:      api.Schemes,
-		Consumes:     []string{"application/json"},
-		Produces:     []string{"application/json"},
+		Consumes:     mediaTypesOrDefault(api.Consumes),
+		Produces:     mediaTypesOrDefault(api.Produces),
 		Parameters:   paramMap,
 		ExternalDocs: docsFromDefinition(api.Docs),
 	}
+	if v := api.APIVersion; v != nil {
+		s.Info.Version = v.Name
+	}
+	api.IterateSecuritySchemes(func(sc *design.SecuritySchemeDefinition) error {
+		if s.SecurityDefinitions == nil {
+			s.SecurityDefinitions = make(map[string]*SecurityDefinition)
+		}
+		s.SecurityDefinitions[sc.Name] = securityDefinitionFromDefinition(sc)
+		return nil
+	})
 	err = api.IterateResponses(func(r *design.ResponseDefinition) error {
 		res, err := responseFromDefinition(api, r)
 		if err != nil {
@@ -368,19 +388,22 @@ func paramsFromDefinition(params *design.AttributeDefinition, path string) ([]*P
 				break
 			}
 		}
+		typ, format := paramType(at.Type)
 		param := &Parameter{
 			Name:        n,
 			Default:     at.DefaultValue,
+			Example:     at.ExampleValue,
+			Deprecated:  at.Deprecation != nil,
 			Description: at.Description,
 			Required:    required,
 			In:          in,
-			Type:        at.Type.Name(),
+			Type:        typ,
+			Format:      format,
 		}
-		var items *Items
 		if at.Type.IsArray() {
-			items = itemsFromDefinition(at)
+			param.Items = itemsFromDefinition(at.Type.ToArray().ElemType)
+			param.CollectionFormat = at.CollectionFormat
 		}
-		param.Items = items
 		initValidations(at, param)
 		res[i] = param
 		i++
@@ -389,8 +412,50 @@ func paramsFromDefinition(params *design.AttributeDefinition, path string) ([]*P
 	return res, nil
 }
 
+// formDataFromDefinition returns the form parameters that describe the given multipart payload.
+func formDataFromDefinition(payload *design.AttributeDefinition) []*Parameter {
+	var res []*Parameter
+	payload.Type.ToObject().IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+		typ, format := paramType(at.Type)
+		param := &Parameter{
+			Name:        n,
+			Default:     at.DefaultValue,
+			Example:     at.ExampleValue,
+			Deprecated:  at.Deprecation != nil,
+			Description: at.Description,
+			Required:    payload.IsRequired(n),
+			In:          "formData",
+			Type:        typ,
+			Format:      format,
+		}
+		if at.Type.IsArray() {
+			param.Items = itemsFromDefinition(at.Type.ToArray().ElemType)
+			param.CollectionFormat = "multi"
+		}
+		initValidations(at, param)
+		res = append(res, param)
+		return nil
+	})
+	return res
+}
+
+// paramType returns the swagger type and format of non-body parameters, items and headers of
+// the given type.
+func paramType(t design.DataType) (string, string) {
+	switch t.Kind() {
+	case design.DateTimeKind:
+		return "string", "date-time"
+	case design.UUIDKind:
+		return "string", "uuid"
+	case design.AnyKind:
+		return "string", ""
+	}
+	return t.Name(), ""
+}
+
 func itemsFromDefinition(at *design.AttributeDefinition) *Items {
-	items := &Items{Type: at.Type.Name()}
+	typ, format := paramType(at.Type)
+	items := &Items{Type: typ, Format: format}
 	initValidations(at, items)
 	if at.Type.IsArray() {
 		items.Items = itemsFromDefinition(at.Type.ToArray().ElemType)
@@ -400,9 +465,13 @@ func itemsFromDefinition(at *design.AttributeDefinition) *Items {
 
 func responseFromDefinition(api *design.APIDefinition, r *design.ResponseDefinition) (*Response, error) {
 	var schema *genschema.JSONSchema
+	var examples map[string]interface{}
 	if r.MediaType != "" {
-		if mt, ok := api.MediaTypes[r.MediaType]; ok {
+		if mt := api.MediaTypeWithIdentifier(r.MediaType); mt != nil {
 			schema = genschema.TypeSchema(api, mt)
+			if mt.ExampleValue != nil {
+				examples = map[string]interface{}{mt.Identifier: mt.ExampleValue}
+			}
 		}
 	}
 	headers, err := headersFromDefinition(r.Headers)
@@ -413,6 +482,7 @@ func responseFromDefinition(api *design.APIDefinition, r *design.ResponseDefinit
 		Description: r.Description,
 		Schema:      schema,
 		Headers:     headers,
+		Examples:    examples,
 	}, nil
 }
 
@@ -426,10 +496,12 @@ func headersFromDefinition(headers *design.AttributeDefinition) (map[string]*Hea
 	}
 	res := make(map[string]*Header)
 	obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+		typ, format := paramType(at.Type)
 		header := &Header{
 			Default:     at.DefaultValue,
 			Description: at.Description,
-			Type:        at.Type.Name(),
+			Type:        typ,
+			Format:      format,
 		}
 		initValidations(at, header)
 		res[n] = header
@@ -438,6 +510,14 @@ func headersFromDefinition(headers *design.AttributeDefinition) (map[string]*Hea
 	return res, nil
 }
 
+// mediaTypesOrDefault returns the given media types or "application/json" if there are none.
+func mediaTypesOrDefault(mts []string) []string {
+	if len(mts) == 0 {
+		return []string{"application/json"}
+	}
+	return mts
+}
+
 func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *design.RouteDefinition) error {
 	action := route.Parent
 	params, err := paramsFromDefinition(action.Params, route.FullPath())
@@ -452,7 +532,9 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		}
 		responses[strconv.Itoa(r.Status)] = resp
 	}
-	if action.Payload != nil {
+	if action.Payload != nil && action.PayloadMultipart {
+		params = append(params, formDataFromDefinition(action.Payload.AttributeDefinition)...)
+	} else if action.Payload != nil {
 		payloadSchema := genschema.TypeSchema(api, action.Payload)
 		pp := &Parameter{
 			Name:        "payload",
@@ -462,6 +544,17 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 			Schema:      payloadSchema,
 		}
 		params = append(params, pp)
+	} else if action.RawBody {
+		bodySchema := genschema.NewJSONSchema()
+		bodySchema.Type = genschema.JSONString
+		bodySchema.Format = "binary"
+		pp := &Parameter{
+			Name:     "body",
+			In:       "body",
+			Required: true,
+			Schema:   bodySchema,
+		}
+		params = append(params, pp)
 	}
 	operationID := fmt.Sprintf("%s#%s", action.Parent.Name, action.Name)
 	index := 0
@@ -478,12 +571,13 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		Description:  action.Description,
 		ExternalDocs: docsFromDefinition(action.Docs),
 		OperationID:  operationID,
-		Consumes:     []string{"application/json"},
-		Produces:     []string{"application/json"},
+		Consumes:     mediaTypesOrDefault(action.ConsumedMediaTypes()),
+		Produces:     mediaTypesOrDefault(action.ProducedMediaTypes()),
 		Parameters:   params,
 		Responses:    responses,
-		Schemes:      []string{"https"},
-		Deprecated:   false,
+		Security:     securityFromDefinition(action.SecurityRequirement()),
+		Schemes:      action.URLSchemes(),
+		Deprecated:   action.Deprecated() != nil,
 	}
 	key := design.WildcardRegex.ReplaceAllStringFunc(
 		route.FullPath(),
@@ -520,6 +614,54 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 	return nil
 }
 
+// securityDefinitionFromDefinition builds the swagger security definition of the given scheme.
+// JWT schemes are described as API keys since swagger has no specific support for them.
+func securityDefinitionFromDefinition(sc *design.SecuritySchemeDefinition) *SecurityDefinition {
+	def := &SecurityDefinition{Description: sc.Description}
+	switch sc.Kind {
+	case design.BasicAuthSecurityKind:
+		def.Type = "basic"
+	case design.APIKeySecurityKind, design.JWTSecurityKind:
+		def.Type = "apiKey"
+		def.In = sc.In
+		def.Name = sc.ParamName
+	case design.OAuth2SecurityKind:
+		def.Type = "oauth2"
+		def.Flow = sc.Flow
+		def.AuthorizationURL = sc.AuthorizationURL
+		def.TokenURL = sc.TokenURL
+		if len(sc.Scopes) > 0 {
+			def.Scopes = make(map[string]*Scope, len(sc.Scopes))
+			for n, d := range sc.Scopes {
+				def.Scopes[n] = &Scope{Description: d}
+			}
+		}
+	}
+	return def
+}
+
+// MarshalJSON renders the scope as its description.
+func (s *Scope) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Description)
+}
+
+// UnmarshalJSON loads the scope description.
+func (s *Scope) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, &s.Description)
+}
+
+// securityFromDefinition builds the swagger security requirement of an operation.
+func securityFromDefinition(sec *design.SecurityDefinition) []map[string][]string {
+	if sec == nil {
+		return nil
+	}
+	scopes := sec.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return []map[string][]string{{sec.Scheme: scopes}}
+}
+
 func docsFromDefinition(docs *design.DocsDefinition) *ExternalDocs {
 	if docs == nil {
 		return nil
@@ -564,25 +706,43 @@ func initValidations(attr *design.AttributeDefinition, def interface{}) {
 			switch actual := def.(type) {
 			case *Parameter:
 				actual.Minimum = val.Min
-				actual.ExclusiveMinimum = true
+				actual.ExclusiveMinimum = val.Exclusive
 			case *Header:
 				actual.Minimum = val.Min
-				actual.ExclusiveMinimum = true
+				actual.ExclusiveMinimum = val.Exclusive
 			case *Items:
 				actual.Minimum = val.Min
-				actual.ExclusiveMinimum = true
+				actual.ExclusiveMinimum = val.Exclusive
 			}
 		case *design.MaximumValidationDefinition:
 			switch actual := def.(type) {
 			case *Parameter:
 				actual.Maximum = val.Max
-				actual.ExclusiveMaximum = true
+				actual.ExclusiveMaximum = val.Exclusive
 			case *Header:
 				actual.Maximum = val.Max
-				actual.ExclusiveMaximum = true
+				actual.ExclusiveMaximum = val.Exclusive
 			case *Items:
 				actual.Maximum = val.Max
-				actual.ExclusiveMaximum = true
+				actual.ExclusiveMaximum = val.Exclusive
+			}
+		case *design.MultipleOfValidationDefinition:
+			switch actual := def.(type) {
+			case *Parameter:
+				actual.MultipleOf = val.MultipleOf
+			case *Header:
+				actual.MultipleOf = val.MultipleOf
+			case *Items:
+				actual.MultipleOf = val.MultipleOf
+			}
+		case *design.UniqueItemsValidationDefinition:
+			switch actual := def.(type) {
+			case *Parameter:
+				actual.UniqueItems = true
+			case *Header:
+				actual.UniqueItems = true
+			case *Items:
+				actual.UniqueItems = true
 			}
 		case *design.MinLengthValidationDefinition:
 			switch actual := def.(type) {
@@ -6,6 +6,7 @@ import (
 	"github.com/go-swagger/go-swagger/spec"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/raphael/goa"
 	. "github.com/raphael/goa/design"
 	. "github.com/raphael/goa/design/dsl"
 	_ "github.com/raphael/goa/examples/cellar/design"
@@ -151,6 +152,7 @@ var _ = Describe("New", func() {
 						})
 						Param(intParam, Integer, func() {
 							Minimum(intMin)
+							MultipleOf(2)
 						})
 						Param(numParam, Number, func() {
 							Maximum(floatMax)
@@ -180,12 +182,15 @@ var _ = Describe("New", func() {
 				Ω(swagger.Parameters[intParam].Required).Should(BeTrue())
 				Ω(swagger.Parameters[intParam].Type).Should(Equal("integer"))
 				Ω(swagger.Parameters[intParam].Minimum).Should(Equal(intMin))
+				Ω(swagger.Parameters[intParam].ExclusiveMinimum).Should(BeFalse())
+				Ω(swagger.Parameters[intParam].MultipleOf).Should(Equal(2.0))
 				Ω(swagger.Parameters[numParam]).ShouldNot(BeNil())
 				Ω(swagger.Parameters[numParam].Name).Should(Equal(numParam))
 				Ω(swagger.Parameters[numParam].In).Should(Equal("path"))
 				Ω(swagger.Parameters[numParam].Required).Should(BeTrue())
 				Ω(swagger.Parameters[numParam].Type).Should(Equal("number"))
 				Ω(swagger.Parameters[numParam].Maximum).Should(Equal(floatMax))
+				Ω(swagger.Parameters[numParam].ExclusiveMaximum).Should(BeFalse())
 				Ω(swagger.Parameters[boolParam]).ShouldNot(BeNil())
 				Ω(swagger.Parameters[boolParam].Name).Should(Equal(boolParam))
 				Ω(swagger.Parameters[boolParam].In).Should(Equal("path"))
@@ -201,6 +206,28 @@ var _ = Describe("New", func() {
 			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
 		})
 
+		Context("with a base param that uses a custom format", func() {
+			BeforeEach(func() {
+				goa.RegisterFormat("swagger-semver", func(string) error { return nil })
+				base := Design.DSL
+				Design.DSL = func() {
+					base()
+					BasePath("/releases/:version")
+					BaseParams(func() {
+						Param("version", String, func() {
+							Format("swagger-semver")
+						})
+					})
+				}
+			})
+
+			It("uses the custom format name", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				Ω(swagger.Parameters["version"]).ShouldNot(BeNil())
+				Ω(swagger.Parameters["version"].Format).Should(Equal("swagger-semver"))
+			})
+		})
+
 		Context("with response templates", func() {
 			const okName = "OK"
 			const okDesc = "OK description"
@@ -386,9 +413,309 @@ var _ = Describe("New", func() {
 			})
 
 			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+
+			Context("with media types", func() {
+				BeforeEach(func() {
+					Design.Resources["res"].Consumes = []string{"application/xml"}
+					Design.Resources["res"].Produces = []string{"application/xml", "application/json"}
+				})
+
+				It("sets the operation consumes and produces fields", func() {
+					Ω(newErr).ShouldNot(HaveOccurred())
+					Ω(swagger.Consumes).Should(Equal([]string{"application/json"}))
+					Ω(swagger.Paths["/bottles/{id}"].Put.Consumes).Should(Equal([]string{"application/xml"}))
+					Ω(swagger.Paths["/bottles/{id}"].Put.Produces).Should(Equal([]string{"application/xml", "application/json"}))
+				})
+			})
+
+			Context("with security", func() {
+				BeforeEach(func() {
+					base := Design.DSL
+					Design.DSL = func() {
+						base()
+						BasicAuthSecurity("basic")
+						OAuth2Security("oauth2", func() {
+							PasswordFlow("http://example.com/token")
+							Scope("write", "write access")
+						})
+					}
+					res := Design.Resources["res"]
+					resDSL := res.DSL
+					res.DSL = func() {
+						resDSL()
+						Security("oauth2", func() {
+							Scope("write")
+						})
+					}
+				})
+
+				It("sets the security definitions and requirements", func() {
+					Ω(newErr).ShouldNot(HaveOccurred())
+					Ω(swagger.SecurityDefinitions).Should(HaveLen(2))
+					Ω(swagger.SecurityDefinitions["basic"].Type).Should(Equal("basic"))
+					oauth2 := swagger.SecurityDefinitions["oauth2"]
+					Ω(oauth2.Type).Should(Equal("oauth2"))
+					Ω(oauth2.Flow).Should(Equal("password"))
+					Ω(oauth2.TokenURL).Should(Equal("http://example.com/token"))
+					scopes := map[string]*genswagger.Scope{"write": {Description: "write access"}}
+					Ω(oauth2.Scopes).Should(Equal(scopes))
+					js, err := json.Marshal(oauth2)
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(string(js)).Should(ContainSubstring(`"scopes":{"write":"write access"}`))
+					Ω(swagger.Paths["/bottles/{id}"].Put.Security).Should(Equal([]map[string][]string{{"oauth2": {"write"}}}))
+				})
+
+				It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+			})
+
+			Context("with a multipart payload", func() {
+				BeforeEach(func() {
+					res := Design.Resources["res"]
+					resDSL := res.DSL
+					res.DSL = func() {
+						resDSL()
+						Action("Upload", func() {
+							Routing(POST("/:id/label"))
+							Params(func() {
+								Param("id", Integer)
+							})
+							MultipartForm()
+							Payload(func() {
+								Member("label", File, "Label image")
+								Member("year", Integer)
+								Required("label")
+							})
+							Response(NoContent)
+						})
+					}
+				})
+
+				It("describes the payload with form parameters", func() {
+					Ω(newErr).ShouldNot(HaveOccurred())
+					op := swagger.Paths["/bottles/{id}/label"].Post
+					Ω(op).ShouldNot(BeNil())
+					Ω(op.Consumes).Should(Equal([]string{"multipart/form-data"}))
+					Ω(op.Parameters).Should(HaveLen(3))
+					var label *genswagger.Parameter
+					for _, p := range op.Parameters {
+						if p.Name == "label" {
+							label = p
+						}
+					}
+					Ω(label).ShouldNot(BeNil())
+					Ω(label.In).Should(Equal("formData"))
+					Ω(label.Type).Should(Equal("file"))
+					Ω(label.Required).Should(BeTrue())
+				})
+
+				It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+			})
+
+			Context("with an inline response media type", func() {
+				BeforeEach(func() {
+					res := Design.Resources["res"]
+					resDSL := res.DSL
+					res.DSL = func() {
+						resDSL()
+						Action("Summary", func() {
+							Routing(GET("/:id/summary"))
+							Params(func() {
+								Param("id", Integer)
+							})
+							Response(OK, func() {
+								Media(func() {
+									Attributes(func() {
+										Attribute("id")
+										Attribute("count", Integer)
+									})
+								})
+							})
+						})
+					}
+				})
+
+				It("describes the response using the inline media type", func() {
+					Ω(newErr).ShouldNot(HaveOccurred())
+					op := swagger.Paths["/bottles/{id}/summary"].Get
+					Ω(op).ShouldNot(BeNil())
+					Ω(op.Responses["200"].Schema.Ref).Should(Equal("#/definitions/GoaExampleBottleSummaryOK"))
+					def := swagger.Definitions["GoaExampleBottleSummaryOK"]
+					Ω(def).ShouldNot(BeNil())
+					Ω(def.Properties).Should(HaveKey("id"))
+					Ω(def.Properties["id"].Description).Should(Equal("ID of bottle"))
+				})
+
+				It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+			})
+
+			Context("with examples", func() {
+				BeforeEach(func() {
+					res := Design.Resources["res"]
+					resDSL := res.DSL
+					res.DSL = func() {
+						resDSL()
+						Action("Summary", func() {
+							Routing(GET("/:id/summary"))
+							Params(func() {
+								Param("id", Integer, func() {
+									Example(42)
+								})
+							})
+							Response(OK, func() {
+								Media(func() {
+									Attributes(func() {
+										Attribute("count", Integer, func() {
+											Example(12)
+										})
+									})
+									Example(map[string]interface{}{"count": 3})
+								})
+							})
+						})
+					}
+				})
+
+				It("uses the examples in the parameters, schemas and responses", func() {
+					Ω(newErr).ShouldNot(HaveOccurred())
+					op := swagger.Paths["/bottles/{id}/summary"].Get
+					Ω(op).ShouldNot(BeNil())
+					Ω(op.Parameters).Should(HaveLen(1))
+					Ω(op.Parameters[0].Example).Should(Equal(42))
+					resp := op.Responses["200"]
+					Ω(resp.Examples).Should(Equal(map[string]interface{}{
+						"application/vnd.goa.example.bottle.summary.ok+json": map[string]interface{}{"count": 3},
+					}))
+					def := swagger.Definitions["GoaExampleBottleSummaryOK"]
+					Ω(def).ShouldNot(BeNil())
+					Ω(def.Example).Should(Equal(map[string]interface{}{"count": 3}))
+					Ω(def.Properties["count"].Example).Should(Equal(12))
+				})
+
+				It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+			})
+
+			Context("with deprecated definitions and schemes", func() {
+				BeforeEach(func() {
+					res := Design.Resources["res"]
+					resDSL := res.DSL
+					res.DSL = func() {
+						resDSL()
+						Action("Archive", func() {
+							Routing(GET("/:id/archive"))
+							Deprecated("use show")
+							Schemes("wss")
+							Params(func() {
+								Param("id", Integer)
+								Param("full", Boolean, func() {
+									Deprecated()
+								})
+							})
+							Response(NoContent)
+						})
+					}
+				})
+
+				It("marks the deprecated operations and parameters", func() {
+					Ω(newErr).ShouldNot(HaveOccurred())
+					op := swagger.Paths["/bottles/{id}/archive"].Get
+					Ω(op).ShouldNot(BeNil())
+					Ω(op.Deprecated).Should(BeTrue())
+					Ω(op.Schemes).Should(Equal([]string{"wss"}))
+					params := make(map[string]*genswagger.Parameter)
+					for _, p := range op.Parameters {
+						params[p.Name] = p
+					}
+					Ω(params["full"].Deprecated).Should(BeTrue())
+					Ω(params["id"].Deprecated).Should(BeFalse())
+					Ω(swagger.Paths["/bottles/{id}"].Put.Deprecated).Should(BeFalse())
+				})
+
+				It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+			})
+
+			Context("with array parameters", func() {
+				BeforeEach(func() {
+					res := Design.Resources["res"]
+					resDSL := res.DSL
+					res.DSL = func() {
+						resDSL()
+						Action("List", func() {
+							Routing(GET("/years/:years"))
+							Params(func() {
+								Param("years", ArrayOf(Integer), func() {
+									CollectionFormat("pipes")
+								})
+								Param("tags", ArrayOf(String), func() {
+									CollectionFormat("multi")
+								})
+							})
+							Response(NoContent)
+						})
+					}
+				})
+
+				It("sets the parameters items and collection formats", func() {
+					Ω(newErr).ShouldNot(HaveOccurred())
+					op := swagger.Paths["/bottles/years/{years}"].Get
+					Ω(op).ShouldNot(BeNil())
+					params := make(map[string]*genswagger.Parameter)
+					for _, p := range op.Parameters {
+						params[p.Name] = p
+					}
+					Ω(params["years"].Type).Should(Equal("array"))
+					Ω(params["years"].Items.Type).Should(Equal("integer"))
+					Ω(params["years"].CollectionFormat).Should(Equal("pipes"))
+					Ω(params["tags"].In).Should(Equal("query"))
+					Ω(params["tags"].Items.Type).Should(Equal("string"))
+					Ω(params["tags"].CollectionFormat).Should(Equal("multi"))
+				})
+
+				It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+			})
 		})
 	})
 
+	Context("with API versions", func() {
+		var versionSwagger *genswagger.Swagger
+
+		BeforeEach(func() {
+			API("test", func() {
+				VersionPrefix("/v")
+			})
+			Version("2.0", func() {
+				Resource("bottle", func() {
+					BasePath("/bottles")
+					Action("show", func() {
+						Routing(GET("/:id"))
+						Params(func() {
+							Param("id", Integer)
+						})
+						Response(NoContent)
+					})
+				})
+			})
+		})
+
+		JustBeforeEach(func() {
+			Design.IterateVersions(func(v *APIDefinition) error {
+				var err error
+				versionSwagger, err = genswagger.New(v)
+				Ω(err).ShouldNot(HaveOccurred())
+				return nil
+			})
+		})
+
+		It("describes the versioned actions in the version spec only", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(swagger.Paths).Should(BeEmpty())
+			Ω(versionSwagger.Info.Version).Should(Equal("2.0"))
+			Ω(versionSwagger.BasePath).Should(Equal("/v2.0"))
+			Ω(versionSwagger.Paths).Should(HaveKey("/bottles/{id}"))
+		})
+
+		It("serializes into valid swagger JSON", func() { validateSwagger(versionSwagger) })
+	})
+
 	Context("using the cellar example API definition", func() {
 		BeforeEach(func() {
 			Design = cellarDesign
@@ -0,0 +1,30 @@
+package goa
+
+import (
+	"net/url"
+	"strings"
+)
+
+// EscapePathSegment escapes the given string so that it can be used as a single URL path segment.
+// The code generated for the action path builders uses EscapePathSegment to build the paths from
+// the values of the path parameters.
+func EscapePathSegment(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
+
+// EscapePath escapes the given string so that it can be used as a URL path. Contrary to
+// EscapePathSegment it does not escape the "/" character. The code generated for the action path
+// builders uses EscapePath to build the paths from the values of catch-all path parameters.
+func EscapePath(s string) string {
+	segments := strings.Split(s, "/")
+	for i, s := range segments {
+		segments[i] = EscapePathSegment(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// UnescapePathSegment is the inverse of EscapePathSegment. It returns an error if the given
+// string contains an invalid escape sequence.
+func UnescapePathSegment(s string) (string, error) {
+	return url.QueryUnescape(strings.Replace(s, "+", "%2B", -1))
+}
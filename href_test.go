@@ -0,0 +1,37 @@
+package goa_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/raphael/goa"
+)
+
+var _ = Describe("EscapePathSegment", func() {
+	It("escapes slashes, spaces and reserved characters", func() {
+		Ω(goa.EscapePathSegment("a b/c?d+e")).Should(Equal("a%20b%2Fc%3Fd%2Be"))
+	})
+
+	It("can be reversed with UnescapePathSegment", func() {
+		s := "a b/c?d+e%"
+		u, err := goa.UnescapePathSegment(goa.EscapePathSegment(s))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(u).Should(Equal(s))
+	})
+})
+
+var _ = Describe("EscapePath", func() {
+	It("escapes each segment but not the slashes", func() {
+		Ω(goa.EscapePath("docs/a b/c?")).Should(Equal("docs/a%20b/c%3F"))
+	})
+})
+
+var _ = Describe("UnescapePathSegment", func() {
+	It("does not unescape plus signs into spaces", func() {
+		Ω(goa.UnescapePathSegment("a+b%20c")).Should(Equal("a+b c"))
+	})
+
+	It("fails on invalid escape sequences", func() {
+		_, err := goa.UnescapePathSegment("a%zz")
+		Ω(err).Should(HaveOccurred())
+	})
+})
@@ -0,0 +1,209 @@
+package goa
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// JSONWriter accumulates the JSON representation of a value. The code generated by goagen uses
+// it to render media types directly into the response body without building intermediary maps
+// and without relying on reflection. The output is identical to what encoding/json produces for
+// the same values. The zero value is an empty writer ready to use.
+type JSONWriter struct {
+	buf       []byte
+	needComma bool
+	err       error
+}
+
+// Bytes returns the JSON written so far.
+func (w *JSONWriter) Bytes() []byte {
+	return w.buf
+}
+
+// Reset discards the JSON written so far but keeps the position in the enclosing object or
+// array if any so that writing can resume with the next key or element.
+func (w *JSONWriter) Reset() {
+	w.buf = w.buf[:0]
+}
+
+// Err returns the first error that occurred while writing if any.
+func (w *JSONWriter) Err() error {
+	return w.err
+}
+
+// ObjectStart writes the beginning of an object.
+func (w *JSONWriter) ObjectStart() {
+	w.sep()
+	w.buf = append(w.buf, '{')
+	w.needComma = false
+}
+
+// ObjectEnd writes the end of an object.
+func (w *JSONWriter) ObjectEnd() {
+	w.buf = append(w.buf, '}')
+	w.needComma = true
+}
+
+// ArrayStart writes the beginning of an array.
+func (w *JSONWriter) ArrayStart() {
+	w.sep()
+	w.buf = append(w.buf, '[')
+	w.needComma = false
+}
+
+// ArrayEnd writes the end of an array.
+func (w *JSONWriter) ArrayEnd() {
+	w.buf = append(w.buf, ']')
+	w.needComma = true
+}
+
+// Key writes the key of the next object member.
+func (w *JSONWriter) Key(k string) {
+	w.sep()
+	w.buf = appendJSONString(w.buf, k)
+	w.buf = append(w.buf, ':')
+	w.needComma = false
+}
+
+// String writes a string value.
+func (w *JSONWriter) String(s string) {
+	w.sep()
+	w.buf = appendJSONString(w.buf, s)
+	w.needComma = true
+}
+
+// Int writes an integer value.
+func (w *JSONWriter) Int(i int) {
+	w.sep()
+	w.buf = strconv.AppendInt(w.buf, int64(i), 10)
+	w.needComma = true
+}
+
+// Float writes a number value. NaN and infinite values cannot be represented in JSON, writing
+// one records an error.
+func (w *JSONWriter) Float(f float64) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		w.fail(fmt.Errorf("json: unsupported value: %s", strconv.FormatFloat(f, 'g', -1, 64)))
+		f = 0
+	}
+	w.sep()
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	w.buf = strconv.AppendFloat(w.buf, f, format, -1, 64)
+	if format == 'e' {
+		// Clean up e-09 to e-9 like encoding/json does.
+		if n := len(w.buf); n >= 4 && w.buf[n-4] == 'e' && w.buf[n-3] == '-' && w.buf[n-2] == '0' {
+			w.buf[n-2] = w.buf[n-1]
+			w.buf = w.buf[:n-1]
+		}
+	}
+	w.needComma = true
+}
+
+// Bool writes a boolean value.
+func (w *JSONWriter) Bool(b bool) {
+	w.sep()
+	w.buf = strconv.AppendBool(w.buf, b)
+	w.needComma = true
+}
+
+// Time writes a time value using the RFC 3339 format.
+func (w *JSONWriter) Time(t time.Time) {
+	w.sep()
+	w.buf = append(w.buf, '"')
+	w.buf = t.AppendFormat(w.buf, time.RFC3339Nano)
+	w.buf = append(w.buf, '"')
+	w.needComma = true
+}
+
+// Null writes a null value.
+func (w *JSONWriter) Null() {
+	w.sep()
+	w.buf = append(w.buf, "null"...)
+	w.needComma = true
+}
+
+// Value writes an arbitrary value using encoding/json. The generated code only uses it for the
+// values whose type is not known at generation time such as attributes of type Any.
+func (w *JSONWriter) Value(v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		w.fail(err)
+		w.Null()
+		return
+	}
+	w.sep()
+	w.buf = append(w.buf, b...)
+	w.needComma = true
+}
+
+// sep writes the separator that precedes a value or key if needed.
+func (w *JSONWriter) sep() {
+	if w.needComma {
+		w.buf = append(w.buf, ',')
+	}
+}
+
+// fail records the given error if no error occurred before.
+func (w *JSONWriter) fail(err error) {
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString appends the JSON representation of s to buf escaping the same characters as
+// encoding/json.
+func appendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				buf = append(buf, '\\', b)
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		c, size := utf8.DecodeRuneInString(s[i:])
+		if c == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		if c == '\u2028' || c == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', hexDigits[c&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
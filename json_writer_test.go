@@ -0,0 +1,92 @@
+package goa_test
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/raphael/goa"
+)
+
+var _ = Describe("JSONWriter", func() {
+	var w *goa.JSONWriter
+
+	BeforeEach(func() {
+		w = new(goa.JSONWriter)
+	})
+
+	// marshaled returns the output of encoding/json for v.
+	marshaled := func(v interface{}) string {
+		b, err := json.Marshal(v)
+		Ω(err).ShouldNot(HaveOccurred())
+		return string(b)
+	}
+
+	It("writes strings like encoding/json", func() {
+		strs := []string{
+			"", "plain", `"quoted" \ back`, "<html> & co", "tab\tnew\nline\rreturn",
+			"\x00\x01\x1f", "h\xe9llo", "line\u2028para\u2029", "日本語",
+		}
+		for _, s := range strs {
+			w = new(goa.JSONWriter)
+			w.String(s)
+			Ω(string(w.Bytes())).Should(Equal(marshaled(s)), s)
+		}
+	})
+
+	It("writes numbers like encoding/json", func() {
+		for _, f := range []float64{0, 1, -1.5, 3.14159, 1e-7, 123456789, 1e21, -2.5e-10} {
+			w = new(goa.JSONWriter)
+			w.Float(f)
+			Ω(string(w.Bytes())).Should(Equal(marshaled(f)))
+		}
+		w = new(goa.JSONWriter)
+		w.Int(-42)
+		Ω(string(w.Bytes())).Should(Equal("-42"))
+	})
+
+	It("records an error for values that cannot be represented", func() {
+		w.Float(math.NaN())
+		Ω(w.Err()).Should(HaveOccurred())
+		w = new(goa.JSONWriter)
+		w.Value(make(chan int))
+		Ω(w.Err()).Should(HaveOccurred())
+		Ω(string(w.Bytes())).Should(Equal("null"))
+	})
+
+	It("writes objects and arrays", func() {
+		t := time.Date(2015, 11, 3, 10, 0, 0, 0, time.UTC)
+		w.ObjectStart()
+		w.Key("a")
+		w.ArrayStart()
+		w.Int(1)
+		w.Bool(true)
+		w.Null()
+		w.ObjectStart()
+		w.ObjectEnd()
+		w.ArrayEnd()
+		w.Key("t")
+		w.Time(t)
+		w.Key("v")
+		w.Value(map[string]int{"x": 1})
+		w.ObjectEnd()
+		Ω(w.Err()).ShouldNot(HaveOccurred())
+		expected := marshaled(map[string]interface{}{
+			"a": []interface{}{1, true, nil, map[string]interface{}{}},
+			"t": t,
+			"v": map[string]int{"x": 1},
+		})
+		Ω(string(w.Bytes())).Should(Equal(expected))
+	})
+
+	It("resumes writing after a reset", func() {
+		w.ArrayStart()
+		w.Int(1)
+		w.Reset()
+		w.Int(2)
+		w.ArrayEnd()
+		Ω(string(w.Bytes())).Should(Equal(",2]"))
+	})
+})
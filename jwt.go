@@ -0,0 +1,256 @@
+package goa
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	// Register the hash functions used by the supported signature algorithms.
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+type (
+	// JWTKeyResolver returns the key used to verify the signature of a JSON Web Token given the
+	// decoded token header. The header makes it possible to select the key using the "kid" or
+	// "alg" fields. The key must be a []byte for the HMAC algorithms (HS256, HS384 and HS512), a
+	// *rsa.PublicKey for the RSA algorithms (RS256, RS384 and RS512) and a *ecdsa.PublicKey for
+	// the ECDSA algorithms (ES256, ES384 and ES512).
+	JWTKeyResolver func(header map[string]interface{}) (interface{}, error)
+
+	// JWTSpec describes how to verify JSON Web Tokens.
+	JWTSpec struct {
+		// KeyResolver returns the key used to verify the token signatures.
+		KeyResolver JWTKeyResolver
+		// Header is the name of the request header which contains the token.
+		// The default is "Authorization"
+		Header string
+		// Audience is the value that the "aud" claim must contain if not empty.
+		Audience string
+		// Issuer is the value that the "iss" claim must match if not empty.
+		Issuer string
+		// Leeway is the clock skew tolerated when checking the "exp" and "nbf" claims.
+		Leeway time.Duration
+	}
+
+	// JWTClaims contains the claims of a verified JSON Web Token.
+	JWTClaims map[string]interface{}
+)
+
+// JWTClaimsKey is the JWT middleware key used to store the token claims in the context.
+const JWTClaimsKey middlewareKey = 1
+
+// JWT is a middleware that verifies the JSON Web Token of each request using the given spec.
+// Retrieve the token claims using ctx.Value(JWTClaimsKey). Requests whose token is missing or
+// invalid produce an UnauthorizedError which the error handler sends as a 401 response.
+func JWT(spec *JWTSpec) Middleware {
+	header := spec.Header
+	if header == "" {
+		header = "Authorization"
+	}
+	return TokenSecurity("header", header, nil, spec.Validate)
+}
+
+// Validate verifies the signature and the claims of the given token and stores the claims in the
+// context. It also checks that the token grants the given scopes, the granted scopes are read
+// from the "scope" claim (a space separated list) or the "scopes" claim (an array). Validate
+// implements TokenValidator so that it can be used to validate the tokens of the JWT security
+// schemes defined in the design.
+func (spec *JWTSpec) Validate(ctx *Context, token string, scopes []string) error {
+	claims, err := spec.Verify(token)
+	if err != nil {
+		return err
+	}
+	if len(scopes) > 0 {
+		granted := claims.Scopes()
+		for _, s := range scopes {
+			found := false
+			for _, g := range granted {
+				if g == s {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return &TypedError{ID: ErrUnauthorized, Mesg: fmt.Sprintf("token does not grant scope %#v", s)}
+			}
+		}
+	}
+	ctx.SetValue(JWTClaimsKey, claims)
+	return nil
+}
+
+// Verify checks the signature of the given token and the values of its "exp", "nbf", "aud" and
+// "iss" claims. It returns the token claims if the token is valid. Verify returns a TypedError
+// with id ErrUnauthorized otherwise.
+func (spec *JWTSpec) Verify(token string) (JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, jwtError("malformed token")
+	}
+	var header map[string]interface{}
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, jwtError("invalid token header: %s", err)
+	}
+	sig, err := decodeJWTBase64(parts[2])
+	if err != nil {
+		return nil, jwtError("invalid token signature encoding: %s", err)
+	}
+	if spec.KeyResolver == nil {
+		return nil, jwtError("no key resolver")
+	}
+	key, err := spec.KeyResolver(header)
+	if err != nil {
+		return nil, jwtError("failed to resolve key: %s", err)
+	}
+	alg, _ := header["alg"].(string)
+	if err := verifyJWTSignature(alg, parts[0]+"."+parts[1], sig, key); err != nil {
+		return nil, jwtError("%s", err)
+	}
+	var claims JWTClaims
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, jwtError("invalid token claims: %s", err)
+	}
+	now := time.Now()
+	if exp, ok := claims.time("exp"); ok && now.After(exp.Add(spec.Leeway)) {
+		return nil, jwtError("token is expired")
+	}
+	if nbf, ok := claims.time("nbf"); ok && now.Add(spec.Leeway).Before(nbf) {
+		return nil, jwtError("token is not valid yet")
+	}
+	if spec.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != spec.Issuer {
+			return nil, jwtError("invalid issuer %#v", iss)
+		}
+	}
+	if spec.Audience != "" && !claims.hasAudience(spec.Audience) {
+		return nil, jwtError("token audience does not include %#v", spec.Audience)
+	}
+	return claims, nil
+}
+
+// Scopes returns the scopes listed in the "scope" or "scopes" claims.
+func (c JWTClaims) Scopes() []string {
+	if s, ok := c["scope"].(string); ok {
+		return strings.Fields(s)
+	}
+	switch s := c["scopes"].(type) {
+	case string:
+		return strings.Fields(s)
+	case []interface{}:
+		scopes := make([]string, 0, len(s))
+		for _, v := range s {
+			if str, ok := v.(string); ok {
+				scopes = append(scopes, str)
+			}
+		}
+		return scopes
+	}
+	return nil
+}
+
+// time returns the value of the numeric date claim with the given name.
+func (c JWTClaims) time(name string) (time.Time, bool) {
+	v, ok := c[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(v), 0), true
+}
+
+// hasAudience returns true if the "aud" claim is or contains the given audience.
+func (c JWTClaims) hasAudience(aud string) bool {
+	switch a := c["aud"].(type) {
+	case string:
+		return a == aud
+	case []interface{}:
+		for _, v := range a {
+			if v == aud {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// verifyJWTSignature verifies the signature of the given input using the given algorithm and key.
+func verifyJWTSignature(alg, input string, sig []byte, key interface{}) error {
+	var hash crypto.Hash
+	switch alg {
+	case "HS256", "RS256", "ES256":
+		hash = crypto.SHA256
+	case "HS384", "RS384", "ES384":
+		hash = crypto.SHA384
+	case "HS512", "RS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported signing algorithm %#v", alg)
+	}
+	switch alg[0] {
+	case 'H':
+		k, ok := key.([]byte)
+		if !ok {
+			return fmt.Errorf("invalid key type %T for algorithm %s", key, alg)
+		}
+		mac := hmac.New(hash.New, k)
+		mac.Write([]byte(input))
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return fmt.Errorf("invalid token signature")
+		}
+	case 'R':
+		k, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("invalid key type %T for algorithm %s", key, alg)
+		}
+		h := hash.New()
+		h.Write([]byte(input))
+		if err := rsa.VerifyPKCS1v15(k, hash, h.Sum(nil), sig); err != nil {
+			return fmt.Errorf("invalid token signature")
+		}
+	case 'E':
+		k, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("invalid key type %T for algorithm %s", key, alg)
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return fmt.Errorf("invalid token signature")
+		}
+		h := hash.New()
+		h.Write([]byte(input))
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(k, h.Sum(nil), r, s) {
+			return fmt.Errorf("invalid token signature")
+		}
+	}
+	return nil
+}
+
+// decodeJWTSegment decodes the given base64url encoded JSON token segment into v.
+func decodeJWTSegment(seg string, v interface{}) error {
+	b, err := decodeJWTBase64(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// decodeJWTBase64 decodes the given unpadded base64url encoded string.
+func decodeJWTBase64(seg string) ([]byte, error) {
+	if m := len(seg) % 4; m != 0 {
+		seg += strings.Repeat("=", 4-m)
+	}
+	return base64.URLEncoding.DecodeString(seg)
+}
+
+// jwtError creates the TypedError returned when a token cannot be verified.
+func jwtError(format string, vals ...interface{}) error {
+	return &TypedError{ID: ErrUnauthorized, Mesg: fmt.Sprintf(format, vals...)}
+}
@@ -0,0 +1,248 @@
+package goa_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/raphael/goa"
+)
+
+// signJWT creates a token signed with the given algorithm and private key.
+func signJWT(alg string, key interface{}, claims map[string]interface{}) string {
+	enc := func(v interface{}) string {
+		b, err := json.Marshal(v)
+		Ω(err).ShouldNot(HaveOccurred())
+		return strings.TrimRight(base64.URLEncoding.EncodeToString(b), "=")
+	}
+	input := enc(map[string]interface{}{"alg": alg, "typ": "JWT"}) + "." + enc(claims)
+	var sig []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(crypto.SHA256.New, k)
+		mac.Write([]byte(input))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		h := crypto.SHA256.New()
+		h.Write([]byte(input))
+		var err error
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, h.Sum(nil))
+		Ω(err).ShouldNot(HaveOccurred())
+	case *ecdsa.PrivateKey:
+		h := crypto.SHA256.New()
+		h.Write([]byte(input))
+		r, s, err := ecdsa.Sign(rand.Reader, k, h.Sum(nil))
+		Ω(err).ShouldNot(HaveOccurred())
+		size := (k.Curve.Params().BitSize + 7) / 8
+		sig = make([]byte, 2*size)
+		rb, sb := r.Bytes(), s.Bytes()
+		copy(sig[size-len(rb):size], rb)
+		copy(sig[2*size-len(sb):], sb)
+	}
+	return input + "." + strings.TrimRight(base64.URLEncoding.EncodeToString(sig), "=")
+}
+
+var _ = Describe("JWTSpec", func() {
+	var secret []byte
+	var rsaKey *rsa.PrivateKey
+	var ecKey *ecdsa.PrivateKey
+	var spec *goa.JWTSpec
+	var claims map[string]interface{}
+
+	BeforeEach(func() {
+		var err error
+		secret = []byte("secret")
+		rsaKey, err = rsa.GenerateKey(rand.Reader, 1024)
+		Ω(err).ShouldNot(HaveOccurred())
+		ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Ω(err).ShouldNot(HaveOccurred())
+		spec = &goa.JWTSpec{
+			KeyResolver: func(header map[string]interface{}) (interface{}, error) {
+				switch header["alg"] {
+				case "HS256":
+					return secret, nil
+				case "RS256":
+					return &rsaKey.PublicKey, nil
+				case "ES256":
+					return &ecKey.PublicKey, nil
+				}
+				return nil, errors.New("unknown algorithm")
+			},
+		}
+		claims = map[string]interface{}{
+			"sub": "joe",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+	})
+
+	Context("Verify", func() {
+		It("accepts tokens signed with HMAC", func() {
+			c, err := spec.Verify(signJWT("HS256", secret, claims))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(c["sub"]).Should(Equal("joe"))
+		})
+
+		It("accepts tokens signed with RSA", func() {
+			_, err := spec.Verify(signJWT("RS256", rsaKey, claims))
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("accepts tokens signed with ECDSA", func() {
+			_, err := spec.Verify(signJWT("ES256", ecKey, claims))
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("rejects tokens with an invalid signature", func() {
+			_, err := spec.Verify(signJWT("HS256", []byte("other"), claims))
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("invalid token signature"))
+		})
+
+		It("rejects tokens signed with another key type", func() {
+			token := signJWT("RS256", rsaKey, claims)
+			spec.KeyResolver = func(map[string]interface{}) (interface{}, error) { return secret, nil }
+			_, err := spec.Verify(token)
+			Ω(err).Should(HaveOccurred())
+		})
+
+		It("rejects unsigned tokens", func() {
+			token := signJWT("HS256", secret, claims)
+			parts := strings.Split(token, ".")
+			none := strings.TrimRight(base64.URLEncoding.EncodeToString([]byte(`{"alg":"none"}`)), "=")
+			_, err := spec.Verify(none + "." + parts[1] + ".")
+			Ω(err).Should(HaveOccurred())
+		})
+
+		It("rejects malformed tokens", func() {
+			_, err := spec.Verify("foo")
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(*goa.TypedError).ID.Title()).Should(Equal("unauthorized"))
+		})
+
+		It("rejects expired tokens", func() {
+			claims["exp"] = time.Now().Add(-time.Minute).Unix()
+			_, err := spec.Verify(signJWT("HS256", secret, claims))
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("expired"))
+		})
+
+		It("tolerates the leeway", func() {
+			claims["exp"] = time.Now().Add(-time.Minute).Unix()
+			spec.Leeway = 2 * time.Minute
+			_, err := spec.Verify(signJWT("HS256", secret, claims))
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("rejects tokens that are not valid yet", func() {
+			claims["nbf"] = time.Now().Add(time.Minute).Unix()
+			_, err := spec.Verify(signJWT("HS256", secret, claims))
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("not valid yet"))
+		})
+
+		It("checks the audience", func() {
+			spec.Audience = "api"
+			claims["aud"] = []string{"other", "api"}
+			_, err := spec.Verify(signJWT("HS256", secret, claims))
+			Ω(err).ShouldNot(HaveOccurred())
+			claims["aud"] = "other"
+			_, err = spec.Verify(signJWT("HS256", secret, claims))
+			Ω(err).Should(HaveOccurred())
+		})
+
+		It("checks the issuer", func() {
+			spec.Issuer = "goa"
+			claims["iss"] = "goa"
+			_, err := spec.Verify(signJWT("HS256", secret, claims))
+			Ω(err).ShouldNot(HaveOccurred())
+			claims["iss"] = "other"
+			_, err = spec.Verify(signJWT("HS256", secret, claims))
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("JWT middleware", func() {
+		var req *http.Request
+		var rw *TestResponseWriter
+		var ctx *goa.Context
+		var handlerClaims interface{}
+		var handlerErr error
+
+		handler := func(c *goa.Context) error {
+			handlerClaims = c.Value(goa.JWTClaimsKey)
+			return nil
+		}
+
+		BeforeEach(func() {
+			var err error
+			req, err = http.NewRequest("GET", "/goo", nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			handlerClaims = nil
+		})
+
+		JustBeforeEach(func() {
+			ctx = goa.NewContext(nil, req, rw, nil, nil, nil)
+			handlerErr = goa.JWT(spec)(handler)(ctx)
+		})
+
+		Context("with a valid token", func() {
+			BeforeEach(func() {
+				req.Header.Set("Authorization", "Bearer "+signJWT("HS256", secret, claims))
+			})
+
+			It("stores the claims in the context", func() {
+				Ω(handlerErr).ShouldNot(HaveOccurred())
+				Ω(handlerClaims).Should(BeAssignableToTypeOf(goa.JWTClaims{}))
+				Ω(handlerClaims.(goa.JWTClaims)["sub"]).Should(Equal("joe"))
+			})
+		})
+
+		Context("with an invalid token", func() {
+			BeforeEach(func() {
+				req.Header.Set("Authorization", "Bearer foo")
+			})
+
+			It("returns an error that the default error handler sends as a 401", func() {
+				Ω(handlerClaims).Should(BeNil())
+				Ω(handlerErr).Should(BeAssignableToTypeOf(&goa.UnauthorizedError{}))
+				goa.DefaultErrorHandler(ctx, handlerErr)
+				Ω(rw.Status).Should(Equal(401))
+			})
+		})
+	})
+
+	Context("Validate", func() {
+		var ctx *goa.Context
+
+		BeforeEach(func() {
+			req, err := http.NewRequest("GET", "/goo", nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			ctx = goa.NewContext(nil, req, new(TestResponseWriter), nil, nil, nil)
+			claims["scope"] = "read write"
+		})
+
+		It("accepts tokens that grant the required scopes", func() {
+			err := spec.Validate(ctx, signJWT("HS256", secret, claims), []string{"read", "write"})
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(ctx.Value(goa.JWTClaimsKey)).ShouldNot(BeNil())
+		})
+
+		It("rejects tokens that do not grant the required scopes", func() {
+			err := spec.Validate(ctx, signJWT("HS256", secret, claims), []string{"admin"})
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("admin"))
+		})
+	})
+})
@@ -125,7 +125,7 @@ func LogRequest() Middleware {
 				ctx.Debug("query", logCtx)
 			}
 			payload := ctx.Value(payloadKey)
-			if r.ContentLength > 0 {
+			if payload != nil {
 				if mp, ok := payload.(map[string]interface{}); ok {
 					ctx.Debug("payload", log.Ctx(mp))
 				} else {
@@ -248,6 +248,25 @@ func Timeout(timeout time.Duration) Middleware {
 	}
 }
 
+// Deprecated returns a middleware used to flag the responses of deprecated actions, see
+// ApplicationController.SetDeprecated. The middleware sets the "Deprecation" response header. It
+// also sets the "Sunset" header to the time after which the action may stop being available (see
+// RFC 8594) unless sunset is the zero time. It logs a warning each time the action is called.
+func Deprecated(sunset time.Time) Middleware {
+	return func(h Handler) Handler {
+		return func(ctx *Context) error {
+			header := ctx.Header()
+			header.Set("Deprecation", "true")
+			if !sunset.IsZero() {
+				header.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			}
+			r := ctx.Request()
+			ctx.Warn("deprecated action called", "method", r.Method, "path", r.URL.Path)
+			return h(ctx)
+		}
+	}
+}
+
 // RequireHeader requires a request header to match a value pattern. If the
 // header is missing or does not match then the failureStatus is the response
 // (e.g. http.StatusUnauthorized). If pathPattern is nil then any path is
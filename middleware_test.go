@@ -247,6 +247,47 @@ var _ = Describe("Timeout", func() {
 	})
 })
 
+var _ = Describe("Deprecated", func() {
+	var handler *testHandler
+	var rw *TestResponseWriter
+	var ctx *goa.Context
+
+	BeforeEach(func() {
+		req, err := http.NewRequest("GET", "/goo", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+		ctx = goa.NewContext(nil, req, rw, nil, nil, nil)
+		handler = new(testHandler)
+		logger := log15.New("test", "test")
+		logger.SetHandler(handler)
+		ctx.Logger = logger
+	})
+
+	It("sets the deprecation headers and logs the call", func() {
+		h := func(ctx *goa.Context) error {
+			return ctx.JSON(200, "ok")
+		}
+		sunset := time.Date(2016, time.December, 31, 0, 0, 0, 0, time.UTC)
+		d := goa.Deprecated(sunset)(h)
+		Ω(d(ctx)).ShouldNot(HaveOccurred())
+		Ω(rw.Status).Should(Equal(200))
+		Ω(rw.ParentHeader.Get("Deprecation")).Should(Equal("true"))
+		Ω(rw.ParentHeader.Get("Sunset")).Should(Equal("Sat, 31 Dec 2016 00:00:00 GMT"))
+		Ω(handler.Records).Should(HaveLen(1))
+		Ω(handler.Records[0].Msg).Should(Equal("deprecated action called"))
+	})
+
+	It("omits the sunset header if there is no sunset time", func() {
+		h := func(ctx *goa.Context) error {
+			return ctx.JSON(200, "ok")
+		}
+		d := goa.Deprecated(time.Time{})(h)
+		Ω(d(ctx)).ShouldNot(HaveOccurred())
+		Ω(rw.ParentHeader.Get("Deprecation")).Should(Equal("true"))
+		Ω(rw.ParentHeader).ShouldNot(HaveKey("Sunset"))
+	})
+})
+
 var _ = Describe("RequireHeader", func() {
 	var handler *testHandler
 	var ctx *goa.Context
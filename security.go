@@ -0,0 +1,110 @@
+package goa
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// BasicAuthValidator is the function used to validate the credentials of requests made to
+	// actions secured with HTTP basic authentication. It returns an error if the credentials are
+	// not valid.
+	BasicAuthValidator func(ctx *Context, username, password string) error
+
+	// APIKeyValidator is the function used to validate the key of requests made to actions
+	// secured with an API key. It returns an error if the key is not valid.
+	APIKeyValidator func(ctx *Context, key string) error
+
+	// TokenValidator is the function used to validate the token of requests made to actions
+	// secured with JWT or OAuth2. scopes lists the scopes required by the action. It returns an
+	// error if the token is not valid or does not grant the required scopes.
+	TokenValidator func(ctx *Context, token string, scopes []string) error
+)
+
+// BasicAuthSecurity returns a middleware that reads the basic authentication credentials of the
+// request and validates them with the given function before calling the next handler. The
+// middleware returns an UnauthorizedError if the credentials are missing or if the validator
+// returns an error.
+func BasicAuthSecurity(validate BasicAuthValidator) Middleware {
+	return func(h Handler) Handler {
+		return func(ctx *Context) error {
+			user, pass, ok := ctx.Request().BasicAuth()
+			if !ok {
+				return unauthorized(ctx, `Basic realm="api"`,
+					fmt.Errorf("missing basic authentication credentials"))
+			}
+			if err := validate(ctx, user, pass); err != nil {
+				return unauthorized(ctx, `Basic realm="api"`, err)
+			}
+			return h(ctx)
+		}
+	}
+}
+
+// APIKeySecurity returns a middleware that reads the API key from the request header or query
+// string parameter with the given name and validates it with the given function before calling
+// the next handler. in must be one of "header" or "query". The middleware returns an
+// UnauthorizedError if the key is missing or if the validator returns an error.
+func APIKeySecurity(in, name string, validate APIKeyValidator) Middleware {
+	return func(h Handler) Handler {
+		return func(ctx *Context) error {
+			key := securityValue(ctx, in, name)
+			if key == "" {
+				return unauthorized(ctx, "", fmt.Errorf("missing API key %s %#v", in, name))
+			}
+			if err := validate(ctx, key); err != nil {
+				return unauthorized(ctx, "", err)
+			}
+			return h(ctx)
+		}
+	}
+}
+
+// TokenSecurity returns a middleware that reads the bearer token from the request header or query
+// string parameter with the given name and validates it against the given scopes with the given
+// function before calling the next handler. in must be one of "header" or "query". The "Bearer"
+// prefix is stripped from header values. The middleware returns an UnauthorizedError if the token
+// is missing or if the validator returns an error.
+func TokenSecurity(in, name string, scopes []string, validate TokenValidator) Middleware {
+	return func(h Handler) Handler {
+		return func(ctx *Context) error {
+			token := securityValue(ctx, in, name)
+			if in == "header" && len(token) > 7 && strings.EqualFold(token[:7], "bearer ") {
+				token = strings.TrimSpace(token[7:])
+			}
+			if token == "" {
+				return unauthorized(ctx, "Bearer", fmt.Errorf("missing token %s %#v", in, name))
+			}
+			if err := validate(ctx, token, scopes); err != nil {
+				return unauthorized(ctx, "Bearer", err)
+			}
+			return h(ctx)
+		}
+	}
+}
+
+// securityValue returns the value of the request header or query string parameter with the given
+// name.
+func securityValue(ctx *Context, in, name string) string {
+	if in == "query" {
+		return ctx.Request().URL.Query().Get(name)
+	}
+	return ctx.Request().Header.Get(name)
+}
+
+// unauthorized sets the given WWW-Authenticate challenge if not empty and wraps the given error
+// into an UnauthorizedError so that the error handler sends a 401 response. Validators may return
+// a ResponseError to send a different response instead, such errors are returned as is so that
+// the controller sends the response they describe.
+func unauthorized(ctx *Context, challenge string, err error) error {
+	if rerr, ok := err.(*ResponseError); ok {
+		return rerr
+	}
+	if h := ctx.Header(); h != nil && challenge != "" {
+		h.Set("WWW-Authenticate", challenge)
+	}
+	if _, ok := err.(*TypedError); !ok {
+		err = &TypedError{ID: ErrUnauthorized, Mesg: err.Error()}
+	}
+	return NewUnauthorizedError(err)
+}
@@ -0,0 +1,148 @@
+package goa_test
+
+import (
+	"errors"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/raphael/goa"
+)
+
+var _ = Describe("Security middlewares", func() {
+	var req *http.Request
+	var rw *TestResponseWriter
+	var called bool
+	var handlerErr error
+
+	handler := func(ctx *goa.Context) error {
+		called = true
+		return ctx.Respond(200, []byte("ok"))
+	}
+
+	BeforeEach(func() {
+		var err error
+		req, err = http.NewRequest("GET", "/goo?key=secret", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+		called = false
+	})
+
+	run := func(m goa.Middleware) {
+		ctx := goa.NewContext(nil, req, rw, nil, nil, nil)
+		handlerErr = m(handler)(ctx)
+	}
+
+	Context("BasicAuthSecurity", func() {
+		var user, pass string
+
+		validate := func(ctx *goa.Context, u, p string) error {
+			user, pass = u, p
+			if p != "secret" {
+				return errors.New("invalid password")
+			}
+			return nil
+		}
+
+		It("calls the handler when the credentials are valid", func() {
+			req.SetBasicAuth("joe", "secret")
+			run(goa.BasicAuthSecurity(validate))
+			Ω(handlerErr).ShouldNot(HaveOccurred())
+			Ω(called).Should(BeTrue())
+			Ω(user).Should(Equal("joe"))
+			Ω(pass).Should(Equal("secret"))
+		})
+
+		It("returns an unauthorized error when the credentials are missing", func() {
+			run(goa.BasicAuthSecurity(validate))
+			Ω(called).Should(BeFalse())
+			Ω(handlerErr).Should(BeAssignableToTypeOf(&goa.UnauthorizedError{}))
+			Ω(rw.ParentHeader.Get("WWW-Authenticate")).Should(HavePrefix("Basic"))
+		})
+
+		It("returns an unauthorized error when the credentials are invalid", func() {
+			req.SetBasicAuth("joe", "wrong")
+			run(goa.BasicAuthSecurity(validate))
+			Ω(called).Should(BeFalse())
+			Ω(handlerErr).Should(BeAssignableToTypeOf(&goa.UnauthorizedError{}))
+			Ω(handlerErr.Error()).Should(ContainSubstring("invalid password"))
+		})
+
+		It("produces a 401 response with the default error handler", func() {
+			ctx := goa.NewContext(nil, req, rw, nil, nil, nil)
+			err := goa.BasicAuthSecurity(validate)(handler)(ctx)
+			goa.DefaultErrorHandler(ctx, err)
+			Ω(rw.Status).Should(Equal(401))
+			Ω(rw.ParentHeader.Get("Content-Type")).Should(Equal(goa.ErrorMediaIdentifier))
+			Ω(string(rw.Body)).Should(ContainSubstring(`"code":14`))
+		})
+	})
+
+	Context("APIKeySecurity", func() {
+		var key string
+
+		validate := func(ctx *goa.Context, k string) error {
+			key = k
+			return nil
+		}
+
+		It("reads the key from the query string", func() {
+			run(goa.APIKeySecurity("query", "key", validate))
+			Ω(called).Should(BeTrue())
+			Ω(key).Should(Equal("secret"))
+		})
+
+		It("reads the key from the header", func() {
+			req.Header.Set("X-Key", "header-secret")
+			run(goa.APIKeySecurity("header", "X-Key", validate))
+			Ω(called).Should(BeTrue())
+			Ω(key).Should(Equal("header-secret"))
+		})
+
+		It("returns an unauthorized error when the key is missing", func() {
+			run(goa.APIKeySecurity("header", "X-Key", validate))
+			Ω(called).Should(BeFalse())
+			Ω(handlerErr).Should(BeAssignableToTypeOf(&goa.UnauthorizedError{}))
+		})
+	})
+
+	Context("TokenSecurity", func() {
+		var token string
+		var scopes []string
+		var validateErr error
+
+		validate := func(ctx *goa.Context, t string, s []string) error {
+			token, scopes = t, s
+			return validateErr
+		}
+
+		BeforeEach(func() {
+			validateErr = nil
+		})
+
+		It("strips the bearer prefix and passes the required scopes", func() {
+			req.Header.Set("Authorization", "Bearer tok")
+			run(goa.TokenSecurity("header", "Authorization", []string{"read"}, validate))
+			Ω(called).Should(BeTrue())
+			Ω(token).Should(Equal("tok"))
+			Ω(scopes).Should(Equal([]string{"read"}))
+		})
+
+		It("returns an unauthorized error when the token is missing", func() {
+			run(goa.TokenSecurity("header", "Authorization", nil, validate))
+			Ω(called).Should(BeFalse())
+			Ω(handlerErr).Should(BeAssignableToTypeOf(&goa.UnauthorizedError{}))
+			Ω(rw.ParentHeader.Get("WWW-Authenticate")).Should(Equal("Bearer"))
+		})
+
+		It("returns response errors as is", func() {
+			req.Header.Set("Authorization", "Bearer tok")
+			rerr := &goa.ResponseError{Kind: "Forbidden", Status: 403}
+			validateErr = rerr
+			run(goa.TokenSecurity("header", "Authorization", nil, validate))
+			Ω(called).Should(BeFalse())
+			Ω(handlerErr).Should(Equal(rerr))
+			Ω(rw.Status).Should(Equal(0))
+		})
+	})
+})
@@ -1,10 +1,12 @@
 package goa
 
 import (
-	"encoding/json"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"golang.org/x/net/context"
@@ -29,6 +31,30 @@ type (
 		// Use adds a middleware to the service-wide middleware chain.
 		Use(m Middleware)
 
+		// SetDecoder registers a decoder factory for the given content types. The decoder is
+		// used to load the bodies of requests whose Content-Type header matches one of the
+		// content types. If makeDefault is true the decoder is also used for requests that do
+		// not specify a Content-Type.
+		SetDecoder(f DecoderFactory, makeDefault bool, contentTypes ...string)
+		// Decoder returns the decoder factory used to decode request bodies with the given
+		// Content-Type header value, nil if there is none.
+		Decoder(contentType string) DecoderFactory
+		// SetEncoder registers an encoder factory for the given content types. The encoders
+		// are used by Context.Send to write response bodies using the content type that best
+		// matches the request Accept header. If makeDefault is true the encoder is also used
+		// for media types that don't have a registered encoder.
+		SetEncoder(f EncoderFactory, makeDefault bool, contentTypes ...string)
+		// Encoder returns the encoder factory registered for the given content type, nil if
+		// there is none.
+		Encoder(contentType string) EncoderFactory
+
+		// SetMaxBodySize sets the maximum size in bytes of the request bodies accepted by the
+		// service actions. A value of 0 or less means no limit. Controllers may override the
+		// limit for specific actions.
+		SetMaxBodySize(n int64)
+		// MaxBodySize returns the service-wide maximum request body size.
+		MaxBodySize() int64
+
 		// ListenAndServe starts a HTTP server on the given port.
 		ListenAndServe(addr string) error
 		// ListenAndServeTLS starts a HTTPS server on the given port.
@@ -44,10 +70,21 @@ type (
 		// use http.Dir:
 		//     service.ServeFiles("/src/*filepath", http.Dir("/var/www"))
 		ServeFiles(path string, root http.FileSystem)
-		// HTTPHandler returns a http.Handler interface to the underlying service.
-		// Note: using the handler directly bypasses the graceful shutdown behavior of
-		// services instantiated with NewGraceful.
+		// HTTPHandler returns the router the service controllers are mounted on.
+		// Note: serving requests with the router directly bypasses the API version
+		// dispatch and the graceful shutdown behavior of services instantiated with
+		// NewGraceful. Use the service ServeHTTP method to serve requests instead.
 		HTTPHandler() http.Handler
+		// ServeHTTP serves requests, dispatching them to the routers of the API versions
+		// they target if any.
+		ServeHTTP(w http.ResponseWriter, req *http.Request)
+
+		// Version returns the service used to mount the controllers of the API version with
+		// the given name. This method is mainly intended for use by generated code.
+		Version(name string) Service
+		// SetVersionSelector sets the function used to select the API version targeted by the
+		// requests that do not match any unversioned route.
+		SetVersionSelector(f SelectVersionFunc)
 
 		// NewController returns a controller for the resource with the given name.
 		// This method is mainly intended for use by generated code.
@@ -70,6 +107,23 @@ type (
 		ErrorHandler() ErrorHandler
 		// SetErrorHandler sets the controller specific error handler.
 		SetErrorHandler(ErrorHandler)
+		// SetConsumes restricts the media types of the request bodies accepted by the
+		// given action. This function is intended for the controller generated code.
+		SetConsumes(actName string, mediaTypes ...string)
+		// SetMaxBodySize overrides the service-wide maximum request body size for the given
+		// action. This function is intended for the controller generated code.
+		SetMaxBodySize(actName string, n int64)
+		// SetRawBody disables the decoding of the given action request bodies, the action
+		// reads the body via the context Body method instead. This function is intended for
+		// the controller generated code.
+		SetRawBody(actName string)
+		// SetDecodeFunc sets the function used to decode the JSON request bodies of the given
+		// action into its payload type. This function is intended for the controller
+		// generated code.
+		SetDecodeFunc(actName string, f DecodeFunc)
+		// SetDeprecated flags the given action as deprecated so that its responses carry the
+		// deprecation headers. This function is intended for the controller generated code.
+		SetDeprecated(actName string, sunset time.Time)
 		// NewHTTPRouterHandle returns a httprouter handle from a goa handler.
 		// This function is intended for the controller generated code.
 		// User code should not need to call it directly.
@@ -92,19 +146,29 @@ type (
 	// where NewResourceController returns an object that implements the resource actions as
 	// defined by the corresponding interface generated by goagen.
 	Application struct {
-		log.Logger                      // Application logger
-		name         string             // Application name
-		errorHandler ErrorHandler       // Application error handler
-		middleware   []Middleware       // Middleware chain
-		Router       *httprouter.Router // Application router
+		log.Logger                        // Application logger
+		name          string              // Application name
+		errorHandler  ErrorHandler        // Application error handler
+		middleware    []Middleware        // Middleware chain
+		decoders      *decoderRegistry    // Request body decoders indexed by media type
+		encoders      *encoderRegistry    // Response body encoders indexed by media type
+		maxBodySize   int64               // Maximum request body size in bytes, 0 if no limit
+		versions      map[string]*version // API versions indexed by name
+		selectVersion SelectVersionFunc   // Selects the API version targeted by requests
+		Router        *httprouter.Router  // Application router
 	}
 
 	// ApplicationController provides the common state and behavior for generated controllers.
 	ApplicationController struct {
-		log.Logger                // Controller logger
-		app          *Application //Application which exposes controller
-		errorHandler ErrorHandler // Controller specific error handler if any
-		middleware   []Middleware // Controller specific middleware if any
+		log.Logger                         // Controller logger
+		app          *Application          //Application which exposes controller
+		errorHandler ErrorHandler          // Controller specific error handler if any
+		middleware   []Middleware          // Controller specific middleware if any
+		consumes     map[string][]string   // Media types accepted by each action if restricted
+		maxBodySizes map[string]int64      // Maximum request body size of each action if overridden
+		rawBodies    map[string]bool       // Actions that read the raw request body
+		decodeFuncs  map[string]DecodeFunc // Typed payload decoders of each action if any
+		deprecations map[string]time.Time  // Sunset time of each deprecated action
 	}
 
 	// Handler defines the controller handler signatures.
@@ -153,6 +217,8 @@ func New(name string) Service {
 		Logger:       Log.New("app", name),
 		name:         name,
 		errorHandler: DefaultErrorHandler,
+		decoders:     newDecoderRegistry(),
+		encoders:     newEncoderRegistry(),
 		Router:       httprouter.New(),
 	}
 }
@@ -176,6 +242,50 @@ func (app *Application) Use(m Middleware) {
 	app.middleware = append(app.middleware, m)
 }
 
+// SetDecoder registers a decoder factory for the given content types. Content types are media
+// types as they appear in the request Content-Type header, parameters (e.g. charset) are ignored.
+// The JSON decoder is registered by default for "application/json" and is also used to decode
+// requests that do not specify a Content-Type. Media types that use a structured syntax suffix
+// (e.g. "application/vnd.goa.bottle+json") are decoded with the decoder registered for the
+// suffix ("application/json") unless a decoder is registered for the media type itself.
+// Register decoders prior to serving requests.
+func (app *Application) SetDecoder(f DecoderFactory, makeDefault bool, contentTypes ...string) {
+	app.decoders.set(f, makeDefault, contentTypes...)
+}
+
+// Decoder returns the decoder factory used to decode request bodies with the given Content-Type
+// header value, nil if there is none. The default decoder is returned if contentType is empty.
+func (app *Application) Decoder(contentType string) DecoderFactory {
+	return app.decoders.lookup(contentType)
+}
+
+// SetEncoder registers an encoder factory for the given content types. The JSON encoder is
+// registered by default for "application/json" and is also used for media types that do not have
+// a registered encoder. Media types that use a structured syntax suffix are encoded with the
+// encoder registered for the suffix unless an encoder is registered for the media type itself.
+// Register encoders prior to serving requests.
+func (app *Application) SetEncoder(f EncoderFactory, makeDefault bool, contentTypes ...string) {
+	app.encoders.set(f, makeDefault, contentTypes...)
+}
+
+// Encoder returns the encoder factory registered for the given content type, nil if there is
+// none.
+func (app *Application) Encoder(contentType string) EncoderFactory {
+	return app.encoders.lookup(contentType)
+}
+
+// SetMaxBodySize sets the maximum size in bytes of the request bodies accepted by the service
+// actions. Requests with larger bodies get a response with status code 413. A value of 0 or less
+// means no limit, this is the default.
+func (app *Application) SetMaxBodySize(n int64) {
+	app.maxBodySize = n
+}
+
+// MaxBodySize returns the service-wide maximum request body size.
+func (app *Application) MaxBodySize() int64 {
+	return app.maxBodySize
+}
+
 // ErrorHandler returns the currently set error handler.
 func (app *Application) ErrorHandler() ErrorHandler {
 	return app.errorHandler
@@ -196,13 +306,13 @@ func (app *Application) SetErrorHandler(handler ErrorHandler) {
 // ListenAndServe starts a HTTP server and sets up a listener on the given host/port.
 func (app *Application) ListenAndServe(addr string) error {
 	app.Info("listen", "addr", addr)
-	return http.ListenAndServe(addr, app.Router)
+	return http.ListenAndServe(addr, app)
 }
 
 // ListenAndServeTLS starts a HTTPS server and sets up a listener on the given host/port.
 func (app *Application) ListenAndServeTLS(addr, certFile, keyFile string) error {
 	app.Info("listen ssl", "addr", addr)
-	return http.ListenAndServeTLS(addr, certFile, keyFile, app.Router)
+	return http.ListenAndServeTLS(addr, certFile, keyFile, app)
 }
 
 // ServeFiles simply delegates to the underlying router.
@@ -250,9 +360,75 @@ func (ctrl *ApplicationController) SetErrorHandler(handler ErrorHandler) {
 	ctrl.errorHandler = handler
 }
 
-// HandleError invokes the controller error handler or - if there isn't one - the service error
-// handler.
+// SetConsumes restricts the media types of the request bodies accepted by the given action.
+// The action responds with 415 to requests whose Content-Type is not one of the given media
+// types even if the service has a decoder registered for it. SetConsumes must be called prior to
+// NewHTTPRouterHandle. This function is intended for the controller generated code. User code
+// should not need to call it directly.
+func (ctrl *ApplicationController) SetConsumes(actName string, mediaTypes ...string) {
+	if ctrl.consumes == nil {
+		ctrl.consumes = make(map[string][]string)
+	}
+	ctrl.consumes[actName] = mediaTypes
+}
+
+// SetMaxBodySize overrides the service-wide maximum size in bytes of the request bodies accepted
+// by the given action. A value of 0 or less means no limit. SetMaxBodySize must be called prior to
+// NewHTTPRouterHandle. This function is intended for the controller generated code. User code
+// should not need to call it directly.
+func (ctrl *ApplicationController) SetMaxBodySize(actName string, n int64) {
+	if ctrl.maxBodySizes == nil {
+		ctrl.maxBodySizes = make(map[string]int64)
+	}
+	ctrl.maxBodySizes[actName] = n
+}
+
+// SetRawBody disables the decoding of the request bodies of the given action. The action reads
+// the body using the context Body method instead, this makes it possible to stream large bodies.
+// SetRawBody must be called prior to NewHTTPRouterHandle. This function is intended for the
+// controller generated code. User code should not need to call it directly.
+func (ctrl *ApplicationController) SetRawBody(actName string) {
+	if ctrl.rawBodies == nil {
+		ctrl.rawBodies = make(map[string]bool)
+	}
+	ctrl.rawBodies[actName] = true
+}
+
+// SetDecodeFunc sets the function used to decode the JSON request bodies of the given action.
+// The function decodes and validates the body in one pass directly into the action payload type
+// instead of going through a generic interface{} value first. The request bodies that use other
+// content types are still decoded generically. SetDecodeFunc must be called prior to
+// NewHTTPRouterHandle. This function is intended for the controller generated code. User code
+// should not need to call it directly.
+func (ctrl *ApplicationController) SetDecodeFunc(actName string, f DecodeFunc) {
+	if ctrl.decodeFuncs == nil {
+		ctrl.decodeFuncs = make(map[string]DecodeFunc)
+	}
+	ctrl.decodeFuncs[actName] = f
+}
+
+// SetDeprecated flags the given action as deprecated, sunset is the time after which the action
+// may stop being available or the zero time. The Deprecated middleware wraps the action handler
+// and the controller and service middleware so that all the responses of the action carry the
+// deprecation headers, including the ones sent when a middleware rejects the request. SetDeprecated
+// must be called prior to NewHTTPRouterHandle. This function is intended for the controller
+// generated code. User code should not need to call it directly.
+func (ctrl *ApplicationController) SetDeprecated(actName string, sunset time.Time) {
+	if ctrl.deprecations == nil {
+		ctrl.deprecations = make(map[string]time.Time)
+	}
+	ctrl.deprecations[actName] = sunset
+}
+
+// HandleError sends the response described by the error if it is a ResponseError (see the error
+// kind constructors generated by goagen). Otherwise it invokes the controller error handler or - if
+// there isn't one - the service error handler.
 func (ctrl *ApplicationController) HandleError(ctx *Context, err error) {
+	if rerr, ok := err.(*ResponseError); ok {
+		if err = rerr.Send(ctx); err == nil {
+			return
+		}
+	}
 	if ctrl.errorHandler != nil {
 		ctrl.errorHandler(ctx, err)
 	} else if ctrl.app.errorHandler != nil {
@@ -272,7 +448,11 @@ func (ctrl *ApplicationController) NewHTTPRouterHandle(actName string, h Handler
 	middleware := func(ctx *Context) error {
 		if !ctx.ResponseWritten() {
 			if err := h(ctx); err != nil {
-				ctrl.HandleError(ctx, err)
+				if l, ok := ctx.Value(bodyKey).(*bodyLimiter); ok && l.exceeded {
+					ctx.SendError(413, l.tooLarge())
+				} else {
+					ctrl.HandleError(ctx, err)
+				}
 			}
 		}
 		return nil
@@ -281,6 +461,14 @@ func (ctrl *ApplicationController) NewHTTPRouterHandle(actName string, h Handler
 		middleware = chain[ml-i-1](middleware)
 	}
 	logger := ctrl.New("action", actName)
+	consumes := ctrl.consumes[actName]
+	raw := ctrl.rawBodies[actName]
+	decode := ctrl.decodeFuncs[actName]
+	sunset, deprecated := ctrl.deprecations[actName]
+	maxBodySize, ok := ctrl.maxBodySizes[actName]
+	if !ok {
+		maxBodySize = ctrl.app.maxBodySize
+	}
 	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 		// Collect URL and query string parameters
 		params := make(map[string]string, len(p))
@@ -293,12 +481,57 @@ func (ctrl *ApplicationController) NewHTTPRouterHandle(actName string, h Handler
 			query[name] = value
 		}
 
-		// Load body if any
+		// Limit the body size if needed
+		var body io.Reader = r.Body
+		var limiter *bodyLimiter
+		if maxBodySize > 0 && r.Body != nil {
+			limiter = &bodyLimiter{Reader: r.Body, max: maxBodySize, remaining: maxBodySize}
+			if r.ContentLength > maxBodySize {
+				limiter.exceeded = true
+			}
+			body = limiter
+		}
+
+		// Load body if any, ContentLength is -1 for chunked requests
 		var payload interface{}
 		var err error
-		if r.ContentLength > 0 {
-			decoder := json.NewDecoder(r.Body)
-			err = decoder.Decode(&payload)
+		var unsupported string
+		if r.ContentLength != 0 && r.Body != nil && (limiter == nil || !limiter.exceeded) {
+			contentType := r.Header.Get("Content-Type")
+			if raw {
+				if !acceptsContentType(consumes, contentType) {
+					unsupported = contentType
+				}
+			} else if isMultipart(contentType) {
+				if acceptsContentType(consumes, contentType) {
+					var form *multipart.Form
+					form, err = decodeMultipart(body, contentType)
+					if err == nil {
+						defer form.RemoveAll()
+						payload = form
+					}
+				} else {
+					unsupported = contentType
+				}
+			} else {
+				factory := ctrl.app.Decoder(contentType)
+				if factory != nil && !acceptsContentType(consumes, contentType) {
+					factory = nil
+				}
+				if factory != nil {
+					if decode != nil && isJSON(contentType) {
+						payload, err = decode(factory(body))
+					} else {
+						err = factory(body).Decode(&payload)
+					}
+					if err == io.EOF {
+						// Chunked request with an empty body
+						err = nil
+					}
+				} else {
+					unsupported = contentType
+				}
+			}
 		}
 
 		// Build context
@@ -306,17 +539,54 @@ func (ctrl *ApplicationController) NewHTTPRouterHandle(actName string, h Handler
 		defer cancel() // Signal completion of request to any child goroutine
 		ctx := NewContext(gctx, r, w, params, query, payload)
 		ctx.Logger = logger
+		ctx.SetValue(encodersKey, ctrl.app.encoders)
+		if limiter != nil {
+			ctx.SetValue(bodyKey, limiter)
+		}
 
 		// Handle invalid payload
 		handler := middleware
-		if err != nil {
+		if limiter != nil && limiter.exceeded {
+			handler = func(ctx *Context) error {
+				return ctx.SendError(413, limiter.tooLarge())
+			}
+			for i := range chain {
+				handler = chain[ml-i-1](handler)
+			}
+		} else if unsupported != "" {
+			handler = func(ctx *Context) error {
+				return ctx.SendError(415, &TypedError{
+					ID:   ErrUnsupportedMediaType,
+					Mesg: fmt.Sprintf("no decoder registered for content type %q", unsupported),
+				})
+			}
+			for i := range chain {
+				handler = chain[ml-i-1](handler)
+			}
+		} else if berr, ok := err.(*BadRequestError); ok {
 			handler = func(ctx *Context) error {
-				ctx.Respond(400, []byte(fmt.Sprintf(`{"kind":"invalid request","msg":"invalid JSON: %s"}`, err)))
+				ctrl.HandleError(ctx, berr)
 				return nil
 			}
 			for i := range chain {
 				handler = chain[ml-i-1](handler)
 			}
+		} else if err != nil {
+			handler = func(ctx *Context) error {
+				return ctx.SendError(400, &TypedError{
+					ID:    ErrInvalidEncoding,
+					Mesg:  fmt.Sprintf("invalid payload: %s", err),
+					Field: "payload",
+				})
+			}
+			for i := range chain {
+				handler = chain[ml-i-1](handler)
+			}
+		}
+
+		// Flag the responses of deprecated actions
+		if deprecated {
+			handler = Deprecated(sunset)(handler)
 		}
 
 		// Invoke middleware chain
@@ -329,31 +599,70 @@ func (ctrl *ApplicationController) NewHTTPRouterHandle(actName string, h Handler
 	}
 }
 
+// bodyLimiter is the reader used to read request bodies whose size is limited. It returns an error
+// and records that the limit was exceeded once more than max bytes have been read.
+type bodyLimiter struct {
+	io.Reader
+	max       int64
+	remaining int64
+	exceeded  bool
+}
+
+// Read reads from the underlying body up to the configured limit.
+func (l *bodyLimiter) Read(p []byte) (int, error) {
+	if l.exceeded {
+		return 0, l.tooLarge()
+	}
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.Reader.Read(p)
+	if int64(n) > l.remaining {
+		l.exceeded = true
+		return int(l.remaining), l.tooLarge()
+	}
+	l.remaining -= int64(n)
+	return n, err
+}
+
+// tooLarge returns the error produced when the body exceeds the limit.
+func (l *bodyLimiter) tooLarge() error {
+	return &TypedError{
+		ID:   ErrBodyTooLarge,
+		Mesg: fmt.Sprintf("request body must not exceed %d bytes", l.max),
+	}
+}
+
 // DefaultErrorHandler returns a 400 response for request validation errors (instances of
-// BadRequestError) and a 500 response for other errors. It writes the error message to the
-// response body in both cases.
+// BadRequestError), a 401 response for authentication errors (instances of UnauthorizedError) and
+// a 500 response for other errors. It writes the error document built from the error to the
+// response body in all cases (see ErrorDocument).
 func DefaultErrorHandler(c *Context, e error) {
 	status := 500
-	if _, ok := e.(*BadRequestError); ok {
-		c.Header().Set("Content-Type", "application/json")
+	switch e.(type) {
+	case *BadRequestError:
 		status = 400
+	case *UnauthorizedError:
+		status = 401
 	}
-	if err := c.Respond(status, []byte(e.Error())); err != nil {
+	if err := c.SendError(status, e); err != nil {
 		Log.Error("failed to send default error handler response", "err", err)
 	}
 }
 
-// TerseErrorHandler behaves like DefaultErrorHandler except that it does not set the response
-// body for internal errors.
+// TerseErrorHandler behaves like DefaultErrorHandler except that the error document written for
+// internal errors does not include the error details.
 func TerseErrorHandler(c *Context, e error) {
 	status := 500
-	var body []byte
-	if _, ok := e.(*BadRequestError); ok {
-		c.Header().Set("Content-Type", "application/json")
+	switch e.(type) {
+	case *BadRequestError:
 		status = 400
-		body = []byte(e.Error())
+	case *UnauthorizedError:
+		status = 401
+	default:
+		e = nil
 	}
-	if err := c.Respond(status, body); err != nil {
+	if err := c.SendError(status, e); err != nil {
 		Log.Error("failed to send terse error handler response", "err", err)
 	}
 }
@@ -1,8 +1,14 @@
 package goa_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"mime/multipart"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	. "github.com/onsi/ginkgo"
@@ -53,6 +59,49 @@ var _ = Describe("Application", func() {
 		})
 	})
 
+	Describe("SetDecoder", func() {
+		It("registers JSON as the default decoder", func() {
+			Ω(s.Decoder("application/json")).ShouldNot(BeNil())
+			Ω(s.Decoder("")).ShouldNot(BeNil())
+		})
+
+		It("uses the suffix decoder for structured media types", func() {
+			Ω(s.Decoder("application/vnd.goa.example+json; charset=utf-8")).ShouldNot(BeNil())
+		})
+
+		It("does not decode unknown content types", func() {
+			Ω(s.Decoder("application/x-www-form-urlencoded")).Should(BeNil())
+		})
+
+		Context("with a registered decoder", func() {
+			BeforeEach(func() {
+				s.SetDecoder(goa.FormDecoderFactory, true, "application/x-www-form-urlencoded")
+			})
+
+			It("returns the decoder", func() {
+				Ω(s.Decoder("application/x-www-form-urlencoded")).ShouldNot(BeNil())
+			})
+
+			It("makes it the default decoder", func() {
+				var payload interface{}
+				err := s.Decoder("")(strings.NewReader("foo=bar")).Decode(&payload)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(payload).Should(Equal(map[string]interface{}{"foo": "bar"}))
+			})
+		})
+
+		Context("with a graceful application", func() {
+			BeforeEach(func() {
+				s = goa.NewGraceful(appName)
+				s.SetDecoder(goa.FormDecoderFactory, false, "application/x-www-form-urlencoded")
+			})
+
+			It("registers the decoder", func() {
+				Ω(s.Decoder("application/x-www-form-urlencoded")).ShouldNot(BeNil())
+			})
+		})
+	})
+
 	Describe("NewHTTPRouterHandle", func() {
 		const resName = "res"
 		const actName = "act"
@@ -60,15 +109,41 @@ var _ = Describe("Application", func() {
 		const respStatus = 200
 		var respContent = []byte("response")
 
+		var consumes []string
+		var maxBodySize int64
+		var rawBody bool
+		var decode goa.DecodeFunc
+		var sunset *time.Time
+
 		var httpHandle httprouter.Handle
 		var ctx *goa.Context
 
 		JustBeforeEach(func() {
 			ctrl := s.NewController("test")
+			if consumes != nil {
+				ctrl.SetConsumes(actName, consumes...)
+			}
+			if maxBodySize != 0 {
+				ctrl.SetMaxBodySize(actName, maxBodySize)
+			}
+			if rawBody {
+				ctrl.SetRawBody(actName)
+			}
+			if decode != nil {
+				ctrl.SetDecodeFunc(actName, decode)
+			}
+			if sunset != nil {
+				ctrl.SetDeprecated(actName, *sunset)
+			}
 			httpHandle = ctrl.NewHTTPRouterHandle(actName, handler)
 		})
 
 		BeforeEach(func() {
+			consumes = nil
+			maxBodySize = 0
+			rawBody = false
+			decode = nil
+			sunset = nil
 			handler = func(c *goa.Context) error {
 				ctx = c
 				c.Respond(respStatus, respContent)
@@ -138,6 +213,299 @@ var _ = Describe("Application", func() {
 				})
 			})
 
+			Context("for a deprecated action", func() {
+				BeforeEach(func() {
+					t := time.Date(2016, time.December, 31, 0, 0, 0, 0, time.UTC)
+					sunset = &t
+					rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+				})
+
+				It("sets the deprecation headers", func() {
+					tw := rw.(*TestResponseWriter)
+					Ω(tw.Status).Should(Equal(respStatus))
+					Ω(tw.Header().Get("Deprecation")).Should(Equal("true"))
+					Ω(tw.Header().Get("Sunset")).Should(Equal("Sat, 31 Dec 2016 00:00:00 GMT"))
+				})
+
+				Context("rejected by a middleware", func() {
+					BeforeEach(func() {
+						s.Use(func(goa.Handler) goa.Handler {
+							return func(ctx *goa.Context) error {
+								return ctx.Respond(401, nil)
+							}
+						})
+					})
+
+					It("sets the deprecation headers", func() {
+						tw := rw.(*TestResponseWriter)
+						Ω(tw.Status).Should(Equal(401))
+						Ω(tw.Header().Get("Deprecation")).Should(Equal("true"))
+					})
+				})
+			})
+
+			Context("with a chunked JSON body", func() {
+				BeforeEach(func() {
+					var err error
+					r, err = http.NewRequest("POST", "/foo", strings.NewReader(`{"foo":"bar"}`))
+					Ω(err).ShouldNot(HaveOccurred())
+					r.ContentLength = -1
+					r.Header.Set("Content-Type", "application/json")
+				})
+
+				It("loads the payload", func() {
+					Ω(ctx.Payload()).Should(Equal(map[string]interface{}{"foo": "bar"}))
+				})
+			})
+
+			Context("with a body using an unsupported content type", func() {
+				BeforeEach(func() {
+					var err error
+					r, err = http.NewRequest("POST", "/foo", strings.NewReader("foo=bar"))
+					Ω(err).ShouldNot(HaveOccurred())
+					r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+					rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+				})
+
+				It("responds with 415", func() {
+					tw := rw.(*TestResponseWriter)
+					Ω(tw.Status).Should(Equal(415))
+					Ω(string(tw.Body)).Should(ContainSubstring("application/x-www-form-urlencoded"))
+					Ω(tw.Header().Get("Content-Type")).Should(Equal(goa.ErrorMediaIdentifier))
+					var doc goa.ErrorDocument
+					Ω(json.Unmarshal(tw.Body, &doc)).ShouldNot(HaveOccurred())
+					Ω(doc.Code).Should(Equal(goa.ErrorID(goa.ErrUnsupportedMediaType)))
+				})
+
+				Context("and a decoder registered for the content type", func() {
+					BeforeEach(func() {
+						s.SetDecoder(goa.FormDecoderFactory, false, "application/x-www-form-urlencoded")
+					})
+
+					It("loads the payload", func() {
+						Ω(ctx.Payload()).Should(Equal(map[string]interface{}{"foo": "bar"}))
+					})
+
+					Context("that the action does not consume", func() {
+						BeforeEach(func() {
+							consumes = []string{"application/json"}
+						})
+
+						It("responds with 415", func() {
+							tw := rw.(*TestResponseWriter)
+							Ω(tw.Status).Should(Equal(415))
+						})
+					})
+				})
+			})
+
+			Context("with a multipart body", func() {
+				BeforeEach(func() {
+					var buf bytes.Buffer
+					mw := multipart.NewWriter(&buf)
+					Ω(mw.WriteField("name", "goa")).ShouldNot(HaveOccurred())
+					part, err := mw.CreateFormFile("label", "label.png")
+					Ω(err).ShouldNot(HaveOccurred())
+					_, err = part.Write([]byte("image"))
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(mw.Close()).ShouldNot(HaveOccurred())
+					r, err = http.NewRequest("POST", "/foo", &buf)
+					Ω(err).ShouldNot(HaveOccurred())
+					r.Header.Set("Content-Type", mw.FormDataContentType())
+					rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+				})
+
+				It("loads the multipart form", func() {
+					Ω(ctx.Payload()).Should(BeAssignableToTypeOf(&multipart.Form{}))
+					form := ctx.Payload().(*multipart.Form)
+					Ω(form.Value["name"]).Should(Equal([]string{"goa"}))
+					Ω(form.File["label"]).Should(HaveLen(1))
+					Ω(form.File["label"][0].Filename).Should(Equal("label.png"))
+				})
+
+				Context("that the action does not consume", func() {
+					BeforeEach(func() {
+						consumes = []string{"application/json"}
+					})
+
+					It("responds with 415", func() {
+						Ω(rw.(*TestResponseWriter).Status).Should(Equal(415))
+					})
+				})
+			})
+
+			Context("with an invalid body", func() {
+				BeforeEach(func() {
+					var err error
+					r, err = http.NewRequest("POST", "/foo", strings.NewReader("{"))
+					Ω(err).ShouldNot(HaveOccurred())
+					r.Header.Set("Content-Type", "application/json")
+				})
+
+				It("responds with 400", func() {
+					tw := rw.(*TestResponseWriter)
+					Ω(tw.Status).Should(Equal(400))
+					var doc goa.ErrorDocument
+					Ω(json.Unmarshal(tw.Body, &doc)).ShouldNot(HaveOccurred())
+					Ω(doc.Code).Should(Equal(goa.ErrorID(goa.ErrInvalidEncoding)))
+					Ω(doc.Errors).Should(HaveLen(1))
+					Ω(doc.Errors[0].Field).Should(Equal("payload"))
+				})
+			})
+
+			Context("with a body larger than the maximum size", func() {
+				BeforeEach(func() {
+					var err error
+					r, err = http.NewRequest("POST", "/foo", strings.NewReader(`{"foo":"bar"}`))
+					Ω(err).ShouldNot(HaveOccurred())
+					r.Header.Set("Content-Type", "application/json")
+					rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+					maxBodySize = 5
+				})
+
+				It("responds with 413", func() {
+					tw := rw.(*TestResponseWriter)
+					Ω(tw.Status).Should(Equal(413))
+					var doc goa.ErrorDocument
+					Ω(json.Unmarshal(tw.Body, &doc)).ShouldNot(HaveOccurred())
+					Ω(doc.Code).Should(Equal(goa.ErrorID(goa.ErrBodyTooLarge)))
+				})
+
+				Context("sent in chunks", func() {
+					BeforeEach(func() {
+						r.ContentLength = -1
+					})
+
+					It("responds with 413", func() {
+						tw := rw.(*TestResponseWriter)
+						Ω(tw.Status).Should(Equal(413))
+					})
+				})
+
+				Context("and a service-wide limit", func() {
+					BeforeEach(func() {
+						maxBodySize = 0
+						s.SetMaxBodySize(5)
+					})
+
+					It("responds with 413", func() {
+						tw := rw.(*TestResponseWriter)
+						Ω(tw.Status).Should(Equal(413))
+					})
+
+					Context("overridden by the action", func() {
+						BeforeEach(func() {
+							maxBodySize = 1024
+						})
+
+						It("loads the payload", func() {
+							Ω(ctx.Payload()).Should(Equal(map[string]interface{}{"foo": "bar"}))
+						})
+					})
+				})
+			})
+
+			Context("with a raw body", func() {
+				var body []byte
+				var readErr error
+
+				BeforeEach(func() {
+					var err error
+					r, err = http.NewRequest("PUT", "/foo", strings.NewReader("some binary data"))
+					Ω(err).ShouldNot(HaveOccurred())
+					r.ContentLength = -1
+					r.Header.Set("Content-Type", "application/octet-stream")
+					rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+					rawBody = true
+					handler = func(c *goa.Context) error {
+						ctx = c
+						body, readErr = ioutil.ReadAll(c.Body())
+						if readErr != nil {
+							return readErr
+						}
+						return c.Respond(respStatus, respContent)
+					}
+				})
+
+				It("does not decode the body", func() {
+					Ω(ctx.Payload()).Should(BeNil())
+					Ω(readErr).ShouldNot(HaveOccurred())
+					Ω(string(body)).Should(Equal("some binary data"))
+					Ω(rw.(*TestResponseWriter).Status).Should(Equal(respStatus))
+				})
+
+				Context("larger than the maximum size", func() {
+					BeforeEach(func() {
+						maxBodySize = 4
+					})
+
+					It("responds with 413 once the action reads past the limit", func() {
+						Ω(readErr).Should(HaveOccurred())
+						Ω(string(body)).Should(Equal("some"))
+						Ω(rw.(*TestResponseWriter).Status).Should(Equal(413))
+					})
+				})
+			})
+
+			Context("with a typed decode function", func() {
+				type payload struct {
+					Foo string `json:"foo"`
+				}
+
+				BeforeEach(func() {
+					var err error
+					r, err = http.NewRequest("POST", "/foo", strings.NewReader(`{"foo":"bar"}`))
+					Ω(err).ShouldNot(HaveOccurred())
+					r.Header.Set("Content-Type", "application/vnd.goa.example+json")
+					rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+					decode = func(dec goa.Decoder) (interface{}, error) {
+						var p payload
+						if err := dec.Decode(&p); err != nil {
+							return nil, err
+						}
+						if p.Foo != "bar" {
+							return nil, goa.NewBadRequestError(goa.InvalidEnumValueError("payload.Foo", p.Foo, []interface{}{"bar"}, nil))
+						}
+						return &p, nil
+					}
+				})
+
+				It("decodes the body into the typed payload", func() {
+					Ω(ctx.Payload()).Should(Equal(&payload{Foo: "bar"}))
+				})
+
+				Context("and an invalid body", func() {
+					BeforeEach(func() {
+						var err error
+						r, err = http.NewRequest("POST", "/foo", strings.NewReader(`{"foo":"baz"}`))
+						Ω(err).ShouldNot(HaveOccurred())
+						r.Header.Set("Content-Type", "application/json")
+					})
+
+					It("responds with 400", func() {
+						tw := rw.(*TestResponseWriter)
+						Ω(tw.Status).Should(Equal(400))
+						var doc goa.ErrorDocument
+						Ω(json.Unmarshal(tw.Body, &doc)).ShouldNot(HaveOccurred())
+						Ω(doc.Code).Should(Equal(goa.ErrorID(goa.ErrInvalidEnumValue)))
+					})
+				})
+
+				Context("and a body that is not JSON", func() {
+					BeforeEach(func() {
+						var err error
+						r, err = http.NewRequest("POST", "/foo", strings.NewReader("foo=bar"))
+						Ω(err).ShouldNot(HaveOccurred())
+						r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+						s.SetDecoder(goa.FormDecoderFactory, false, "application/x-www-form-urlencoded")
+					})
+
+					It("decodes the body generically", func() {
+						Ω(ctx.Payload()).Should(Equal(map[string]interface{}{"foo": "bar"}))
+					})
+				})
+			})
+
 			Context("with a handler that fails", func() {
 				errorHandlerCalled := false
 
@@ -157,6 +525,48 @@ var _ = Describe("Application", func() {
 					})
 				})
 
+				Context("by returning a response error", func() {
+					BeforeEach(func() {
+						errorHandlerCalled = false
+						rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+						handler = func(ctx *goa.Context) error {
+							return &goa.ResponseError{Kind: "NotThere", Status: 404, Actual: fmt.Errorf("boom")}
+						}
+					})
+
+					It("sends the response", func() {
+						Ω(errorHandlerCalled).Should(BeFalse())
+						tw := rw.(*TestResponseWriter)
+						Ω(tw.Status).Should(Equal(404))
+						Ω(tw.Header().Get("Content-Type")).Should(Equal(goa.ErrorMediaIdentifier))
+						var doc goa.ErrorDocument
+						Ω(json.Unmarshal(tw.Body, &doc)).ShouldNot(HaveOccurred())
+						Ω(doc.Kind).Should(Equal("NotThere"))
+						Ω(doc.Detail).Should(Equal("boom"))
+					})
+
+					Context("with a body", func() {
+						BeforeEach(func() {
+							handler = func(ctx *goa.Context) error {
+								return &goa.ResponseError{
+									Kind:      "Taken",
+									Status:    409,
+									MediaType: "application/vnd.goa.test+json",
+									Body:      map[string]interface{}{"name": "foo"},
+								}
+							}
+						})
+
+						It("sends the body", func() {
+							Ω(errorHandlerCalled).Should(BeFalse())
+							tw := rw.(*TestResponseWriter)
+							Ω(tw.Status).Should(Equal(409))
+							Ω(tw.Header().Get("Content-Type")).Should(Equal("application/vnd.goa.test+json; charset=utf-8"))
+							Ω(string(tw.Body)).Should(MatchJSON(`{"name":"foo"}`))
+						})
+					})
+				})
+
 				Context("by not handling the request", func() {
 					BeforeEach(func() {
 						handler = func(ctx *goa.Context) error {
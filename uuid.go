@@ -0,0 +1,58 @@
+package goa
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+// UUID is a RFC4122 universally unique identifier. The code generated for attributes of type
+// design.UUID uses UUID values. UUID implements encoding.TextMarshaler and
+// encoding.TextUnmarshaler so that UUIDs are serialized using their canonical string
+// representation, e.g. "6ba7b810-9dad-11d1-80b4-00c04fd430c8".
+type UUID [16]byte
+
+// NewUUID returns a random (version 4) UUID.
+func NewUUID() UUID {
+	var u UUID
+	if _, err := rand.Read(u[:]); err != nil {
+		panic(fmt.Sprintf("goa: failed to generate UUID: %s", err)) // bug
+	}
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+	return u
+}
+
+// UUIDFromString parses the canonical string representation of a UUID.
+func UUIDFromString(s string) (UUID, error) {
+	var u UUID
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, fmt.Errorf("invalid UUID %#v", s)
+	}
+	b, err := hex.DecodeString(s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:])
+	if err != nil {
+		return u, fmt.Errorf("invalid UUID %#v", s)
+	}
+	copy(u[:], b)
+	return u, nil
+}
+
+// String returns the canonical string representation of the UUID.
+func (u UUID) String() string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
+
+// MarshalText returns the canonical string representation of the UUID.
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText parses the canonical string representation of a UUID.
+func (u *UUID) UnmarshalText(text []byte) error {
+	id, err := UUIDFromString(string(text))
+	if err != nil {
+		return err
+	}
+	*u = id
+	return nil
+}
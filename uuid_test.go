@@ -0,0 +1,42 @@
+package goa_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/raphael/goa"
+)
+
+var _ = Describe("UUID", func() {
+	const canonical = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
+	It("parses and prints the canonical representation", func() {
+		u, err := goa.UUIDFromString(canonical)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(u.String()).Should(Equal(canonical))
+	})
+
+	It("rejects invalid representations", func() {
+		_, err := goa.UUIDFromString("6ba7b810-9dad-11d1-80b4")
+		Ω(err).Should(HaveOccurred())
+		_, err = goa.UUIDFromString("6ba7b810-9dad-11d1-80b4-00c04fd430zz")
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("generates version 4 UUIDs", func() {
+		u := goa.NewUUID()
+		Ω(u.String()[14]).Should(Equal(byte('4')))
+		Ω(goa.NewUUID()).ShouldNot(Equal(u))
+	})
+
+	It("is serialized as a JSON string", func() {
+		u, _ := goa.UUIDFromString(canonical)
+		b, err := json.Marshal(map[string]interface{}{"id": u})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`{"id":"` + canonical + `"}`))
+		var v struct{ ID goa.UUID }
+		Ω(json.Unmarshal(b, &v)).Should(Succeed())
+		Ω(v.ID).Should(Equal(u))
+	})
+})
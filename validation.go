@@ -2,10 +2,14 @@ package goa
 
 import (
 	"fmt"
+	"math"
 	"net"
 	"net/mail"
 	"net/url"
+	"reflect"
 	"regexp"
+	"sort"
+	"sync"
 	"time"
 )
 
@@ -41,6 +45,10 @@ const (
 	FormatRegexp = "regexp"
 )
 
+// FormatValidator is the type of the functions that validate string values against a custom
+// format. It returns nil if the value conforms to the format, an error otherwise.
+type FormatValidator func(val string) error
+
 var (
 	// Regular expression used to validate RFC1035 hostnames*/
 	hostnameRegex = regexp.MustCompile(`^[[:alnum:]][[:alnum:]\-]{0,61}[[:alnum:]]|[[:alpha:]]$`)
@@ -49,6 +57,70 @@ var (
 	ipv4Regex = regexp.MustCompile(`^(?:[0-9]{1,3}\.){3}[0-9]{1,3}$`)
 )
 
+// customFormats records the formats registered with RegisterFormat, it is safe for concurrent use.
+var customFormats = struct {
+	sync.RWMutex
+	m map[Format]FormatValidator
+}{m: make(map[Format]FormatValidator)}
+
+// RegisterFormat registers a custom format so that ValidateFormat validates values of format f
+// using validator. Custom formats make it possible to use domain specific formats such as "semver"
+// or "e164" in the Format DSL.
+// The design package must register the format for the DSL to accept it and the application must
+// also register it - typically by calling a function shared with the design package - as the code
+// generated by goagen validates custom formats by calling ValidateFormat. Registering a format
+// that is already registered replaces its validator. RegisterFormat panics if f is empty, if f is
+// one of the built-in formats or if validator is nil.
+func RegisterFormat(f Format, validator FormatValidator) {
+	if f == "" {
+		panic("goa: format name cannot be empty")
+	}
+	if isBuiltinFormat(f) {
+		panic(fmt.Sprintf("goa: cannot register built-in format %#v", string(f)))
+	}
+	if validator == nil {
+		panic(fmt.Sprintf("goa: nil validator for format %#v", string(f)))
+	}
+	customFormats.Lock()
+	customFormats.m[f] = validator
+	customFormats.Unlock()
+}
+
+// LookupFormat returns the validator registered for the custom format f with RegisterFormat, nil
+// if there is none.
+func LookupFormat(f Format) FormatValidator {
+	customFormats.RLock()
+	defer customFormats.RUnlock()
+	return customFormats.m[f]
+}
+
+// RegisteredFormats returns the names of the custom formats registered with RegisterFormat in
+// lexicographical order.
+func RegisteredFormats() []Format {
+	customFormats.RLock()
+	names := make([]string, 0, len(customFormats.m))
+	for f := range customFormats.m {
+		names = append(names, string(f))
+	}
+	customFormats.RUnlock()
+	sort.Strings(names)
+	res := make([]Format, len(names))
+	for i, n := range names {
+		res[i] = Format(n)
+	}
+	return res
+}
+
+// isBuiltinFormat returns true if f is one of the formats validated by goa out of the box.
+func isBuiltinFormat(f Format) bool {
+	switch f {
+	case FormatDateTime, FormatEmail, FormatHostname, FormatIPv4, FormatIPv6, FormatURI,
+		FormatMAC, FormatCIDR, FormatRegexp:
+		return true
+	}
+	return false
+}
+
 // ValidateFormat validates a string against a standard format.
 // It returns nil if the string conforms to the format, an error otherwise.
 // The format specification follows the json schema draft 4 validation extension.
@@ -62,55 +134,168 @@ var (
 // - "mac": IEEE 802 MAC-48, EUI-48 or EUI-64 MAC address value
 // - "cidr": RFC4632 and RFC4291 CIDR notation IP address value
 // - "regexp": Regular expression syntax accepted by RE2
+// Other formats are validated using the validator registered with RegisterFormat.
+// The code generated by goagen calls the validator of each built-in format (e.g. ValidateEmail)
+// directly and calls ValidateFormat for custom formats.
 func ValidateFormat(f Format, val string) error {
-	var err error
 	switch f {
 	case FormatDateTime:
-		_, err = time.Parse(time.RFC3339, val)
+		return ValidateDateTime(val)
 	case FormatEmail:
-		_, err = mail.ParseAddress(val)
+		return ValidateEmail(val)
 	case FormatHostname:
-		if !hostnameRegex.MatchString(val) {
-			err = fmt.Errorf("hostname value '%s' does not match %s",
-				val, hostnameRegex.String())
-		}
-	case FormatIPv4, FormatIPv6:
-		ip := net.ParseIP(val)
-		if ip == nil {
-			err = fmt.Errorf("\"%s\" is an invalid %s value", val, f)
-		}
-		if f == "ipv4" {
-			if !ipv4Regex.MatchString(val) {
-				err = fmt.Errorf("\"%s\" is an invalid ipv4 value", val)
-			}
-		}
+		return ValidateHostname(val)
+	case FormatIPv4:
+		return ValidateIPv4(val)
+	case FormatIPv6:
+		return ValidateIPv6(val)
 	case FormatURI:
-		_, err = url.ParseRequestURI(val)
+		return ValidateURI(val)
 	case FormatMAC:
-		_, err = net.ParseMAC(val)
+		return ValidateMAC(val)
 	case FormatCIDR:
-		_, _, err = net.ParseCIDR(val)
+		return ValidateCIDR(val)
 	case FormatRegexp:
-		_, err = regexp.Compile(val)
+		return ValidateRegexp(val)
 	default:
+		if validator := LookupFormat(f); validator != nil {
+			return formatError(f, validator(val))
+		}
 		return fmt.Errorf("unknown format %#v", f)
 	}
-	if err != nil {
-		return fmt.Errorf("invalid %s value, %s", f, err)
+}
+
+// ValidateDateTime validates RFC3339 date time values, see ValidateFormat.
+func ValidateDateTime(val string) error {
+	_, err := time.Parse(time.RFC3339, val)
+	return formatError(FormatDateTime, err)
+}
+
+// ValidateEmail validates RFC5322 email addresses, see ValidateFormat.
+func ValidateEmail(val string) error {
+	_, err := mail.ParseAddress(val)
+	return formatError(FormatEmail, err)
+}
+
+// ValidateHostname validates RFC1035 Internet host names, see ValidateFormat.
+func ValidateHostname(val string) error {
+	if !hostnameRegex.MatchString(val) {
+		return formatError(FormatHostname, fmt.Errorf("hostname value '%s' does not match %s",
+			val, hostnameRegex.String()))
 	}
 	return nil
 }
 
-// knownPatterns records the compiled patterns.
-var knownPatterns = make(map[string]*regexp.Regexp)
+// ValidateIPv4 validates RFC2373 IPv4 address values, see ValidateFormat.
+func ValidateIPv4(val string) error {
+	if net.ParseIP(val) == nil || !ipv4Regex.MatchString(val) {
+		return formatError(FormatIPv4, fmt.Errorf("\"%s\" is an invalid ipv4 value", val))
+	}
+	return nil
+}
+
+// ValidateIPv6 validates RFC2373 IPv6 address values, see ValidateFormat.
+func ValidateIPv6(val string) error {
+	if net.ParseIP(val) == nil {
+		return formatError(FormatIPv6, fmt.Errorf("\"%s\" is an invalid ipv6 value", val))
+	}
+	return nil
+}
+
+// ValidateURI validates RFC3986 URI values, see ValidateFormat.
+func ValidateURI(val string) error {
+	_, err := url.ParseRequestURI(val)
+	return formatError(FormatURI, err)
+}
 
-// ValidatePattern returns an error if val does not match the regular expression p.
-// It makes an effort to minimize the number of times the regular expression needs to be compiled.
+// ValidateMAC validates IEEE 802 MAC-48, EUI-48 or EUI-64 MAC address values, see
+// ValidateFormat.
+func ValidateMAC(val string) error {
+	_, err := net.ParseMAC(val)
+	return formatError(FormatMAC, err)
+}
+
+// ValidateCIDR validates RFC4632 and RFC4291 CIDR notation IP address values, see
+// ValidateFormat.
+func ValidateCIDR(val string) error {
+	_, _, err := net.ParseCIDR(val)
+	return formatError(FormatCIDR, err)
+}
+
+// ValidateRegexp validates regular expressions using the syntax accepted by RE2, see
+// ValidateFormat.
+func ValidateRegexp(val string) error {
+	_, err := regexp.Compile(val)
+	return formatError(FormatRegexp, err)
+}
+
+// formatError wraps the error returned when validating a value against the format f.
+func formatError(f Format, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("invalid %s value, %s", f, err)
+}
+
+// knownPatterns records the compiled patterns, it is safe for concurrent use.
+var knownPatterns = struct {
+	sync.RWMutex
+	m map[string]*regexp.Regexp
+}{m: make(map[string]*regexp.Regexp)}
+
+// ValidatePattern returns true if val matches the regular expression p.
+// It makes an effort to minimize the number of times the regular expression needs to be compiled
+// and may be called concurrently. The code generated by goagen does not call ValidatePattern, it
+// compiles the patterns that appear in the design once when the generated package is initialized.
 func ValidatePattern(p string, val string) bool {
-	r, ok := knownPatterns[p]
+	knownPatterns.RLock()
+	r, ok := knownPatterns.m[p]
+	knownPatterns.RUnlock()
 	if !ok {
 		r = regexp.MustCompile(p) // DSL validation makes sure regexp is valid
-		knownPatterns[p] = r
+		knownPatterns.Lock()
+		knownPatterns.m[p] = r
+		knownPatterns.Unlock()
 	}
 	return r.MatchString(val)
 }
+
+// IsMultipleOf returns true if val is a multiple of divisor. The comparison tolerates the rounding
+// errors inherent to floating point arithmetic so that for example 0.3 is a multiple of 0.1.
+func IsMultipleOf(val, divisor float64) bool {
+	if divisor == 0 {
+		return false
+	}
+	q := val / divisor
+	return math.Abs(q-math.Floor(q+0.5)) < 1e-9
+}
+
+// HasUniqueItems returns true if the elements of the array or slice val are all different.
+// Elements that are pointers, interfaces, slices or maps are compared using reflect.DeepEqual.
+func HasUniqueItems(val interface{}) bool {
+	v := reflect.ValueOf(val)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return true
+	}
+	n := v.Len()
+	switch v.Type().Elem().Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map, reflect.Struct, reflect.Array:
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				if reflect.DeepEqual(v.Index(i).Interface(), v.Index(j).Interface()) {
+					return false
+				}
+			}
+		}
+	default:
+		seen := make(map[interface{}]struct{}, n)
+		for i := 0; i < n; i++ {
+			e := v.Index(i).Interface()
+			if _, ok := seen[e]; ok {
+				return false
+			}
+			seen[e] = struct{}{}
+		}
+	}
+	return true
+}
@@ -1,6 +1,9 @@
 package goa_test
 
 import (
+	"fmt"
+	"sync"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/raphael/goa"
@@ -262,3 +265,127 @@ var _ = Describe("ValidateFormat", func() {
 
 	})
 })
+
+var _ = Describe("ValidatePattern", func() {
+	It("matches values", func() {
+		Ω(goa.ValidatePattern("^goa$", "goa")).Should(BeTrue())
+		Ω(goa.ValidatePattern("^goa$", "goagen")).Should(BeFalse())
+	})
+
+	It("is safe for concurrent use", func() {
+		const workers = 8
+		var wg sync.WaitGroup
+		failed := make(chan string, workers*50)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					p := fmt.Sprintf("^v%d$", j)
+					if !goa.ValidatePattern(p, fmt.Sprintf("v%d", j)) {
+						failed <- p
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(failed)
+		Ω(failed).Should(BeEmpty())
+	})
+})
+
+var _ = Describe("format validators", func() {
+	It("are safe for concurrent use", func() {
+		const workers = 8
+		var wg sync.WaitGroup
+		errs := make(chan error, workers*4)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				errs <- goa.ValidateHostname("goa.design")
+				errs <- goa.ValidateIPv4("192.168.0.1")
+				errs <- goa.ValidateFormat(goa.FormatEmail, "raphael@goa.design")
+				errs <- goa.ValidateFormat(goa.FormatDateTime, "2015-10-26T08:31:23Z")
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			Ω(err).ShouldNot(HaveOccurred())
+		}
+	})
+
+	It("report the format in errors", func() {
+		err := goa.ValidateIPv4("192-168.0.1")
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(HavePrefix("invalid ipv4 value"))
+	})
+})
+
+var _ = Describe("IsMultipleOf", func() {
+	It("accepts multiples", func() {
+		Ω(goa.IsMultipleOf(10, 5)).Should(BeTrue())
+		Ω(goa.IsMultipleOf(-10, 5)).Should(BeTrue())
+		Ω(goa.IsMultipleOf(0, 5)).Should(BeTrue())
+		Ω(goa.IsMultipleOf(0.3, 0.1)).Should(BeTrue())
+	})
+
+	It("rejects other values", func() {
+		Ω(goa.IsMultipleOf(11, 5)).Should(BeFalse())
+		Ω(goa.IsMultipleOf(0.35, 0.1)).Should(BeFalse())
+		Ω(goa.IsMultipleOf(1, 0)).Should(BeFalse())
+	})
+})
+
+var _ = Describe("HasUniqueItems", func() {
+	It("checks slices of primitive values", func() {
+		Ω(goa.HasUniqueItems([]string{"a", "b"})).Should(BeTrue())
+		Ω(goa.HasUniqueItems([]string{"a", "b", "a"})).Should(BeFalse())
+		Ω(goa.HasUniqueItems([]int(nil))).Should(BeTrue())
+	})
+
+	It("compares pointed and dynamic values", func() {
+		a, b := "a", "a"
+		Ω(goa.HasUniqueItems([]*string{&a, &b})).Should(BeFalse())
+		Ω(goa.HasUniqueItems([]interface{}{[]int{1}, []int{2}})).Should(BeTrue())
+		Ω(goa.HasUniqueItems([]interface{}{map[string]int{"a": 1}, map[string]int{"a": 1}})).Should(BeFalse())
+	})
+})
+
+var _ = Describe("RegisterFormat", func() {
+	const semver goa.Format = "test-semver"
+
+	BeforeEach(func() {
+		goa.RegisterFormat(semver, func(val string) error {
+			if _, err := fmt.Sscanf(val, "%d.%d.%d", new(int), new(int), new(int)); err != nil {
+				return fmt.Errorf("%#v is not a semantic version", val)
+			}
+			return nil
+		})
+	})
+
+	It("makes ValidateFormat use the registered validator", func() {
+		Ω(goa.ValidateFormat(semver, "1.2.3")).ShouldNot(HaveOccurred())
+		err := goa.ValidateFormat(semver, "latest")
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(Equal(`invalid test-semver value, "latest" is not a semantic version`))
+	})
+
+	It("lists the custom formats", func() {
+		Ω(goa.LookupFormat(semver)).ShouldNot(BeNil())
+		Ω(goa.RegisteredFormats()).Should(ContainElement(semver))
+	})
+
+	It("does not override built-in formats", func() {
+		Ω(func() { goa.RegisterFormat(goa.FormatEmail, func(string) error { return nil }) }).Should(Panic())
+		Ω(func() { goa.RegisterFormat("test-nil", nil) }).Should(Panic())
+		Ω(goa.LookupFormat(goa.FormatEmail)).Should(BeNil())
+	})
+
+	It("rejects values of unknown formats", func() {
+		Ω(goa.ValidateFormat("test-unknown", "foo")).Should(HaveOccurred())
+	})
+})
@@ -0,0 +1,147 @@
+package goa
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	log "gopkg.in/inconshreveable/log15.v2"
+)
+
+type (
+	// SelectVersionFunc computes the name of the API version targeted by a request, it returns
+	// the empty string if the request does not target a specific version.
+	SelectVersionFunc func(*http.Request) string
+
+	// version is the service used to mount the controllers of an API version. It shares the
+	// middleware, encoders, decoders and error handler of its application but has its own router.
+	version struct {
+		log.Logger                      // Version logger
+		*Application                    // Parent application
+		name         string             // Version name
+		router       *httprouter.Router // Version router
+	}
+)
+
+// PathSelectVersionFunc returns a SelectVersionFunc that extracts the version name from the
+// request path. The version name is the path segment that follows the given prefix, for example
+// with the prefix "/api/v" the version of requests made to "/api/v1/bottles" is "1".
+func PathSelectVersionFunc(prefix string) SelectVersionFunc {
+	return func(req *http.Request) string {
+		p := req.URL.Path
+		if !strings.HasPrefix(p, prefix) {
+			return ""
+		}
+		p = p[len(prefix):]
+		if i := strings.Index(p, "/"); i > -1 {
+			p = p[:i]
+		}
+		return p
+	}
+}
+
+// HeaderSelectVersionFunc returns a SelectVersionFunc that reads the version name from the request
+// header with the given name.
+func HeaderSelectVersionFunc(header string) SelectVersionFunc {
+	return func(req *http.Request) string {
+		return req.Header.Get(header)
+	}
+}
+
+// QuerySelectVersionFunc returns a SelectVersionFunc that reads the version name from the query
+// string parameter with the given name.
+func QuerySelectVersionFunc(param string) SelectVersionFunc {
+	return func(req *http.Request) string {
+		return req.URL.Query().Get(param)
+	}
+}
+
+// CombineSelectVersionFunc returns a SelectVersionFunc that calls the given functions in order and
+// returns the first non empty version name.
+func CombineSelectVersionFunc(funcs ...SelectVersionFunc) SelectVersionFunc {
+	return func(req *http.Request) string {
+		for _, f := range funcs {
+			if v := f(req); v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+}
+
+// Version returns the service used to mount the controllers of the API version with the given
+// name, creating it if needed. The application ServeHTTP method dispatches the requests that
+// target the version to the version router first. The function given to SetVersionSelector
+// computes the version targeted by each request. This method is mainly intended for use by
+// generated code.
+func (app *Application) Version(name string) Service {
+	if app.versions == nil {
+		app.versions = make(map[string]*version)
+	}
+	v, ok := app.versions[name]
+	if !ok {
+		router := httprouter.New()
+		// Requests that match no version route are handled by the application router so that
+		// unversioned routes and the application NotFound handler apply.
+		router.NotFound = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			app.Router.ServeHTTP(w, req)
+		})
+		v = &version{
+			Logger:      app.New("version", name),
+			Application: app,
+			name:        name,
+			router:      router,
+		}
+		app.versions[name] = v
+	}
+	return v
+}
+
+// SetVersionSelector sets the function used to select the API version targeted by the requests.
+func (app *Application) SetVersionSelector(f SelectVersionFunc) {
+	app.selectVersion = f
+}
+
+// ServeHTTP implements http.Handler. Requests that target an API version are handled by the
+// version route that matches them if any, by the application route that matches them otherwise.
+// The version router responds to the requests that match no route at all so that it may respond
+// with 405 for paths it defines, its NotFound handler delegates to the application router.
+// Requests that do not target a version are handled by the application router.
+func (app *Application) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if app.selectVersion == nil || len(app.versions) == 0 {
+		app.Router.ServeHTTP(w, req)
+		return
+	}
+	v, ok := app.versions[app.selectVersion(req)]
+	if !ok {
+		app.Router.ServeHTTP(w, req)
+		return
+	}
+	if h, params, _ := v.router.Lookup(req.Method, req.URL.Path); h != nil {
+		h(w, req, params)
+		return
+	}
+	if h, params, _ := app.Router.Lookup(req.Method, req.URL.Path); h != nil {
+		h(w, req, params)
+		return
+	}
+	v.router.ServeHTTP(w, req)
+}
+
+// ServeFiles serves files from the version router.
+func (v *version) ServeFiles(path string, root http.FileSystem) {
+	v.router.ServeFiles(path, root)
+}
+
+// HTTPHandler returns the version router.
+func (v *version) HTTPHandler() http.Handler {
+	return v.router
+}
+
+// NewController returns a controller for the given resource of the version.
+func (v *version) NewController(resName string) Controller {
+	return &ApplicationController{
+		Logger: v.New("ctrl", resName),
+		app:    v.Application,
+	}
+}
@@ -0,0 +1,177 @@
+package goa_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/julienschmidt/httprouter"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/raphael/goa"
+)
+
+var _ = Describe("SelectVersionFunc", func() {
+	var req *http.Request
+
+	BeforeEach(func() {
+		var err error
+		req, err = http.NewRequest("GET", "/api/v1.0/bottles?version=2.0", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		req.Header.Set("X-API-Version", "3.0")
+	})
+
+	It("selects the version from the path", func() {
+		Ω(goa.PathSelectVersionFunc("/api/v")(req)).Should(Equal("1.0"))
+		Ω(goa.PathSelectVersionFunc("/v")(req)).Should(BeEmpty())
+	})
+
+	It("selects the version from a header", func() {
+		Ω(goa.HeaderSelectVersionFunc("X-API-Version")(req)).Should(Equal("3.0"))
+	})
+
+	It("selects the version from the query string", func() {
+		Ω(goa.QuerySelectVersionFunc("version")(req)).Should(Equal("2.0"))
+	})
+
+	It("combines selectors", func() {
+		f := goa.CombineSelectVersionFunc(
+			goa.PathSelectVersionFunc("/v"),
+			goa.QuerySelectVersionFunc("version"),
+			goa.HeaderSelectVersionFunc("X-API-Version"),
+		)
+		Ω(f(req)).Should(Equal("2.0"))
+	})
+})
+
+var _ = Describe("Version", func() {
+	var service goa.Service
+	var rw *httptest.ResponseRecorder
+	var req *http.Request
+
+	mountRoute := func(s goa.Service, method, path, body string) {
+		ctrl := s.NewController("bottle")
+		h := func(ctx *goa.Context) error {
+			return ctx.Respond(200, []byte(body))
+		}
+		s.HTTPHandler().(*httprouter.Router).Handle(method, path, ctrl.NewHTTPRouterHandle("list", h))
+	}
+
+	mount := func(s goa.Service, body string) {
+		mountRoute(s, "GET", "/bottles", body)
+	}
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		service.SetVersionSelector(goa.HeaderSelectVersionFunc("X-API-Version"))
+		mount(service.Version("1.0"), "v1")
+		mount(service.Version("2.0"), "v2")
+		rw = httptest.NewRecorder()
+		var err error
+		req, err = http.NewRequest("GET", "/bottles", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		service.ServeHTTP(rw, req)
+	})
+
+	It("returns the same service for a given version", func() {
+		Ω(service.Version("1.0")).Should(BeIdenticalTo(service.Version("1.0")))
+	})
+
+	Context("with a request targeting a version", func() {
+		BeforeEach(func() {
+			req.Header.Set("X-API-Version", "2.0")
+		})
+
+		It("dispatches the request to the version router", func() {
+			Ω(rw.Code).Should(Equal(200))
+			Ω(rw.Body.String()).Should(Equal("v2"))
+		})
+	})
+
+	Context("with a request targeting an unknown version", func() {
+		BeforeEach(func() {
+			req.Header.Set("X-API-Version", "3.0")
+		})
+
+		It("responds with 404", func() {
+			Ω(rw.Code).Should(Equal(404))
+		})
+	})
+
+	Context("with an unversioned route on the same path", func() {
+		BeforeEach(func() {
+			req.Header.Set("X-API-Version", "1.0")
+			mount(service, "unversioned")
+		})
+
+		It("uses the version route", func() {
+			Ω(rw.Body.String()).Should(Equal("v1"))
+		})
+
+		Context("and a request that does not target a version", func() {
+			BeforeEach(func() {
+				req.Header.Del("X-API-Version")
+			})
+
+			It("uses the unversioned route", func() {
+				Ω(rw.Body.String()).Should(Equal("unversioned"))
+			})
+		})
+	})
+
+	Context("with unversioned routes the version does not define", func() {
+		BeforeEach(func() {
+			req.Header.Set("X-API-Version", "1.0")
+			mountRoute(service, "POST", "/bottles", "create")
+			mountRoute(service, "GET", "/accounts", "accounts")
+		})
+
+		Context("using another method", func() {
+			BeforeEach(func() {
+				req.Method = "POST"
+			})
+
+			It("uses the unversioned route", func() {
+				Ω(rw.Body.String()).Should(Equal("create"))
+			})
+		})
+
+		Context("using another path", func() {
+			BeforeEach(func() {
+				req.URL.Path = "/accounts"
+			})
+
+			It("uses the unversioned route", func() {
+				Ω(rw.Body.String()).Should(Equal("accounts"))
+			})
+		})
+	})
+
+	Context("with a request using a method the version route does not handle", func() {
+		BeforeEach(func() {
+			req.Header.Set("X-API-Version", "1.0")
+			req.Method = "DELETE"
+		})
+
+		It("responds with 405", func() {
+			Ω(rw.Code).Should(Equal(405))
+		})
+	})
+
+	Context("with a NotFound handler set on the application router", func() {
+		BeforeEach(func() {
+			req.Header.Set("X-API-Version", "1.0")
+			req.URL.Path = "/unknown"
+			service.HTTPHandler().(*httprouter.Router).NotFound = http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(418)
+				})
+		})
+
+		It("keeps using it", func() {
+			Ω(rw.Code).Should(Equal(418))
+		})
+	})
+})